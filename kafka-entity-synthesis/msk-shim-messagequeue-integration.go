@@ -1,8 +1,12 @@
 package msk
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,6 +14,218 @@ import (
 	"github.com/newrelic/infra-integrations-sdk/integration"
 )
 
+// EventSink is implemented by anything submitEvent can hand a finished
+// MessageQueueSample event to: the integration SDK's entity model, the
+// batched Event API, or an OTLP metrics receiver. Selection is driven by
+// MSK_EMIT_BACKEND (comma-separated "infra", "events", "otlp"), and more
+// than one sink can be active at once via multiSink for side-by-side
+// migration between backends.
+type EventSink interface {
+	Submit(event map[string]interface{}) error
+	Flush() error
+}
+
+// infraSDKSink submits events as infra-integrations SDK entity metrics:
+// numeric values become GAUGE metrics, strings become ATTRIBUTEs on the
+// same metric set. Earlier behavior silently dropped strings into
+// inventory instead, which isn't queryable the way metric-set attributes
+// are -- this sink fixes that.
+type infraSDKSink struct {
+	integration *integration.Integration
+}
+
+func newInfraSDKSink(i *integration.Integration) *infraSDKSink {
+	return &infraSDKSink{integration: i}
+}
+
+func (s *infraSDKSink) Submit(event map[string]interface{}) error {
+	entityName, _ := event["entity.name"].(string)
+	if entityName == "" {
+		return fmt.Errorf("event missing entity.name")
+	}
+
+	entity := s.integration.NewEntity(entityName, "msk")
+	ms := entity.NewMetricSet(fmt.Sprintf("%v", event["eventType"]))
+
+	for key, value := range event {
+		if key == "eventType" || key == "entity.name" {
+			continue
+		}
+		switch v := value.(type) {
+		case float64:
+			ms.SetMetric(key, v, metric.GAUGE)
+		case int:
+			ms.SetMetric(key, float64(v), metric.GAUGE)
+		case int64:
+			ms.SetMetric(key, float64(v), metric.GAUGE)
+		case string:
+			ms.SetMetric(key, v, metric.ATTRIBUTE)
+		default:
+			ms.SetMetric(key, fmt.Sprintf("%v", v), metric.ATTRIBUTE)
+		}
+	}
+
+	return nil
+}
+
+// Flush is a no-op: the infra-integrations SDK publishes entities as they
+// are created, with no separate batch-flush step of its own.
+func (s *infraSDKSink) Flush() error {
+	return nil
+}
+
+// eventAPISink submits events through the file's BatchProcessor, which
+// batches up to maxBatch events (or flushes 30 seconds after the first
+// buffered event, whichever comes first) before POSTing them to the Event API.
+type eventAPISink struct {
+	processor *BatchProcessor
+}
+
+func newEventAPISink(processor *BatchProcessor) *eventAPISink {
+	return &eventAPISink{processor: processor}
+}
+
+func (s *eventAPISink) Submit(event map[string]interface{}) error {
+	return s.processor.Add(event)
+}
+
+func (s *eventAPISink) Flush() error {
+	return s.processor.Flush()
+}
+
+// otlpSink maps submitted events to OTLP metrics and POSTs them to an
+// OTLP/HTTP metrics receiver, tagging every data point with the resource
+// attributes the OpenTelemetry messaging semantic conventions expect:
+// cloud.provider=aws, cloud.region, messaging.system=kafka, and
+// aws.msk.cluster.name.
+type otlpSink struct {
+	endpoint    string
+	clusterName string
+	region      string
+	httpClient  *http.Client
+
+	points []otlpDataPoint
+}
+
+type otlpDataPoint struct {
+	Name  string
+	Value float64
+}
+
+func newOTLPSink(endpoint, clusterName, region string) *otlpSink {
+	return &otlpSink{
+		endpoint:    endpoint,
+		clusterName: clusterName,
+		region:      region,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *otlpSink) Submit(event map[string]interface{}) error {
+	eventType, _ := event["eventType"].(string)
+	if eventType == "" {
+		return fmt.Errorf("event missing eventType")
+	}
+
+	for key, value := range event {
+		if key == "eventType" || key == "entity.name" {
+			continue
+		}
+		if floatVal, ok := getFloat64(value); ok {
+			s.points = append(s.points, otlpDataPoint{Name: eventType + "." + key, Value: floatVal})
+		}
+	}
+
+	return nil
+}
+
+func (s *otlpSink) Flush() error {
+	if len(s.points) == 0 {
+		return nil
+	}
+	points := s.points
+	s.points = nil
+
+	resourceAttrs := []map[string]interface{}{
+		{"key": "cloud.provider", "value": map[string]interface{}{"stringValue": "aws"}},
+		{"key": "cloud.region", "value": map[string]interface{}{"stringValue": s.region}},
+		{"key": "messaging.system", "value": map[string]interface{}{"stringValue": "kafka"}},
+		{"key": "aws.msk.cluster.name", "value": map[string]interface{}{"stringValue": s.clusterName}},
+	}
+
+	timeUnixNano := strconv.FormatInt(time.Now().UnixNano(), 10)
+	metrics := make([]map[string]interface{}, 0, len(points))
+	for _, p := range points {
+		metrics = append(metrics, map[string]interface{}{
+			"name": p.Name,
+			"gauge": map[string]interface{}{
+				"dataPoints": []map[string]interface{}{
+					{"asDouble": p.Value, "timeUnixNano": timeUnixNano},
+				},
+			},
+		})
+	}
+
+	payload := map[string]interface{}{
+		"resourceMetrics": []map[string]interface{}{
+			{
+				"resource":     map[string]interface{}{"attributes": resourceAttrs},
+				"scopeMetrics": []map[string]interface{}{{"metrics": metrics}},
+			},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling OTLP payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending OTLP metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("OTLP endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// multiSink fans a single Submit/Flush call out to multiple EventSinks, so
+// a new backend can be verified side-by-side with the existing one before
+// cutting over.
+type multiSink struct {
+	sinks []EventSink
+}
+
+func (m *multiSink) Submit(event map[string]interface{}) error {
+	var firstErr error
+	for _, sink := range m.sinks {
+		if err := sink.Submit(event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *multiSink) Flush() error {
+	var firstErr error
+	for _, sink := range m.sinks {
+		if err := sink.Flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
 // MessageQueueTransformer handles transformation of MSK metrics to MessageQueueSample events
 type MessageQueueTransformer struct {
 	integration   *integration.Integration
@@ -17,9 +233,13 @@ type MessageQueueTransformer struct {
 	accountID     string
 	region        string
 	collectorName string
+	sink          EventSink
 }
 
-// NewMessageQueueTransformer creates a new transformer instance
+// NewMessageQueueTransformer creates a new transformer instance. The emit
+// backend(s) are selected by MSK_EMIT_BACKEND (comma-separated "infra",
+// "events", "otlp"; OTLP's endpoint comes from MSK_OTLP_ENDPOINT), defaulting
+// to "infra" alone so existing deployments keep their current behavior.
 func NewMessageQueueTransformer(i *integration.Integration, clusterName, accountID, region string) *MessageQueueTransformer {
 	return &MessageQueueTransformer{
 		integration:   i,
@@ -27,7 +247,32 @@ func NewMessageQueueTransformer(i *integration.Integration, clusterName, account
 		accountID:     accountID,
 		region:        region,
 		collectorName: "cloudwatch-metric-streams",
+		sink:          buildEventSink(i, clusterName, region),
+	}
+}
+
+// buildEventSink constructs the EventSink(s) MSK_EMIT_BACKEND names,
+// combining more than one backend with multiSink.
+func buildEventSink(i *integration.Integration, clusterName, region string) EventSink {
+	backends := strings.Split(os.Getenv("MSK_EMIT_BACKEND"), ",")
+	var sinks []EventSink
+	for _, backend := range backends {
+		switch strings.TrimSpace(backend) {
+		case "events":
+			sinks = append(sinks, newEventAPISink(NewBatchProcessor(nil, 100)))
+		case "otlp":
+			sinks = append(sinks, newOTLPSink(os.Getenv("MSK_OTLP_ENDPOINT"), clusterName, region))
+		case "infra", "":
+			sinks = append(sinks, newInfraSDKSink(i))
+		}
+	}
+	if len(sinks) == 0 {
+		sinks = append(sinks, newInfraSDKSink(i))
+	}
+	if len(sinks) == 1 {
+		return sinks[0]
 	}
+	return &multiSink{sinks: sinks}
 }
 
 // TransformClusterMetrics transforms cluster metrics to MessageQueueSample
@@ -184,33 +429,11 @@ func (t *MessageQueueTransformer) mapMetrics(event map[string]interface{}, metri
 	}
 }
 
-// submitEvent submits the event to New Relic
+// submitEvent hands event to whichever EventSink(s) MSK_EMIT_BACKEND
+// selected, rather than hardcoding a choice between the integration SDK and
+// a stubbed HTTP path.
 func (t *MessageQueueTransformer) submitEvent(event map[string]interface{}) error {
-	// Option 1: Use the integration SDK's event API
-	if t.integration != nil {
-		entity := t.integration.NewEntity(event["entity.name"].(string), "msk")
-		for key, value := range event {
-			if key != "eventType" && key != "timestamp" && key != "entity.name" {
-				switch v := value.(type) {
-				case float64:
-					entity.SetMetric(key, v, metric.GAUGE)
-				case int, int64:
-					entity.SetMetric(key, float64(v.(int)), metric.GAUGE)
-				case string:
-					entity.SetInventoryItem(key, "value", v)
-				default:
-					entity.SetInventoryItem(key, "value", fmt.Sprintf("%v", v))
-				}
-			}
-		}
-		return nil
-	}
-	
-	// Option 2: Direct Event API submission (implement based on your needs)
-	// This would involve making an HTTP POST to the New Relic Event API
-	// Example implementation would go here
-	
-	return nil
+	return t.sink.Submit(event)
 }
 
 // getFloat64 safely converts various numeric types to float64
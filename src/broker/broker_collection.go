@@ -81,6 +81,12 @@ func brokerWorker(brokerChan <-chan *connection.Broker, collectedTopics []string
 
 			collectBrokerMetrics(broker, collectedTopics, i, jmxConn)
 
+			if msk.GlobalMSKHook != nil && msk.GlobalMSKHook.IsEnabled() {
+				if admin, ok := connection.AdminClient(broker); ok {
+					collectVolumeMetrics(broker, admin)
+				}
+			}
+
 			if err := jmxConn.Close(); err != nil {
 				log.Error("Unable to close JMX connection for broker: '%s', error: %v", broker.Host, err)
 			}
@@ -142,6 +148,95 @@ func collectBrokerMetrics(b *connection.Broker, collectedTopics []string, i *int
 	if args.GlobalArgs.CollectTopicOffset {
 		gatherTopicOffset(b, topicSampleLookup, i, conn)
 	}
+
+	// Prefer the authoritative DescribeLogDirs (KIP-113) view of disk usage
+	// over JMX/filesystem-guessing when the broker exposes an admin client.
+	if admin, ok := connection.AdminClient(b); ok {
+		collectLogDirMetrics(b, admin, topicSampleLookup, i)
+	}
+}
+
+// collectVolumeMetrics emits one AwsMskVolumeSample per log directory the
+// broker reports, via DescribeLogDirs, so brokers with multiple EBS
+// volumes get per-volume storage health instead of a single aggregated
+// broker.logDirSize figure.
+func collectVolumeMetrics(b *connection.Broker, admin sarama.ClusterAdmin) {
+	collector := NewLogDirCollector(admin)
+	results, err := collector.Collect([]int32{int32(b.ID)})
+	if err != nil {
+		log.Error("Failed to collect volume metrics for broker %d via admin API: %s", b.ID, err)
+		return
+	}
+
+	dirs, ok := results[int32(b.ID)]
+	if !ok {
+		return
+	}
+
+	sizeByPath := make(map[string]int64)
+	for _, p := range dirs.Partitions {
+		sizeByPath[p.Path] += p.Size
+	}
+
+	for path, size := range sizeByPath {
+		data := msk.VolumeData{
+			BrokerID:   int32(b.ID),
+			LogDirPath: path,
+			BytesUsed:  size,
+		}
+		if err := msk.GlobalMSKHook.TransformVolumeData(data); err != nil {
+			log.Error("Failed to transform volume data for broker %d, path %s: %s", b.ID, path, err)
+		}
+	}
+}
+
+// collectLogDirMetrics populates broker.logDirSize/broker.logDirCount/
+// broker.offlineLogDirs on the broker's KafkaBrokerSample, and folds each
+// topic's reported partition sizes into topic.diskSize on
+// topicSampleLookup, using Sarama's DescribeLogDirs instead of the
+// filesystem-scanning DiskMountDetector.
+func collectLogDirMetrics(b *connection.Broker, admin sarama.ClusterAdmin, topicSampleLookup map[string]*metric.Set, i *integration.Integration) {
+	collector := NewLogDirCollector(admin)
+	results, err := collector.Collect([]int32{int32(b.ID)})
+	if err != nil {
+		log.Error("Failed to collect log directories for broker %d via admin API: %s", b.ID, err)
+		return
+	}
+
+	dirs, ok := results[int32(b.ID)]
+	if !ok {
+		return
+	}
+
+	entity, err := b.Entity(i)
+	if err != nil {
+		log.Error("Failed to get entity for broker: %s", err)
+		return
+	}
+	sample := entity.NewMetricSet("KafkaBrokerSample",
+		attribute.Attribute{Key: "displayName", Value: entity.Metadata.Name},
+		attribute.Attribute{Key: "entityName", Value: "broker:" + entity.Metadata.Name},
+		attribute.Attribute{Key: "clusterName", Value: args.GlobalArgs.ClusterName},
+	)
+	if err := sample.SetMetric("broker.logDirSize", dirs.TotalSize(), metric.GAUGE); err != nil {
+		log.Error("Unable to set broker.logDirSize for broker %d: %s", b.ID, err)
+	}
+	if err := sample.SetMetric("broker.logDirCount", float64(dirs.LogDirCount()), metric.GAUGE); err != nil {
+		log.Error("Unable to set broker.logDirCount for broker %d: %s", b.ID, err)
+	}
+	if err := sample.SetMetric("broker.offlineLogDirs", float64(len(dirs.OfflineDirs)), metric.GAUGE); err != nil {
+		log.Error("Unable to set broker.offlineLogDirs for broker %d: %s", b.ID, err)
+	}
+
+	for topic, size := range dirs.TopicSizes() {
+		topicSample, ok := topicSampleLookup[topic]
+		if !ok {
+			continue
+		}
+		if err := topicSample.SetMetric("topic.diskSize", size, metric.GAUGE); err != nil {
+			log.Error("Unable to set topic.diskSize for topic %s: %s", topic, err)
+		}
+	}
 }
 
 // For a given broker struct, collect and populate its entity with broker metrics
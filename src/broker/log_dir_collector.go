@@ -0,0 +1,114 @@
+package broker
+
+import (
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// LogDirInfo is one partition's storage footprint within a single log
+// directory, as reported by Sarama's DescribeLogDirs (KIP-113).
+type LogDirInfo struct {
+	Path       string
+	Topic      string
+	Partition  int32
+	Size       int64
+	OffsetLag  int64
+	IsTemp     bool
+}
+
+// BrokerLogDirs is the per-broker result of a DescribeLogDirs call: the
+// set of log directories it reported, plus any it flagged as offline.
+type BrokerLogDirs struct {
+	BrokerID     int32
+	Partitions   []LogDirInfo
+	OfflineDirs  []string
+}
+
+// LogDirCollector fetches authoritative log-directory and partition-size
+// information from the brokers themselves via Sarama's admin API, rather
+// than guessing at mount points by scanning the filesystem the collector
+// happens to be running on (which doesn't work once the collector no
+// longer has host access to the broker, as in MSK/containerized deploys).
+type LogDirCollector struct {
+	admin sarama.ClusterAdmin
+}
+
+// NewLogDirCollector wraps an already-connected ClusterAdmin.
+func NewLogDirCollector(admin sarama.ClusterAdmin) *LogDirCollector {
+	return &LogDirCollector{admin: admin}
+}
+
+// Collect calls DescribeLogDirs for the given broker IDs and returns each
+// broker's reported log directories, partition sizes, and any log
+// directories it reports as offline (failed disks).
+func (c *LogDirCollector) Collect(brokerIDs []int32) (map[int32]*BrokerLogDirs, error) {
+	result, err := c.admin.DescribeLogDirs(brokerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("describing log dirs for brokers %v: %w", brokerIDs, err)
+	}
+
+	out := make(map[int32]*BrokerLogDirs, len(result))
+	for brokerID, dirs := range result {
+		bld := &BrokerLogDirs{BrokerID: brokerID}
+
+		for _, dir := range dirs {
+			if dir.ErrorCode != 0 {
+				bld.OfflineDirs = append(bld.OfflineDirs, dir.Path)
+				continue
+			}
+
+			for _, topicDir := range dir.Topics {
+				for _, partDir := range topicDir.Partitions {
+					bld.Partitions = append(bld.Partitions, LogDirInfo{
+						Path:      dir.Path,
+						Topic:     topicDir.Topic,
+						Partition: partDir.PartitionID,
+						Size:      partDir.Size,
+						OffsetLag: partDir.OffsetLag,
+						IsTemp:    partDir.IsTemp,
+					})
+				}
+			}
+		}
+
+		out[brokerID] = bld
+		if len(bld.OfflineDirs) > 0 {
+			log.Warn("broker %d reported %d offline log director(ies): %v", brokerID, len(bld.OfflineDirs), bld.OfflineDirs)
+		}
+	}
+
+	return out, nil
+}
+
+// LogDirCount returns the number of distinct log directory paths this
+// broker holds partitions in.
+func (b *BrokerLogDirs) LogDirCount() int {
+	seen := make(map[string]bool)
+	for _, p := range b.Partitions {
+		seen[p.Path] = true
+	}
+	return len(seen)
+}
+
+// TotalSize sums the reported size across every partition this broker
+// holds, across all of its log directories.
+func (b *BrokerLogDirs) TotalSize() int64 {
+	var total int64
+	for _, p := range b.Partitions {
+		total += p.Size
+	}
+	return total
+}
+
+// TopicSizes sums partition sizes per topic, for feeding into
+// topicSampleLookup's topic.diskSize metric.
+func (b *BrokerLogDirs) TopicSizes() map[string]int64 {
+	sizes := make(map[string]int64)
+	for _, p := range b.Partitions {
+		sizes[p.Topic] += p.Size
+	}
+	return sizes
+}
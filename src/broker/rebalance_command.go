@@ -0,0 +1,72 @@
+package broker
+
+import (
+	"strconv"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/nri-kafka/src/reassignment"
+)
+
+// RunRebalancePlanCommand is the `rebalance-plan` subcommand entry point:
+// it collects log-dir storage signals and broker.rack config for every
+// broker, computes a storage-equalizing RebalancePlan, and either prints
+// it or (when apply is true) hands it straight to the reassignment
+// subsystem for submission.
+func RunRebalancePlanCommand(admin sarama.ClusterAdmin, brokerIDs []int32, opts RebalanceOptions, apply bool) (*RebalancePlan, error) {
+	collector := NewLogDirCollector(admin)
+	logDirs, err := collector.Collect(brokerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BrokerRack == nil {
+		opts.BrokerRack = resolveBrokerRacks(admin, brokerIDs)
+	}
+
+	plan := PlanRebalance(logDirs, opts)
+	log.Info("rebalance-plan: computed %d partition move(s) across %d broker(s)", len(plan.Partitions), len(brokerIDs))
+
+	if !apply {
+		return plan, nil
+	}
+
+	submitter := reassignment.NewSubmitter(admin, 0)
+	reassignPlan := &reassignment.Plan{Assignments: make([]reassignment.PartitionAssignment, len(plan.Partitions))}
+	for i, a := range plan.Partitions {
+		reassignPlan.Assignments[i] = reassignment.PartitionAssignment{
+			Topic:     a.Topic,
+			Partition: a.Partition,
+			Replicas:  a.Replicas,
+		}
+	}
+
+	if err := submitter.Submit(reassignPlan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// resolveBrokerRacks reads each broker's broker.rack config via
+// DescribeConfigs, the same call getBrokerConfig already makes for
+// inventory collection.
+func resolveBrokerRacks(admin sarama.ClusterAdmin, brokerIDs []int32) map[int32]string {
+	racks := make(map[int32]string)
+	for _, id := range brokerIDs {
+		configs, err := admin.DescribeConfig(sarama.ConfigResource{
+			Type: sarama.BrokerResource,
+			Name: strconv.Itoa(int(id)),
+		})
+		if err != nil {
+			log.Warn("rebalance-plan: failed to read broker.rack for broker %d: %v", id, err)
+			continue
+		}
+		for _, c := range configs {
+			if c.Name == "broker.rack" {
+				racks[id] = c.Value
+			}
+		}
+	}
+	return racks
+}
@@ -0,0 +1,222 @@
+package broker
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// RebalanceAssignment is one partition's planned replica set, in the
+// shape Kafka's kafka-reassign-partitions.sh tool expects.
+type RebalanceAssignment struct {
+	Topic     string  `json:"topic"`
+	Partition int32   `json:"partition"`
+	Replicas  []int32 `json:"replicas"`
+}
+
+// RebalancePlan is the JSON document a rebalance-plan run produces,
+// matching kafka-reassign-partitions.sh's --generate output format.
+type RebalancePlan struct {
+	Version      int                   `json:"version"`
+	Partitions   []RebalanceAssignment `json:"partitions"`
+}
+
+// RebalanceOptions tunes the greedy planner's stopping conditions and
+// rack-awareness constraints.
+type RebalanceOptions struct {
+	// StdDevThreshold stops the planner once the standard deviation of
+	// per-broker load (in bytes) falls at or below this value.
+	StdDevThreshold float64
+	// MaxMoves caps the number of partition moves the planner will plan,
+	// regardless of whether StdDevThreshold has been reached.
+	MaxMoves int
+	// BrokerRack maps broker ID to its broker.rack config value. When
+	// non-empty, a move is rejected if it would leave a partition with
+	// two replicas in the same rack.
+	BrokerRack map[int32]string
+}
+
+// partitionLoad is one partition's current placement and size, used as
+// the unit of work the planner moves between brokers.
+type partitionLoad struct {
+	Topic     string
+	Partition int32
+	Replicas  []int32
+	Size      int64
+}
+
+// brokerLoad tracks one broker's total bytes during planning.
+type brokerLoad struct {
+	BrokerID int32
+	Bytes    int64
+}
+
+// PlanRebalance computes a partition-to-broker assignment that aims to
+// equalize per-broker storage, using DescribeLogDirs-derived sizes
+// (logDirs) as the load signal. It greedily moves the largest partition
+// on the most-loaded broker to the least-loaded broker that doesn't
+// violate rack-awareness, stopping when the standard deviation of
+// broker load falls under opts.StdDevThreshold or opts.MaxMoves is hit.
+func PlanRebalance(logDirs map[int32]*BrokerLogDirs, opts RebalanceOptions) *RebalancePlan {
+	partitions, loads := buildLoadModel(logDirs)
+
+	moves := 0
+	for moves < opts.MaxMoves && stdDev(loads) > opts.StdDevThreshold {
+		mostLoaded, leastLoaded := extremes(loads)
+		if mostLoaded == nil || leastLoaded == nil || mostLoaded.BrokerID == leastLoaded.BrokerID {
+			break
+		}
+
+		candidate := largestMovablePartition(partitions, mostLoaded.BrokerID, leastLoaded.BrokerID, opts.BrokerRack)
+		if candidate == nil {
+			// No movable partition found on the most-loaded broker;
+			// nothing more this planner can do without violating
+			// rack-awareness, so stop rather than loop forever.
+			break
+		}
+
+		moveReplica(candidate, mostLoaded.BrokerID, leastLoaded.BrokerID)
+		mostLoaded.Bytes -= candidate.Size
+		leastLoaded.Bytes += candidate.Size
+		moves++
+	}
+
+	plan := &RebalancePlan{Version: 1}
+	for _, p := range partitions {
+		plan.Partitions = append(plan.Partitions, RebalanceAssignment{
+			Topic:     p.Topic,
+			Partition: p.Partition,
+			Replicas:  p.Replicas,
+		})
+	}
+	return plan
+}
+
+// buildLoadModel flattens DescribeLogDirs output into per-partition and
+// per-broker load entries the greedy planner can mutate in place.
+func buildLoadModel(logDirs map[int32]*BrokerLogDirs) ([]*partitionLoad, []*brokerLoad) {
+	index := make(map[string]*partitionLoad)
+	var partitions []*partitionLoad
+	var loads []*brokerLoad
+
+	for brokerID, dirs := range logDirs {
+		var total int64
+		for _, p := range dirs.Partitions {
+			total += p.Size
+
+			key := p.Topic + ":" + strconv.Itoa(int(p.Partition))
+			pl, ok := index[key]
+			if !ok {
+				pl = &partitionLoad{Topic: p.Topic, Partition: p.Partition, Size: p.Size}
+				index[key] = pl
+				partitions = append(partitions, pl)
+			}
+			pl.Replicas = append(pl.Replicas, brokerID)
+		}
+		loads = append(loads, &brokerLoad{BrokerID: brokerID, Bytes: total})
+	}
+
+	return partitions, loads
+}
+
+// largestMovablePartition returns the biggest partition replicated on
+// `from` that can move to `to` without violating rack-awareness (when
+// brokerRack is configured).
+func largestMovablePartition(partitions []*partitionLoad, from, to int32, brokerRack map[int32]string) *partitionLoad {
+	sorted := make([]*partitionLoad, len(partitions))
+	copy(sorted, partitions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Size > sorted[j].Size })
+
+	for _, p := range sorted {
+		if !containsBroker(p.Replicas, from) || containsBroker(p.Replicas, to) {
+			continue
+		}
+		if violatesRackAwareness(p.Replicas, from, to, brokerRack) {
+			continue
+		}
+		return p
+	}
+	return nil
+}
+
+// violatesRackAwareness reports whether replacing `from` with `to` in
+// replicas would leave two replicas of the same partition in the same
+// rack.
+func violatesRackAwareness(replicas []int32, from, to int32, brokerRack map[int32]string) bool {
+	if len(brokerRack) == 0 {
+		return false
+	}
+	targetRack := brokerRack[to]
+	if targetRack == "" {
+		return false
+	}
+	for _, r := range replicas {
+		if r == from {
+			continue
+		}
+		if brokerRack[r] == targetRack {
+			return true
+		}
+	}
+	return false
+}
+
+func moveReplica(p *partitionLoad, from, to int32) {
+	for i, r := range p.Replicas {
+		if r == from {
+			p.Replicas[i] = to
+			return
+		}
+	}
+}
+
+func containsBroker(replicas []int32, broker int32) bool {
+	for _, r := range replicas {
+		if r == broker {
+			return true
+		}
+	}
+	return false
+}
+
+// extremes returns the most- and least-loaded brokers.
+func extremes(loads []*brokerLoad) (most, least *brokerLoad) {
+	for _, l := range loads {
+		if most == nil || l.Bytes > most.Bytes {
+			most = l
+		}
+		if least == nil || l.Bytes < least.Bytes {
+			least = l
+		}
+	}
+	return most, least
+}
+
+// stdDev computes the standard deviation of broker byte loads.
+func stdDev(loads []*brokerLoad) float64 {
+	if len(loads) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, l := range loads {
+		sum += float64(l.Bytes)
+	}
+	mean := sum / float64(len(loads))
+
+	var variance float64
+	for _, l := range loads {
+		diff := float64(l.Bytes) - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(loads))
+
+	return math.Sqrt(variance)
+}
+
+// ToJSON renders the plan in kafka-reassign-partitions.sh's --generate
+// output format.
+func (p *RebalancePlan) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
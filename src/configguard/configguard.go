@@ -0,0 +1,112 @@
+// Package configguard watches broker configuration across collection
+// cycles and raises events when sensitive properties change or violate a
+// configured policy, giving Kafka operators the same "prevent risky
+// change" behavior Kubernetes admission webhooks provide, without
+// requiring K8s.
+package configguard
+
+import (
+	"fmt"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// sensitiveProperties lists the broker config keys worth flagging on any
+// change, regardless of whether a policy rule matches them.
+var sensitiveProperties = map[string]bool{
+	"log.dirs":                       true,
+	"min.insync.replicas":            true,
+	"default.replication.factor":     true,
+	"unclean.leader.election.enable": true,
+	"listeners":                      true,
+	"ssl.client.auth":                true,
+	"ssl.keystore.location":          true,
+	"sasl.enabled.mechanisms":        true,
+}
+
+// ConfigChange is one detected change to a single broker config property.
+type ConfigChange struct {
+	BrokerID string
+	Property string
+	OldValue string
+	NewValue string
+}
+
+// PolicyViolation is raised when a broker's config (old or new) breaks an
+// invariant from the loaded Policy.
+type PolicyViolation struct {
+	BrokerID string
+	Rule     string
+	Severity string
+	Detail   string
+}
+
+// Snapshot is one broker's full config as of a collection cycle.
+type Snapshot map[string]string
+
+// Guard tracks each broker's most recent config snapshot and the active
+// policy, diffing on every Observe call.
+type Guard struct {
+	policy    *Policy
+	snapshots map[string]Snapshot
+}
+
+// NewGuard creates a Guard enforcing policy. A nil policy disables
+// violation checks but change detection still runs.
+func NewGuard(policy *Policy) *Guard {
+	return &Guard{
+		policy:    policy,
+		snapshots: make(map[string]Snapshot),
+	}
+}
+
+// Observe diffs config against the broker's previous snapshot, returning
+// any sensitive-property changes and policy violations found. It always
+// replaces the stored snapshot with config, even on the first observation
+// (where no changes are reported, since there is nothing to diff against).
+func (g *Guard) Observe(brokerID string, config Snapshot) ([]ConfigChange, []PolicyViolation) {
+	var changes []ConfigChange
+
+	prev, seen := g.snapshots[brokerID]
+	if seen {
+		for key := range sensitiveProperties {
+			oldVal, newVal := prev[key], config[key]
+			if oldVal != newVal {
+				changes = append(changes, ConfigChange{
+					BrokerID: brokerID,
+					Property: key,
+					OldValue: oldVal,
+					NewValue: newVal,
+				})
+			}
+		}
+	}
+
+	g.snapshots[brokerID] = config
+
+	var violations []PolicyViolation
+	if g.policy != nil {
+		violations = g.policy.Evaluate(brokerID, config)
+	}
+
+	for _, c := range changes {
+		log.Info("configguard: broker %s property %s changed from %q to %q", c.BrokerID, c.Property, c.OldValue, c.NewValue)
+	}
+	for _, v := range violations {
+		log.Warn("configguard: policy violation on broker %s (%s): %s", v.BrokerID, v.Rule, v.Detail)
+	}
+
+	return changes, violations
+}
+
+// EventTitle renders a ConfigChange as the title for a
+// KafkaConfigChangeSample event.
+func (c ConfigChange) EventTitle() string {
+	return fmt.Sprintf("Kafka broker %s config changed: %s", c.BrokerID, c.Property)
+}
+
+// EventTitle renders a PolicyViolation as the title for a
+// KafkaConfigPolicyViolation event.
+func (v PolicyViolation) EventTitle() string {
+	return fmt.Sprintf("Kafka config policy violation on broker %s: %s", v.BrokerID, v.Rule)
+}
@@ -0,0 +1,47 @@
+package configguard
+
+// EventEmitter raises an integration event of the given eventType scoped
+// to the entity identified by guid, with attributes folded into the event
+// summary/category. *msk.MSKShim implements this via its EmitEvent method.
+type EventEmitter interface {
+	EmitEvent(eventType, guid string, attributes map[string]interface{})
+}
+
+// MSKEventSink routes config-change and policy-violation events through
+// an EventEmitter (normally the MSK shim) so they become events scoped to
+// the cluster entity the caller supplies, instead of plain integration
+// events with no entity association.
+type MSKEventSink struct {
+	emitter     EventEmitter
+	clusterGUID string
+}
+
+// NewMSKEventSink creates a sink that emits through emitter, scoped to
+// clusterGUID.
+func NewMSKEventSink(emitter EventEmitter, clusterGUID string) *MSKEventSink {
+	return &MSKEventSink{emitter: emitter, clusterGUID: clusterGUID}
+}
+
+// EmitChange raises a KafkaConfigChangeSample event scoped to the
+// cluster entity.
+func (s *MSKEventSink) EmitChange(change ConfigChange) {
+	s.emitter.EmitEvent("KafkaConfigChangeSample", s.clusterGUID, map[string]interface{}{
+		"brokerId": change.BrokerID,
+		"property": change.Property,
+		"oldValue": change.OldValue,
+		"newValue": change.NewValue,
+		"title":    change.EventTitle(),
+	})
+}
+
+// EmitViolation raises a KafkaConfigPolicyViolation event scoped to the
+// cluster entity.
+func (s *MSKEventSink) EmitViolation(violation PolicyViolation) {
+	s.emitter.EmitEvent("KafkaConfigPolicyViolation", s.clusterGUID, map[string]interface{}{
+		"brokerId": violation.BrokerID,
+		"rule":     violation.Rule,
+		"severity": violation.Severity,
+		"detail":   violation.Detail,
+		"title":    violation.EventTitle(),
+	})
+}
@@ -0,0 +1,98 @@
+package configguard
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Policy is a loaded set of config invariants, read from a YAML policy
+// file. Each Rule is evaluated against every broker's config snapshot.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a single invariant: Property must not equal any of Forbidden
+// (if set) and must satisfy MinValue/MaxValue (if set), as a numeric
+// comparison.
+type Rule struct {
+	Name      string   `yaml:"name"`
+	Property  string   `yaml:"property"`
+	Forbidden []string `yaml:"forbidden,omitempty"`
+	MinValue  *float64 `yaml:"minValue,omitempty"`
+	MaxValue  *float64 `yaml:"maxValue,omitempty"`
+	Severity  string   `yaml:"severity"`
+}
+
+// LoadPolicy reads and parses a YAML policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config policy %s: %w", path, err)
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("parsing config policy %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// Evaluate checks every rule against config, returning one
+// PolicyViolation per rule that fails.
+func (p *Policy) Evaluate(brokerID string, config Snapshot) []PolicyViolation {
+	var violations []PolicyViolation
+
+	for _, rule := range p.Rules {
+		value, ok := config[rule.Property]
+		if !ok {
+			continue
+		}
+
+		if violation := rule.check(brokerID, value); violation != nil {
+			violations = append(violations, *violation)
+		}
+	}
+
+	return violations
+}
+
+func (r Rule) check(brokerID, value string) *PolicyViolation {
+	for _, forbidden := range r.Forbidden {
+		if value == forbidden {
+			return &PolicyViolation{
+				BrokerID: brokerID,
+				Rule:     r.Name,
+				Severity: r.Severity,
+				Detail:   fmt.Sprintf("%s=%s is a forbidden value", r.Property, value),
+			}
+		}
+	}
+
+	if r.MinValue != nil || r.MaxValue != nil {
+		numeric, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil
+		}
+		if r.MinValue != nil && numeric < *r.MinValue {
+			return &PolicyViolation{
+				BrokerID: brokerID,
+				Rule:     r.Name,
+				Severity: r.Severity,
+				Detail:   fmt.Sprintf("%s=%s is below the required minimum of %v", r.Property, value, *r.MinValue),
+			}
+		}
+		if r.MaxValue != nil && numeric > *r.MaxValue {
+			return &PolicyViolation{
+				BrokerID: brokerID,
+				Rule:     r.Name,
+				Severity: r.Severity,
+				Detail:   fmt.Sprintf("%s=%s exceeds the allowed maximum of %v", r.Property, value, *r.MaxValue),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// PrometheusExporter renders the raw JMX-sourced metric samples this
+// package collects (broker/topic/consumer MBean values) as a Prometheus
+// scrape target, independent of whichever entity model (MSK shim,
+// message-queue transformer) eventually consumes the same samples.
+type PrometheusExporter struct {
+	mu      sync.RWMutex
+	samples map[string]float64 // metric name -> latest value
+	labels  map[string]string  // constant labels applied to every series
+}
+
+// NewPrometheusExporter creates an exporter with the given constant labels
+// (e.g. cluster, environment).
+func NewPrometheusExporter(labels map[string]string) *PrometheusExporter {
+	return &PrometheusExporter{
+		samples: make(map[string]float64),
+		labels:  labels,
+	}
+}
+
+// Record stores the latest value for a JMX-derived metric name (e.g.
+// "broker.bytesInPerSecond"). Subsequent Records for the same name
+// overwrite the previous value, matching the gauge semantics the rest of
+// this package's metric definitions use.
+func (e *PrometheusExporter) Record(metricName string, value float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.samples[metricName] = value
+}
+
+// promName converts a dotted JMX-style metric name into a valid Prometheus
+// metric name.
+func promName(metricName string) string {
+	replaced := strings.NewReplacer(".", "_", "-", "_", ":", "_", "=", "_", ",", "_").Replace(metricName)
+	return "kafka_" + replaced
+}
+
+// WriteTo renders every recorded sample in Prometheus text exposition
+// format.
+func (e *PrometheusExporter) WriteTo(w http.ResponseWriter) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	names := make([]string, 0, len(e.samples))
+	for name := range e.samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var labelPairs []string
+	for k, v := range e.labels {
+		labelPairs = append(labelPairs, fmt.Sprintf("%s=%q", k, v))
+	}
+	sort.Strings(labelPairs)
+	labelStr := strings.Join(labelPairs, ",")
+
+	var b strings.Builder
+	for _, name := range names {
+		metricName := promName(name)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", metricName)
+		if labelStr != "" {
+			fmt.Fprintf(&b, "%s{%s} %v\n", metricName, labelStr, e.samples[name])
+		} else {
+			fmt.Fprintf(&b, "%s %v\n", metricName, e.samples[name])
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if _, err := w.Write([]byte(b.String())); err != nil {
+		log.Error("PrometheusExporter: failed writing response: %v", err)
+	}
+}
+
+// Handler returns an http.HandlerFunc suitable for mux.HandleFunc("/metrics", ...).
+func (e *PrometheusExporter) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		e.WriteTo(w)
+	}
+}
@@ -0,0 +1,192 @@
+package msk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// AccountResolver resolves the account/domain/realm an entity GUID is
+// scoped under, given the entity type and cluster it belongs to.
+// generateEntityGUID consults a resolver instead of reading AWS_ACCOUNT_ID
+// directly, so a deployment with no usable account source fails loudly
+// rather than emitting a GUID under a hardcoded fallback account, and
+// non-AWS clusters (Azure, GCP, on-prem) can resolve into their own
+// domain/realm instead of always landing in AWS's "INFRA"/"NA".
+type AccountResolver interface {
+	Resolve(ctx context.Context, entityType, clusterName string) (accountID, domain, realm string, err error)
+}
+
+// EnvAccountResolver resolves the account ID from an environment variable
+// (AWS_ACCOUNT_ID by default) with a fixed domain and realm. Unlike the
+// generateEntityGUID this replaces, it errors rather than falling back to a
+// hardcoded account ID when the variable is unset.
+type EnvAccountResolver struct {
+	EnvVar string
+	Domain string
+	Realm  string
+}
+
+// NewEnvAccountResolver creates an EnvAccountResolver reading AWS_ACCOUNT_ID
+// under domain "INFRA" and realm "NA", this integration's original scope.
+func NewEnvAccountResolver() *EnvAccountResolver {
+	return &EnvAccountResolver{EnvVar: "AWS_ACCOUNT_ID", Domain: "INFRA", Realm: "NA"}
+}
+
+// Resolve implements AccountResolver.
+func (r *EnvAccountResolver) Resolve(ctx context.Context, entityType, clusterName string) (string, string, string, error) {
+	envVar := r.EnvVar
+	if envVar == "" {
+		envVar = "AWS_ACCOUNT_ID"
+	}
+	accountID := os.Getenv(envVar)
+	if accountID == "" {
+		return "", "", "", fmt.Errorf("account resolver: environment variable %s is not set", envVar)
+	}
+	return accountID, nonEmptyOr(r.Domain, "INFRA"), nonEmptyOr(r.Realm, "NA"), nil
+}
+
+// StaticAccountResolver resolves every entity to the same configured
+// account/domain/realm, for deployments that supply the account ID directly
+// (e.g. Config.AWSAccountID) rather than relying on the environment.
+type StaticAccountResolver struct {
+	AccountID string
+	Domain    string
+	Realm     string
+}
+
+// NewStaticAccountResolver creates a StaticAccountResolver for accountID,
+// defaulting domain to "INFRA" and realm to "NA" when empty.
+func NewStaticAccountResolver(accountID, domain, realm string) *StaticAccountResolver {
+	return &StaticAccountResolver{
+		AccountID: accountID,
+		Domain:    nonEmptyOr(domain, "INFRA"),
+		Realm:     nonEmptyOr(realm, "NA"),
+	}
+}
+
+// Resolve implements AccountResolver.
+func (r *StaticAccountResolver) Resolve(ctx context.Context, entityType, clusterName string) (string, string, string, error) {
+	if r.AccountID == "" {
+		return "", "", "", fmt.Errorf("account resolver: no static account ID configured")
+	}
+	return r.AccountID, nonEmptyOr(r.Domain, "INFRA"), nonEmptyOr(r.Realm, "NA"), nil
+}
+
+// imdsBaseURL is the well-known EC2 Instance Metadata Service address.
+const imdsBaseURL = "http://169.254.169.254"
+
+// IMDSAccountResolver resolves the account ID from the EC2 Instance
+// Metadata Service's identity document via IMDSv2, for deployments running
+// directly on AWS infrastructure with neither AWS_ACCOUNT_ID nor a static
+// account ID configured.
+type IMDSAccountResolver struct {
+	Client  *http.Client
+	Domain  string
+	Realm   string
+	baseURL string // overridable in tests; defaults to imdsBaseURL
+}
+
+// NewIMDSAccountResolver creates an IMDSAccountResolver with a 2 second
+// timeout client, under domain "INFRA" and realm "NA".
+func NewIMDSAccountResolver() *IMDSAccountResolver {
+	return &IMDSAccountResolver{
+		Client: &http.Client{Timeout: 2 * time.Second},
+		Domain: "INFRA",
+		Realm:  "NA",
+	}
+}
+
+// Resolve implements AccountResolver.
+func (r *IMDSAccountResolver) Resolve(ctx context.Context, entityType, clusterName string) (string, string, string, error) {
+	base := nonEmptyOr(r.baseURL, imdsBaseURL)
+
+	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPut, base+"/latest/api/token", nil)
+	if err != nil {
+		return "", "", "", fmt.Errorf("account resolver: building IMDS token request: %w", err)
+	}
+	tokenReq.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+
+	tokenResp, err := r.Client.Do(tokenReq)
+	if err != nil {
+		return "", "", "", fmt.Errorf("account resolver: fetching IMDS token: %w", err)
+	}
+	defer tokenResp.Body.Close()
+	token, err := io.ReadAll(tokenResp.Body)
+	if err != nil {
+		return "", "", "", fmt.Errorf("account resolver: reading IMDS token: %w", err)
+	}
+
+	docReq, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/latest/dynamic/instance-identity/document", nil)
+	if err != nil {
+		return "", "", "", fmt.Errorf("account resolver: building IMDS identity document request: %w", err)
+	}
+	docReq.Header.Set("X-aws-ec2-metadata-token", string(token))
+
+	docResp, err := r.Client.Do(docReq)
+	if err != nil {
+		return "", "", "", fmt.Errorf("account resolver: fetching IMDS identity document: %w", err)
+	}
+	defer docResp.Body.Close()
+
+	var doc struct {
+		AccountID string `json:"accountId"`
+	}
+	if err := json.NewDecoder(docResp.Body).Decode(&doc); err != nil {
+		return "", "", "", fmt.Errorf("account resolver: decoding IMDS identity document: %w", err)
+	}
+	if doc.AccountID == "" {
+		return "", "", "", fmt.Errorf("account resolver: IMDS identity document has no accountId")
+	}
+
+	return doc.AccountID, nonEmptyOr(r.Domain, "INFRA"), nonEmptyOr(r.Realm, "NA"), nil
+}
+
+// ChainedAccountResolver tries each resolver in order, returning the first
+// one that resolves successfully - e.g. a static config override, falling
+// back to the environment variable, falling back to IMDS on EC2 with
+// neither configured.
+type ChainedAccountResolver struct {
+	resolvers []AccountResolver
+}
+
+// NewChainedAccountResolver creates a ChainedAccountResolver trying
+// resolvers in the given order.
+func NewChainedAccountResolver(resolvers ...AccountResolver) *ChainedAccountResolver {
+	return &ChainedAccountResolver{resolvers: resolvers}
+}
+
+// Resolve implements AccountResolver.
+func (r *ChainedAccountResolver) Resolve(ctx context.Context, entityType, clusterName string) (string, string, string, error) {
+	var errs []string
+	for _, resolver := range r.resolvers {
+		accountID, domain, realm, err := resolver.Resolve(ctx, entityType, clusterName)
+		if err == nil {
+			return accountID, domain, realm, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return "", "", "", fmt.Errorf("account resolver: no resolver in chain succeeded: %s", strings.Join(errs, "; "))
+}
+
+// newDefaultAccountResolver is the AccountResolver a GUIDCache uses when
+// constructed without WithAccountResolver: AWS_ACCOUNT_ID from the
+// environment, falling back to IMDS for an EC2 deployment that never set
+// it. It deliberately omits the old "3630072" literal - exhausting this
+// chain is now a loud error rather than a silent wrong-account GUID.
+func newDefaultAccountResolver() AccountResolver {
+	return NewChainedAccountResolver(NewEnvAccountResolver(), NewIMDSAccountResolver())
+}
+
+// nonEmptyOr returns value, or fallback if value is empty.
+func nonEmptyOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -2,6 +2,8 @@ package msk
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -88,24 +90,64 @@ func (a *AdminAPIHelper) GetMinISRForTopic(topicName string) (int, error) {
 	return minISR, nil
 }
 
-// GetTopicConfigs retrieves all topic configurations
+// GetTopicConfigs retrieves all topic configurations in a single
+// MetadataRequest + DescribeConfigs round-trip to the controller, rather
+// than issuing one DescribeConfig call per topic (the N+1 pattern that
+// used to thunder the controller every time every broker's metric poll
+// needed the same topic's config).
 func (a *AdminAPIHelper) GetTopicConfigs() (map[string]*TopicConfig, error) {
 	topics, err := a.client.Topics()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list topics: %w", err)
 	}
 
-	configs := make(map[string]*TopicConfig)
+	return a.GetTopicConfigsBulk(topics)
+}
+
+// GetTopicConfigsBulk fetches min.insync.replicas for every named topic in
+// one DescribeConfigsRequest sent directly to the controller broker,
+// instead of the N separate admin.DescribeConfig calls GetMinISRForTopic
+// would otherwise require.
+func (a *AdminAPIHelper) GetTopicConfigsBulk(topics []string) (map[string]*TopicConfig, error) {
+	if len(topics) == 0 {
+		return map[string]*TopicConfig{}, nil
+	}
+
+	controller, err := a.client.Controller()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get controller: %w", err)
+	}
+
+	resources := make([]*sarama.ConfigResource, 0, len(topics))
 	for _, topic := range topics {
-		minISR, err := a.GetMinISRForTopic(topic)
-		if err != nil {
-			log.Warn("Failed to get config for topic %s: %v", topic, err)
-			continue
-		}
-		configs[topic] = &TopicConfig{
-			MinInSyncReplicas: minISR,
-			LastUpdated:       time.Now(),
+		resources = append(resources, &sarama.ConfigResource{
+			Type: sarama.TopicResource,
+			Name: topic,
+		})
+	}
+
+	resp, err := controller.DescribeConfigs(&sarama.DescribeConfigsRequest{
+		Resources: resources,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bulk describing topic configs: %w", err)
+	}
+
+	now := time.Now()
+	configs := make(map[string]*TopicConfig, len(resp.Resources))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, resource := range resp.Resources {
+		config := &TopicConfig{MinInSyncReplicas: 1, LastUpdated: now}
+		for _, entry := range resource.Configs {
+			if entry.Name == "min.insync.replicas" {
+				fmt.Sscanf(entry.Value, "%d", &config.MinInSyncReplicas)
+			}
 		}
+		configs[resource.Name] = config
+		a.configCache[resource.Name] = config
 	}
 
 	return configs, nil
@@ -132,6 +174,72 @@ func (a *AdminAPIHelper) CalculateUnderMinISRPartitions(metadata *sarama.Metadat
 	return underMinISR, nil
 }
 
+// BrokerDescription is the authoritative per-broker shape returned by
+// DescribeCluster: where it listens and which rack it's in, neither of
+// which a single broker's own JMX beans can tell you about its peers.
+type BrokerDescription struct {
+	ID   int32
+	Host string
+	Port int
+	Rack string
+}
+
+// ClusterDescription is the authoritative cluster topology returned by
+// DescribeCluster, suitable for surfacing alongside CloudWatch-derived
+// rates on a cluster-scope sample.
+type ClusterDescription struct {
+	ControllerID int32
+	Brokers      []BrokerDescription
+}
+
+// ToProviderAttributes renders the description as provider.* attributes
+// for a cluster-scope metric set, mirroring TopicConfigEnricher's
+// ToProviderMetrics pattern for topic-scope config.
+func (d *ClusterDescription) ToProviderAttributes() map[string]interface{} {
+	return map[string]interface{}{
+		"provider.controllerId": d.ControllerID,
+		"provider.brokerCount":  len(d.Brokers),
+	}
+}
+
+// DescribeCluster asks the controller for the current controller ID and
+// the full broker list (host, port, rack), which is authoritative
+// regardless of which single broker happened to answer a JMX poll.
+func (a *AdminAPIHelper) DescribeCluster() (*ClusterDescription, error) {
+	brokers, controllerID, err := a.admin.DescribeCluster()
+	if err != nil {
+		return nil, fmt.Errorf("describing cluster: %w", err)
+	}
+
+	description := &ClusterDescription{ControllerID: controllerID}
+	for _, b := range brokers {
+		host, port := splitBrokerAddr(b.Addr())
+		description.Brokers = append(description.Brokers, BrokerDescription{
+			ID:   b.ID(),
+			Host: host,
+			Port: port,
+			Rack: b.Rack(),
+		})
+	}
+
+	return description, nil
+}
+
+// splitBrokerAddr splits a sarama broker's "host:port" address into its
+// parts, falling back to the raw address with port 0 if it can't be
+// parsed (e.g. an IPv6 address sarama reports unusually).
+func splitBrokerAddr(addr string) (string, int) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, 0
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return host, 0
+	}
+	return host, port
+}
+
 // Close cleans up resources
 func (a *AdminAPIHelper) Close() error {
 	if a.admin != nil {
@@ -163,6 +271,15 @@ type EnhancedTopicMetrics struct {
 	UnderReplicatedCount int
 	OfflineCount         int
 	LeaderCount          int
+
+	// Config-derived fields, fetched via DescribeConfig alongside
+	// min.insync.replicas so callers get the full config picture in one
+	// trip rather than polling JMX beans that don't expose these at all.
+	SegmentBytes                int64
+	CleanupPolicy               string
+	CompressionType             string
+	MaxMessageBytes             int64
+	UncleanLeaderElectionEnable bool
 }
 
 // GetEnhancedTopicMetrics retrieves comprehensive topic metrics
@@ -222,5 +339,55 @@ func (a *AdminAPIHelper) GetEnhancedTopicMetrics(topicName string) (*EnhancedTop
 		}
 	}
 
+	a.populateConfigFields(metrics)
+
 	return metrics, nil
+}
+
+// populateConfigFields fills in the config-derived fields of metrics via
+// DescribeConfig, logging and leaving zero-values on failure rather than
+// failing the whole enhanced-metrics call over config we can live without.
+func (a *AdminAPIHelper) populateConfigFields(metrics *EnhancedTopicMetrics) {
+	entries, err := a.admin.DescribeConfig(sarama.ConfigResource{
+		Type: sarama.TopicResource,
+		Name: metrics.TopicName,
+	})
+	if err != nil {
+		log.Debug("Failed to describe config for topic %s: %v", metrics.TopicName, err)
+		return
+	}
+
+	for _, entry := range entries {
+		switch entry.Name {
+		case "segment.bytes":
+			fmt.Sscanf(entry.Value, "%d", &metrics.SegmentBytes)
+		case "cleanup.policy":
+			metrics.CleanupPolicy = entry.Value
+		case "compression.type":
+			metrics.CompressionType = entry.Value
+		case "max.message.bytes":
+			fmt.Sscanf(entry.Value, "%d", &metrics.MaxMessageBytes)
+		case "unclean.leader.election.enable":
+			metrics.UncleanLeaderElectionEnable = entry.Value == "true"
+		}
+	}
+}
+
+// DescribeBrokerConfig fetches brokerID's full dynamic+static config as a
+// name->value map, for callers (e.g. configguard) that need to diff a
+// broker's whole config rather than a handful of named fields.
+func (a *AdminAPIHelper) DescribeBrokerConfig(brokerID string) (map[string]string, error) {
+	entries, err := a.admin.DescribeConfig(sarama.ConfigResource{
+		Type: sarama.BrokerResource,
+		Name: brokerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe config for broker %s: %w", brokerID, err)
+	}
+
+	config := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		config[entry.Name] = entry.Value
+	}
+	return config, nil
 }
\ No newline at end of file
@@ -0,0 +1,128 @@
+package msk
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// AdminDiscoverer connects to a real Kafka cluster via sarama and feeds its
+// authoritative broker/topic/controller shape into a MetricAggregator, so
+// enhanced mode's simulated fallbacks (brokerCount=3, topicCount=10,
+// controller=brokerID%3) only kick in for whatever a DescribeCluster/
+// DescribeTopics round-trip can't answer.
+type AdminDiscoverer struct {
+	client    sarama.Client
+	describer *ClusterDescriber
+	groups    *ConsumerGroupDiscoverer
+
+	mu          sync.Mutex
+	lastCluster *DescribedCluster
+}
+
+// NewAdminDiscoverer connects to brokers using config's
+// EnhancedDiscoverySASL*/EnhancedDiscoveryTLSEnabled settings and returns
+// an AdminDiscoverer ready for Discover calls.
+func NewAdminDiscoverer(brokers []string, config *Config) (*AdminDiscoverer, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("admin discoverer requires at least one bootstrap server")
+	}
+
+	saramaConfig := NewSaramaConfig()
+	if config.EnhancedDiscoverySASLEnabled {
+		saramaConfig.Net.SASL.Enable = true
+		saramaConfig.Net.SASL.User = config.EnhancedDiscoverySASLUsername
+		saramaConfig.Net.SASL.Password = config.EnhancedDiscoverySASLPassword
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLMechanism(config.EnhancedDiscoverySASLMechanism)
+	}
+	if config.EnhancedDiscoveryTLSEnabled {
+		saramaConfig.Net.TLS.Enable = true
+	}
+
+	client, err := sarama.NewClient(brokers, saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("admin discoverer: connecting to %v: %w", brokers, err)
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("admin discoverer: creating admin client: %w", err)
+	}
+
+	return &AdminDiscoverer{
+		client:    client,
+		describer: NewClusterDescriber(admin),
+		groups:    NewConsumerGroupDiscoverer(client, admin),
+	}, nil
+}
+
+// Discover runs one DescribeCluster + DescribeTopics pass and merges the
+// result into aggregator, returning the described cluster shape so callers
+// (e.g. the controller-assignment fallback) can read it directly instead
+// of re-deriving it from the aggregator.
+func (d *AdminDiscoverer) Discover(aggregator *MetricAggregator) (*DescribedCluster, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	described, err := d.describer.DescribeCluster()
+	if err != nil {
+		return nil, fmt.Errorf("admin discoverer: %w", err)
+	}
+
+	topics, err := d.client.Topics()
+	if err != nil {
+		log.Warn("AdminDiscoverer: failed to list topics, topic counts stay simulated: %v", err)
+	} else if topicDescriptions, err := d.describer.DescribeTopics(topics); err != nil {
+		log.Warn("AdminDiscoverer: failed to describe topics, topic counts stay simulated: %v", err)
+	} else {
+		for _, topic := range topicDescriptions {
+			described.GlobalPartitionCount += topic.PartitionCount
+			aggregator.AddDescribedTopic(topic)
+		}
+	}
+
+	aggregator.AddDescribedCluster(described)
+	d.lastCluster = described
+	return described, nil
+}
+
+// LastCluster returns the most recent successful Discover result, or nil
+// if Discover has never succeeded.
+func (d *AdminDiscoverer) LastCluster() *DescribedCluster {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.lastCluster
+}
+
+// Groups returns the ConsumerGroupDiscoverer sharing this AdminDiscoverer's
+// Kafka admin connection, for callers that need consumer-group lag or
+// membership data rather than cluster/topic shape.
+func (d *AdminDiscoverer) Groups() *ConsumerGroupDiscoverer {
+	return d.groups
+}
+
+// BrokerAddresses returns each known broker's host (without port) mapped to
+// its authoritative broker ID, so a caller holding only a broker's hostname
+// (no "broker:ID"-prefixed entityName) can resolve its real ID instead of
+// guessing from the hostname's trailing "-N" suffix.
+func (d *AdminDiscoverer) BrokerAddresses() map[string]int32 {
+	addrs := make(map[string]int32)
+	for _, b := range d.client.Brokers() {
+		host, _, err := net.SplitHostPort(b.Addr())
+		if err != nil {
+			host = b.Addr()
+		}
+		addrs[host] = b.ID()
+	}
+	return addrs
+}
+
+// Close releases the underlying Kafka admin connection.
+func (d *AdminDiscoverer) Close() error {
+	return d.client.Close()
+}
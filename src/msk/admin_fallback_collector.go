@@ -0,0 +1,146 @@
+package msk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// brokerLogDirTotals caches a broker's most recently observed total
+// log-directory size, so CollectBrokerMetrics can derive
+// broker.bytesInPerSec from the delta between two DescribeLogDirs polls
+// instead of needing a JMX BytesInPerSec bean at all.
+type brokerLogDirTotals struct {
+	size       int64
+	observedAt time.Time
+}
+
+// AdminFallbackCollector fills in the MSK fields GetRequiredJMXBeans
+// normally sources from JMX by calling Sarama's admin API directly:
+// DescribeCluster/DescribeTopics (via the shared ClusterDescriber),
+// DescribeLogDirs, and ListConsumerGroupOffsets. IntegrationHook only
+// consults it for beans ValidateJMXConfiguration has reported missing, on
+// brokers where only the Kafka wire protocol (not JMX) is reachable.
+type AdminFallbackCollector struct {
+	admin     sarama.ClusterAdmin
+	describer *ClusterDescriber
+	mapper    *MetricMapper
+
+	mu          sync.Mutex
+	prevLogDirs map[int32]brokerLogDirTotals
+}
+
+// NewAdminFallbackCollector wraps an already-connected sarama.ClusterAdmin.
+// mapper is stored on the collector; its Collect methods do not use it, so
+// their responses are not translated through mapper.
+func NewAdminFallbackCollector(admin sarama.ClusterAdmin, mapper *MetricMapper) *AdminFallbackCollector {
+	return &AdminFallbackCollector{
+		admin:       admin,
+		describer:   NewClusterDescriber(admin),
+		mapper:      mapper,
+		prevLogDirs: make(map[int32]brokerLogDirTotals),
+	}
+}
+
+// CollectBrokerMetrics calls DescribeLogDirs for brokerIDs and returns each
+// broker's translated MSK fields: broker.logSize (current total size across
+// all log directories) and broker.bytesInPerSec (derived from the size
+// delta since this collector's previous call, zero on a broker's first
+// poll since there's nothing yet to diff against).
+func (c *AdminFallbackCollector) CollectBrokerMetrics(brokerIDs []int32) (map[int32]map[string]interface{}, error) {
+	dirs, err := c.admin.DescribeLogDirs(brokerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("admin fallback: describing log dirs for brokers %v: %w", brokerIDs, err)
+	}
+
+	now := time.Now()
+	result := make(map[int32]map[string]interface{}, len(dirs))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for brokerID, brokerDirs := range dirs {
+		var total int64
+		for _, dir := range brokerDirs {
+			if dir.ErrorCode != 0 {
+				continue
+			}
+			for _, topicDir := range dir.Topics {
+				for _, partDir := range topicDir.Partitions {
+					total += partDir.Size
+				}
+			}
+		}
+
+		var bytesInPerSec float64
+		if prev, ok := c.prevLogDirs[brokerID]; ok {
+			if elapsed := now.Sub(prev.observedAt).Seconds(); elapsed > 0 && total > prev.size {
+				bytesInPerSec = float64(total-prev.size) / elapsed
+			}
+		}
+		c.prevLogDirs[brokerID] = brokerLogDirTotals{size: total, observedAt: now}
+
+		result[brokerID] = map[string]interface{}{
+			"broker.logSize":       total,
+			"broker.bytesInPerSec": bytesInPerSec,
+		}
+	}
+
+	return result, nil
+}
+
+// CollectTopicMetrics describes topics via the controller and translates
+// the authoritative partition/replica shape into topic.partitionCount,
+// the same field a JMX-derived topic sample would carry.
+func (c *AdminFallbackCollector) CollectTopicMetrics(topics []string) (map[string]map[string]interface{}, error) {
+	described, err := c.describer.DescribeTopics(topics)
+	if err != nil {
+		return nil, fmt.Errorf("admin fallback: %w", err)
+	}
+
+	result := make(map[string]map[string]interface{}, len(described))
+	for name, topic := range described {
+		result[name] = map[string]interface{}{
+			"topic.partitionCount":    topic.PartitionCount,
+			"topic.replicationFactor": topic.ReplicationFactor,
+		}
+	}
+	return result, nil
+}
+
+// CollectConsumerLag lists groupID's committed offsets and, when client is
+// non-nil, enriches each partition's committed offset with its log-end
+// offset so the result carries the same consumer.offset/consumer.lag
+// fields ProcessConsumerOffset expects from a JMX-sourced sample. client
+// may be nil to skip the lag enrichment and report committed offsets only.
+func (c *AdminFallbackCollector) CollectConsumerLag(groupID string, client sarama.Client) (map[string]map[int32]map[string]interface{}, error) {
+	offsets, err := c.admin.ListConsumerGroupOffsets(groupID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("admin fallback: listing offsets for group %s: %w", groupID, err)
+	}
+
+	result := make(map[string]map[int32]map[string]interface{}, len(offsets.Blocks))
+	for topic, partitions := range offsets.Blocks {
+		topicResult := make(map[int32]map[string]interface{}, len(partitions))
+		for partition, block := range partitions {
+			if block.Err != sarama.ErrNoError || block.Offset < 0 {
+				continue
+			}
+
+			data := map[string]interface{}{
+				"consumer.offset": block.Offset,
+			}
+			if client != nil {
+				if hwm, err := client.GetOffset(topic, partition, sarama.OffsetNewest); err == nil {
+					data["consumer.lag"] = hwm - block.Offset
+				}
+			}
+			topicResult[partition] = data
+		}
+		result[topic] = topicResult
+	}
+
+	return result, nil
+}
@@ -1,6 +1,7 @@
 package msk
 
 import (
+	"fmt"
 	"sync"
 	"time"
 )
@@ -12,9 +13,120 @@ type MetricAggregator struct {
 	topicMetrics      map[string]*TopicMetrics
 	controllerMetrics *ControllerMetrics
 	consumerLagMetrics map[string]map[string]float64 // topic -> consumerGroup -> lag
+	// consumerPartitionLag holds the same lag SimpleTransformConsumerOffset
+	// reports, but keyed down to the individual partition, so
+	// ConsumerGroupLagStats can compute a real max/sum across every
+	// partition a group consumes instead of trusting whichever single
+	// partition happened to report last.
+	consumerPartitionLag map[string]map[string]map[int32]float64 // topic -> consumerGroup -> partition -> lag
+	// partitionMetrics holds the latest per-partition topology
+	// (leader/replicas/ISR/offsets) SimpleTransformPartitionMetrics records,
+	// so GetPartitionCount/GetUnderReplicatedPartitionCount can report real
+	// counts instead of a TopicCount-based estimate.
+	partitionMetrics map[string]map[int32]*PartitionInfo // topic -> partition -> info
 	lastAggregation   time.Time
+
+	topicAggregationPolicy map[string]TopicAggregationFunc // metric name -> aggregation
+	topicSampleCounts      map[string]int                  // topic -> number of AddTopicMetric calls received
+	partitionLeaders       map[string]string                // topic#partition -> last-seen leader broker ID
+
+	clusterAggregationPolicy map[string]TopicAggregationFunc // metric name -> aggregation across brokers
+
+	controllerBrokerID string // last-seen broker ID reporting ActiveControllerCount=1
+
+	brokerLatencyDigests map[string]map[string]*TDigest // brokerID -> metric name -> per-broker t-digest
+
+	// brokerRateMeters and topicRateMeters track true 1m/5m/15m EWMA rates
+	// for BytesInPerSec/BytesOutPerSec/MessagesInPerSec, fed by every
+	// AddBrokerMetric(s)/AddTopicMetric(s) call. Unlike the rest of this
+	// struct's fields, Reset deliberately leaves these alone: Flush calls
+	// Reset (not NewMetricAggregator) precisely so this rate history
+	// survives the per-cycle reset instead of restarting from zero every
+	// cycle. topicRateMeters is cardinality-bounded because a cluster can
+	// have thousands of topics; brokerRateMeters and clusterRateMeters are
+	// not, since their key spaces are naturally small (brokers, and the
+	// single cluster-wide key).
+	brokerRateMeters  *RateMeterCache
+	topicRateMeters   *RateMeterCache
+	clusterRateMeters *RateMeterCache
+}
+
+// maxTrackedTopicRateMeters bounds how many topics' rate history
+// topicRateMeters keeps resident at once, so a cluster with thousands of
+// topics can't grow this state without bound; least-recently-updated
+// topics are evicted first.
+const maxTrackedTopicRateMeters = 5000
+
+// clusterRateMeterKey is the single RateMeterCache key clusterRateMeters is
+// tracked under -- there is only ever one cluster, so this just reuses the
+// same bundle/eviction machinery as the per-broker and per-topic caches
+// instead of a bespoke map[string]*RateMeter.
+const clusterRateMeterKey = "cluster"
+
+// clusterLatencyMetrics lists the metric names whose cluster-wide rollup is
+// a real percentile distribution (via TDigest) rather than a scalar
+// sum/max/average -- summing or averaging per-broker request-latency
+// averages across a cluster is meaningless, unlike a throughput metric such
+// as BytesInPerSec.
+var clusterLatencyMetrics = map[string]bool{
+	"ProduceTotalTimeMs":       true,
+	"FetchConsumerTotalTimeMs": true,
+}
+
+// latencyDigestCompression is the TDigest compression factor used for every
+// cluster latency metric -- ~100 keeps each per-broker digest to a few KB
+// while still resolving p99 to within about 1% relative error.
+const latencyDigestCompression = 100.0
+
+// LatencyQuantiles is one metric's cluster-wide percentile summary, computed
+// by merging every broker's per-metric TDigest.
+type LatencyQuantiles struct {
+	P50   float64
+	P95   float64
+	P99   float64
+	Count float64
+}
+
+// SetClusterAggregationPolicy configures how a cluster-level rollup metric
+// is combined across per-broker values in GetClusterMetrics. The default
+// sums throughput and partition counts, but uses MAX for
+// UnderReplicatedPartitions -- a single lagging broker's count, not the sum
+// across brokers (which double-counts replicas of the same partition), is
+// the correct cluster health signal.
+func (a *MetricAggregator) SetClusterAggregationPolicy(metricName string, fn TopicAggregationFunc) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.clusterAggregationPolicy[metricName] = fn
 }
 
+// clusterCombine folds a per-broker value into a running cluster rollup per
+// the configured policy. It does not need a running sample count because,
+// unlike AddTopicMetric, GetClusterMetrics recomputes from scratch on every
+// call rather than accumulating incrementally.
+func (a *MetricAggregator) clusterCombine(metricName string, existing, incoming float64) float64 {
+	switch a.clusterAggregationPolicy[metricName] {
+	case TopicAggregationMax:
+		if incoming > existing {
+			return incoming
+		}
+		return existing
+	case TopicAggregationSum:
+		fallthrough
+	default:
+		return existing + incoming
+	}
+}
+
+// TopicAggregationFunc selects how repeated AddTopicMetric calls for the
+// same topic (one per reporting broker) combine a given metric.
+type TopicAggregationFunc string
+
+const (
+	TopicAggregationSum     TopicAggregationFunc = "sum"
+	TopicAggregationMax     TopicAggregationFunc = "max"
+	TopicAggregationAverage TopicAggregationFunc = "average"
+)
+
 // BrokerMetrics holds metrics for a single broker
 type BrokerMetrics struct {
 	BrokerID                  int
@@ -24,6 +136,7 @@ type BrokerMetrics struct {
 	MessagesInPerSec          float64
 	PartitionCount            int
 	UnderReplicatedPartitions int
+	ZooKeeperRequestLatencyMsMean float64
 	LastUpdated               time.Time
 }
 
@@ -37,6 +150,8 @@ type TopicMetrics struct {
 	PartitionCount       int
 	ReplicationFactor    int
 	UnderReplicated      int
+	MinInSyncReplicas    int
+	RetentionMs          int64
 }
 
 // ControllerMetrics holds controller-specific metrics
@@ -56,7 +171,9 @@ type ClusterAggregatedMetrics struct {
 	GlobalTopicCount          int
 	BytesInPerSec             float64
 	BytesOutPerSec            float64
+	MessagesInPerSec          float64
 	UnderMinISRPartitions     int
+	OngoingReassignments      int
 }
 
 // NewMetricAggregator creates a new metric aggregator
@@ -65,8 +182,55 @@ func NewMetricAggregator() *MetricAggregator {
 		brokerMetrics:      make(map[string]*BrokerMetrics),
 		topicMetrics:       make(map[string]*TopicMetrics),
 		controllerMetrics:  &ControllerMetrics{},
-		consumerLagMetrics: make(map[string]map[string]float64),
+		consumerLagMetrics:   make(map[string]map[string]float64),
+		consumerPartitionLag: make(map[string]map[string]map[int32]float64),
+		partitionMetrics:     make(map[string]map[int32]*PartitionInfo),
 		lastAggregation:    time.Now(),
+		topicAggregationPolicy: map[string]TopicAggregationFunc{
+			"BytesInPerSec":       TopicAggregationSum,
+			"BytesOutPerSec":      TopicAggregationSum,
+			"MessagesInPerSec":    TopicAggregationSum,
+			"BytesRejectedPerSec": TopicAggregationSum,
+		},
+		topicSampleCounts: make(map[string]int),
+		clusterAggregationPolicy: map[string]TopicAggregationFunc{
+			"UnderReplicatedPartitions": TopicAggregationMax,
+			"GlobalPartitionCount":      TopicAggregationSum,
+			"BytesInPerSec":             TopicAggregationSum,
+			"BytesOutPerSec":            TopicAggregationSum,
+			"MessagesInPerSec":          TopicAggregationSum,
+		},
+		brokerRateMeters:  NewRateMeterCache(0),
+		topicRateMeters:   NewRateMeterCache(maxTrackedTopicRateMeters),
+		clusterRateMeters: NewRateMeterCache(0),
+	}
+}
+
+// SetTopicAggregationPolicy configures how a specific topic metric is
+// combined across the per-broker AddTopicMetric calls that feed it. The
+// default policy sums every metric, matching the pre-existing behavior.
+func (a *MetricAggregator) SetTopicAggregationPolicy(metricName string, fn TopicAggregationFunc) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.topicAggregationPolicy[metricName] = fn
+}
+
+// combine applies the configured aggregation policy for metricName to
+// (existing, incoming, sampleCount) and returns the new value.
+func (a *MetricAggregator) combine(topicName, metricName string, existing, incoming float64, sampleCount int) float64 {
+	switch a.topicAggregationPolicy[metricName] {
+	case TopicAggregationMax:
+		if incoming > existing {
+			return incoming
+		}
+		return existing
+	case TopicAggregationAverage:
+		// Running average: new = old + (incoming-old)/n
+		return existing + (incoming-existing)/float64(sampleCount)
+	case TopicAggregationSum:
+		fallthrough
+	default:
+		return existing + incoming
 	}
 }
 
@@ -80,7 +244,19 @@ func (a *MetricAggregator) AddBrokerMetric(brokerID string, metric *BrokerMetric
 	// Update controller metrics if this is the controller
 	if metric.IsController {
 		a.controllerMetrics.ActiveControllerCount = 1
+		a.controllerBrokerID = brokerID
 	}
+
+	a.updateBrokerRateMeters(brokerID, metric)
+}
+
+// updateBrokerRateMeters folds brokerID's current-cycle throughput into its
+// rate meters; callers must hold a.mu.
+func (a *MetricAggregator) updateBrokerRateMeters(brokerID string, metric *BrokerMetrics) {
+	now := time.Now()
+	a.brokerRateMeters.Update(brokerID, "BytesInPerSec", metric.BytesInPerSec, now)
+	a.brokerRateMeters.Update(brokerID, "BytesOutPerSec", metric.BytesOutPerSec, now)
+	a.brokerRateMeters.Update(brokerID, "MessagesInPerSec", metric.MessagesInPerSec, now)
 }
 
 // AddTopicMetric adds or updates metrics for a topic
@@ -88,16 +264,25 @@ func (a *MetricAggregator) AddTopicMetric(topicName string, metric *TopicMetrics
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	a.topicSampleCounts[topicName]++
+	count := a.topicSampleCounts[topicName]
+
 	if a.topicMetrics[topicName] == nil {
 		a.topicMetrics[topicName] = metric
 	} else {
-		// Aggregate with existing metrics
+		// Combine with existing metrics per the configured per-metric policy
 		existing := a.topicMetrics[topicName]
-		existing.BytesInPerSec += metric.BytesInPerSec
-		existing.BytesOutPerSec += metric.BytesOutPerSec
-		existing.MessagesInPerSec += metric.MessagesInPerSec
-		existing.BytesRejectedPerSec += metric.BytesRejectedPerSec
+		existing.BytesInPerSec = a.combine(topicName, "BytesInPerSec", existing.BytesInPerSec, metric.BytesInPerSec, count)
+		existing.BytesOutPerSec = a.combine(topicName, "BytesOutPerSec", existing.BytesOutPerSec, metric.BytesOutPerSec, count)
+		existing.MessagesInPerSec = a.combine(topicName, "MessagesInPerSec", existing.MessagesInPerSec, metric.MessagesInPerSec, count)
+		existing.BytesRejectedPerSec = a.combine(topicName, "BytesRejectedPerSec", existing.BytesRejectedPerSec, metric.BytesRejectedPerSec, count)
 	}
+
+	current := a.topicMetrics[topicName]
+	now := time.Now()
+	a.topicRateMeters.Update(topicName, "BytesInPerSec", current.BytesInPerSec, now)
+	a.topicRateMeters.Update(topicName, "BytesOutPerSec", current.BytesOutPerSec, now)
+	a.topicRateMeters.Update(topicName, "MessagesInPerSec", current.MessagesInPerSec, now)
 }
 
 // GetClusterMetrics returns aggregated cluster-wide metrics
@@ -111,17 +296,113 @@ func (a *MetricAggregator) GetClusterMetrics() *ClusterAggregatedMetrics {
 		GlobalTopicCount:       len(a.topicMetrics),
 	}
 
-	// Aggregate across all brokers
+	// GlobalPartitionCount and UnderReplicatedPartitions are cluster-wide
+	// counts, not per-broker ones: summing every broker's view of them
+	// triple-counts each partition once per replica. The controller's own
+	// sample is authoritative for these, so prefer it when known.
+	if controller, ok := a.brokerMetrics[a.controllerBrokerID]; ok {
+		result.GlobalPartitionCount = controller.PartitionCount
+		result.UnderReplicatedPartitions = controller.UnderReplicatedPartitions
+	} else {
+		var underReplicated float64
+		for _, broker := range a.brokerMetrics {
+			result.GlobalPartitionCount += broker.PartitionCount
+			underReplicated = a.clusterCombine("UnderReplicatedPartitions", underReplicated, float64(broker.UnderReplicatedPartitions))
+		}
+		result.UnderReplicatedPartitions = int(underReplicated)
+	}
+
+	// Byte rates are genuinely per-broker (each broker serves its own
+	// leader partitions), so they still sum across every broker.
 	for _, broker := range a.brokerMetrics {
-		result.GlobalPartitionCount += broker.PartitionCount
-		result.UnderReplicatedPartitions += broker.UnderReplicatedPartitions
-		result.BytesInPerSec += broker.BytesInPerSec
-		result.BytesOutPerSec += broker.BytesOutPerSec
+		result.BytesInPerSec = a.clusterCombine("BytesInPerSec", result.BytesInPerSec, broker.BytesInPerSec)
+		result.BytesOutPerSec = a.clusterCombine("BytesOutPerSec", result.BytesOutPerSec, broker.BytesOutPerSec)
+		result.MessagesInPerSec = a.clusterCombine("MessagesInPerSec", result.MessagesInPerSec, broker.MessagesInPerSec)
 	}
 
 	return result
 }
 
+// UpdateClusterRateMeters folds one cycle's scalar cluster throughput
+// (typically data, as just computed by GetClusterMetrics) into the
+// cluster-wide rate meters, so repeated Flush cycles build true 1m/5m/15m
+// EWMAs instead of each cycle only reporting its own instantaneous value.
+func (a *MetricAggregator) UpdateClusterRateMeters(data *ClusterAggregatedMetrics) {
+	now := time.Now()
+	a.clusterRateMeters.Update(clusterRateMeterKey, "BytesInPerSec", data.BytesInPerSec, now)
+	a.clusterRateMeters.Update(clusterRateMeterKey, "BytesOutPerSec", data.BytesOutPerSec, now)
+	a.clusterRateMeters.Update(clusterRateMeterKey, "MessagesInPerSec", data.MessagesInPerSec, now)
+}
+
+// ClusterRateSnapshot returns the cluster-wide 1m/5m/15m EWMA rates for
+// BytesInPerSec, BytesOutPerSec and MessagesInPerSec, or nil before the
+// first UpdateClusterRateMeters call.
+func (a *MetricAggregator) ClusterRateSnapshot() map[string]RateMeterSnapshot {
+	return a.clusterRateMeters.Snapshot(clusterRateMeterKey)
+}
+
+// TopicRateSnapshot returns topicName's current 1m/5m/15m EWMA rates, or
+// nil if the topic has no rate history resident (never observed, or
+// evicted under cardinality pressure).
+func (a *MetricAggregator) TopicRateSnapshot(topicName string) map[string]RateMeterSnapshot {
+	return a.topicRateMeters.Snapshot(topicName)
+}
+
+// BrokerRateSnapshot is the broker-level equivalent of TopicRateSnapshot.
+func (a *MetricAggregator) BrokerRateSnapshot(brokerID string) map[string]RateMeterSnapshot {
+	return a.brokerRateMeters.Snapshot(brokerID)
+}
+
+// RateMeterStats reports the topic-rate-meter cache's current residency,
+// eviction count and lifetime sample count, for provider.shim.*
+// self-metrics that let operators see whether the bounded-cardinality guard
+// is actively dropping topics.
+type RateMeterStats struct {
+	TrackedTopics int
+	Evictions     int64
+	Samples       int64
+}
+
+// TopicRateMeterStats returns the current RateMeterStats for topicRateMeters.
+func (a *MetricAggregator) TopicRateMeterStats() RateMeterStats {
+	return RateMeterStats{
+		TrackedTopics: a.topicRateMeters.Len(),
+		Evictions:     a.topicRateMeters.Evictions(),
+		Samples:       a.topicRateMeters.Samples(),
+	}
+}
+
+// ClusterTopology is a point-in-time snapshot of controller and
+// partition-leader assignment, refreshed on each flush so tests and
+// downstream consumers can ask "who's the controller" and "who leads
+// topic X partition Y" without reaching back into JMX samples.
+type ClusterTopology struct {
+	ControllerBrokerID string
+	PartitionLeaders   map[string]string // topic#partition -> leader broker ID
+}
+
+// Topology returns the current ClusterTopology snapshot.
+func (a *MetricAggregator) Topology() *ClusterTopology {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	leaders := make(map[string]string, len(a.partitionLeaders))
+	for k, v := range a.partitionLeaders {
+		leaders[k] = v
+	}
+
+	return &ClusterTopology{
+		ControllerBrokerID: a.controllerBrokerID,
+		PartitionLeaders:   leaders,
+	}
+}
+
+// partitionLeaderKey builds the "topic#partition" key partitionLeaders is
+// indexed by.
+func partitionLeaderKey(topic string, partition int32) string {
+	return fmt.Sprintf("%s#%d", topic, partition)
+}
+
 // GetTopicMetrics returns aggregated metrics for a specific topic
 func (a *MetricAggregator) GetTopicMetrics(topicName string) *TopicMetrics {
 	a.mu.RLock()
@@ -134,7 +415,10 @@ func (a *MetricAggregator) GetTopicMetrics(topicName string) *TopicMetrics {
 	return &TopicMetrics{Name: topicName}
 }
 
-// Reset clears all aggregated metrics
+// Reset clears every per-cycle aggregated metric, but deliberately leaves
+// brokerRateMeters/topicRateMeters/clusterRateMeters untouched -- their
+// whole purpose is to carry rate history across the per-cycle reset Flush
+// triggers, so clearing them here would defeat it.
 func (a *MetricAggregator) Reset() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -143,6 +427,12 @@ func (a *MetricAggregator) Reset() {
 	a.topicMetrics = make(map[string]*TopicMetrics)
 	a.controllerMetrics = &ControllerMetrics{}
 	a.consumerLagMetrics = make(map[string]map[string]float64)
+	a.consumerPartitionLag = make(map[string]map[string]map[int32]float64)
+	a.partitionMetrics = make(map[string]map[int32]*PartitionInfo)
+	a.topicSampleCounts = make(map[string]int)
+	a.partitionLeaders = make(map[string]string)
+	a.controllerBrokerID = ""
+	a.brokerLatencyDigests = make(map[string]map[string]*TDigest)
 	a.lastAggregation = time.Now()
 }
 
@@ -157,6 +447,95 @@ func (a *MetricAggregator) AddConsumerLag(topicName, consumerGroup string, lag f
 	a.consumerLagMetrics[topicName][consumerGroup] = lag
 }
 
+// PartitionInfo is the latest topology SimpleTransformPartitionMetrics
+// recorded for one topic/partition, mirroring the provider.* fields its
+// AwsMskTopicPartitionSample reports.
+type PartitionInfo struct {
+	Leader          int32
+	Replicas        int
+	ISR             int
+	LogStartOffset  int64
+	LogEndOffset    int64
+	UnderReplicated bool
+	PreferredLeader bool
+}
+
+// AddPartitionMetrics records info as the latest topology for topic/
+// partition, so GetPartitionCount/GetUnderReplicatedPartitionCount reflect
+// real per-partition state instead of an estimate.
+func (a *MetricAggregator) AddPartitionMetrics(topic string, partition int32, info *PartitionInfo) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, exists := a.partitionMetrics[topic]; !exists {
+		a.partitionMetrics[topic] = make(map[int32]*PartitionInfo)
+	}
+	a.partitionMetrics[topic][partition] = info
+}
+
+// GetPartitionCount returns the total number of partitions recorded across
+// every topic via AddPartitionMetrics.
+func (a *MetricAggregator) GetPartitionCount() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	count := 0
+	for _, partitions := range a.partitionMetrics {
+		count += len(partitions)
+	}
+	return count
+}
+
+// GetUnderReplicatedPartitionCount returns how many recorded partitions
+// currently have UnderReplicated set.
+func (a *MetricAggregator) GetUnderReplicatedPartitionCount() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	count := 0
+	for _, partitions := range a.partitionMetrics {
+		for _, info := range partitions {
+			if info.UnderReplicated {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// AddConsumerPartitionLag records lag as the latest value for consumerGroup
+// reading topic/partition, so ConsumerGroupLagStats can compute a real
+// max/sum across every partition the group consumes.
+func (a *MetricAggregator) AddConsumerPartitionLag(topic, consumerGroup string, partition int32, lag float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, exists := a.consumerPartitionLag[topic]; !exists {
+		a.consumerPartitionLag[topic] = make(map[string]map[int32]float64)
+	}
+	if _, exists := a.consumerPartitionLag[topic][consumerGroup]; !exists {
+		a.consumerPartitionLag[topic][consumerGroup] = make(map[int32]float64)
+	}
+	a.consumerPartitionLag[topic][consumerGroup][partition] = lag
+}
+
+// ConsumerGroupLagStats returns the max and sum lag across every partition
+// of topic consumerGroup has reported via AddConsumerPartitionLag so far
+// this cycle, plus how many partitions that covers.
+func (a *MetricAggregator) ConsumerGroupLagStats(topic, consumerGroup string) (maxLag, sumLag float64, partitionCount int) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	partitions := a.consumerPartitionLag[topic][consumerGroup]
+	for _, lag := range partitions {
+		if lag > maxLag {
+			maxLag = lag
+		}
+		sumLag += lag
+	}
+	return maxLag, sumLag, len(partitions)
+}
+
 // AddSimpleBrokerMetric adds a simple broker metric (for use by transformer)
 func (a *MetricAggregator) AddSimpleBrokerMetric(metricName string, value interface{}) {
 	// This is a simplified version - in production you'd track per broker
@@ -202,6 +581,42 @@ func (a *MetricAggregator) GetTopicCount() int {
 	return len(a.topicMetrics)
 }
 
+// TopicNames returns the names of every topic currently known to the
+// aggregator, whether reported via JMX samples or AddDescribedTopic.
+func (a *MetricAggregator) TopicNames() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	names := make([]string, 0, len(a.topicMetrics))
+	for name := range a.topicMetrics {
+		names = append(names, name)
+	}
+	return names
+}
+
+// BrokerIDs returns the IDs of every broker currently known to the
+// aggregator, whether reported via JMX samples or AddDescribedCluster.
+func (a *MetricAggregator) BrokerIDs() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	ids := make([]string, 0, len(a.brokerMetrics))
+	for id := range a.brokerMetrics {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// IsControllerBroker reports whether brokerID is the cluster's current
+// controller, per the most recent AddDescribedCluster/AddBrokerMetrics call.
+func (a *MetricAggregator) IsControllerBroker(brokerID string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	broker, ok := a.brokerMetrics[brokerID]
+	return ok && broker.IsController
+}
+
 // GetAggregatedMetrics returns aggregated metrics for cluster level
 func (a *MetricAggregator) GetAggregatedMetrics() map[string]interface{} {
 	a.mu.RLock()
@@ -211,38 +626,148 @@ func (a *MetricAggregator) GetAggregatedMetrics() map[string]interface{} {
 	return make(map[string]interface{})
 }
 
-// AddBrokerMetrics adds broker metrics from a data map (for transformer)
-func (a *MetricAggregator) AddBrokerMetrics(brokerID string, brokerData map[string]interface{}) {
+// AddBrokerMetrics adds broker metrics from a data map (for transformer).
+// isController reflects the ControllerResolver's authoritative view of
+// whether brokerID is the current controller, not anything present in
+// brokerData -- a misconfigured source reporting its own
+// controller.activeControllerCount must not be able to corrupt the
+// cluster-level ActiveControllerCount this feeds into GetClusterMetrics.
+func (a *MetricAggregator) AddBrokerMetrics(brokerID string, brokerData map[string]interface{}, isController bool) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	
+
 	// Convert to BrokerMetrics struct
 	metric := &BrokerMetrics{
-		LastUpdated: time.Now(),
+		IsController: isController,
+		LastUpdated:  time.Now(),
 	}
-	
+
 	// Extract metrics from brokerData
 	if idInt, ok := getIntValue(brokerData, "broker.id"); ok {
 		metric.BrokerID = idInt
 	}
-	
+
 	if bytesIn, ok := getFloatValue(brokerData, "broker.IOInPerSecond"); ok {
 		metric.BytesInPerSec = bytesIn
 	}
-	
+
 	if bytesOut, ok := getFloatValue(brokerData, "broker.IOOutPerSecond"); ok {
 		metric.BytesOutPerSec = bytesOut
 	}
-	
+
 	if messagesIn, ok := getFloatValue(brokerData, "broker.messagesInPerSecond"); ok {
 		metric.MessagesInPerSec = messagesIn
 	}
-	
+
 	if underReplicated, ok := getFloatValue(brokerData, "replication.unreplicatedPartitions"); ok {
 		metric.UnderReplicatedPartitions = int(underReplicated)
 	}
-	
+
+	if zkLatency, ok := getFloatValue(brokerData, "broker.zooKeeperRequestLatencyMsMean"); ok {
+		metric.ZooKeeperRequestLatencyMsMean = zkLatency
+	}
+
 	a.brokerMetrics[brokerID] = metric
+
+	if isController {
+		a.controllerBrokerID = brokerID
+	} else if a.controllerBrokerID == brokerID {
+		// This broker used to be the controller and no longer is -- clear
+		// the stale pointer rather than leaving GetClusterMetrics reading
+		// GlobalPartitionCount/UnderReplicatedPartitions off a broker that
+		// lost an election.
+		a.controllerBrokerID = ""
+	}
+
+	a.updateBrokerRateMeters(brokerID, metric)
+}
+
+// AddBrokerLatencySample records one (metricName, value) observation into
+// brokerID's per-metric TDigest, used by ClusterLatencyPercentiles to
+// compute real cross-broker percentiles instead of a sum or average of
+// latency values. Metric names outside clusterLatencyMetrics are ignored.
+func (a *MetricAggregator) AddBrokerLatencySample(brokerID, metricName string, value float64) {
+	if !clusterLatencyMetrics[metricName] {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.brokerLatencyDigests == nil {
+		a.brokerLatencyDigests = make(map[string]map[string]*TDigest)
+	}
+	perMetric, ok := a.brokerLatencyDigests[brokerID]
+	if !ok {
+		perMetric = make(map[string]*TDigest)
+		a.brokerLatencyDigests[brokerID] = perMetric
+	}
+	digest, ok := perMetric[metricName]
+	if !ok {
+		digest = NewTDigest(latencyDigestCompression)
+		perMetric[metricName] = digest
+	}
+	digest.Add(value, 1)
+}
+
+// ClusterLatencyPercentiles merges every broker's per-metric TDigest into a
+// cluster-wide digest and returns p50/p95/p99 for each clusterLatencyMetrics
+// name with at least one sample, then resets every broker's digests so the
+// next call reflects only samples recorded since.
+func (a *MetricAggregator) ClusterLatencyPercentiles() map[string]LatencyQuantiles {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	merged := make(map[string]*TDigest, len(clusterLatencyMetrics))
+	for metricName := range clusterLatencyMetrics {
+		merged[metricName] = NewTDigest(latencyDigestCompression)
+	}
+	for _, perMetric := range a.brokerLatencyDigests {
+		for metricName, digest := range perMetric {
+			if cluster, ok := merged[metricName]; ok {
+				cluster.Merge(digest)
+			}
+		}
+	}
+
+	result := make(map[string]LatencyQuantiles, len(merged))
+	for metricName, digest := range merged {
+		if digest.Count() == 0 {
+			continue
+		}
+		result[metricName] = LatencyQuantiles{
+			P50:   digest.Quantile(0.50),
+			P95:   digest.Quantile(0.95),
+			P99:   digest.Quantile(0.99),
+			Count: digest.Count(),
+		}
+	}
+
+	for _, perMetric := range a.brokerLatencyDigests {
+		for _, digest := range perMetric {
+			digest.Reset()
+		}
+	}
+
+	return result
+}
+
+// ActiveControllerCount reports how many known brokers the
+// ControllerResolver currently considers the controller -- exactly 1 in
+// steady state, 0 only during a real election, and never >1 since
+// ControllerResolver resolves a single authoritative ID for every broker
+// in the same poll cycle.
+func (a *MetricAggregator) ActiveControllerCount() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	count := 0
+	for _, broker := range a.brokerMetrics {
+		if broker.IsController {
+			count++
+		}
+	}
+	return count
 }
 
 // AddTopicMetrics adds topic metrics from a data map (for transformer)
@@ -267,8 +792,13 @@ func (a *MetricAggregator) AddTopicMetrics(topicName string, topicData map[strin
 	if messagesIn, ok := getFloatValue(topicData, "topic.messagesInPerSecond"); ok {
 		metric.MessagesInPerSec = messagesIn
 	}
-	
+
 	a.topicMetrics[topicName] = metric
+
+	now := time.Now()
+	a.topicRateMeters.Update(topicName, "BytesInPerSec", metric.BytesInPerSec, now)
+	a.topicRateMeters.Update(topicName, "BytesOutPerSec", metric.BytesOutPerSec, now)
+	a.topicRateMeters.Update(topicName, "MessagesInPerSec", metric.MessagesInPerSec, now)
 }
 
 // AddConsumerLagMetrics adds consumer lag metrics (for transformer)
@@ -0,0 +1,137 @@
+// Package awsenrich calls the real AWS MSK control plane
+// (DescribeClusterV2 and ListNodes) to replace
+// SimpleTransformClusterMetrics's hardcoded cluster-level defaults
+// (brokerCount=3, clusterStatus="HEALTHY", state="ACTIVE", and the absent
+// storage/encryption/Kafka-version attributes) with what AWS actually
+// provisioned - the same fields the AWS Terraform MSK resource exposes.
+package awsenrich
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// DefaultTTL is how long Client caches a successful DescribeClusterV2/
+// ListNodes answer before refreshing it, chosen to stay well clear of the
+// MSK control plane's rate limits.
+const DefaultTTL = 5 * time.Minute
+
+// ClusterInfo is the subset of AWS's DescribeClusterV2/ListNodes answer
+// SimpleTransformClusterMetrics needs to replace its hardcoded cluster-level
+// defaults.
+type ClusterInfo struct {
+	NumberOfBrokerNodes int
+	ClusterStatus       string // AWS's State: ACTIVE, CREATING, FAILED, etc.
+	KafkaVersion        string
+	EnhancedMonitoring  string
+	InstanceType        string
+	EBSVolumeSize       int
+	EncryptionAtRest    bool
+	Tags                map[string]string
+}
+
+// api is the subset of *kafka.Client Client depends on, so a fake can stand
+// in for the real AWS SDK client in place of talking to AWS.
+type api interface {
+	DescribeClusterV2(ctx context.Context, params *kafka.DescribeClusterV2Input, optFns ...func(*kafka.Options)) (*kafka.DescribeClusterV2Output, error)
+	ListNodes(ctx context.Context, params *kafka.ListNodesInput, optFns ...func(*kafka.Options)) (*kafka.ListNodesOutput, error)
+}
+
+// Client calls the AWS MSK control plane for one cluster ARN and caches the
+// answer for ttl, falling back to the last good cache entry (nil before the
+// first successful fetch) if a refresh fails.
+type Client struct {
+	api        api
+	clusterARN string
+	ttl        time.Duration
+
+	mu        sync.Mutex
+	cached    *ClusterInfo
+	fetchedAt time.Time
+}
+
+// NewClient loads AWS's default credential chain (environment, shared
+// config, EC2/ECS instance role) for region and returns a Client that
+// enriches clusterARN's cluster sample. ttl <= 0 falls back to DefaultTTL.
+func NewClient(ctx context.Context, region, clusterARN string, ttl time.Duration) (*Client, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS credentials: %w", err)
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Client{api: kafka.NewFromConfig(cfg), clusterARN: clusterARN, ttl: ttl}, nil
+}
+
+// Describe returns the cached ClusterInfo, refreshing it first if ttl has
+// elapsed since the last successful fetch. A refresh failure logs and
+// returns the last good cache entry, so a transient AWS API error falls
+// back to whatever defaults the caller already has instead of failing the
+// whole cluster sample.
+func (c *Client) Describe(ctx context.Context) *ClusterInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.ttl {
+		return c.cached
+	}
+
+	info, err := c.fetch(ctx)
+	if err != nil {
+		log.Warn("awsenrich: refreshing cluster info failed, falling back to cached/default values: %v", err)
+		return c.cached
+	}
+	c.cached = info
+	c.fetchedAt = time.Now()
+	return c.cached
+}
+
+func (c *Client) fetch(ctx context.Context) (*ClusterInfo, error) {
+	resp, err := c.api.DescribeClusterV2(ctx, &kafka.DescribeClusterV2Input{ClusterArn: aws.String(c.clusterARN)})
+	if err != nil {
+		return nil, fmt.Errorf("DescribeClusterV2: %w", err)
+	}
+	cluster := resp.ClusterInfo
+	if cluster == nil {
+		return nil, fmt.Errorf("DescribeClusterV2 returned no cluster info")
+	}
+
+	info := &ClusterInfo{
+		ClusterStatus: string(cluster.State),
+		Tags:          cluster.Tags,
+	}
+
+	if provisioned := cluster.Provisioned; provisioned != nil {
+		if provisioned.CurrentBrokerSoftwareInfo != nil {
+			info.KafkaVersion = aws.ToString(provisioned.CurrentBrokerSoftwareInfo.KafkaVersion)
+		}
+		info.EnhancedMonitoring = string(provisioned.EnhancedMonitoring)
+		if provisioned.EncryptionInfo != nil && provisioned.EncryptionInfo.EncryptionAtRest != nil {
+			info.EncryptionAtRest = true
+		}
+		if nodeGroup := provisioned.BrokerNodeGroupInfo; nodeGroup != nil {
+			info.InstanceType = aws.ToString(nodeGroup.InstanceType)
+			if storage := nodeGroup.StorageInfo; storage != nil && storage.EbsStorageInfo != nil {
+				info.EBSVolumeSize = int(aws.ToInt32(storage.EbsStorageInfo.VolumeSize))
+			}
+		}
+	}
+
+	nodes, err := c.api.ListNodes(ctx, &kafka.ListNodesInput{ClusterArn: aws.String(c.clusterARN)})
+	if err != nil {
+		log.Debug("awsenrich: ListNodes failed, numberOfBrokerNodes left unset: %v", err)
+	} else {
+		info.NumberOfBrokerNodes = len(nodes.NodeInfoList)
+	}
+
+	return info, nil
+}
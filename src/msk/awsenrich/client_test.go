@@ -0,0 +1,128 @@
+package awsenrich
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI is a test double for the subset of *kafka.Client Client depends on.
+type fakeAPI struct {
+	describeCalls int
+	describeOut   *kafka.DescribeClusterV2Output
+	describeErr   error
+
+	listNodesOut *kafka.ListNodesOutput
+	listNodesErr error
+}
+
+func (f *fakeAPI) DescribeClusterV2(ctx context.Context, params *kafka.DescribeClusterV2Input, optFns ...func(*kafka.Options)) (*kafka.DescribeClusterV2Output, error) {
+	f.describeCalls++
+	return f.describeOut, f.describeErr
+}
+
+func (f *fakeAPI) ListNodes(ctx context.Context, params *kafka.ListNodesInput, optFns ...func(*kafka.Options)) (*kafka.ListNodesOutput, error) {
+	return f.listNodesOut, f.listNodesErr
+}
+
+func newTestClient(api *fakeAPI, ttl time.Duration) *Client {
+	return &Client{api: api, clusterARN: "arn:aws:kafka:us-east-1:123456789012:cluster/test/abc", ttl: ttl}
+}
+
+func TestDescribe_MapsProvisionedClusterFields(t *testing.T) {
+	api := &fakeAPI{
+		describeOut: &kafka.DescribeClusterV2Output{
+			ClusterInfo: &types.Cluster{
+				State: types.ClusterStateActive,
+				Tags:  map[string]string{"env": "test"},
+				Provisioned: &types.Provisioned{
+					CurrentBrokerSoftwareInfo: &types.BrokerSoftwareInfo{KafkaVersion: aws.String("3.5.1")},
+					EnhancedMonitoring:        types.EnhancedMonitoringPerTopicPerBroker,
+					EncryptionInfo: &types.EncryptionInfo{
+						EncryptionAtRest: &types.EncryptionAtRest{DataVolumeKMSKeyId: aws.String("key-id")},
+					},
+					BrokerNodeGroupInfo: &types.BrokerNodeGroupInfo{
+						InstanceType: aws.String("kafka.m5.large"),
+						StorageInfo: &types.StorageInfo{
+							EbsStorageInfo: &types.EBSStorageInfo{VolumeSize: aws.Int32(200)},
+						},
+					},
+				},
+			},
+		},
+		listNodesOut: &kafka.ListNodesOutput{NodeInfoList: []types.NodeInfo{{}, {}, {}}},
+	}
+
+	c := newTestClient(api, time.Minute)
+	info := c.Describe(context.Background())
+
+	require.NotNil(t, info)
+	assert.Equal(t, "ACTIVE", info.ClusterStatus)
+	assert.Equal(t, "3.5.1", info.KafkaVersion)
+	assert.Equal(t, string(types.EnhancedMonitoringPerTopicPerBroker), info.EnhancedMonitoring)
+	assert.True(t, info.EncryptionAtRest)
+	assert.Equal(t, "kafka.m5.large", info.InstanceType)
+	assert.Equal(t, 200, info.EBSVolumeSize)
+	assert.Equal(t, 3, info.NumberOfBrokerNodes)
+	assert.Equal(t, map[string]string{"env": "test"}, info.Tags)
+}
+
+func TestDescribe_CachesUntilTTLElapses(t *testing.T) {
+	api := &fakeAPI{
+		describeOut: &kafka.DescribeClusterV2Output{ClusterInfo: &types.Cluster{State: types.ClusterStateActive}},
+		listNodesOut: &kafka.ListNodesOutput{},
+	}
+	c := newTestClient(api, time.Hour)
+
+	first := c.Describe(context.Background())
+	second := c.Describe(context.Background())
+
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, api.describeCalls, "a live TTL should serve the second call from cache, not refetch")
+}
+
+func TestDescribe_RefreshFailure_FallsBackToLastGoodCache(t *testing.T) {
+	api := &fakeAPI{
+		describeOut: &kafka.DescribeClusterV2Output{ClusterInfo: &types.Cluster{State: types.ClusterStateActive}},
+		listNodesOut: &kafka.ListNodesOutput{},
+	}
+	c := newTestClient(api, time.Nanosecond)
+
+	first := c.Describe(context.Background())
+	require.NotNil(t, first)
+
+	api.describeErr = errors.New("DescribeClusterV2: throttled")
+	time.Sleep(time.Millisecond)
+	second := c.Describe(context.Background())
+
+	assert.Same(t, first, second, "a failed refresh should fall back to the last good cache entry")
+}
+
+func TestDescribe_NoCacheYetAndFetchFails_ReturnsNil(t *testing.T) {
+	api := &fakeAPI{describeErr: errors.New("DescribeClusterV2: access denied")}
+	c := newTestClient(api, time.Minute)
+
+	info := c.Describe(context.Background())
+
+	assert.Nil(t, info)
+}
+
+func TestDescribe_ListNodesFailure_LeavesNumberOfBrokerNodesUnset(t *testing.T) {
+	api := &fakeAPI{
+		describeOut:  &kafka.DescribeClusterV2Output{ClusterInfo: &types.Cluster{State: types.ClusterStateActive}},
+		listNodesErr: errors.New("ListNodes: throttled"),
+	}
+	c := newTestClient(api, time.Minute)
+
+	info := c.Describe(context.Background())
+
+	require.NotNil(t, info)
+	assert.Equal(t, 0, info.NumberOfBrokerNodes)
+}
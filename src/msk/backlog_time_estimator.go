@@ -0,0 +1,115 @@
+package msk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// RecordTimestampSource is the minimal Kafka surface BacklogTimeEstimator
+// needs to turn an offset into wall-clock backlog time.
+type RecordTimestampSource interface {
+	// LatestOffset returns the current high-water-mark offset for the partition.
+	LatestOffset(topic string, partition int32) (int64, error)
+	// CommittedOffset returns the group's committed offset for the partition.
+	CommittedOffset(group, topic string, partition int32) (int64, error)
+	// RecordTimestamp returns the record timestamp (ms since epoch) stored
+	// at offset, via a single-record fetch. Returns ok=false when the
+	// broker's message format predates 0.10 and carries no timestamps.
+	RecordTimestamp(topic string, partition int32, offset int64) (millis int64, ok bool, err error)
+}
+
+type backlogCacheEntry struct {
+	backlogSeconds float64
+	expiresAt      time.Time
+}
+
+// BacklogTimeEstimator computes how far behind (in wall-clock seconds) a
+// consumer group's committed offset is, by looking up the timestamp of the
+// record at that offset rather than dividing lag by messages/sec -- which
+// is wildly inaccurate under bursty traffic or on idle topics
+// (lag / messagesInPerSec divides by near-zero).
+type BacklogTimeEstimator struct {
+	source RecordTimestampSource
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]backlogCacheEntry
+}
+
+// NewBacklogTimeEstimator creates an estimator that caches per-partition
+// results for ttl to avoid issuing a ListOffsets/fetch pair every tick.
+func NewBacklogTimeEstimator(source RecordTimestampSource, ttl time.Duration) *BacklogTimeEstimator {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &BacklogTimeEstimator{
+		source: source,
+		ttl:    ttl,
+		cache:  make(map[string]backlogCacheEntry),
+	}
+}
+
+func cacheKey(topic string, partition int32, group string) string {
+	return fmt.Sprintf("%s|%d|%s", topic, partition, group)
+}
+
+// BacklogSeconds returns how many seconds of backlog the group has on
+// (topic, partition): now minus the record timestamp at the committed
+// offset. When messageFormatHasTimestamps is false (pre-0.10 broker), the
+// caller should fall back to the legacy messages/sec estimate instead.
+func (e *BacklogTimeEstimator) BacklogSeconds(group, topic string, partition int32, messageFormatHasTimestamps bool) (float64, error) {
+	key := cacheKey(topic, partition, group)
+
+	e.mu.Lock()
+	if entry, ok := e.cache[key]; ok && time.Now().Before(entry.expiresAt) {
+		e.mu.Unlock()
+		return entry.backlogSeconds, nil
+	}
+	e.mu.Unlock()
+
+	if !messageFormatHasTimestamps {
+		return 0, fmt.Errorf("%s: broker message format predates 0.10, no record timestamps available", key)
+	}
+
+	committed, err := e.source.CommittedOffset(group, topic, partition)
+	if err != nil {
+		return 0, fmt.Errorf("fetching committed offset for %s: %w", key, err)
+	}
+
+	recordMillis, ok, err := e.source.RecordTimestamp(topic, partition, committed)
+	if err != nil {
+		return 0, fmt.Errorf("fetching record timestamp for %s at offset %d: %w", key, committed, err)
+	}
+	if !ok {
+		return 0, fmt.Errorf("%s: no timestamp available at offset %d", key, committed)
+	}
+
+	backlogSeconds := time.Since(time.UnixMilli(recordMillis)).Seconds()
+	if backlogSeconds < 0 {
+		backlogSeconds = 0
+	}
+
+	e.mu.Lock()
+	e.cache[key] = backlogCacheEntry{backlogSeconds: backlogSeconds, expiresAt: time.Now().Add(e.ttl)}
+	e.mu.Unlock()
+
+	return backlogSeconds, nil
+}
+
+// EstimateOrFallback returns the timestamp-based backlog seconds when
+// possible, otherwise the legacy lag/messagesInPerSec estimate used by the
+// existing enrichment path.
+func (e *BacklogTimeEstimator) EstimateOrFallback(group, topic string, partition int32, messageFormatHasTimestamps bool, lag, messagesInPerSec float64) float64 {
+	seconds, err := e.BacklogSeconds(group, topic, partition, messageFormatHasTimestamps)
+	if err != nil {
+		log.Debug("BacklogTimeEstimator falling back to messages/sec estimate for %s/%s[%d]: %v", group, topic, partition, err)
+		if messagesInPerSec <= 0 {
+			return 0
+		}
+		return lag / messagesInPerSec
+	}
+	return seconds
+}
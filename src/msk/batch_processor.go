@@ -0,0 +1,197 @@
+package msk
+
+import (
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// batchQueueCapacity bounds the in-memory queue BatchProcessor.Add feeds
+// into. Once full, Add blocks until the background flusher drains room,
+// giving producers real backpressure instead of an unbounded buffer.
+const batchQueueCapacity = 10000
+
+// BatchProcessor batches MessageQueueSample-style events per type and
+// flushes them to an EventAPIClient on a size or interval trigger, using
+// Config.BatchSize and Config.FlushInterval. A bounded queue provides
+// backpressure so a slow sink can't make the processor's memory grow
+// without limit, and per-type counters are exposed for the shim to
+// surface as self-telemetry.
+type BatchProcessor struct {
+	client        *EventAPIClient
+	maxBatch      int
+	flushInterval time.Duration
+
+	queue chan map[string]interface{}
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	pending map[string][]map[string]interface{}
+
+	countersMu sync.Mutex
+	counters   map[string]*batchTypeCounters
+}
+
+// batchTypeCounters tracks per-event-type submitted/retried/dropped
+// counts, mirroring the EventAPIClient's own counters but scoped to a
+// single sample type.
+type batchTypeCounters struct {
+	Submitted int64
+	Retried   int64
+	Dropped   int64
+}
+
+// NewBatchProcessor creates a BatchProcessor wired to config's batch size
+// and flush interval, and starts its background flush loop.
+func NewBatchProcessor(client *EventAPIClient, config *Config) *BatchProcessor {
+	maxBatch := config.BatchSize
+	if maxBatch <= 0 {
+		maxBatch = 1000
+	}
+	flushInterval := config.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = 5 * time.Second
+	}
+
+	bp := &BatchProcessor{
+		client:        client,
+		maxBatch:      maxBatch,
+		flushInterval: flushInterval,
+		queue:         make(chan map[string]interface{}, batchQueueCapacity),
+		done:          make(chan struct{}),
+		pending:       make(map[string][]map[string]interface{}),
+		counters:      make(map[string]*batchTypeCounters),
+	}
+
+	bp.wg.Add(1)
+	go bp.run()
+
+	return bp
+}
+
+// Add enqueues an event of the given sample type (e.g.
+// "AwsMskTopicSample"), blocking if the queue is full so producers feel
+// backpressure from a slow sink rather than growing memory unbounded.
+func (bp *BatchProcessor) Add(eventType string, event map[string]interface{}) {
+	enriched := make(map[string]interface{}, len(event)+1)
+	for k, v := range event {
+		enriched[k] = v
+	}
+	enriched["eventType"] = eventType
+
+	bp.queue <- enriched
+}
+
+// run drains the queue into per-type pending batches, flushing a type's
+// batch when it reaches maxBatch and flushing everything pending on each
+// flushInterval tick.
+func (bp *BatchProcessor) run() {
+	defer bp.wg.Done()
+
+	ticker := time.NewTicker(bp.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case evt := <-bp.queue:
+			eventType, _ := evt["eventType"].(string)
+
+			bp.mu.Lock()
+			bp.pending[eventType] = append(bp.pending[eventType], evt)
+			shouldFlush := len(bp.pending[eventType]) >= bp.maxBatch
+			bp.mu.Unlock()
+
+			if shouldFlush {
+				bp.flushType(eventType)
+			}
+
+		case <-ticker.C:
+			bp.flushAll()
+
+		case <-bp.done:
+			// Drain whatever is already queued before exiting.
+			for {
+				select {
+				case evt := <-bp.queue:
+					eventType, _ := evt["eventType"].(string)
+					bp.mu.Lock()
+					bp.pending[eventType] = append(bp.pending[eventType], evt)
+					bp.mu.Unlock()
+				default:
+					bp.flushAll()
+					return
+				}
+			}
+		}
+	}
+}
+
+// flushType sends the pending batch for a single event type.
+func (bp *BatchProcessor) flushType(eventType string) {
+	bp.mu.Lock()
+	batch := bp.pending[eventType]
+	delete(bp.pending, eventType)
+	bp.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	before := bp.client.Counters()
+	if err := bp.client.SendEvents(batch); err != nil {
+		log.Error("BatchProcessor: failed to flush %d %s events: %v", len(batch), eventType, err)
+	}
+	after := bp.client.Counters()
+
+	bp.countersMu.Lock()
+	c, ok := bp.counters[eventType]
+	if !ok {
+		c = &batchTypeCounters{}
+		bp.counters[eventType] = c
+	}
+	c.Submitted += after.Submitted - before.Submitted
+	c.Retried += after.Retried - before.Retried
+	c.Dropped += after.Dropped - before.Dropped
+	bp.countersMu.Unlock()
+}
+
+// flushAll flushes every event type with pending events.
+func (bp *BatchProcessor) flushAll() {
+	bp.mu.Lock()
+	types := make([]string, 0, len(bp.pending))
+	for eventType := range bp.pending {
+		types = append(types, eventType)
+	}
+	bp.mu.Unlock()
+
+	for _, eventType := range types {
+		bp.flushType(eventType)
+	}
+}
+
+// Counters returns a snapshot of submitted/retried/dropped counts per
+// event type, for the shim to surface as self-telemetry.
+func (bp *BatchProcessor) Counters() map[string]batchTypeCounters {
+	bp.countersMu.Lock()
+	defer bp.countersMu.Unlock()
+
+	out := make(map[string]batchTypeCounters, len(bp.counters))
+	for eventType, c := range bp.counters {
+		out[eventType] = *c
+	}
+	return out
+}
+
+// FlushAll forces an immediate flush of every event type with pending
+// events, rather than waiting for the next size or interval trigger.
+func (bp *BatchProcessor) FlushAll() {
+	bp.flushAll()
+}
+
+// Stop flushes any pending events and halts the background flush loop.
+func (bp *BatchProcessor) Stop() {
+	close(bp.done)
+	bp.wg.Wait()
+}
@@ -0,0 +1,174 @@
+package msk
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// maxResolvedBrokerID bounds the jump-consistent-hash fallback so unmapped
+// listeners still land in the same ID space ([0, maxResolvedBrokerID)) the rest of
+// the shim assumes, instead of the old hashString(host) % 1000 range.
+const maxResolvedBrokerID = 1000
+
+// BrokerIdentityResolver assigns stable broker IDs from a cluster's
+// advertised listener strings, replacing hashString's plain hash (which
+// collides easily and reassigns IDs whenever a hostname format changes,
+// e.g. rolling DNS updates or an IPv4->IPv6 switch). A clusterArn+listener
+// pairing is resolved once, persisted to disk, and reused forever after,
+// so broker entity GUIDs stay stable across restarts.
+type BrokerIdentityResolver struct {
+	mu         sync.Mutex
+	statePath  string
+	clusterArn string
+	assigned   map[string]int // "clusterArn|listener" -> broker ID
+	used       map[int]bool
+}
+
+// brokerIdentityState is broker_ids.json's on-disk shape.
+type brokerIdentityState struct {
+	Assigned map[string]int `json:"assigned"`
+}
+
+// NewBrokerIdentityResolver loads any existing $STATE_DIR/broker_ids.json
+// mapping for clusterArn. A missing or unreadable state file starts an
+// empty resolver rather than failing construction, since the mapping is
+// rebuilt lazily as brokers are resolved.
+func NewBrokerIdentityResolver(clusterArn, stateDir string) *BrokerIdentityResolver {
+	r := &BrokerIdentityResolver{
+		statePath:  filepath.Join(stateDir, "broker_ids.json"),
+		clusterArn: clusterArn,
+		assigned:   make(map[string]int),
+		used:       make(map[int]bool),
+	}
+
+	data, err := os.ReadFile(r.statePath)
+	if err != nil {
+		return r
+	}
+
+	var state brokerIdentityState
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Warn("BrokerIdentityResolver: ignoring unreadable state file %s: %v", r.statePath, err)
+		return r
+	}
+	for key, id := range state.Assigned {
+		r.assigned[key] = id
+		r.used[id] = true
+	}
+	return r
+}
+
+// ParseAdvertisedListener normalizes a Kafka advertised listener string
+// (e.g. "PLAINTEXT://broker-1.kafka.internal:9092") the same way a Kafka
+// client would: protocol lowercased, host lowercased, port kept verbatim.
+// A bare "host:port" or "host" with no protocol prefix is accepted too, so
+// callers don't need to know whether the source already included one.
+func ParseAdvertisedListener(listener string) string {
+	listener = strings.TrimSpace(listener)
+	if idx := strings.Index(listener, "://"); idx != -1 {
+		protocol := strings.ToLower(listener[:idx])
+		rest := listener[idx+3:]
+		return protocol + "://" + strings.ToLower(rest)
+	}
+	return strings.ToLower(listener)
+}
+
+// Resolve returns the stable broker ID for listener, assigning and
+// persisting one on first sight. Concurrent calls are serialized; the
+// state file is rewritten on every new assignment so a crash immediately
+// after can't lose it.
+func (r *BrokerIdentityResolver) Resolve(listener string) int {
+	normalized := ParseAdvertisedListener(listener)
+	key := r.clusterArn + "|" + normalized
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id, ok := r.assigned[key]; ok {
+		return id
+	}
+
+	id := r.nextAvailableID(normalized)
+	r.assigned[key] = id
+	r.used[id] = true
+	r.persist()
+	return id
+}
+
+// nextAvailableID walks forward from the jump-consistent-hash of
+// normalized until it finds an ID this cluster hasn't already assigned to
+// a different listener, so two listeners that hash to the same bucket
+// don't collide and silently merge into one broker entity.
+func (r *BrokerIdentityResolver) nextAvailableID(normalized string) int {
+	start := jumpConsistentHash(normalized, maxResolvedBrokerID)
+	for offset := 0; offset < maxResolvedBrokerID; offset++ {
+		candidate := (start + offset) % maxResolvedBrokerID
+		if !r.used[candidate] {
+			return candidate
+		}
+	}
+	return start
+}
+
+// persist writes the current mapping to statePath. A write failure is
+// logged and otherwise ignored: the in-memory mapping still makes this
+// process's assignments stable, even if they won't survive a restart.
+func (r *BrokerIdentityResolver) persist() {
+	if r.statePath == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.statePath), 0o755); err != nil {
+		log.Warn("BrokerIdentityResolver: failed to create state dir for %s: %v", r.statePath, err)
+		return
+	}
+
+	data, err := json.Marshal(brokerIdentityState{Assigned: r.assigned})
+	if err != nil {
+		log.Warn("BrokerIdentityResolver: failed to encode state: %v", err)
+		return
+	}
+	if err := os.WriteFile(r.statePath, data, 0o644); err != nil {
+		log.Warn("BrokerIdentityResolver: failed to write state file %s: %v", r.statePath, err)
+	}
+}
+
+// jumpConsistentHash is Lamping and Veach's jump consistent hash,
+// mapping key into [0, buckets) with minimal remapping as buckets grows.
+// Used here only for its even, deterministic spread across
+// [0, maxResolvedBrokerID); the "consistent under resizing" property
+// doesn't matter since maxResolvedBrokerID is fixed.
+func jumpConsistentHash(key string, buckets int) int {
+	h := fnv64a(key)
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		h = h*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((h>>33)+1)))
+	}
+	return int(b)
+}
+
+// fnv64a is the 64-bit FNV-1a hash, used as jumpConsistentHash's seed
+// since it distributes short strings like listener addresses evenly.
+func fnv64a(s string) int64 {
+	var h uint64 = 14695981039346656037
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	return int64(h)
+}
+
+// stateDirFromEnv returns $STATE_DIR, or os.TempDir()'s "nri-kafka-msk"
+// subdirectory if unset, mirroring DefaultRetryConfig's SpoolDir fallback.
+func stateDirFromEnv() string {
+	if dir := os.Getenv("STATE_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "nri-kafka-msk")
+}
@@ -0,0 +1,196 @@
+package msk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// BrokerCapacity holds a broker's theoretical resource capacity as plain
+// numbers. Cruise Control's capacity.json field names and string-encoded
+// numbers are read through capacityFileEntry, so an existing Cruise Control
+// capacity file can be reused verbatim.
+type BrokerCapacity struct {
+	DiskMB        float64
+	CPUPercent    float64
+	NetworkInKBs  float64
+	NetworkOutKBs float64
+}
+
+// capacityFileEntry is one element of capacity.json's brokerCapacities
+// array. brokerId "-1" is the cluster-wide default Cruise Control falls
+// back to for any broker without its own entry.
+type capacityFileEntry struct {
+	BrokerID string `json:"brokerId"`
+	Capacity struct {
+		Disk  string `json:"DISK"`
+		CPU   string `json:"CPU"`
+		NWIn  string `json:"NW_IN"`
+		NWOut string `json:"NW_OUT"`
+	} `json:"capacity"`
+}
+
+type capacityFile struct {
+	BrokerCapacities []capacityFileEntry `json:"brokerCapacities"`
+}
+
+// CapacityProvider answers a broker's DISK/CPU/NW_IN/NW_OUT capacity,
+// falling back to the "-1" cluster-wide default entry for any broker ID
+// without its own override, mirroring Cruise Control's
+// BrokerCapacityConfigFileResolver lookup.
+type CapacityProvider struct {
+	perBroker   map[string]BrokerCapacity
+	fallback    BrokerCapacity
+	hasFallback bool
+}
+
+// LoadCapacityProvider reads a Cruise-Control-style capacity.json file.
+func LoadCapacityProvider(path string) (*CapacityProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading capacity file %s: %w", path, err)
+	}
+
+	var parsed capacityFile
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("parsing capacity file %s: %w", path, err)
+	}
+
+	provider := &CapacityProvider{perBroker: make(map[string]BrokerCapacity, len(parsed.BrokerCapacities))}
+	for _, entry := range parsed.BrokerCapacities {
+		capacity := BrokerCapacity{
+			DiskMB:        parseCapacityValue(entry.Capacity.Disk),
+			CPUPercent:    parseCapacityValue(entry.Capacity.CPU),
+			NetworkInKBs:  parseCapacityValue(entry.Capacity.NWIn),
+			NetworkOutKBs: parseCapacityValue(entry.Capacity.NWOut),
+		}
+
+		if entry.BrokerID == "-1" {
+			provider.fallback = capacity
+			provider.hasFallback = true
+			continue
+		}
+		provider.perBroker[entry.BrokerID] = capacity
+	}
+
+	return provider, nil
+}
+
+// parseCapacityValue parses one capacity.json numeric field, which Cruise
+// Control encodes as a JSON string rather than a number. A malformed or
+// empty value yields 0 rather than failing the whole file load.
+func parseCapacityValue(raw string) float64 {
+	var value float64
+	if _, err := fmt.Sscanf(raw, "%g", &value); err != nil {
+		return 0
+	}
+	return value
+}
+
+// CapacityFor returns brokerID's capacity, falling back to the "-1"
+// cluster-wide default entry, and reports false if neither is configured.
+func (c *CapacityProvider) CapacityFor(brokerID string) (BrokerCapacity, bool) {
+	if capacity, ok := c.perBroker[brokerID]; ok {
+		return capacity, true
+	}
+	if c.hasFallback {
+		return c.fallback, true
+	}
+	return BrokerCapacity{}, false
+}
+
+// BrokerUtilization holds a broker's actual/capacity ratios for each
+// Cruise-Control-modeled resource, each typically in [0,1] (it can exceed
+// 1 if actual usage outpaces the configured capacity).
+type BrokerUtilization struct {
+	DiskUsedPercent       float64
+	NetworkInUtilization  float64
+	NetworkOutUtilization float64
+	CPUUtilization        float64
+}
+
+// Utilization computes actual/capacity for each resource dimension
+// against brokerID's configured capacity, reporting false if brokerID has
+// neither a specific nor a "-1" default capacity entry.
+func (c *CapacityProvider) Utilization(brokerID string, diskUsedMB, networkInKBs, networkOutKBs, cpuPercent float64) (BrokerUtilization, bool) {
+	capacity, ok := c.CapacityFor(brokerID)
+	if !ok {
+		return BrokerUtilization{}, false
+	}
+
+	return BrokerUtilization{
+		DiskUsedPercent:       utilizationRatio(diskUsedMB, capacity.DiskMB),
+		NetworkInUtilization:  utilizationRatio(networkInKBs, capacity.NetworkInKBs),
+		NetworkOutUtilization: utilizationRatio(networkOutKBs, capacity.NetworkOutKBs),
+		CPUUtilization:        utilizationRatio(cpuPercent, capacity.CPUPercent),
+	}, true
+}
+
+// utilizationRatio returns actual/capacity, or 0 if capacity is unset
+// (rather than dividing by zero).
+func utilizationRatio(actual, capacity float64) float64 {
+	if capacity <= 0 {
+		return 0
+	}
+	return actual / capacity
+}
+
+// ClusterUtilizationPercentiles summarizes one resource dimension's p50/
+// p95/max across a cluster's brokers, for a cluster-wide headroom rollup.
+type ClusterUtilizationPercentiles struct {
+	P50 float64
+	P95 float64
+	Max float64
+}
+
+// SummarizeUtilization computes p50/p95/max across samples for each of
+// the four Cruise-Control-modeled resource dimensions, keyed "disk",
+// "networkIn", "networkOut", and "cpu".
+func SummarizeUtilization(samples []BrokerUtilization) map[string]ClusterUtilizationPercentiles {
+	disk := make([]float64, 0, len(samples))
+	networkIn := make([]float64, 0, len(samples))
+	networkOut := make([]float64, 0, len(samples))
+	cpu := make([]float64, 0, len(samples))
+
+	for _, s := range samples {
+		disk = append(disk, s.DiskUsedPercent)
+		networkIn = append(networkIn, s.NetworkInUtilization)
+		networkOut = append(networkOut, s.NetworkOutUtilization)
+		cpu = append(cpu, s.CPUUtilization)
+	}
+
+	return map[string]ClusterUtilizationPercentiles{
+		"disk":       percentileSummary(disk),
+		"networkIn":  percentileSummary(networkIn),
+		"networkOut": percentileSummary(networkOut),
+		"cpu":        percentileSummary(cpu),
+	}
+}
+
+// percentileSummary sorts a copy of values and reads off p50/p95/max,
+// returning the zero value for an empty input.
+func percentileSummary(values []float64) ClusterUtilizationPercentiles {
+	if len(values) == 0 {
+		return ClusterUtilizationPercentiles{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	return ClusterUtilizationPercentiles{
+		P50: percentileOf(sorted, 0.50),
+		P95: percentileOf(sorted, 0.95),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// percentileOf reads the q-th percentile off an already-sorted slice at
+// index floor(q*(n-1)), with no interpolation.
+func percentileOf(sorted []float64, q float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	idx := int(q * float64(len(sorted)-1))
+	return sorted[idx]
+}
@@ -0,0 +1,85 @@
+package msk
+
+import (
+	"fmt"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// ClientMetricSample is one client-id/user's observed value for a single
+// quota/throughput attribute, as reported by the collector for the
+// kafka.server:type={Produce,Fetch,Request},user=<u>,client-id=<c>
+// MBeans. brokerData carries these under the "client.metrics" key so
+// EnhancedShim.TransformBrokerMetrics can emit them per client without
+// that dimensionality being dropped on the floor.
+type ClientMetricSample struct {
+	ClientID string
+	User     string
+	Attr     string // e.g. "produce.byte-rate"
+	Value    float64
+}
+
+// TransformClientMetrics emits a dimensioned KafkaClientSample per
+// observed client-id (a known principal is attached as an attribute, not
+// split into a sample of its own), using
+// mapper to resolve each attribute to its MSK metric name. Operators can
+// use these to find noisy producers/consumers, a use case otherwise only
+// reachable through raw JMX.
+func (s *EnhancedShim) TransformClientMetrics(samples []ClientMetricSample, mapper *MetricMapper) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	byClient := make(map[string][]ClientMetricSample)
+	for _, sample := range samples {
+		byClient[sample.ClientID] = append(byClient[sample.ClientID], sample)
+	}
+
+	for clientID, clientSamples := range byClient {
+		if err := s.emitClientSample(clientID, clientSamples, mapper); err != nil {
+			log.Error("Failed to emit client metrics for client-id %s: %v", clientID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *EnhancedShim) emitClientSample(clientID string, samples []ClientMetricSample, mapper *MetricMapper) error {
+	entityName := fmt.Sprintf("%s-client-%s", s.config.ClusterName, clientID)
+	entity, err := s.integration.Entity(entityName, "KAFKA_CLIENT")
+	if err != nil {
+		return fmt.Errorf("failed to create client entity: %v", err)
+	}
+
+	user := ""
+	for _, sample := range samples {
+		if sample.User != "" {
+			user = sample.User
+			break
+		}
+	}
+
+	ms := entity.NewMetricSet("KafkaClientSample",
+		attribute.Attribute{Key: "clusterName", Value: s.config.ClusterName},
+		attribute.Attribute{Key: "entityName", Value: entityName},
+		attribute.Attribute{Key: "clientId", Value: clientID},
+		attribute.Attribute{Key: "user", Value: user},
+	)
+
+	for _, sample := range samples {
+		mapping, ok := mapper.MapClientMetric(sample.Attr)
+		if !ok {
+			continue
+		}
+		if err := ms.SetMetric(mapping.MetricName, sample.Value, metric.GAUGE); err != nil {
+			log.Error("Unable to set %s for client %s: %v", mapping.MetricName, clientID, err)
+		}
+		if err := ms.SetMetric("quotaType", mapping.QuotaType, metric.ATTRIBUTE); err != nil {
+			log.Error("Unable to set quotaType for client %s: %v", clientID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+// Package cloudwatch queries the real AWS/Kafka CloudWatch namespace for
+// host-level broker metrics (CPU, memory, disk, network) that JMX has no
+// way to expose, so SimpleTransformBrokerMetrics can replace its hardcoded
+// cpuIdle/memoryUsed/networkRxPackets/etc constants with real values.
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// Namespace is the CloudWatch namespace MSK publishes per-broker host
+// metrics under.
+const Namespace = "AWS/Kafka"
+
+// MetricNames are the AWS/Kafka metrics Query requests for every broker,
+// mirroring the provider.* attributes SimpleTransformBrokerMetrics
+// currently hardcodes.
+var MetricNames = []string{
+	"CpuIdle",
+	"CpuUser",
+	"CpuSystem",
+	"MemoryUsed",
+	"MemoryFree",
+	"KafkaDataLogsDiskUsed",
+	"RootDiskUsed",
+	"NetworkRxDropped",
+	"NetworkRxErrors",
+	"NetworkRxPackets",
+	"NetworkTxDropped",
+	"NetworkTxErrors",
+	"NetworkTxPackets",
+}
+
+// maxQueriesPerRequest mirrors GetMetricData's per-request limit of 500
+// MetricDataQuery entries.
+const maxQueriesPerRequest = 500
+
+// DefaultTTL is how long Client caches a successful GetMetricData answer
+// before refreshing it, matching the 1-minute period AWS/Kafka metrics
+// publish at.
+const DefaultTTL = time.Minute
+
+// DefaultTimeout bounds how long a single refresh may take before giving up
+// and letting the caller fall back to its existing defaults/stale cache.
+const DefaultTimeout = 10 * time.Second
+
+// BrokerHostMetrics is one broker's latest AWS/Kafka CloudWatch datapoints,
+// keyed by metric name (e.g. "CpuIdle", "MemoryUsed").
+type BrokerHostMetrics map[string]float64
+
+type api interface {
+	GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+// Client queries AWS/Kafka CloudWatch host metrics for every broker in a
+// cluster, batching up to maxQueriesPerRequest MetricDataQuerys per
+// GetMetricData call, and caches the result for ttl so every broker sample
+// this cycle doesn't re-issue the same query.
+type Client struct {
+	api         api
+	clusterName string
+	timeout     time.Duration
+	ttl         time.Duration
+
+	mu        sync.Mutex
+	cached    map[string]BrokerHostMetrics
+	fetchedAt time.Time
+}
+
+// NewClient loads AWS's default credential chain (environment, shared
+// config, EC2/ECS instance role) for region and returns a Client that
+// queries clusterName's AWS/Kafka host metrics. timeout/ttl <= 0 fall back
+// to DefaultTimeout/DefaultTTL.
+func NewClient(ctx context.Context, region, clusterName string, timeout, ttl time.Duration) (*Client, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS credentials: %w", err)
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Client{api: cloudwatch.NewFromConfig(cfg), clusterName: clusterName, timeout: timeout, ttl: ttl}, nil
+}
+
+// Metrics returns the cached per-broker host metrics for brokerIDs,
+// refreshing first if ttl has elapsed since the last successful fetch. A
+// broker missing from the result (because every query for it failed or
+// returned no datapoint) means the caller should fall back to its existing
+// default for that broker rather than failing the whole sample.
+func (c *Client) Metrics(ctx context.Context, brokerIDs []string) map[string]BrokerHostMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.ttl {
+		return c.cached
+	}
+
+	results := c.query(ctx, brokerIDs)
+	c.cached = results
+	c.fetchedAt = time.Now()
+	return c.cached
+}
+
+// query fetches the last-completed 1-minute datapoint for every metric in
+// MetricNames, for each of brokerIDs, batching queries across as many
+// GetMetricData calls as maxQueriesPerRequest requires. A batch failure is
+// logged and skipped rather than aborting the whole query, so one broker's
+// dimension mismatch doesn't take down every other broker's host metrics.
+func (c *Client) query(ctx context.Context, brokerIDs []string) map[string]BrokerHostMetrics {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	now := time.Now()
+	// CloudWatch needs the query window to fully contain a completed
+	// 1-minute period; asking for the last 5 minutes and taking the most
+	// recent datapoint tolerates CloudWatch's own ingestion delay.
+	startTime := now.Add(-5 * time.Minute)
+
+	queries := make([]types.MetricDataQuery, 0, len(brokerIDs)*len(MetricNames))
+	// queryBroker/queryMetric let query map each response timeseries'
+	// generated Id back to which broker/metric it belongs to.
+	queryBroker := make(map[string]string, len(queries))
+	queryMetric := make(map[string]string, len(queries))
+
+	for bi, brokerID := range brokerIDs {
+		for mi, metricName := range MetricNames {
+			id := fmt.Sprintf("q%d_%d", bi, mi)
+			queryBroker[id] = brokerID
+			queryMetric[id] = metricName
+			queries = append(queries, types.MetricDataQuery{
+				Id: aws.String(id),
+				MetricStat: &types.MetricStat{
+					Metric: &types.Metric{
+						Namespace:  aws.String(Namespace),
+						MetricName: aws.String(metricName),
+						Dimensions: []types.Dimension{
+							{Name: aws.String("Cluster Name"), Value: aws.String(c.clusterName)},
+							{Name: aws.String("Broker ID"), Value: aws.String(brokerID)},
+						},
+					},
+					Period: aws.Int32(60),
+					Stat:   aws.String("Average"),
+				},
+			})
+		}
+	}
+
+	results := make(map[string]BrokerHostMetrics, len(brokerIDs))
+	for start := 0; start < len(queries); start += maxQueriesPerRequest {
+		end := start + maxQueriesPerRequest
+		if end > len(queries) {
+			end = len(queries)
+		}
+
+		resp, err := c.api.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+			MetricDataQueries: queries[start:end],
+			StartTime:         aws.Time(startTime),
+			EndTime:           aws.Time(now),
+		})
+		if err != nil {
+			log.Warn("msk/cloudwatch: GetMetricData batch failed, falling back to defaults for its brokers: %v", err)
+			continue
+		}
+
+		for _, series := range resp.MetricDataResults {
+			id := aws.ToString(series.Id)
+			brokerID := queryBroker[id]
+			if brokerID == "" || len(series.Values) == 0 {
+				continue
+			}
+			if results[brokerID] == nil {
+				results[brokerID] = make(BrokerHostMetrics, len(MetricNames))
+			}
+			// GetMetricData returns datapoints newest-first.
+			results[brokerID][queryMetric[id]] = series.Values[0]
+		}
+	}
+
+	return results
+}
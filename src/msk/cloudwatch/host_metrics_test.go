@@ -0,0 +1,84 @@
+package cloudwatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI is a test double for the subset of *cloudwatch.Client Client
+// depends on.
+type fakeAPI struct {
+	calls int
+	out   *cloudwatch.GetMetricDataOutput
+	err   error
+}
+
+func (f *fakeAPI) GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	f.calls++
+	return f.out, f.err
+}
+
+func newTestClient(api *fakeAPI, ttl time.Duration) *Client {
+	return &Client{api: api, clusterName: "test-cluster", timeout: DefaultTimeout, ttl: ttl}
+}
+
+func TestMetrics_MapsResultsBackToBrokerAndMetricName(t *testing.T) {
+	api := &fakeAPI{
+		out: &cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []types.MetricDataResult{
+				{Id: aws.String("q0_0"), Values: []float64{42.5, 40.1}},
+				{Id: aws.String("q1_1"), Values: []float64{7.3}},
+			},
+		},
+	}
+	c := newTestClient(api, time.Minute)
+
+	results := c.Metrics(context.Background(), []string{"1", "2"})
+
+	require.Contains(t, results, "1")
+	assert.Equal(t, 42.5, results["1"]["CpuIdle"], "should keep the newest (first) datapoint")
+	require.Contains(t, results, "2")
+	assert.Equal(t, 7.3, results["2"]["CpuUser"])
+}
+
+func TestMetrics_SeriesWithNoDatapoints_IsSkipped(t *testing.T) {
+	api := &fakeAPI{
+		out: &cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []types.MetricDataResult{
+				{Id: aws.String("q0_0"), Values: nil},
+			},
+		},
+	}
+	c := newTestClient(api, time.Minute)
+
+	results := c.Metrics(context.Background(), []string{"1"})
+
+	assert.NotContains(t, results, "1")
+}
+
+func TestMetrics_CachesUntilTTLElapses(t *testing.T) {
+	api := &fakeAPI{out: &cloudwatch.GetMetricDataOutput{}}
+	c := newTestClient(api, time.Hour)
+
+	c.Metrics(context.Background(), []string{"1"})
+	c.Metrics(context.Background(), []string{"1"})
+
+	assert.Equal(t, 1, api.calls, "a live TTL should serve the second call from cache, not re-query")
+}
+
+func TestMetrics_BatchFailure_ReturnsEmptyResultsRatherThanError(t *testing.T) {
+	api := &fakeAPI{err: errors.New("GetMetricData: throttled")}
+	c := newTestClient(api, time.Minute)
+
+	results := c.Metrics(context.Background(), []string{"1"})
+
+	assert.Empty(t, results, "a failed batch should fall back to empty results, not block the caller")
+}
@@ -2,44 +2,157 @@ package msk
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
-	
+
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 )
 
-// CloudWatchEmulator emulates CloudWatch Metric Streams format
+// cloudWatchMetricCap mirrors the real PutMetricData per-request limit:
+// CloudWatch accepts at most 1000 MetricDatum entries in a single call.
+const cloudWatchMetricCap = 1000
+
+// StatisticSet mirrors CloudWatch's StatisticValues: a MetricDatum carries
+// the Min/Max/Sum/SampleCount observed over a period instead of a single
+// point-in-time value. EmitBrokerMetrics/EmitTopicMetrics/EmitClusterMetrics
+// feed individual GAUGE-style samples into recordMetric, which folds each
+// one into its bucket's statistic set as a degenerate case
+// (Min == Max == Sum/SampleCount) until the bucket is flushed.
+type StatisticSet struct {
+	Min         float64
+	Max         float64
+	Sum         float64
+	SampleCount int64
+}
+
+// record folds value into the statistic set as one more sample.
+func (s *StatisticSet) record(value float64) {
+	if s.SampleCount == 0 {
+		s.Min = value
+		s.Max = value
+	} else {
+		if value < s.Min {
+			s.Min = value
+		}
+		if value > s.Max {
+			s.Max = value
+		}
+	}
+	s.Sum += value
+	s.SampleCount++
+}
+
+// cloudWatchBucketKey identifies one (metric name, dimension set) pair -
+// CloudWatch's own unit of aggregation for a PutMetricData period.
+type cloudWatchBucketKey struct {
+	metricName    string
+	dimensionsKey string
+}
+
+// cloudWatchBucket accumulates samples for one cloudWatchBucketKey between
+// flushes.
+type cloudWatchBucket struct {
+	stats      StatisticSet
+	attributes map[string]interface{}
+}
+
+// CloudWatchEmulator emulates CloudWatch Metric Streams format. Rather than
+// sending every sample as soon as it's observed, it accumulates samples per
+// (metric, dimension-set) bucket and periodically flushes each bucket as a
+// single statistic set, mirroring how CloudWatch itself aggregates
+// PutMetricData calls over a period instead of reporting a raw point-in-time
+// gauge.
 type CloudWatchEmulator struct {
 	metricClient *MetricAPIClient
 	config       *Config
+
+	maxBatchSize int
+	maxBuckets   int
+
+	controllerResolver *ControllerResolver
+
+	mu      sync.Mutex
+	buckets map[cloudWatchBucketKey]*cloudWatchBucket
+
+	flushTicker *time.Ticker
+	done        chan struct{}
+	wg          sync.WaitGroup
 }
 
-// NewCloudWatchEmulator creates a new CloudWatch emulator
+// SetControllerResolver wires in a ControllerResolver that
+// EmitBrokerMetrics/EmitClusterMetrics use to override ActiveControllerCount
+// with the Kafka AdminClient's authoritative controller ID instead of
+// trusting whatever value the caller supplies per broker. Passing nil
+// reverts to passing the caller-supplied value straight through.
+func (e *CloudWatchEmulator) SetControllerResolver(resolver *ControllerResolver) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.controllerResolver = resolver
+}
+
+// NewCloudWatchEmulator creates a new CloudWatch emulator and starts its
+// background flush loop, driven by config.CloudWatchFlushInterval and
+// config.CloudWatchBatchSize.
 func NewCloudWatchEmulator(config *Config, apiKey string) *CloudWatchEmulator {
-	return &CloudWatchEmulator{
-		metricClient: NewMetricAPIClient(apiKey),
+	maxBatchSize := config.CloudWatchBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = 20
+	}
+	if maxBatchSize > cloudWatchMetricCap {
+		maxBatchSize = cloudWatchMetricCap
+	}
+
+	maxBuckets := config.CloudWatchMaxBuckets
+	if maxBuckets <= 0 {
+		maxBuckets = 10000
+	}
+
+	flushInterval := config.CloudWatchFlushInterval
+	if flushInterval <= 0 {
+		flushInterval = 60 * time.Second
+	}
+
+	e := &CloudWatchEmulator{
+		metricClient: NewMetricAPIClient(apiKey, config.MetricAPICompression),
 		config:       config,
+		maxBatchSize: maxBatchSize,
+		maxBuckets:   maxBuckets,
+		buckets:      make(map[cloudWatchBucketKey]*cloudWatchBucket),
+		flushTicker:  time.NewTicker(flushInterval),
+		done:         make(chan struct{}),
 	}
+
+	e.wg.Add(1)
+	go e.backgroundFlusher()
+
+	return e
 }
 
-// EmitBrokerMetrics sends broker metrics in CloudWatch format
+// EmitBrokerMetrics records broker metrics in CloudWatch format
 func (e *CloudWatchEmulator) EmitBrokerMetrics(brokerID string, metrics map[string]interface{}) error {
 	log.Info("Emulating CloudWatch metrics for broker %s", brokerID)
-	
+
 	// Build base attributes that CloudWatch would send
 	baseAttrs := e.buildCloudWatchAttributes("Broker", brokerID)
-	
-	// CloudWatch MSK metric mappings
-	metricMappings := map[string]string{
-		"broker.IOInPerSecond":              "BytesInPerSec",
-		"broker.IOOutPerSecond":             "BytesOutPerSec",
-		"broker.messagesInPerSecond":        "MessagesInPerSec",
-		"replication.unreplicatedPartitions": "UnderReplicatedPartitions",
-		"request.handlerIdle":               "RequestHandlerAvgIdlePercent",
-		"broker.networkProcessorAvgIdlePercent": "NetworkProcessorAvgIdlePercent",
-		"controller.activeControllerCount":   "ActiveControllerCount",
-		"controller.offlinePartitionsCount":  "OfflinePartitionsCount",
-	}
-	
+
+	// CloudWatch MSK metric mappings come from the shared metricmap table,
+	// so this emulator and InfraSDKSink/OTLPSink never drift apart on what
+	// a given kafka metric name is supposed to render as. ActiveController-
+	// Count is handled separately below, since it must come from the
+	// ControllerResolver rather than whatever the caller happens to report
+	// per broker, and request.avgTime* are latency metrics this emulator
+	// doesn't (yet) surface, so both are skipped here.
+	metricMappings := make(map[string]string, len(brokerMetricNameMap))
+	for kafkaMetric, cwMetric := range brokerMetricNameMap {
+		if cwMetric == "ProduceTotalTimeMs" || cwMetric == "FetchConsumerTotalTimeMs" {
+			continue
+		}
+		metricMappings[kafkaMetric] = cwMetric
+	}
+
 	// CPU and Memory metrics (with defaults if not available)
 	cpuMemoryMetrics := map[string]float64{
 		"CpuIdle":    getFloatValueWithDefault(metrics, "cpu.idle", 70.0),
@@ -49,50 +162,83 @@ func (e *CloudWatchEmulator) EmitBrokerMetrics(brokerID string, metrics map[stri
 		"MemoryFree": getFloatValueWithDefault(metrics, "memory.free", 50.0),
 		"KafkaDataLogDiskUsed": getFloatValueWithDefault(metrics, "disk.used", 30.0),
 	}
-	
-	// Send regular metrics
+
+	// Record regular metrics
 	for kafkaMetric, cwMetric := range metricMappings {
 		if value, ok := getFloatValue(metrics, kafkaMetric); ok {
-			if err := e.sendCloudWatchMetric(cwMetric, value, baseAttrs); err != nil {
-				log.Error("Failed to send metric %s: %v", cwMetric, err)
-			}
+			e.recordMetric(cwMetric, value, baseAttrs)
 		}
 	}
-	
-	// Send CPU/Memory metrics
+
+	// Record CPU/Memory metrics
 	for metricName, value := range cpuMemoryMetrics {
-		if err := e.sendCloudWatchMetric(metricName, value, baseAttrs); err != nil {
-			log.Error("Failed to send metric %s: %v", metricName, err)
+		e.recordMetric(metricName, value, baseAttrs)
+	}
+
+	// ActiveControllerCount comes from the ControllerResolver when one is
+	// wired in, so a misconfigured source reporting its own
+	// controller.activeControllerCount can't emit 0 or >1 across the
+	// cluster. Without a resolver, fall back to the caller-supplied value.
+	if e.controllerResolver != nil {
+		activeControllerCount := 0.0
+		if brokerIDInt, err := strconv.Atoi(brokerID); err == nil && e.controllerResolver.IsController(int32(brokerIDInt)) {
+			activeControllerCount = 1.0
 		}
+		e.recordMetric("ActiveControllerCount", activeControllerCount, baseAttrs)
+	} else if value, ok := getFloatValue(metrics, "controller.activeControllerCount"); ok {
+		e.recordMetric("ActiveControllerCount", value, baseAttrs)
 	}
-	
+
 	return nil
 }
 
-// EmitClusterMetrics sends cluster-level metrics in CloudWatch format
+// EmitClusterMetrics records cluster-level metrics in CloudWatch format
 func (e *CloudWatchEmulator) EmitClusterMetrics(clusterMetrics map[string]interface{}) error {
 	log.Info("Emulating CloudWatch metrics for cluster %s", e.config.ClusterName)
-	
+
 	// Build base attributes for cluster
 	baseAttrs := e.buildCloudWatchAttributes("Cluster", "")
-	
-	// Cluster-level metrics
-	clusterMetricMappings := map[string]string{
-		"GlobalPartitionCount":      "GlobalPartitionCount",
-		"GlobalTopicCount":          "GlobalTopicCount",
-		"OfflinePartitionsCount":    "OfflinePartitionsCount",
-		"ActiveControllerCount":     "ActiveControllerCount",
-		"UnderReplicatedPartitions": "UnderReplicatedPartitions",
-	}
-	
-	// Send cluster metrics
-	for metricName, metricName2 := range clusterMetricMappings {
+
+	// Cluster-level metrics come from the shared metricmap table.
+	// ActiveControllerCount is handled separately below, since it must come
+	// from the ControllerResolver rather than whatever the caller happens
+	// to report.
+	for metricName, metricName2 := range clusterMetricNameMap {
 		value := getFloatValueWithDefault(clusterMetrics, metricName, 0.0)
-		if err := e.sendCloudWatchMetric(metricName2, value, baseAttrs); err != nil {
-			log.Error("Failed to send cluster metric %s: %v", metricName, err)
+		e.recordMetric(metricName2, value, baseAttrs)
+	}
+
+	// ActiveControllerCount comes from the ControllerResolver when one is
+	// wired in: exactly 1 once a controller is known, 0 only while none
+	// is (a real election in progress or the cluster unreachable).
+	// Without a resolver, fall back to the caller-supplied value.
+	if e.controllerResolver != nil {
+		activeControllerCount := 0.0
+		if e.controllerResolver.ControllerID() >= 0 {
+			activeControllerCount = 1.0
+		}
+		e.recordMetric("ActiveControllerCount", activeControllerCount, baseAttrs)
+	} else {
+		value := getFloatValueWithDefault(clusterMetrics, "ActiveControllerCount", 0.0)
+		e.recordMetric("ActiveControllerCount", value, baseAttrs)
+	}
+
+	return nil
+}
+
+// EmitTopicMetrics records topic metrics in CloudWatch format
+func (e *CloudWatchEmulator) EmitTopicMetrics(topicName string, metrics map[string]interface{}) error {
+	log.Info("Emulating CloudWatch metrics for topic %s", topicName)
+
+	baseAttrs := e.buildCloudWatchAttributes("Topic", topicName)
+
+	// Topic metrics come from the shared metricmap table.
+	for kafkaMetric, cwMetric := range topicMetricNameMap {
+		if value, ok := getFloatValue(metrics, kafkaMetric); ok {
+			e.recordMetric(cwMetric, value, baseAttrs)
 		}
 	}
-	
+
 	return nil
 }
 
@@ -104,25 +250,25 @@ func (e *CloudWatchEmulator) buildCloudWatchAttributes(dimensionType string, dim
 		"eventType":               "Metric",
 		"instrumentation.provider": "cloudwatch",
 		"instrumentation.source":   "cloudwatch",
-		
+
 		// AWS namespace
 		"aws.Namespace": "AWS/Kafka",
-		
+
 		// AWS account info
 		"aws.accountId": e.config.AWSAccountID,
 		"aws.region":    e.config.AWSRegion,
-		
+
 		// MSK specific attributes
 		"aws.kafka.clusterName": e.config.ClusterName,
 		"clusterName":          e.config.ClusterName,
-		
+
 		// Entity synthesis helpers
 		"provider":              "AwsMsk",
 		"providerAccountId":     e.config.AWSAccountID,
 		"providerAccountName":   "AWS Account",
 		"providerRegion":        e.config.AWSRegion,
 	}
-	
+
 	// Add dimension-specific attributes
 	switch dimensionType {
 	case "Broker":
@@ -130,77 +276,162 @@ func (e *CloudWatchEmulator) buildCloudWatchAttributes(dimensionType string, dim
 		attrs["entity.type"] = "AWS_KAFKA_BROKER"
 		attrs["entity.name"] = fmt.Sprintf("%s:broker-%s", e.config.ClusterName, dimensionValue)
 		attrs["entity.guid"] = GenerateEntityGUID(EntityTypeBroker, e.config.AWSAccountID, e.config.ClusterName, dimensionValue)
-		
+
 		// CloudWatch dimensions
 		attrs["aws.Dimensions"] = []map[string]string{
 			{"Name": "ClusterName", "Value": e.config.ClusterName},
 			{"Name": "BrokerID", "Value": dimensionValue},
 		}
-		
+
 	case "Cluster":
 		attrs["entity.type"] = "AWS_KAFKA_CLUSTER"
 		attrs["entity.name"] = e.config.ClusterName
 		attrs["entity.guid"] = GenerateEntityGUID(EntityTypeCluster, e.config.AWSAccountID, e.config.ClusterName, nil)
-		
+
 		// CloudWatch dimensions
 		attrs["aws.Dimensions"] = []map[string]string{
 			{"Name": "ClusterName", "Value": e.config.ClusterName},
 		}
-		
+
 	case "Topic":
 		attrs["aws.kafka.topicName"] = dimensionValue
 		attrs["entity.type"] = "AWS_KAFKA_TOPIC"
 		attrs["entity.name"] = fmt.Sprintf("topic:%s", dimensionValue)
 		attrs["entity.guid"] = GenerateEntityGUID(EntityTypeTopic, e.config.AWSAccountID, e.config.ClusterName, dimensionValue)
-		
+
 		// CloudWatch dimensions
 		attrs["aws.Dimensions"] = []map[string]string{
 			{"Name": "ClusterName", "Value": e.config.ClusterName},
 			{"Name": "TopicName", "Value": dimensionValue},
 		}
 	}
-	
+
 	return attrs
 }
 
-// sendCloudWatchMetric sends a single metric in CloudWatch format
-func (e *CloudWatchEmulator) sendCloudWatchMetric(metricName string, value float64, attributes map[string]interface{}) error {
-	// CloudWatch sends metrics with specific naming
-	// AWS/Kafka namespace metrics don't have prefix
-	fullMetricName := metricName // Just the metric name, no prefix
-	
-	// Add CloudWatch-specific timestamp format
-	attributes["timestamp"] = time.Now().Unix() * 1000
-	
-	// Add metric-specific attributes
-	attributes["aws.MetricName"] = metricName
-	attributes["metricName"] = fullMetricName
-	
-	log.Debug("Sending CloudWatch-style metric: %s = %f", fullMetricName, value)
-	
-	return e.metricClient.SendGaugeMetric(fullMetricName, value, attributes)
-}
-
-// EmitTopicMetrics sends topic metrics in CloudWatch format
-func (e *CloudWatchEmulator) EmitTopicMetrics(topicName string, metrics map[string]interface{}) error {
-	log.Info("Emulating CloudWatch metrics for topic %s", topicName)
-	
-	baseAttrs := e.buildCloudWatchAttributes("Topic", topicName)
-	
-	// Topic metrics
-	topicMetricMappings := map[string]string{
-		"topic.bytesInPerSecond":  "BytesInPerSec",
-		"topic.bytesOutPerSecond": "BytesOutPerSec",
-		"topic.messagesInPerSecond": "MessagesInPerSec",
-	}
-	
-	for kafkaMetric, cwMetric := range topicMetricMappings {
-		if value, ok := getFloatValue(metrics, kafkaMetric); ok {
-			if err := e.sendCloudWatchMetric(cwMetric, value, baseAttrs); err != nil {
-				log.Error("Failed to send topic metric %s: %v", cwMetric, err)
+// recordMetric folds value into the bucket identified by (metricName,
+// attributes["aws.Dimensions"]) rather than sending it immediately. The
+// background flusher (or an explicit Flush) later sends the accumulated
+// statistic set. If the emulator is already tracking maxBuckets distinct
+// buckets, new buckets are dropped and logged rather than grown without
+// bound - existing buckets keep accumulating normally.
+func (e *CloudWatchEmulator) recordMetric(metricName string, value float64, attributes map[string]interface{}) {
+	key := cloudWatchBucketKey{
+		metricName:    metricName,
+		dimensionsKey: dimensionsKey(attributes["aws.Dimensions"]),
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	bucket, exists := e.buckets[key]
+	if !exists {
+		if len(e.buckets) >= e.maxBuckets {
+			log.Warn("CloudWatchEmulator: dropping new bucket for metric %s, at max buckets (%d)", metricName, e.maxBuckets)
+			return
+		}
+		bucket = &cloudWatchBucket{attributes: attributes}
+		e.buckets[key] = bucket
+	}
+	bucket.stats.record(value)
+}
+
+// dimensionsKey renders an aws.Dimensions value (as built by
+// buildCloudWatchAttributes) into a stable string so buckets with the same
+// dimension set - regardless of slice identity - map to the same key.
+func dimensionsKey(dimensions interface{}) string {
+	dims, ok := dimensions.([]map[string]string)
+	if !ok {
+		return ""
+	}
+
+	parts := make([]string, 0, len(dims))
+	for _, d := range dims {
+		parts = append(parts, fmt.Sprintf("%s=%s", d["Name"], d["Value"]))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Flush sends every accumulated bucket as a single MetricDatum carrying its
+// statistic set, in batches of at most maxBatchSize (mirroring the real
+// PutMetricData limit), retrying each failed batch send with backoff
+// (SendMetricsWithRetry retries on any error, not only 5xx/429).
+func (e *CloudWatchEmulator) Flush() error {
+	e.mu.Lock()
+	if len(e.buckets) == 0 {
+		e.mu.Unlock()
+		return nil
+	}
+	buckets := e.buckets
+	e.buckets = make(map[cloudWatchBucketKey]*cloudWatchBucket)
+	e.mu.Unlock()
+
+	metrics := make([]MetricData, 0, len(buckets))
+	for key, bucket := range buckets {
+		metrics = append(metrics, MetricData{
+			Name:       key.metricName,
+			Type:       "gauge",
+			Value:      statisticSetValue(bucket.stats),
+			Timestamp:  time.Now().UnixNano() / 1e6,
+			Attributes: bucket.attributes,
+		})
+	}
+
+	log.Info("CloudWatchEmulator: flushing %d metric bucket(s)", len(metrics))
+
+	retryConfig := DefaultRetryConfig()
+	var firstErr error
+	for i := 0; i < len(metrics); i += e.maxBatchSize {
+		end := i + e.maxBatchSize
+		if end > len(metrics) {
+			end = len(metrics)
+		}
+
+		if err := e.metricClient.SendMetricsWithRetry(metrics[i:end], retryConfig); err != nil {
+			log.Error("CloudWatchEmulator: failed to send metric batch: %v", err)
+			if firstErr == nil {
+				firstErr = err
 			}
 		}
 	}
-	
-	return nil
-}
\ No newline at end of file
+
+	return firstErr
+}
+
+// statisticSetValue renders a StatisticSet as the degenerate case CloudWatch
+// uses for a GAUGE-style metric: Min == Max == Sum/SampleCount when there's
+// exactly one sample, widening into a real range as more samples fold in.
+func statisticSetValue(stats StatisticSet) map[string]interface{} {
+	return map[string]interface{}{
+		"min":         stats.Min,
+		"max":         stats.Max,
+		"sum":         stats.Sum,
+		"sampleCount": stats.SampleCount,
+	}
+}
+
+// backgroundFlusher periodically flushes accumulated buckets.
+func (e *CloudWatchEmulator) backgroundFlusher() {
+	defer e.wg.Done()
+	for {
+		select {
+		case <-e.flushTicker.C:
+			if err := e.Flush(); err != nil {
+				log.Error("CloudWatchEmulator: background flush failed: %v", err)
+			}
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// Stop halts the background flusher and flushes any remaining buckets.
+func (e *CloudWatchEmulator) Stop() {
+	e.flushTicker.Stop()
+	close(e.done)
+	e.wg.Wait()
+	if err := e.Flush(); err != nil {
+		log.Error("CloudWatchEmulator: final flush failed: %v", err)
+	}
+}
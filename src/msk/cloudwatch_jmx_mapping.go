@@ -0,0 +1,104 @@
+package msk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// GetReverseBrokerMetricMappings derives a Target->Source lookup from
+// BrokerMetricSpecs, for TransformMSKCloudWatchMetrics to use when going
+// the other direction: an MSK CloudWatch stream -> a JMX-shaped sample.
+// When more than one spec shares a Target (the registry isn't strictly
+// 1:1 - e.g. both broker.totalFetchRequestsPerSecond and
+// consumer.avgFetchSizeBytes currently map to
+// aws.msk.FetchMessageConversionsPerSec), the first one BrokerMetricSpecs
+// declares wins.
+func GetReverseBrokerMetricMappings() map[string]string {
+	reverse := make(map[string]string)
+	for _, spec := range BrokerMetricSpecs() {
+		if _, exists := reverse[spec.Target]; !exists {
+			reverse[spec.Target] = spec.Source
+		}
+	}
+	return reverse
+}
+
+// TransformMSKCloudWatchMetrics populates a JMX-shaped outputSet from an
+// MSK CloudWatch metric stream, the reverse of TransformBrokerMetrics, so
+// NRQL dashboards built against JMX-shaped attributes keep working for
+// clusters that only expose CloudWatch (hybrid self-managed+MSK-Connect
+// setups, or a cluster mid-migration onto/off MSK).
+func (t *TransformerFixed) TransformMSKCloudWatchMetrics(cw map[string]interface{}, outputSet *metric.Set) error {
+	t.ensureCloudWatchBrokerAttributes(cw, outputSet)
+
+	for target, source := range GetReverseBrokerMetricMappings() {
+		value, exists := cw[target]
+		if !exists || value == nil {
+			continue
+		}
+		floatVal, err := toFloat64(value)
+		if err != nil {
+			log.Debug("TransformMSKCloudWatchMetrics: failed to convert %s value %v: %v", target, value, err)
+			continue
+		}
+		outputSet.SetMetric(source, floatVal, metric.GAUGE)
+	}
+
+	return nil
+}
+
+// ensureCloudWatchBrokerAttributes mirrors ensureBrokerAttributes for the
+// CloudWatch direction: an MSK CloudWatch stream identifies a broker via
+// the "Cluster Name"/"Broker ID" dimensions rather than a JMX entityName,
+// so provider.clusterName/provider.brokerId have to be synthesized from
+// those dimensions instead.
+func (t *TransformerFixed) ensureCloudWatchBrokerAttributes(cw map[string]interface{}, outputSet *metric.Set) {
+	clusterName := t.clusterName
+	if fromDimension, ok := getStringValue(cw, "Cluster Name"); ok {
+		clusterName = fromDimension
+	}
+	outputSet.SetMetric("clusterName", clusterName, metric.ATTRIBUTE)
+	outputSet.SetMetric("provider.clusterName", clusterName, metric.ATTRIBUTE)
+
+	if brokerID, ok := getStringValue(cw, "Broker ID"); ok {
+		outputSet.SetMetric("brokerId", brokerID, metric.ATTRIBUTE)
+		outputSet.SetMetric("provider.brokerId", brokerID, metric.ATTRIBUTE)
+	}
+}
+
+// ValidateMetricMappingConsistency scans BrokerMetricSpecs for targets
+// whose name implies one aggregation semantic (a ".Sum"/".Average" suffix,
+// or a "PerSec" rate name) but whose declared Kind/Rollup says something
+// else - e.g. a PerSec gauge mapped to a Sum-suffixed provider metric -
+// and logs each mismatch so a registry edit that drifts out of sync with
+// its own naming convention gets caught at startup instead of silently
+// producing misleading dashboards. It returns the warnings too, for
+// callers that want to assert on them directly.
+func ValidateMetricMappingConsistency() []string {
+	var warnings []string
+
+	for _, spec := range BrokerMetricSpecs() {
+		switch {
+		case strings.HasSuffix(spec.Target, ".Sum") && spec.Kind != RollupMetricKindCounter && spec.Rollup != RollupSum:
+			warnings = append(warnings, fmt.Sprintf(
+				"%s -> %s: target name implies a Sum rollup but spec declares Kind=%v Rollup=%v",
+				spec.Source, spec.Target, spec.Kind, spec.Rollup))
+		case strings.HasSuffix(spec.Target, ".Average") && spec.Rollup == RollupSum:
+			warnings = append(warnings, fmt.Sprintf(
+				"%s -> %s: target name implies an Average rollup but spec declares Rollup=Sum",
+				spec.Source, spec.Target))
+		case strings.Contains(spec.Source, "PerSec") && spec.Kind == RollupMetricKindGauge && strings.HasSuffix(spec.Target, ".Sum"):
+			warnings = append(warnings, fmt.Sprintf(
+				"%s -> %s: a PerSec rate is mapped to a Sum-suffixed provider metric",
+				spec.Source, spec.Target))
+		}
+	}
+
+	for _, w := range warnings {
+		log.Warn("metric mapping inconsistency: %s", w)
+	}
+	return warnings
+}
@@ -0,0 +1,41 @@
+package msk
+
+import "fmt"
+
+// CloudWatchEmulatorSink adapts a CloudWatchEmulator to the EventSink
+// interface, so MultiSink can fan the same flattened event stream out to
+// the CloudWatch Metric Streams emulation alongside InfraSDKSink/
+// EventAPISink/OTLPSink instead of CloudWatchEmulator being wired up as a
+// one-off special case.
+type CloudWatchEmulatorSink struct {
+	emulator *CloudWatchEmulator
+}
+
+// NewCloudWatchEmulatorSink wraps an already-constructed CloudWatchEmulator.
+func NewCloudWatchEmulatorSink(emulator *CloudWatchEmulator) *CloudWatchEmulatorSink {
+	return &CloudWatchEmulatorSink{emulator: emulator}
+}
+
+// Submit dispatches event to the wrapped emulator's EmitBrokerMetrics/
+// EmitTopicMetrics/EmitClusterMetrics based on its eventType.
+func (s *CloudWatchEmulatorSink) Submit(event map[string]interface{}) error {
+	eventType, _ := event["eventType"].(string)
+	switch eventType {
+	case "AwsMskBrokerSample":
+		brokerID, _ := event["provider.brokerId"].(string)
+		return s.emulator.EmitBrokerMetrics(brokerID, event)
+	case "AwsMskTopicSample":
+		topicName, _ := event["provider.topicName"].(string)
+		return s.emulator.EmitTopicMetrics(topicName, event)
+	case "AwsMskClusterSample":
+		return s.emulator.EmitClusterMetrics(event)
+	default:
+		return fmt.Errorf("CloudWatchEmulatorSink: unrecognized eventType %q", eventType)
+	}
+}
+
+// Flush delegates to the wrapped emulator's own Flush, which sends every
+// accumulated CloudWatch statistic bucket.
+func (s *CloudWatchEmulatorSink) Flush() error {
+	return s.emulator.Flush()
+}
@@ -0,0 +1,196 @@
+package msk
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// DescribedTopic is the authoritative per-topic shape returned by the
+// controller via DescribeTopics, as opposed to whatever a single broker's
+// JMX beans happen to report.
+type DescribedTopic struct {
+	Name              string
+	ReplicationFactor int
+	MinInSyncReplicas int
+	RetentionMs       int64
+	PartitionCount    int
+	InSyncReplicas    map[int32][]int32
+	Leaders           map[int32]int32
+}
+
+// DescribedCluster is the authoritative cluster shape returned by
+// DescribeCluster (KIP-700).
+type DescribedCluster struct {
+	ActiveControllerCount int
+	GlobalPartitionCount  int
+	ControllerID          int32
+	BrokerIDs             []int32
+}
+
+// ClusterDescriber opens a Kafka admin connection and asks the controller
+// directly for cluster/topic shape, instead of relying on pre-flattened
+// map[string]interface{} samples scraped from a single broker's JMX beans.
+type ClusterDescriber struct {
+	admin sarama.ClusterAdmin
+	mu    sync.Mutex
+}
+
+// NewClusterDescriber wraps an already-connected sarama.ClusterAdmin.
+func NewClusterDescriber(admin sarama.ClusterAdmin) *ClusterDescriber {
+	return &ClusterDescriber{admin: admin}
+}
+
+// DescribeCluster implements KIP-700: it asks the controller for the
+// current controller ID and broker list, which is authoritative regardless
+// of which broker happened to answer the JMX poll.
+func (d *ClusterDescriber) DescribeCluster() (*DescribedCluster, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	brokers, controllerID, err := d.admin.DescribeCluster()
+	if err != nil {
+		return nil, fmt.Errorf("describing cluster: %w", err)
+	}
+
+	result := &DescribedCluster{
+		ControllerID:          controllerID,
+		ActiveControllerCount: 1,
+	}
+	for _, b := range brokers {
+		result.BrokerIDs = append(result.BrokerIDs, b.ID())
+	}
+
+	return result, nil
+}
+
+// DescribeTopics returns the authoritative replication factor, min-ISR,
+// in-sync-replica set and leader assignment for each named topic.
+func (d *ClusterDescriber) DescribeTopics(topics []string) (map[string]*DescribedTopic, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	metadata, err := d.admin.DescribeTopics(topics)
+	if err != nil {
+		return nil, fmt.Errorf("describing topics %v: %w", topics, err)
+	}
+
+	result := make(map[string]*DescribedTopic, len(metadata))
+	for _, topicMeta := range metadata {
+		if topicMeta.Err != sarama.ErrNoError {
+			log.Warn("ClusterDescriber: topic %s returned error %v", topicMeta.Name, topicMeta.Err)
+			continue
+		}
+
+		described := &DescribedTopic{
+			Name:           topicMeta.Name,
+			PartitionCount: len(topicMeta.Partitions),
+			InSyncReplicas: make(map[int32][]int32),
+			Leaders:        make(map[int32]int32),
+		}
+
+		if len(topicMeta.Partitions) > 0 {
+			described.ReplicationFactor = len(topicMeta.Partitions[0].Replicas)
+		}
+
+		for _, p := range topicMeta.Partitions {
+			described.InSyncReplicas[p.ID] = p.Isr
+			described.Leaders[p.ID] = p.Leader
+		}
+
+		configEntries, err := d.describeTopicConfigs(topicMeta.Name)
+		if err != nil {
+			log.Debug("ClusterDescriber: failed to read config for topic %s, min.insync.replicas and retention.ms will be unset: %v", topicMeta.Name, err)
+		} else {
+			if minISR, ok := configEntries["min.insync.replicas"]; ok {
+				if value, err := strconv.Atoi(minISR); err != nil {
+					log.Debug("ClusterDescriber: parsing min.insync.replicas %q for topic %s: %v", minISR, topicMeta.Name, err)
+				} else {
+					described.MinInSyncReplicas = value
+				}
+			}
+			if retentionMs, ok := configEntries["retention.ms"]; ok {
+				if value, err := strconv.ParseInt(retentionMs, 10, 64); err != nil {
+					log.Debug("ClusterDescriber: parsing retention.ms %q for topic %s: %v", retentionMs, topicMeta.Name, err)
+				} else {
+					described.RetentionMs = value
+				}
+			}
+		}
+
+		result[topicMeta.Name] = described
+	}
+
+	return result, nil
+}
+
+// describeTopicConfigs fetches topic's dynamic/static config entries in a
+// single round-trip and returns them as a plain name->value map, so callers
+// needing more than one config key (min.insync.replicas, retention.ms, ...)
+// don't each pay for their own DescribeConfig call.
+func (d *ClusterDescriber) describeTopicConfigs(topic string) (map[string]string, error) {
+	entries, err := d.admin.DescribeConfig(sarama.ConfigResource{
+		Type: sarama.TopicResource,
+		Name: topic,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		result[entry.Name] = entry.Value
+	}
+	return result, nil
+}
+
+// AddDescribedCluster merges a controller-sourced cluster description into
+// the aggregator, preferentially overriding any JMX-sourced values since
+// the controller's answer is canonical regardless of which broker was
+// polled for JMX beans. Every broker DescribeCluster reported gets a
+// brokerMetrics entry (if one doesn't already exist from JMX), so
+// GetBrokerCount reflects the real cluster size instead of whatever subset
+// happened to report JMX samples, and the controller's broker is marked
+// IsController so the active-controller metric no longer has to be guessed.
+func (a *MetricAggregator) AddDescribedCluster(described *DescribedCluster) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.controllerMetrics.ActiveControllerCount = described.ActiveControllerCount
+	a.controllerMetrics.GlobalPartitionCount = described.GlobalPartitionCount
+
+	controllerID := fmt.Sprintf("%d", described.ControllerID)
+	for _, brokerID := range described.BrokerIDs {
+		id := fmt.Sprintf("%d", brokerID)
+		existing, ok := a.brokerMetrics[id]
+		if !ok {
+			existing = &BrokerMetrics{BrokerID: int(brokerID)}
+			a.brokerMetrics[id] = existing
+		}
+		existing.IsController = id == controllerID
+	}
+	a.controllerBrokerID = controllerID
+}
+
+// AddDescribedTopic merges a controller-sourced topic description into the
+// aggregator, overriding the replication factor and partition count that
+// would otherwise come from a single broker's JMX beans.
+func (a *MetricAggregator) AddDescribedTopic(described *DescribedTopic) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	existing, ok := a.topicMetrics[described.Name]
+	if !ok {
+		existing = &TopicMetrics{Name: described.Name}
+		a.topicMetrics[described.Name] = existing
+	}
+
+	existing.ReplicationFactor = described.ReplicationFactor
+	existing.PartitionCount = described.PartitionCount
+	existing.MinInSyncReplicas = described.MinInSyncReplicas
+	existing.RetentionMs = described.RetentionMs
+}
@@ -0,0 +1,130 @@
+// Package clusterinfo issues Kafka Metadata requests (via sarama.Client,
+// which itself issues and caches the Metadata RPC) to learn the cluster's
+// real controller broker ID and per-partition leader assignment, so MSKShim
+// can replace its hardcoded provider.activeControllerCount/leaderCount
+// defaults with the cluster's actual topology.
+package clusterinfo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// DefaultTTL bounds how often Client re-issues Metadata, matching the
+// collection interval MSKShim is polled at.
+const DefaultTTL = 30 * time.Second
+
+// Topology is a point-in-time snapshot of the cluster's controller and
+// per-broker leader counts, derived from a single Metadata request.
+type Topology struct {
+	ControllerID int32
+	// LeaderCounts is the number of partitions each broker ID currently
+	// leads, keyed the same way AwsMskBrokerSample's provider.brokerId is.
+	LeaderCounts map[int32]int
+}
+
+// client is the subset of sarama.Client Client depends on, so a fake can
+// stand in for the real sarama client in place of talking to Kafka.
+type client interface {
+	RefreshMetadata(topics ...string) error
+	Controller() (*sarama.Broker, error)
+	Topics() ([]string, error)
+	Partitions(topic string) ([]int32, error)
+	Leader(topic string, partitionID int32) (*sarama.Broker, error)
+	Close() error
+}
+
+// Client resolves cluster topology from a live Kafka Metadata request and
+// caches the answer for ttl, falling back to the last good snapshot (nil
+// before the first successful fetch) if a refresh fails.
+type Client struct {
+	client client
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	cached    *Topology
+	fetchedAt time.Time
+}
+
+// NewClient connects to brokers via sarama and returns a Client that
+// resolves cluster topology on demand. ttl <= 0 falls back to DefaultTTL.
+func NewClient(brokers []string, config *sarama.Config, ttl time.Duration) (*Client, error) {
+	sc, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Client{client: sc, ttl: ttl}, nil
+}
+
+// Close releases the underlying Kafka client connection.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// Topology returns the cached cluster topology, refreshing it first via a
+// fresh Metadata request if ttl has elapsed since the last successful
+// fetch. A refresh failure logs and returns the last good snapshot, so a
+// transient broker error falls back to whatever defaults the caller
+// already has instead of failing the whole collection cycle.
+func (c *Client) Topology() *Topology {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.ttl {
+		return c.cached
+	}
+
+	topology, err := c.fetch()
+	if err != nil {
+		log.Warn("clusterinfo: refreshing cluster topology failed, falling back to cached/default values: %v", err)
+		return c.cached
+	}
+	c.cached = topology
+	c.fetchedAt = time.Now()
+	return c.cached
+}
+
+func (c *Client) fetch() (*Topology, error) {
+	if err := c.client.RefreshMetadata(); err != nil {
+		return nil, fmt.Errorf("RefreshMetadata: %w", err)
+	}
+
+	controller, err := c.client.Controller()
+	if err != nil {
+		return nil, fmt.Errorf("Controller: %w", err)
+	}
+
+	topics, err := c.client.Topics()
+	if err != nil {
+		return nil, fmt.Errorf("Topics: %w", err)
+	}
+
+	leaderCounts := make(map[int32]int)
+	for _, topic := range topics {
+		partitions, err := c.client.Partitions(topic)
+		if err != nil {
+			log.Debug("clusterinfo: Partitions(%s) failed, skipping: %v", topic, err)
+			continue
+		}
+		for _, partition := range partitions {
+			leader, err := c.client.Leader(topic, partition)
+			if err != nil {
+				log.Debug("clusterinfo: Leader(%s/%d) failed, skipping: %v", topic, partition, err)
+				continue
+			}
+			leaderCounts[leader.ID()]++
+		}
+	}
+
+	return &Topology{
+		ControllerID: controller.ID(),
+		LeaderCounts: leaderCounts,
+	}, nil
+}
@@ -0,0 +1,161 @@
+package clusterinfo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is a test double for the subset of sarama.Client Client
+// depends on.
+type fakeClient struct {
+	refreshCalls int
+	refreshErr   error
+
+	controller    *sarama.Broker
+	controllerErr error
+
+	topics    []string
+	topicsErr error
+
+	partitions    map[string][]int32
+	partitionsErr map[string]error
+
+	leader    *sarama.Broker
+	leaderErr map[string]error
+
+	closed bool
+}
+
+func (f *fakeClient) RefreshMetadata(topics ...string) error {
+	f.refreshCalls++
+	return f.refreshErr
+}
+
+func (f *fakeClient) Controller() (*sarama.Broker, error) { return f.controller, f.controllerErr }
+func (f *fakeClient) Topics() ([]string, error)           { return f.topics, f.topicsErr }
+
+func (f *fakeClient) Partitions(topic string) ([]int32, error) {
+	if err, ok := f.partitionsErr[topic]; ok {
+		return nil, err
+	}
+	return f.partitions[topic], nil
+}
+
+func (f *fakeClient) Leader(topic string, partitionID int32) (*sarama.Broker, error) {
+	key := topic
+	if err, ok := f.leaderErr[key]; ok {
+		return nil, err
+	}
+	return f.leader, nil
+}
+
+func (f *fakeClient) Close() error {
+	f.closed = true
+	return nil
+}
+
+func newTestClient(fc *fakeClient, ttl time.Duration) *Client {
+	return &Client{client: fc, ttl: ttl}
+}
+
+func TestTopology_SumsLeaderCountsAcrossTopicsAndPartitions(t *testing.T) {
+	broker := sarama.NewBroker("broker:9092")
+	fc := &fakeClient{
+		controller: broker,
+		topics:     []string{"topic-a", "topic-b"},
+		partitions: map[string][]int32{
+			"topic-a": {0, 1},
+			"topic-b": {0},
+		},
+		leader: broker,
+	}
+	c := newTestClient(fc, time.Minute)
+
+	topology := c.Topology()
+
+	require.NotNil(t, topology)
+	assert.Equal(t, broker.ID(), topology.ControllerID)
+	assert.Equal(t, 3, topology.LeaderCounts[broker.ID()])
+}
+
+func TestTopology_PartitionsFailure_SkipsThatTopicOnly(t *testing.T) {
+	broker := sarama.NewBroker("broker:9092")
+	fc := &fakeClient{
+		controller: broker,
+		topics:     []string{"topic-a", "topic-b"},
+		partitions: map[string][]int32{
+			"topic-b": {0},
+		},
+		partitionsErr: map[string]error{"topic-a": errors.New("Partitions: topic-a not found")},
+		leader:        broker,
+	}
+	c := newTestClient(fc, time.Minute)
+
+	topology := c.Topology()
+
+	require.NotNil(t, topology)
+	assert.Equal(t, 1, topology.LeaderCounts[broker.ID()])
+}
+
+func TestTopology_LeaderFailure_SkipsThatPartitionOnly(t *testing.T) {
+	broker := sarama.NewBroker("broker:9092")
+	fc := &fakeClient{
+		controller: broker,
+		topics:     []string{"topic-a"},
+		partitions: map[string][]int32{"topic-a": {0, 1}},
+		leader:     broker,
+		leaderErr:  map[string]error{"topic-a": errors.New("Leader: no leader elected")},
+	}
+	c := newTestClient(fc, time.Minute)
+
+	topology := c.Topology()
+
+	require.NotNil(t, topology)
+	assert.Empty(t, topology.LeaderCounts)
+}
+
+func TestTopology_CachesUntilTTLElapses(t *testing.T) {
+	broker := sarama.NewBroker("broker:9092")
+	fc := &fakeClient{controller: broker, leader: broker}
+	c := newTestClient(fc, time.Hour)
+
+	c.Topology()
+	c.Topology()
+
+	assert.Equal(t, 1, fc.refreshCalls, "a live TTL should serve the second call from cache, not refetch")
+}
+
+func TestTopology_RefreshFailure_FallsBackToLastGoodCache(t *testing.T) {
+	broker := sarama.NewBroker("broker:9092")
+	fc := &fakeClient{controller: broker, leader: broker}
+	c := newTestClient(fc, time.Nanosecond)
+
+	first := c.Topology()
+	require.NotNil(t, first)
+
+	fc.refreshErr = errors.New("RefreshMetadata: no brokers available")
+	time.Sleep(time.Millisecond)
+	second := c.Topology()
+
+	assert.Same(t, first, second)
+}
+
+func TestTopology_ControllerFailure_NoCacheYet_ReturnsNil(t *testing.T) {
+	fc := &fakeClient{controllerErr: errors.New("Controller: no controller available")}
+	c := newTestClient(fc, time.Minute)
+
+	assert.Nil(t, c.Topology())
+}
+
+func TestClose_ClosesUnderlyingSaramaClient(t *testing.T) {
+	fc := &fakeClient{}
+	c := newTestClient(fc, time.Minute)
+
+	require.NoError(t, c.Close())
+	assert.True(t, fc.closed)
+}
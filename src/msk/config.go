@@ -1,25 +1,336 @@
 package msk
 
 import (
+	"encoding/json"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds the configuration for the MSK shim
 type Config struct {
-	Enabled           bool
-	ClusterName       string
-	ClusterARN        string
-	AWSAccountID      string
-	AWSRegion         string
-	Environment       string
-	DiskMountRegex    string
-	LogMountRegex     string
-	ConsumerLagEnrich bool
-	BatchSize         int
-	FlushInterval     time.Duration
-	AggregationMethod string
+	Enabled                 bool
+	ClusterName             string
+	ClusterARN              string
+	AWSAccountID            string
+	AWSRegion               string
+	Environment             string
+	DiskMountRegex          string
+	LogMountRegex           string
+	ConsumerLagEnrich       bool
+	ConsumerLagWorkers      int
+	// TransformConcurrency bounds how many goroutines MSKShim.TransformAll
+	// runs at once when fanning broker/topic/offset transforms out across a
+	// worker pool. 0 (the default) falls back to defaultTransformConcurrency.
+	TransformConcurrency   int
+	BatchSize               int
+	FlushInterval           time.Duration
+	AggregationMethod       string
+	PrometheusListenAddr    string
+	Source                  SourceKind
+	KafkaSink               *KafkaSinkConfig
+	EmitBackend             string
+	TimestampMode           string
+	BestEffortWindow        time.Duration
+	BestEffortOnly          bool
+	MinTimestamp            int64
+	MaxTimestamp            int64
+	MetadataRefreshInterval time.Duration
+	TopicIncludeRegex       string
+	TopicExcludeRegex       string
+	CloudWatchBatchSize     int
+	CloudWatchFlushInterval time.Duration
+	CloudWatchMaxBuckets    int
+	WorkloadProfile         string
+
+	// EnhancedDiscovery, when EnhancedDiscoveryBootstrapServers is set,
+	// lets enhanced mode replace its hardcoded broker/topic/controller
+	// defaults with a real Kafka AdminClient's DescribeCluster/
+	// DescribeTopics answer.
+	EnhancedDiscoveryBootstrapServers []string
+	EnhancedDiscoverySASLEnabled      bool
+	EnhancedDiscoverySASLMechanism    string
+	EnhancedDiscoverySASLUsername     string
+	EnhancedDiscoverySASLPassword     string
+	EnhancedDiscoveryTLSEnabled       bool
+
+	// CapacityFilePath, when set, points at a Cruise-Control-style
+	// capacity.json the enhanced transformer loads into a CapacityProvider
+	// to derive per-broker disk/network/CPU utilization metrics.
+	CapacityFilePath string
+
+	// EnhancedSeed seeds EnhancedTransformer's random source. 0 (the
+	// default) seeds from the current time, same as the transformer's old
+	// rand.Seed(time.Now().UnixNano()) behavior; a non-zero value makes
+	// its simulated metrics and broker/topic ID fallbacks reproducible,
+	// which golden-file tests for the MSK payload rely on.
+	EnhancedSeed int64
+
+	// ExcludePersistentLag, when true, has DimensionalTransformer skip
+	// partitions whose lag has been non-decreasing across the last
+	// PersistentLagWindowSize observations from kafka.consumer.MaxLag/
+	// TotalLag: those partitions represent a consumer that's down or not
+	// committing, not one under real load, and including them inflates
+	// alerting metrics (the same distinction KEDA's Kafka scaler makes).
+	ExcludePersistentLag bool
+	// PersistentLagWindowSize is how many recent lag observations
+	// LagHistoryCache keeps per partition before it judges a lag series
+	// non-decreasing.
+	PersistentLagWindowSize int
+	// PersistentLagThreshold is the minimum current lag a non-decreasing
+	// partition must have to be excluded; 0 (the default) means any
+	// non-decreasing series counts.
+	PersistentLagThreshold float64
+
+	// MSKDiscoveryEnabled, when true, has ComprehensiveMSKShim call the real
+	// AWS MSK control plane's ListNodes API against
+	// ClusterARN and reconcile the result against what extractBrokerInfo
+	// inferred from JMX, surfacing any discrepancy as
+	// provider.shim.discoveryDrift - for self-managed Kafka-on-EC2 users
+	// gradually migrating to real MSK who want confidence the shim's
+	// synthesized entities line up with what AWS actually reports.
+	MSKDiscoveryEnabled bool
+	// MSKDiscoveryRefreshInterval bounds how often MSKDiscoveryClient calls
+	// ListNodes, since AWS's MSK control-plane APIs are rate-limited and the
+	// node list rarely changes between broker scaling events.
+	MSKDiscoveryRefreshInterval time.Duration
+
+	// AWSClusterEnrichEnabled, when true, has MSKShim replace
+	// SimpleTransformClusterMetrics's hardcoded cluster-level defaults
+	// (brokerCount, clusterStatus, state, and the absent storage/encryption/
+	// Kafka-version attributes) with a live msk/awsenrich.Client answer for
+	// ClusterARN. Left false by default since ClusterARN is synthesized
+	// (possibly from a fake AWSAccountID) even when no real AWS credentials
+	// are available, and a real AWS MSK control-plane call would otherwise
+	// fail every Flush cycle.
+	AWSClusterEnrichEnabled bool
+	// AWSClusterEnrichTTL bounds how often the awsenrich.Client refreshes its
+	// DescribeClusterV2/ListNodes answer. 0 falls back to awsenrich.DefaultTTL.
+	AWSClusterEnrichTTL time.Duration
+
+	// CloudWatchHostMetricsEnabled, when true, has MSKShim replace
+	// SimpleTransformBrokerMetrics's hardcoded cpuIdle/memoryUsed/
+	// networkRxPackets/etc constants with real values queried from the
+	// AWS/Kafka CloudWatch namespace via msk/cloudwatch.Client. Left false
+	// by default for the same reason as AWSClusterEnrichEnabled: it costs a
+	// real AWS API call every refresh.
+	CloudWatchHostMetricsEnabled bool
+	// CloudWatchHostMetricsTimeout bounds a single GetMetricData refresh. 0
+	// falls back to cloudwatch.DefaultTimeout.
+	CloudWatchHostMetricsTimeout time.Duration
+	// CloudWatchHostMetricsTTL bounds how often msk/cloudwatch.Client
+	// refreshes its GetMetricData answer. 0 falls back to
+	// cloudwatch.DefaultTTL.
+	CloudWatchHostMetricsTTL time.Duration
+
+	// PromExportEnabled, when true, has MSKShim mirror every provider.*
+	// metric SimpleTransformBrokerMetrics/SimpleTransformTopicMetrics/
+	// SimpleTransformClusterMetrics/SimpleTransformConsumerOffset emit onto
+	// a native msk/promexport.Exporter, so a cluster can be scraped by
+	// Prometheus directly instead of only through the New Relic agent.
+	// Left false by default since it opens a listening socket on every
+	// collection host.
+	PromExportEnabled bool
+	// PromExportListenAddr is where the promexport /metrics endpoint
+	// listens. Empty falls back to promexport.DefaultListenAddr.
+	PromExportListenAddr string
+
+	// SaramaLiveLagEnabled, when true, has Flush run a wired-in
+	// SaramaConsumerCollector (via SetSaramaConsumerCollector) alongside
+	// ConsumerGroupLagCollector, fetching each partition's high-water mark
+	// directly from the broker right before emitting rather than relying
+	// on whatever CloudWatch/JMX last reported, and pushing the result
+	// through DimensionalTransformer.TransformConsumerMetrics for
+	// sub-minute lag freshness.
+	SaramaLiveLagEnabled bool
+
+	// ConsumerGroupStateEnabled, when true, has Flush run a wired-in
+	// ConsumerGroupStateCollector, snapshotting every consumer group's
+	// Stable/PreparingRebalance/CompletingRebalance/Empty/Dead state,
+	// member count, and per-member assignment through
+	// DimensionalTransformer.TransformConsumerGroupState.
+	ConsumerGroupStateEnabled bool
+
+	// DataStreamsEnabled, when true, has NewDimensionalTransformer wire in a
+	// datastreams.Tracker so TransformConsumerMetrics additionally emits
+	// aws.msk.pipeline.latency and aws.msk.pipeline.backlog, a
+	// topology-level view of a streaming pipeline path (producer service ->
+	// topic -> consumer group) alongside the per-topic/per-group lag
+	// metrics it already emits.
+	DataStreamsEnabled bool
+
+	// OffsetPipelineEnabled, when true, has Flush run a wired-in
+	// OffsetPipelineCollector, reading a merged (group, topic, partition)
+	// snapshot from an offsetpipeline.Pipeline that may combine CloudWatch,
+	// Sarama, Burrow, and Kminion sources by configurable precedence,
+	// alongside the lag-only collectors above.
+	OffsetPipelineEnabled bool
+
+	// EnableReassignmentMetrics, when true, has DimensionalTransformer
+	// consult a ReassignmentCollector (wired in via SetReassignmentCollector)
+	// inside TransformClusterMetrics to surface in-flight KIP-455 partition
+	// reassignments as first-class metrics.
+	EnableReassignmentMetrics bool
+	// ReassignmentPollInterval is how often the wired-in ReassignmentCollector
+	// polls ListPartitionReassignments.
+	ReassignmentPollInterval time.Duration
+	// ReassignmentBootstrapServers, when EnableReassignmentMetrics is true,
+	// is the Kafka cluster NewMSKShim connects to build a real
+	// ReassignmentCollector. Reassignment metrics are skipped (the -1
+	// sentinel is emitted instead) if this is empty.
+	ReassignmentBootstrapServers []string
+
+	// ConfigGuardEnabled, when true, has NewMSKShim poll every known
+	// broker's config on ConfigGuardPollInterval via configguard.Guard,
+	// raising KafkaConfigChangeSample/KafkaConfigPolicyViolation events
+	// through EmitEvent when a sensitive property changes or a loaded
+	// policy rule is violated.
+	ConfigGuardEnabled bool
+	// ConfigGuardBootstrapServers is the Kafka cluster NewMSKShim connects
+	// to build the admin client ConfigGuard polls broker config with.
+	// ConfigGuard is disabled if this is empty.
+	ConfigGuardBootstrapServers []string
+	// ConfigGuardPollInterval is how often ConfigGuard re-fetches and
+	// diffs every broker's config.
+	ConfigGuardPollInterval time.Duration
+	// ConfigGuardPolicyPath, if set, is a YAML file of configguard.Policy
+	// rules loaded at startup. An unset or unreadable path leaves change
+	// detection active but policy-violation checks disabled.
+	ConfigGuardPolicyPath string
+
+	// AdminFallbackEnabled, when true, allows IntegrationHook.SetAdminClient
+	// to wire up an AdminFallbackCollector, which IntegrationHook falls back
+	// to for brokers whose JMX beans ValidateJMXConfiguration reports as
+	// missing -- translating DescribeCluster/DescribeTopics/
+	// ListConsumerGroupOffsets/DescribeLogDirs responses into the same MSK
+	// fields a JMX-sourced sample would have carried, so dashboards keep
+	// populating on brokers where only the Kafka wire protocol is reachable.
+	AdminFallbackEnabled bool
+
+	// GUIDLegacyCompatCycles, when positive, opens a migration window of that
+	// many GetOrGenerate calls per entity during which the GUID cache also
+	// hands back each entity's pre-chunk13-5 GUID (the one built from the
+	// buggy signed-int64 hash truncation) via GUIDCache.LegacyGUID, so
+	// DimensionalTransformer can dual-write entity.guid and
+	// entity.guid.legacy and an operator's existing New Relic entities don't
+	// go stale the moment the hash fix ships. 0 (the default) disables
+	// legacy-compat entirely.
+	GUIDLegacyCompatCycles int
+
+	// GUIDCachePersistencePath, when set, has DimensionalTransformer back its
+	// entity GUID cache with a PersistentGUIDCache backed by a
+	// JSONFileCacheBackend at this path instead of a plain in-memory
+	// GUIDCache, so a restart reloads previously generated GUIDs instead of
+	// regenerating (and potentially duplicating, on a descriptor-version
+	// mismatch or transient AWS_ACCOUNT_ID loss) every entity. Empty (the
+	// default) keeps the existing in-memory-only behavior.
+	GUIDCachePersistencePath string
+	// GUIDCacheFlushInterval is how often a persistence-enabled GUID cache
+	// flushes its snapshot to GUIDCachePersistencePath.
+	GUIDCacheFlushInterval time.Duration
+
+	// StaleGroupTTLCycles is how many consecutive Flush cycles a consumer
+	// group can go without a ProcessConsumerOffsetSample call before
+	// ComprehensiveMSKShim evicts it from entityCache, so a group that's
+	// been deleted or has stopped consuming eventually stops being
+	// reported instead of lingering forever on its last-known lag.
+	StaleGroupTTLCycles int
+
+	// MetricAPICompression selects the Content-Encoding MetricAPIClient
+	// compresses POST bodies with: "none", "gzip", "snappy", or "zstd".
+	// Metric API batches are flushed every FlushInterval across every
+	// broker this shim watches, so compressing them is a meaningful cost
+	// reduction the same way Sarama's producer compression is.
+	MetricAPICompression string
+
+	// Exporters lists which Exporter implementations DimensionalTransformer
+	// fans metrics out to in addition to the default New Relic Metric API
+	// push ("newrelic" is always included even if omitted here): "prometheus"
+	// starts a local OpenMetrics scrape endpoint on PrometheusListenAddr,
+	// "kafka" republishes onto KafkaSink's configured topic.
+	Exporters []string
+
+	// ConsumerPartitionLimitation restricts lag distribution reporting to a
+	// subset of partitions, entries like "0-15" (inclusive range) or "32"
+	// (single partition) - the same partitionLimitation idea KEDA's Kafka
+	// scaler offers so a noisy partition outside the scaled range doesn't
+	// skew the distribution. Empty means report every partition.
+	ConsumerPartitionLimitation []string
+
+	// ConsumerLagThresholds maps a consumer group ID to its own
+	// warn/critical/activation lag boundaries, borrowing KEDA's Kafka
+	// scaler model of per-group lagThreshold/activationLagThreshold. A
+	// group missing from this map uses DefaultLagThreshold instead.
+	ConsumerLagThresholds map[string]LagThreshold
+	// DefaultLagThreshold is applied to any consumer group with no entry
+	// in ConsumerLagThresholds.
+	DefaultLagThreshold LagThreshold
+	// LagIdleWindow is how long a consumer group's total lag must stay at
+	// or below its ActivationLag before it's classified "idle" rather
+	// than "ok", so a single low reading doesn't flip its status.
+	LagIdleWindow time.Duration
+}
+
+// LagThreshold is one consumer group's lag classification boundaries, the
+// same per-group knobs KEDA's Kafka scaler exposes as lagThreshold and
+// activationLagThreshold: WarnLag/CritLag set the ok->warn->critical
+// boundaries, and ActivationLag sets the floor below which a group that's
+// stayed quiet for Config.LagIdleWindow is classified idle instead of ok.
+// A zero value for any field disables the check it gates.
+type LagThreshold struct {
+	WarnLag       float64 `json:"warnLag"`
+	CritLag       float64 `json:"critLag"`
+	ActivationLag float64 `json:"activationLag"`
+}
+
+// partitionRange is an inclusive [lo, hi] partition bound parsed from one
+// ConsumerPartitionLimitation entry.
+type partitionRange struct {
+	lo, hi int32
+}
+
+// parsePartitionLimitation parses ConsumerPartitionLimitation entries
+// ("0-15" or "32") into partitionRanges; malformed entries are skipped
+// rather than failing startup.
+func parsePartitionLimitation(entries []string) []partitionRange {
+	var ranges []partitionRange
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if dash := strings.Index(entry, "-"); dash >= 0 {
+			loVal, loErr := strconv.ParseInt(strings.TrimSpace(entry[:dash]), 10, 32)
+			hiVal, hiErr := strconv.ParseInt(strings.TrimSpace(entry[dash+1:]), 10, 32)
+			if loErr != nil || hiErr != nil {
+				continue
+			}
+			ranges = append(ranges, partitionRange{lo: int32(loVal), hi: int32(hiVal)})
+			continue
+		}
+		val, err := strconv.ParseInt(entry, 10, 32)
+		if err != nil {
+			continue
+		}
+		ranges = append(ranges, partitionRange{lo: int32(val), hi: int32(val)})
+	}
+	return ranges
+}
+
+// partitionInLimitation reports whether partition falls within any of
+// ranges; an empty ranges means no limitation is configured, so every
+// partition passes.
+func partitionInLimitation(partition int32, ranges []partitionRange) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if partition >= r.lo && partition <= r.hi {
+			return true
+		}
+	}
+	return false
 }
 
 // NewConfig creates a new MSK configuration from environment variables
@@ -52,8 +363,28 @@ func NewConfig() *Config {
 		LogMountRegex:     getEnvOrDefault("LOG_MOUNT_REGEX", "logs|kafka-logs"),
 		ConsumerLagEnrich: os.Getenv("CONSUMER_LAG_ENRICHMENT") == "true",
 		AggregationMethod: getEnvOrDefault("MSK_AGGREGATION_METHOD", "max"),
+		PrometheusListenAddr: getEnvOrDefault("MSK_PROMETHEUS_LISTEN_ADDR", ""),
+		Source:            SourceKind(getEnvOrDefault("MSK_SOURCE", string(SourceJMX))),
+		EmitBackend:       getEnvOrDefault("MSK_EMIT_BACKEND", string(EmitBackendInfra)),
+		TimestampMode:     getEnvOrDefault("MSK_TIMESTAMP_MODE", string(TimestampModeBestEffort)),
+		BestEffortOnly:    os.Getenv("MSK_BEST_EFFORT_ONLY") == "true",
+		WorkloadProfile:   getEnvOrDefault("MSK_WORKLOAD_PROFILE", "steady"),
 	}
 
+	// Parse consumer lag collector worker pool size
+	lagWorkers, err := strconv.Atoi(getEnvOrDefault("MSK_CONSUMER_LAG_WORKERS", "4"))
+	if err != nil || lagWorkers <= 0 {
+		lagWorkers = 4
+	}
+	config.ConsumerLagWorkers = lagWorkers
+
+	// Parse the TransformAll worker-pool concurrency knob.
+	transformConcurrency, err := strconv.Atoi(getEnvOrDefault("MSK_TRANSFORM_CONCURRENCY", "0"))
+	if err != nil || transformConcurrency < 0 {
+		transformConcurrency = 0
+	}
+	config.TransformConcurrency = transformConcurrency
+
 	// Parse batch size
 	batchSize, err := strconv.Atoi(getEnvOrDefault("MSK_BATCH_SIZE", "1000"))
 	if err != nil {
@@ -68,6 +399,222 @@ func NewConfig() *Config {
 	}
 	config.FlushInterval = flushInterval
 
+	// Parse dimensional metrics timestamp-windowing settings, used to
+	// absorb CloudWatch Metric Streams' 2-5 minute late/out-of-order
+	// delivery without either blocking emission or silently discarding
+	// the sample's real timestamp.
+	bestEffortWindow, err := time.ParseDuration(getEnvOrDefault("MSK_BEST_EFFORT_WINDOW", "2m"))
+	if err != nil {
+		bestEffortWindow = 2 * time.Minute
+	}
+	config.BestEffortWindow = bestEffortWindow
+
+	if minTs, err := strconv.ParseInt(os.Getenv("MSK_MIN_TIMESTAMP"), 10, 64); err == nil {
+		config.MinTimestamp = minTs
+	}
+	if maxTs, err := strconv.ParseInt(os.Getenv("MSK_MAX_TIMESTAMP"), 10, 64); err == nil {
+		config.MaxTimestamp = maxTs
+	}
+
+	// Parse metadata refresh loop settings
+	metadataRefreshInterval, err := time.ParseDuration(getEnvOrDefault("MSK_METADATA_REFRESH_INTERVAL", "30s"))
+	if err != nil {
+		metadataRefreshInterval = 30 * time.Second
+	}
+	config.MetadataRefreshInterval = metadataRefreshInterval
+	config.TopicIncludeRegex = os.Getenv("MSK_TOPIC_INCLUDE_REGEX")
+	config.TopicExcludeRegex = os.Getenv("MSK_TOPIC_EXCLUDE_REGEX")
+
+	// Parse CloudWatch emulator batching settings. CloudWatchBatchSize is
+	// capped at 1000 to mirror the real PutMetricData limit.
+	cwBatchSize, err := strconv.Atoi(getEnvOrDefault("MSK_CLOUDWATCH_BATCH_SIZE", "20"))
+	if err != nil || cwBatchSize <= 0 {
+		cwBatchSize = 20
+	}
+	if cwBatchSize > 1000 {
+		cwBatchSize = 1000
+	}
+	config.CloudWatchBatchSize = cwBatchSize
+
+	cwFlushInterval, err := time.ParseDuration(getEnvOrDefault("MSK_CLOUDWATCH_FLUSH_INTERVAL", "60s"))
+	if err != nil {
+		cwFlushInterval = 60 * time.Second
+	}
+	config.CloudWatchFlushInterval = cwFlushInterval
+
+	cwMaxBuckets, err := strconv.Atoi(getEnvOrDefault("MSK_CLOUDWATCH_MAX_BUCKETS", "10000"))
+	if err != nil || cwMaxBuckets <= 0 {
+		cwMaxBuckets = 10000
+	}
+	config.CloudWatchMaxBuckets = cwMaxBuckets
+
+	// Parse enhanced-mode discovery settings (enhanced.discovery.bootstrap_servers
+	// and its SASL/TLS options): when bootstrap servers are configured,
+	// enhanced mode backs its simulated defaults with a real AdminDiscoverer
+	// instead of guessing brokerCount=3/topicCount=10/controller=brokerID%3.
+	if bootstrapServers := os.Getenv("MSK_ENHANCED_DISCOVERY_BOOTSTRAP_SERVERS"); bootstrapServers != "" {
+		config.EnhancedDiscoveryBootstrapServers = strings.Split(bootstrapServers, ",")
+	}
+	config.EnhancedDiscoverySASLEnabled = os.Getenv("MSK_ENHANCED_DISCOVERY_SASL_ENABLED") == "true"
+	config.EnhancedDiscoverySASLMechanism = getEnvOrDefault("MSK_ENHANCED_DISCOVERY_SASL_MECHANISM", "PLAIN")
+	config.EnhancedDiscoverySASLUsername = os.Getenv("MSK_ENHANCED_DISCOVERY_SASL_USERNAME")
+	config.EnhancedDiscoverySASLPassword = os.Getenv("MSK_ENHANCED_DISCOVERY_SASL_PASSWORD")
+	config.EnhancedDiscoveryTLSEnabled = os.Getenv("MSK_ENHANCED_DISCOVERY_TLS_ENABLED") == "true"
+	config.CapacityFilePath = os.Getenv("MSK_CAPACITY_FILE")
+	if seed, err := strconv.ParseInt(os.Getenv("MSK_ENHANCED_SEED"), 10, 64); err == nil {
+		config.EnhancedSeed = seed
+	}
+
+	// Parse persistent-lag exclusion settings (see ExcludePersistentLag).
+	config.ExcludePersistentLag = os.Getenv("MSK_EXCLUDE_PERSISTENT_LAG") == "true"
+	lagWindowSize, err := strconv.Atoi(getEnvOrDefault("MSK_PERSISTENT_LAG_WINDOW_SIZE", "5"))
+	if err != nil || lagWindowSize <= 0 {
+		lagWindowSize = 5
+	}
+	config.PersistentLagWindowSize = lagWindowSize
+	if threshold, err := strconv.ParseFloat(os.Getenv("MSK_PERSISTENT_LAG_THRESHOLD"), 64); err == nil {
+		config.PersistentLagThreshold = threshold
+	}
+	if partitionLimitation := os.Getenv("MSK_CONSUMER_PARTITION_LIMITATION"); partitionLimitation != "" {
+		config.ConsumerPartitionLimitation = strings.Split(partitionLimitation, ",")
+	}
+
+	// Parse real-MSK discovery-drift reconciliation settings (see
+	// MSKDiscoveryEnabled).
+	config.MSKDiscoveryEnabled = os.Getenv("MSK_DISCOVERY_ENABLED") == "true"
+	mskDiscoveryRefreshInterval, err := time.ParseDuration(getEnvOrDefault("MSK_DISCOVERY_REFRESH_INTERVAL", "5m"))
+	if err != nil || mskDiscoveryRefreshInterval <= 0 {
+		mskDiscoveryRefreshInterval = 5 * time.Minute
+	}
+	config.MSKDiscoveryRefreshInterval = mskDiscoveryRefreshInterval
+
+	// Parse live AWS MSK cluster-enrichment settings (see
+	// AWSClusterEnrichEnabled).
+	config.AWSClusterEnrichEnabled = os.Getenv("MSK_AWS_CLUSTER_ENRICH_ENABLED") == "true"
+	awsClusterEnrichTTL, err := time.ParseDuration(getEnvOrDefault("MSK_AWS_CLUSTER_ENRICH_TTL", "5m"))
+	if err != nil || awsClusterEnrichTTL <= 0 {
+		awsClusterEnrichTTL = 5 * time.Minute
+	}
+	config.AWSClusterEnrichTTL = awsClusterEnrichTTL
+
+	// Parse CloudWatch host-metrics settings (see
+	// CloudWatchHostMetricsEnabled).
+	config.CloudWatchHostMetricsEnabled = os.Getenv("MSK_CLOUDWATCH_HOST_METRICS_ENABLED") == "true"
+	cloudWatchHostMetricsTimeout, err := time.ParseDuration(getEnvOrDefault("MSK_CLOUDWATCH_HOST_METRICS_TIMEOUT", "10s"))
+	if err != nil || cloudWatchHostMetricsTimeout <= 0 {
+		cloudWatchHostMetricsTimeout = 10 * time.Second
+	}
+	config.CloudWatchHostMetricsTimeout = cloudWatchHostMetricsTimeout
+	cloudWatchHostMetricsTTL, err := time.ParseDuration(getEnvOrDefault("MSK_CLOUDWATCH_HOST_METRICS_TTL", "1m"))
+	if err != nil || cloudWatchHostMetricsTTL <= 0 {
+		cloudWatchHostMetricsTTL = time.Minute
+	}
+	config.CloudWatchHostMetricsTTL = cloudWatchHostMetricsTTL
+
+	// Parse native Prometheus export settings (see PromExportEnabled).
+	config.PromExportEnabled = os.Getenv("MSK_PROM_EXPORT_ENABLED") == "true"
+	config.PromExportListenAddr = getEnvOrDefault("MSK_PROM_LISTEN_ADDR", ":9682")
+
+	// Parse live Sarama consumer-lag collection settings (see
+	// SaramaLiveLagEnabled).
+	config.SaramaLiveLagEnabled = os.Getenv("MSK_SARAMA_LIVE_LAG_ENABLED") == "true"
+
+	// Parse consumer-group state/membership collection settings (see
+	// ConsumerGroupStateEnabled).
+	config.ConsumerGroupStateEnabled = os.Getenv("MSK_CONSUMER_GROUP_STATE_ENABLED") == "true"
+
+	// Parse data streams monitoring-style checkpoint settings (see
+	// DataStreamsEnabled).
+	config.DataStreamsEnabled = os.Getenv("MSK_DATA_STREAMS_ENABLED") == "true"
+
+	// Parse multi-source offset pipeline settings (see
+	// OffsetPipelineEnabled).
+	config.OffsetPipelineEnabled = os.Getenv("MSK_OFFSET_PIPELINE_ENABLED") == "true"
+
+	// Parse KIP-455 reassignment metrics settings.
+	config.EnableReassignmentMetrics = os.Getenv("MSK_ENABLE_REASSIGNMENT_METRICS") == "true"
+	reassignmentPollInterval, err := time.ParseDuration(getEnvOrDefault("MSK_REASSIGNMENT_POLL_INTERVAL", "60s"))
+	if err != nil || reassignmentPollInterval <= 0 {
+		reassignmentPollInterval = 60 * time.Second
+	}
+	config.ReassignmentPollInterval = reassignmentPollInterval
+	if bootstrapServers := os.Getenv("MSK_REASSIGNMENT_BOOTSTRAP_SERVERS"); bootstrapServers != "" {
+		config.ReassignmentBootstrapServers = strings.Split(bootstrapServers, ",")
+	}
+
+	// Parse broker config-change/policy guard settings (see
+	// ConfigGuardEnabled).
+	config.ConfigGuardEnabled = os.Getenv("MSK_CONFIG_GUARD_ENABLED") == "true"
+	if bootstrapServers := os.Getenv("MSK_CONFIG_GUARD_BOOTSTRAP_SERVERS"); bootstrapServers != "" {
+		config.ConfigGuardBootstrapServers = strings.Split(bootstrapServers, ",")
+	}
+	configGuardPollInterval, err := time.ParseDuration(getEnvOrDefault("MSK_CONFIG_GUARD_POLL_INTERVAL", "5m"))
+	if err != nil || configGuardPollInterval <= 0 {
+		configGuardPollInterval = 5 * time.Minute
+	}
+	config.ConfigGuardPollInterval = configGuardPollInterval
+	config.ConfigGuardPolicyPath = os.Getenv("MSK_CONFIG_GUARD_POLICY_PATH")
+
+	// Parse admin-client JMX fallback settings (see AdminFallbackEnabled).
+	config.AdminFallbackEnabled = os.Getenv("MSK_ADMIN_FALLBACK_ENABLED") == "true"
+
+	// Parse GUID legacy-compat settings (see GUIDLegacyCompatCycles).
+	guidLegacyCompatCycles, err := strconv.Atoi(getEnvOrDefault("MSK_GUID_LEGACY_COMPAT_CYCLES", "0"))
+	if err != nil || guidLegacyCompatCycles < 0 {
+		guidLegacyCompatCycles = 0
+	}
+	config.GUIDLegacyCompatCycles = guidLegacyCompatCycles
+
+	// Parse persistent GUID cache settings (see GUIDCachePersistencePath).
+	config.GUIDCachePersistencePath = os.Getenv("MSK_GUID_CACHE_PERSISTENCE_PATH")
+	guidCacheFlushInterval, err := time.ParseDuration(getEnvOrDefault("MSK_GUID_CACHE_FLUSH_INTERVAL", "60s"))
+	if err != nil || guidCacheFlushInterval <= 0 {
+		guidCacheFlushInterval = 60 * time.Second
+	}
+	config.GUIDCacheFlushInterval = guidCacheFlushInterval
+
+	// Parse stale consumer-group eviction settings (see StaleGroupTTLCycles).
+	staleGroupTTLCycles, err := strconv.Atoi(getEnvOrDefault("MSK_STALE_GROUP_TTL_CYCLES", "5"))
+	if err != nil || staleGroupTTLCycles < 0 {
+		staleGroupTTLCycles = 5
+	}
+	config.StaleGroupTTLCycles = staleGroupTTLCycles
+
+	// Parse Metric API compression codec.
+	switch compression := getEnvOrDefault("MSK_METRIC_COMPRESSION", "gzip"); compression {
+	case "none", "gzip", "snappy", "zstd":
+		config.MetricAPICompression = compression
+	default:
+		config.MetricAPICompression = "gzip"
+	}
+
+	if exporters := os.Getenv("MSK_EXPORTERS"); exporters != "" {
+		config.Exporters = strings.Split(exporters, ",")
+	}
+
+	// Parse per-group lag-threshold classification settings (see
+	// ConsumerLagThresholds). Malformed JSON is logged nowhere here since
+	// Config construction runs before logging is set up elsewhere in this
+	// file (e.g. EnhancedSeed above) - it's simply left unset, same as a
+	// missing env var.
+	if raw := os.Getenv("MSK_CONSUMER_LAG_THRESHOLDS"); raw != "" {
+		var thresholds map[string]LagThreshold
+		if err := json.Unmarshal([]byte(raw), &thresholds); err == nil {
+			config.ConsumerLagThresholds = thresholds
+		}
+	}
+	if raw := os.Getenv("MSK_CONSUMER_LAG_DEFAULT_THRESHOLD"); raw != "" {
+		var defaultThreshold LagThreshold
+		if err := json.Unmarshal([]byte(raw), &defaultThreshold); err == nil {
+			config.DefaultLagThreshold = defaultThreshold
+		}
+	}
+	lagIdleWindow, err := time.ParseDuration(getEnvOrDefault("MSK_CONSUMER_LAG_IDLE_WINDOW", "5m"))
+	if err != nil || lagIdleWindow <= 0 {
+		lagIdleWindow = 5 * time.Minute
+	}
+	config.LagIdleWindow = lagIdleWindow
+
 	// Generate cluster ARN if not provided
 	if config.ClusterARN == "" && config.AWSAccountID != "" && config.AWSRegion != "" && config.ClusterName != "" {
 		// Generate a valid AWS ARN format
@@ -0,0 +1,77 @@
+package msk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// awsAccountIDPattern matches a valid 12-digit AWS account ID.
+var awsAccountIDPattern = regexp.MustCompile(`^\d{12}$`)
+
+// awsRegionPattern matches a valid AWS region identifier, e.g. us-east-1.
+var awsRegionPattern = regexp.MustCompile(`^[a-z]{2}-[a-z]+-\d$`)
+
+// clusterARNPattern matches an MSK cluster ARN:
+// arn:aws:kafka:<region>:<account-id>:cluster/<name>/<uuid>
+var clusterARNPattern = regexp.MustCompile(`^arn:aws:kafka:[a-z0-9-]+:\d{12}:cluster/[^/]+/[0-9a-f-]+$`)
+
+// ConfigValidationError reports every schema violation found, so a
+// misconfigured shim fails loudly with a complete list instead of
+// one-error-at-a-time.
+type ConfigValidationError struct {
+	Violations []string
+}
+
+func (e *ConfigValidationError) Error() string {
+	return fmt.Sprintf("invalid MSK shim config: %s", strings.Join(e.Violations, "; "))
+}
+
+// ValidateConfig schema-validates the cluster/AWS/environment identifiers
+// a Config carries before the shim emits any entity, so a malformed
+// account ID or cluster ARN surfaces as a startup error rather than a
+// silently wrong entity GUID downstream.
+func ValidateConfig(config *Config) error {
+	var violations []string
+
+	if config.ClusterName == "" {
+		violations = append(violations, "ClusterName must not be empty")
+	}
+
+	if config.AWSAccountID == "" {
+		violations = append(violations, "AWSAccountID must not be empty")
+	} else if !awsAccountIDPattern.MatchString(config.AWSAccountID) {
+		violations = append(violations, fmt.Sprintf("AWSAccountID %q must be a 12-digit AWS account ID", config.AWSAccountID))
+	}
+
+	if config.AWSRegion == "" {
+		violations = append(violations, "AWSRegion must not be empty")
+	} else if !awsRegionPattern.MatchString(config.AWSRegion) {
+		violations = append(violations, fmt.Sprintf("AWSRegion %q does not look like a valid AWS region", config.AWSRegion))
+	}
+
+	if config.ClusterARN != "" && !clusterARNPattern.MatchString(config.ClusterARN) {
+		violations = append(violations, fmt.Sprintf("ClusterARN %q is not a valid MSK cluster ARN", config.ClusterARN))
+	}
+
+	if config.DiskMountRegex != "" {
+		if _, err := regexp.Compile(config.DiskMountRegex); err != nil {
+			violations = append(violations, fmt.Sprintf("DiskMountRegex %q is not a valid regex: %v", config.DiskMountRegex, err))
+		}
+	}
+
+	if config.LogMountRegex != "" {
+		if _, err := regexp.Compile(config.LogMountRegex); err != nil {
+			violations = append(violations, fmt.Sprintf("LogMountRegex %q is not a valid regex: %v", config.LogMountRegex, err))
+		}
+	}
+
+	if config.BatchSize <= 0 {
+		violations = append(violations, fmt.Sprintf("BatchSize must be positive, got %d", config.BatchSize))
+	}
+
+	if len(violations) > 0 {
+		return &ConfigValidationError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package msk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// hwmCacheTTL bounds how long a cached log-end offset is reused before
+// refetching. GetOffset is the hot call in lag computation, and most
+// deployments don't need sub-second freshness on it.
+const hwmCacheTTL = 3 * time.Second
+
+// ConsumerGroupDescription mirrors the subset of DescribeConsumerGroups
+// fields operators care about most: state, assignment strategy, membership,
+// and whether a rebalance is currently in flight.
+type ConsumerGroupDescription struct {
+	GroupID             string
+	State               string
+	AssignmentStrategy  string
+	MemberCount         int
+	RebalanceInProgress bool
+}
+
+// GroupTopicLag is the per-(group,topic) lag rollup
+// ConsumerGroupLagCollector computes from ListConsumerGroupOffsets and
+// cached log-end offsets.
+type GroupTopicLag struct {
+	Group          string
+	Topic          string
+	SumLag         int64
+	MaxLag         int64
+	PartitionCount int
+}
+
+// hwmCacheEntry caches one partition's most recently fetched log-end
+// offset.
+type hwmCacheEntry struct {
+	offset    int64
+	fetchedAt time.Time
+}
+
+// ConsumerGroupLagCollector actively computes consumer-group lag from the
+// Kafka admin API (ListConsumerGroups, DescribeConsumerGroups,
+// ListConsumerGroupOffsets, and client.GetOffset for log-end offsets),
+// replacing SimpleConsumerLagEnricher's passive approach of only recording
+// whatever lag value happened to already be present in an inbound offset
+// sample. It emits entities at two levels, per-group
+// (AwsMskConsumerGroupSample) and per-group-topic
+// (AwsMskConsumerGroupTopicSample), and merges each group-topic's summed
+// lag into the aggregator's per-topic lag map so the existing topic entity picks it up
+// without this collector reaching into SimpleTransformTopicMetrics itself.
+type ConsumerGroupLagCollector struct {
+	admin       sarama.ClusterAdmin
+	client      sarama.Client
+	aggregator  *MetricAggregator
+	integration *integration.Integration
+	config      *Config
+
+	hwmMu    sync.Mutex
+	hwmCache map[string]hwmCacheEntry
+}
+
+// NewConsumerGroupLagCollector wraps an already-connected admin client and
+// sarama.Client (for GetOffset), feeding results into aggregator and
+// emitting entities through i.
+func NewConsumerGroupLagCollector(admin sarama.ClusterAdmin, client sarama.Client, aggregator *MetricAggregator, i *integration.Integration, config *Config) *ConsumerGroupLagCollector {
+	return &ConsumerGroupLagCollector{
+		admin:       admin,
+		client:      client,
+		aggregator:  aggregator,
+		integration: i,
+		config:      config,
+		hwmCache:    make(map[string]hwmCacheEntry),
+	}
+}
+
+// Collect lists every consumer group, describes each one's state and
+// membership, and computes per-(group,topic) lag in parallel across a
+// worker pool bounded by Config.ConsumerLagWorkers.
+func (c *ConsumerGroupLagCollector) Collect() error {
+	groups, err := c.admin.ListConsumerGroups()
+	if err != nil {
+		return fmt.Errorf("listing consumer groups: %w", err)
+	}
+
+	groupNames := make([]string, 0, len(groups))
+	for name := range groups {
+		groupNames = append(groupNames, name)
+	}
+	if len(groupNames) == 0 {
+		return nil
+	}
+
+	descriptions, err := c.admin.DescribeConsumerGroups(groupNames)
+	if err != nil {
+		return fmt.Errorf("describing consumer groups: %w", err)
+	}
+
+	workers := c.config.ConsumerLagWorkers
+	if workers <= 0 {
+		workers = 4
+	}
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	for _, desc := range descriptions {
+		if desc.Err != sarama.ErrNoError {
+			log.Warn("ConsumerGroupLagCollector: group %s returned error %v", desc.GroupId, desc.Err)
+			continue
+		}
+
+		desc := desc
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			c.collectGroup(desc)
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// collectGroup describes one group's state/membership, emits its
+// per-group entity, then computes and emits per-topic lag for every topic
+// it has committed offsets on.
+func (c *ConsumerGroupLagCollector) collectGroup(desc *sarama.GroupDescription) {
+	description := describeGroup(desc)
+	c.emitGroupEntity(description)
+
+	offsets, err := c.admin.ListConsumerGroupOffsets(desc.GroupId, nil)
+	if err != nil {
+		log.Warn("ConsumerGroupLagCollector: failed to list offsets for group %s: %v", desc.GroupId, err)
+		return
+	}
+
+	for topic, partitions := range offsets.Blocks {
+		lag := c.computeTopicLag(desc.GroupId, topic, partitions)
+		c.emitGroupTopicEntity(description, lag)
+		c.aggregator.AddConsumerLag(topic, desc.GroupId, float64(lag.SumLag))
+	}
+}
+
+// describeGroup extracts state, assignment strategy, member count, and
+// rebalance status from a raw sarama.GroupDescription.
+func describeGroup(desc *sarama.GroupDescription) *ConsumerGroupDescription {
+	return &ConsumerGroupDescription{
+		GroupID:             desc.GroupId,
+		State:               desc.State,
+		AssignmentStrategy:  desc.Protocol,
+		MemberCount:         len(desc.Members),
+		RebalanceInProgress: desc.State == "PreparingRebalance" || desc.State == "CompletingRebalance",
+	}
+}
+
+// computeTopicLag sums and maxes per-partition lag for one (group, topic)
+// pair, using cached log-end offsets rather than issuing one GetOffset
+// call per partition per collection cycle.
+func (c *ConsumerGroupLagCollector) computeTopicLag(group, topic string, partitions map[int32]*sarama.OffsetFetchResponseBlock) *GroupTopicLag {
+	lag := &GroupTopicLag{Group: group, Topic: topic}
+
+	for partition, block := range partitions {
+		if block.Err != sarama.ErrNoError || block.Offset < 0 {
+			continue
+		}
+
+		logEndOffset, err := c.cachedLogEndOffset(topic, partition)
+		if err != nil {
+			log.Debug("ConsumerGroupLagCollector: failed to fetch log-end offset for %s[%d]: %v", topic, partition, err)
+			continue
+		}
+
+		partitionLag := logEndOffset - block.Offset
+		if partitionLag < 0 {
+			partitionLag = 0
+		}
+
+		lag.SumLag += partitionLag
+		if partitionLag > lag.MaxLag {
+			lag.MaxLag = partitionLag
+		}
+		lag.PartitionCount++
+	}
+
+	return lag
+}
+
+// cachedLogEndOffset returns topic/partition's log-end offset, reusing a
+// cached value younger than hwmCacheTTL instead of calling GetOffset again.
+func (c *ConsumerGroupLagCollector) cachedLogEndOffset(topic string, partition int32) (int64, error) {
+	key := topicPartitionKey(topic, partition)
+
+	c.hwmMu.Lock()
+	if entry, ok := c.hwmCache[key]; ok && time.Since(entry.fetchedAt) < hwmCacheTTL {
+		c.hwmMu.Unlock()
+		return entry.offset, nil
+	}
+	c.hwmMu.Unlock()
+
+	if c.client == nil {
+		return 0, fmt.Errorf("no sarama.Client configured, cannot fetch log-end offset")
+	}
+
+	offset, err := c.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, err
+	}
+
+	c.hwmMu.Lock()
+	c.hwmCache[key] = hwmCacheEntry{offset: offset, fetchedAt: time.Now()}
+	c.hwmMu.Unlock()
+
+	return offset, nil
+}
+
+// emitGroupEntity creates/updates the per-group AwsMskConsumerGroupSample
+// entity with state, assignment strategy, member count, and rebalance
+// status.
+func (c *ConsumerGroupLagCollector) emitGroupEntity(desc *ConsumerGroupDescription) {
+	if c.integration == nil {
+		return
+	}
+
+	entityName := fmt.Sprintf("%s-consumergroup-%s", c.config.ClusterName, desc.GroupID)
+	entity, err := c.integration.Entity(entityName, "aws-msk")
+	if err != nil {
+		log.Error("ConsumerGroupLagCollector: failed to create entity for group %s: %v", desc.GroupID, err)
+		return
+	}
+
+	guid := GenerateEntityGUID(EntityTypeConsumerGroup, c.config.AWSAccountID, c.config.ClusterName, desc.GroupID)
+
+	ms := entity.NewMetricSet("AwsMskConsumerGroupSample",
+		attribute.Attribute{Key: "entity.guid", Value: guid},
+		attribute.Attribute{Key: "entity.type", Value: string(EntityTypeConsumerGroup)},
+		attribute.Attribute{Key: "entityName", Value: entityName},
+		attribute.Attribute{Key: "provider.clusterName", Value: c.config.ClusterName},
+		attribute.Attribute{Key: "provider.accountId", Value: c.config.AWSAccountID},
+		attribute.Attribute{Key: "consumerGroup", Value: desc.GroupID},
+		attribute.Attribute{Key: "provider.groupState", Value: desc.State},
+		attribute.Attribute{Key: "provider.assignmentStrategy", Value: desc.AssignmentStrategy},
+	)
+
+	ms.SetMetric("provider.memberCount", float64(desc.MemberCount), metric.GAUGE)
+	ms.SetMetric("provider.rebalanceInProgress", boolToFloat(desc.RebalanceInProgress), metric.GAUGE)
+}
+
+// emitGroupTopicEntity creates/updates the per-group-topic
+// AwsMskConsumerGroupTopicSample entity with the computed lag rollup.
+func (c *ConsumerGroupLagCollector) emitGroupTopicEntity(desc *ConsumerGroupDescription, lag *GroupTopicLag) {
+	if c.integration == nil {
+		return
+	}
+
+	entityName := fmt.Sprintf("%s-consumergroup-%s-%s", c.config.ClusterName, desc.GroupID, lag.Topic)
+	entity, err := c.integration.Entity(entityName, "aws-msk")
+	if err != nil {
+		log.Error("ConsumerGroupLagCollector: failed to create group-topic entity for %s/%s: %v", desc.GroupID, lag.Topic, err)
+		return
+	}
+
+	guid := GenerateEntityGUID(EntityTypeConsumerGroup, c.config.AWSAccountID, c.config.ClusterName,
+		fmt.Sprintf("%s:%s", desc.GroupID, lag.Topic))
+
+	ms := entity.NewMetricSet("AwsMskConsumerGroupTopicSample",
+		attribute.Attribute{Key: "entity.guid", Value: guid},
+		attribute.Attribute{Key: "entity.type", Value: string(EntityTypeConsumerGroup)},
+		attribute.Attribute{Key: "entityName", Value: entityName},
+		attribute.Attribute{Key: "provider.clusterName", Value: c.config.ClusterName},
+		attribute.Attribute{Key: "provider.accountId", Value: c.config.AWSAccountID},
+		attribute.Attribute{Key: "consumerGroup", Value: desc.GroupID},
+		attribute.Attribute{Key: "topic", Value: lag.Topic},
+	)
+
+	ms.SetMetric("provider.sumLag", float64(lag.SumLag), metric.GAUGE)
+	ms.SetMetric("provider.maxLag", float64(lag.MaxLag), metric.GAUGE)
+	ms.SetMetric("provider.partitionCount", float64(lag.PartitionCount), metric.GAUGE)
+}
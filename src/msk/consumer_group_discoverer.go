@@ -0,0 +1,206 @@
+package msk
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// DescribedConsumerGroup is the authoritative shape of a consumer group as
+// reported by the controller via ListConsumerGroups/DescribeConsumerGroups,
+// as opposed to whatever a single JMX sample's "consumerGroup"/"consumer.lag"
+// fields happen to report.
+type DescribedConsumerGroup struct {
+	Group              string
+	State              ConsumerGroupState
+	MemberCount        int
+	AssignmentStrategy string
+	Topics             []string
+}
+
+// ConsumerGroupDiscoverer enumerates consumer groups and their committed and
+// log-end offsets via a Kafka admin connection, implementing
+// KafkaOffsetSource so LagCalculator can compute real per-partition lag
+// instead of relying on a single broker's self-reported consumer.lag - the
+// same ListConsumerGroups/ListConsumerGroupOffsets/ListOffsets combination
+// kafka-lag-exporter and confluent-kafka-go's admin API use.
+type ConsumerGroupDiscoverer struct {
+	client sarama.Client
+	admin  sarama.ClusterAdmin
+}
+
+// NewConsumerGroupDiscoverer wraps an already-connected sarama client/admin
+// pair, typically the same connection an AdminDiscoverer already holds.
+func NewConsumerGroupDiscoverer(client sarama.Client, admin sarama.ClusterAdmin) *ConsumerGroupDiscoverer {
+	return &ConsumerGroupDiscoverer{client: client, admin: admin}
+}
+
+// ListGroups returns every consumer group ID the cluster currently knows
+// about.
+func (d *ConsumerGroupDiscoverer) ListGroups() ([]string, error) {
+	groups, err := d.admin.ListConsumerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("listing consumer groups: %w", err)
+	}
+
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// DescribeGroup returns group's high-level state, member count and
+// assignment strategy.
+func (d *ConsumerGroupDiscoverer) DescribeGroup(group string) (*DescribedConsumerGroup, error) {
+	descriptions, err := d.admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return nil, fmt.Errorf("describing consumer group %s: %w", group, err)
+	}
+	if len(descriptions) == 0 {
+		return nil, fmt.Errorf("consumer group %s not found", group)
+	}
+
+	desc := descriptions[0]
+	topicSet := make(map[string]bool)
+	for _, member := range desc.Members {
+		assignment, err := member.GetMemberAssignment()
+		if err != nil {
+			continue
+		}
+		for topic := range assignment.Topics {
+			topicSet[topic] = true
+		}
+	}
+	topics := make([]string, 0, len(topicSet))
+	for topic := range topicSet {
+		topics = append(topics, topic)
+	}
+
+	return &DescribedConsumerGroup{
+		Group:              group,
+		State:              ConsumerGroupState(desc.State),
+		MemberCount:        len(desc.Members),
+		AssignmentStrategy: desc.Protocol,
+		Topics:             topics,
+	}, nil
+}
+
+// ListCommittedOffsets implements KafkaOffsetSource.
+func (d *ConsumerGroupDiscoverer) ListCommittedOffsets(group, topic string) (map[int32]int64, error) {
+	partitions, err := d.client.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("listing partitions for topic %s: %w", topic, err)
+	}
+
+	response, err := d.admin.ListConsumerGroupOffsets(group, map[string][]int32{topic: partitions})
+	if err != nil {
+		return nil, fmt.Errorf("listing committed offsets for group %s topic %s: %w", group, topic, err)
+	}
+
+	result := make(map[int32]int64)
+	for partition, block := range response.Blocks[topic] {
+		if block != nil && block.Offset >= 0 {
+			result[partition] = block.Offset
+		}
+	}
+	return result, nil
+}
+
+// DescribePartitionOffsets implements KafkaOffsetSource.
+func (d *ConsumerGroupDiscoverer) DescribePartitionOffsets(topic string) (map[int32]PartitionOffsets, error) {
+	partitions, err := d.client.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("listing partitions for topic %s: %w", topic, err)
+	}
+
+	result := make(map[int32]PartitionOffsets, len(partitions))
+	for _, partition := range partitions {
+		logStart, err := d.client.GetOffset(topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return nil, fmt.Errorf("getting log-start offset for %s[%d]: %w", topic, partition, err)
+		}
+		highWaterMark, err := d.client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, fmt.Errorf("getting high-water-mark offset for %s[%d]: %w", topic, partition, err)
+		}
+		result[partition] = PartitionOffsets{LogStartOffset: logStart, HighWaterMark: highWaterMark}
+	}
+	return result, nil
+}
+
+// OffsetForTimestamp implements KafkaOffsetSource.
+func (d *ConsumerGroupDiscoverer) OffsetForTimestamp(topic string, partition int32, timestampMillis int64) (int64, error) {
+	offset, err := d.client.GetOffset(topic, partition, timestampMillis)
+	if err != nil {
+		return 0, fmt.Errorf("getting offset for timestamp on %s[%d]: %w", topic, partition, err)
+	}
+	if offset < 0 {
+		return 0, ErrOffsetOutOfRange
+	}
+	return offset, nil
+}
+
+// GroupState implements KafkaOffsetSource.
+func (d *ConsumerGroupDiscoverer) GroupState(group string) (ConsumerGroupState, error) {
+	described, err := d.DescribeGroup(group)
+	if err != nil {
+		return "", err
+	}
+	return described.State, nil
+}
+
+// LatestOffset implements RecordTimestampSource.
+func (d *ConsumerGroupDiscoverer) LatestOffset(topic string, partition int32) (int64, error) {
+	offset, err := d.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, fmt.Errorf("getting high-water-mark offset for %s[%d]: %w", topic, partition, err)
+	}
+	return offset, nil
+}
+
+// CommittedOffset implements RecordTimestampSource.
+func (d *ConsumerGroupDiscoverer) CommittedOffset(group, topic string, partition int32) (int64, error) {
+	response, err := d.admin.ListConsumerGroupOffsets(group, map[string][]int32{topic: {partition}})
+	if err != nil {
+		return 0, fmt.Errorf("getting committed offset for group %s topic %s[%d]: %w", group, topic, partition, err)
+	}
+	block, ok := response.Blocks[topic][partition]
+	if !ok || block == nil || block.Offset < 0 {
+		return 0, fmt.Errorf("no committed offset for group %s topic %s[%d]", group, topic, partition)
+	}
+	return block.Offset, nil
+}
+
+// RecordTimestamp implements RecordTimestampSource, fetching the single
+// record at offset via a short-lived PartitionConsumer. ok is false when
+// the record carries no timestamp (broker message format predates 0.10).
+func (d *ConsumerGroupDiscoverer) RecordTimestamp(topic string, partition int32, offset int64) (int64, bool, error) {
+	consumer, err := sarama.NewConsumerFromClient(d.client)
+	if err != nil {
+		return 0, false, fmt.Errorf("creating consumer for %s[%d]: %w", topic, partition, err)
+	}
+	defer consumer.Close()
+
+	partitionConsumer, err := consumer.ConsumePartition(topic, partition, offset)
+	if err != nil {
+		return 0, false, fmt.Errorf("consuming %s[%d] at offset %d: %w", topic, partition, offset, err)
+	}
+	defer partitionConsumer.Close()
+
+	select {
+	case msg, ok := <-partitionConsumer.Messages():
+		if !ok {
+			return 0, false, fmt.Errorf("consumer closed before reading %s[%d] offset %d", topic, partition, offset)
+		}
+		if msg.Timestamp.IsZero() {
+			return 0, false, nil
+		}
+		return msg.Timestamp.UnixMilli(), true, nil
+	case err := <-partitionConsumer.Errors():
+		return 0, false, fmt.Errorf("reading record at %s[%d] offset %d: %w", topic, partition, offset, err)
+	case <-time.After(5 * time.Second):
+		return 0, false, fmt.Errorf("timed out reading record at %s[%d] offset %d", topic, partition, offset)
+	}
+}
@@ -0,0 +1,247 @@
+package msk
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// ConsumerGroupCollectorConfig bounds which groups and topics
+// ConsumerGroupCollector scrapes, so a cluster with thousands of groups
+// or topics doesn't explode per-partition cardinality by default. A nil
+// regexp means "no filter" for that dimension.
+type ConsumerGroupCollectorConfig struct {
+	IncludeGroups *regexp.Regexp
+	ExcludeGroups *regexp.Regexp
+	IncludeTopics *regexp.Regexp
+	ExcludeTopics *regexp.Regexp
+}
+
+func (cfg ConsumerGroupCollectorConfig) groupIncluded(name string) bool {
+	return passesFilter(cfg.IncludeGroups, cfg.ExcludeGroups, name)
+}
+
+func (cfg ConsumerGroupCollectorConfig) topicIncluded(name string) bool {
+	return passesFilter(cfg.IncludeTopics, cfg.ExcludeTopics, name)
+}
+
+func passesFilter(include, exclude *regexp.Regexp, name string) bool {
+	if include != nil && !include.MatchString(name) {
+		return false
+	}
+	if exclude != nil && exclude.MatchString(name) {
+		return false
+	}
+	return true
+}
+
+// PartitionLagSample is one (group, topic, partition)'s computed lag plus
+// the Loki-promtail-style meta-labels TransformConsumerGroupMetrics
+// attaches to its KafkaOffsetSample: clientId/memberHost identify which
+// consumer instance owns the partition, state is the group's rebalance
+// state at scrape time.
+type PartitionLagSample struct {
+	Group           string
+	Topic           string
+	Partition       int32
+	ClientID        string
+	MemberHost      string
+	State           string
+	LogEndOffset    int64
+	CommittedOffset int64
+	Lag             int64
+}
+
+// memberIdentity is the (clientId, clientHost) pair a group member's
+// assignment resolves to, for attributing a partition's lag to whichever
+// consumer instance currently owns it.
+type memberIdentity struct {
+	clientID string
+	host     string
+}
+
+// ConsumerGroupCollector scrapes per-partition consumer lag directly off
+// the Kafka admin/client API already used elsewhere in this package
+// (ListConsumerGroups+DescribeConsumerGroups for group state and
+// membership, ListConsumerGroupOffsets for committed offsets, and
+// client.GetOffset(..., OffsetNewest) for log-end offsets), the same
+// calls ConsumerGroupLagCollector makes, but kept at partition
+// granularity instead of rolling straight up to a per-(group,topic) sum,
+// and returned as plain PartitionLagSample values rather than being
+// wired directly to the AwsMsk* entity pipeline.
+type ConsumerGroupCollector struct {
+	admin  sarama.ClusterAdmin
+	client sarama.Client
+	config ConsumerGroupCollectorConfig
+}
+
+// NewConsumerGroupCollector wraps an already-connected admin client and
+// sarama.Client (needed for log-end offset lookups).
+func NewConsumerGroupCollector(admin sarama.ClusterAdmin, client sarama.Client, config ConsumerGroupCollectorConfig) *ConsumerGroupCollector {
+	return &ConsumerGroupCollector{admin: admin, client: client, config: config}
+}
+
+// Collect enumerates every consumer group and returns one
+// PartitionLagSample per (group, topic, partition) that survives the
+// configured include/exclude filters.
+func (c *ConsumerGroupCollector) Collect() ([]PartitionLagSample, error) {
+	groups, err := c.admin.ListConsumerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("listing consumer groups: %w", err)
+	}
+
+	var groupNames []string
+	for name := range groups {
+		if c.config.groupIncluded(name) {
+			groupNames = append(groupNames, name)
+		}
+	}
+	if len(groupNames) == 0 {
+		return nil, nil
+	}
+
+	descriptions, err := c.admin.DescribeConsumerGroups(groupNames)
+	if err != nil {
+		return nil, fmt.Errorf("describing consumer groups: %w", err)
+	}
+
+	var samples []PartitionLagSample
+	for _, desc := range descriptions {
+		if desc.Err != sarama.ErrNoError {
+			log.Warn("ConsumerGroupCollector: group %s returned error %v", desc.GroupId, desc.Err)
+			continue
+		}
+		samples = append(samples, c.collectGroup(desc)...)
+	}
+	return samples, nil
+}
+
+// collectGroup resolves one group's per-partition committed offsets,
+// member assignments, and log-end offsets into PartitionLagSamples.
+func (c *ConsumerGroupCollector) collectGroup(desc *sarama.GroupDescription) []PartitionLagSample {
+	assignments := memberAssignments(desc)
+
+	offsets, err := c.admin.ListConsumerGroupOffsets(desc.GroupId, nil)
+	if err != nil {
+		log.Warn("ConsumerGroupCollector: failed to fetch offsets for group %s: %v", desc.GroupId, err)
+		return nil
+	}
+
+	var samples []PartitionLagSample
+	for topic, partitions := range offsets.Blocks {
+		if !c.config.topicIncluded(topic) {
+			continue
+		}
+		for partition, block := range partitions {
+			if block.Err != sarama.ErrNoError || block.Offset < 0 {
+				continue
+			}
+
+			logEndOffset, err := c.logEndOffset(topic, partition)
+			if err != nil {
+				log.Debug("ConsumerGroupCollector: failed to fetch log-end offset for %s[%d]: %v", topic, partition, err)
+				continue
+			}
+
+			lag := logEndOffset - block.Offset
+			if lag < 0 {
+				lag = 0
+			}
+
+			member := assignments[topicPartitionKey(topic, partition)]
+			samples = append(samples, PartitionLagSample{
+				Group:           desc.GroupId,
+				Topic:           topic,
+				Partition:       partition,
+				ClientID:        member.clientID,
+				MemberHost:      member.host,
+				State:           desc.State,
+				LogEndOffset:    logEndOffset,
+				CommittedOffset: block.Offset,
+				Lag:             lag,
+			})
+		}
+	}
+	return samples
+}
+
+// logEndOffset fetches topic/partition's current log-end (high-water-mark)
+// offset via ListOffsets(latest).
+func (c *ConsumerGroupCollector) logEndOffset(topic string, partition int32) (int64, error) {
+	if c.client == nil {
+		return 0, fmt.Errorf("no sarama.Client configured, cannot fetch log-end offset")
+	}
+	return c.client.GetOffset(topic, partition, sarama.OffsetNewest)
+}
+
+// memberAssignments maps every (topic, partition) a group's members own
+// back to that member's clientId/clientHost, the same identity Loki
+// promtail's Kafka service discovery attaches as meta-labels.
+func memberAssignments(desc *sarama.GroupDescription) map[string]memberIdentity {
+	assignments := make(map[string]memberIdentity)
+	for _, member := range desc.Members {
+		assignment, err := member.GetMemberAssignment()
+		if err != nil || assignment == nil {
+			continue
+		}
+		for topic, partitions := range assignment.Topics {
+			for _, partition := range partitions {
+				assignments[topicPartitionKey(topic, partition)] = memberIdentity{
+					clientID: member.ClientId,
+					host:     member.ClientHost,
+				}
+			}
+		}
+	}
+	return assignments
+}
+
+// TransformConsumerGroupMetrics emits one KafkaOffsetSample per
+// PartitionLagSample onto outputSet, attaching the Loki-promtail-style
+// meta-label surface (consumerGroup/topic/partition/clientId/memberHost/
+// state) plus the raw offsets and lag, then rolls every sample in
+// samples up into per-group consumer.totalLag/consumer.maxLag and
+// cluster-wide aws.msk.SumOffsetLag/aws.msk.MaxOffsetLag for MSK
+// dashboard parity.
+func (t *TransformerFixed) TransformConsumerGroupMetrics(samples []PartitionLagSample, outputSet *metric.Set) error {
+	groupTotals := make(map[string]int64)
+	groupMax := make(map[string]int64)
+	var clusterSum, clusterMax int64
+
+	for _, s := range samples {
+		outputSet.SetMetric("consumerGroup", s.Group, metric.ATTRIBUTE)
+		outputSet.SetMetric("topic", s.Topic, metric.ATTRIBUTE)
+		outputSet.SetMetric("partition", fmt.Sprintf("%d", s.Partition), metric.ATTRIBUTE)
+		outputSet.SetMetric("clientId", s.ClientID, metric.ATTRIBUTE)
+		outputSet.SetMetric("memberHost", s.MemberHost, metric.ATTRIBUTE)
+		outputSet.SetMetric("state", s.State, metric.ATTRIBUTE)
+		outputSet.SetMetric("provider.clusterName", t.clusterName, metric.ATTRIBUTE)
+
+		outputSet.SetMetric("consumer.logEndOffset", float64(s.LogEndOffset), metric.GAUGE)
+		outputSet.SetMetric("consumer.committedOffset", float64(s.CommittedOffset), metric.GAUGE)
+		outputSet.SetMetric("consumer.lag", float64(s.Lag), metric.GAUGE)
+
+		groupTotals[s.Group] += s.Lag
+		if s.Lag > groupMax[s.Group] {
+			groupMax[s.Group] = s.Lag
+		}
+		clusterSum += s.Lag
+		if s.Lag > clusterMax {
+			clusterMax = s.Lag
+		}
+	}
+
+	for group, total := range groupTotals {
+		outputSet.SetMetric(fmt.Sprintf("consumer.totalLag.%s", group), float64(total), metric.GAUGE)
+		outputSet.SetMetric(fmt.Sprintf("consumer.maxLag.%s", group), float64(groupMax[group]), metric.GAUGE)
+	}
+
+	outputSet.SetMetric("aws.msk.SumOffsetLag", float64(clusterSum), metric.GAUGE)
+	outputSet.SetMetric("aws.msk.MaxOffsetLag", float64(clusterMax), metric.GAUGE)
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package msk
+
+import (
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// ConsumerGroupMemberSnapshot is one group member's decoded
+// ConsumerGroupMemberAssignment: which topics/partitions the coordinator
+// assigned it, plus the assignment protocol version and how much opaque
+// user data it carries.
+type ConsumerGroupMemberSnapshot struct {
+	MemberID          string
+	ClientID          string
+	ClientHost        string
+	AssignmentVersion int16
+	Assignment        map[string][]int32
+	UserDataLen       int
+}
+
+// ConsumerGroupStateSnapshot is one DescribeGroups snapshot:
+// Stable/PreparingRebalance/CompletingRebalance/Empty/Dead state, member
+// count and per-member assignment, and which broker is acting as this
+// group's coordinator.
+type ConsumerGroupStateSnapshot struct {
+	GroupID             string
+	State               string
+	ProtocolType        string
+	CoordinatorBrokerID int32
+	MemberCount         int
+	Members             []ConsumerGroupMemberSnapshot
+}
+
+// ConsumerGroupStateCollector periodically snapshots every consumer
+// group's state and membership via sarama's ListConsumerGroups/
+// DescribeConsumerGroups admin APIs and Client.Coordinator, feeding the
+// result through DimensionalTransformer.TransformConsumerGroupState --
+// the parallel-to-offsets path this subsystem adds alongside
+// ConsumerGroupLagCollector/SaramaConsumerCollector's lag-only view.
+type ConsumerGroupStateCollector struct {
+	admin  sarama.ClusterAdmin
+	client sarama.Client
+
+	dimensionalTransformer *DimensionalTransformer
+}
+
+// NewConsumerGroupStateCollector wraps an already-connected
+// sarama.ClusterAdmin (for ListConsumerGroups/DescribeConsumerGroups) and
+// sarama.Client (for resolving each group's coordinator broker id),
+// feeding snapshots through dt.
+func NewConsumerGroupStateCollector(admin sarama.ClusterAdmin, client sarama.Client, dt *DimensionalTransformer) *ConsumerGroupStateCollector {
+	return &ConsumerGroupStateCollector{
+		admin:                  admin,
+		client:                 client,
+		dimensionalTransformer: dt,
+	}
+}
+
+// Collect snapshots every consumer group's state/membership and emits
+// each one through TransformConsumerGroupState.
+func (c *ConsumerGroupStateCollector) Collect() error {
+	groups, err := c.admin.ListConsumerGroups()
+	if err != nil {
+		return fmt.Errorf("listing consumer groups: %w", err)
+	}
+
+	groupNames := make([]string, 0, len(groups))
+	for name := range groups {
+		groupNames = append(groupNames, name)
+	}
+	if len(groupNames) == 0 {
+		return nil
+	}
+
+	descriptions, err := c.admin.DescribeConsumerGroups(groupNames)
+	if err != nil {
+		return fmt.Errorf("describing consumer groups: %w", err)
+	}
+
+	for _, desc := range descriptions {
+		if desc.Err != sarama.ErrNoError {
+			log.Warn("ConsumerGroupStateCollector: group %s returned error %v", desc.GroupId, desc.Err)
+			continue
+		}
+		snapshot := c.buildSnapshot(desc)
+		if c.dimensionalTransformer != nil {
+			if err := c.dimensionalTransformer.TransformConsumerGroupState(snapshot); err != nil {
+				log.Warn("ConsumerGroupStateCollector: failed to transform state for group %s: %v", desc.GroupId, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildSnapshot decodes desc's members' assignment bytes and resolves its
+// coordinator broker id, falling back to an empty assignment for a member
+// whose bytes fail to decode and to -1 when the coordinator lookup fails,
+// rather than dropping the whole snapshot.
+func (c *ConsumerGroupStateCollector) buildSnapshot(desc *sarama.GroupDescription) *ConsumerGroupStateSnapshot {
+	snapshot := &ConsumerGroupStateSnapshot{
+		GroupID:             desc.GroupId,
+		State:               desc.State,
+		ProtocolType:        desc.ProtocolType,
+		CoordinatorBrokerID: c.coordinatorBrokerID(desc.GroupId),
+		MemberCount:         len(desc.Members),
+	}
+
+	for _, member := range desc.Members {
+		snapshot.Members = append(snapshot.Members, c.decodeMember(member))
+	}
+
+	return snapshot
+}
+
+// decodeMember decodes one GroupMemberDescription's MemberAssignment
+// bytes into the topics/partitions the coordinator assigned it. Falls
+// back to an empty assignment when the member's protocol isn't the
+// standard consumer assignment format this repo understands.
+func (c *ConsumerGroupStateCollector) decodeMember(member *sarama.GroupMemberDescription) ConsumerGroupMemberSnapshot {
+	snapshot := ConsumerGroupMemberSnapshot{
+		MemberID:   member.MemberId,
+		ClientID:   member.ClientId,
+		ClientHost: member.ClientHost,
+	}
+
+	assignment, err := member.GetMemberAssignment()
+	if err != nil {
+		log.Debug("ConsumerGroupStateCollector: failed to decode assignment for member %s: %v", member.MemberId, err)
+		return snapshot
+	}
+
+	snapshot.AssignmentVersion = assignment.Version
+	snapshot.Assignment = assignment.Topics
+	snapshot.UserDataLen = len(assignment.UserData)
+
+	return snapshot
+}
+
+// coordinatorBrokerID resolves groupID's coordinator broker id via
+// Client.Coordinator, returning -1 if the client isn't wired in or the
+// lookup fails.
+func (c *ConsumerGroupStateCollector) coordinatorBrokerID(groupID string) int32 {
+	if c.client == nil {
+		return -1
+	}
+	broker, err := c.client.Coordinator(groupID)
+	if err != nil {
+		log.Debug("ConsumerGroupStateCollector: failed to resolve coordinator for group %s: %v", groupID, err)
+		return -1
+	}
+	return broker.ID()
+}
@@ -0,0 +1,139 @@
+package msk
+
+import (
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// defaultLagRateWindow is how far back ConsumerRateTracker looks when
+// estimating consumption/production rate, matching the Prometheus
+// rate()-style windowing convention of a handful of scrape intervals.
+const defaultLagRateWindow = 5 * time.Minute
+
+// minRateSamples is the fewest (timestamp, offset) pairs a window needs
+// before LagSeconds will trust the regression enough to return a value.
+const minRateSamples = 2
+
+// offsetSample is one (timestamp, committed offset, high-water-mark)
+// observation for a consumer group/topic pair.
+type offsetSample struct {
+	timestamp     time.Time
+	offset        int64
+	highWaterMark int64
+}
+
+// ConsumerRateTracker keeps a rolling window of offset samples per
+// ConsumerGroupKey and derives a lag-in-seconds estimate from them: the
+// consumption rate (how fast the group's committed offset advances) and
+// the producer rate (how fast the partition's high-water-mark advances)
+// are both fit by linear regression over the window, and the lag is
+// divided by whichever rate is faster, since a consumer that is keeping
+// up despite a slow producer shouldn't report an ever-growing time lag.
+type ConsumerRateTracker struct {
+	mu      sync.Mutex
+	window  time.Duration
+	samples map[ConsumerGroupKey][]offsetSample
+}
+
+// NewConsumerRateTracker creates a tracker using window as the rolling
+// lookback. A window <= 0 falls back to defaultLagRateWindow.
+func NewConsumerRateTracker(window time.Duration) *ConsumerRateTracker {
+	if window <= 0 {
+		window = defaultLagRateWindow
+	}
+	return &ConsumerRateTracker{
+		window:  window,
+		samples: make(map[ConsumerGroupKey][]offsetSample),
+	}
+}
+
+// Observe records a new (offset, high-water-mark) sample for key at the
+// current time, evicting samples that have fallen outside the window.
+func (t *ConsumerRateTracker) Observe(key ConsumerGroupKey, offset, highWaterMark int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	samples := append(t.samples[key], offsetSample{
+		timestamp:     now,
+		offset:        offset,
+		highWaterMark: highWaterMark,
+	})
+
+	cutoff := now.Add(-t.window)
+	trimmed := samples[:0]
+	for _, s := range samples {
+		if s.timestamp.After(cutoff) {
+			trimmed = append(trimmed, s)
+		}
+	}
+	t.samples[key] = trimmed
+}
+
+// LagSeconds estimates how many seconds behind the producer key's
+// consumer group currently is: lag (highWaterMark - offset, from the most
+// recent sample) divided by the faster of the consumption rate and the
+// producer rate, both fit over the tracked window. It reports ok=false,
+// logging a warning, when fewer than minRateSamples have been observed
+// for key yet.
+func (t *ConsumerRateTracker) LagSeconds(key ConsumerGroupKey) (float64, bool) {
+	t.mu.Lock()
+	samples := append([]offsetSample(nil), t.samples[key]...)
+	t.mu.Unlock()
+
+	if len(samples) < minRateSamples {
+		log.Warn("ConsumerRateTracker: insufficient samples (%d) for group=%s topic=%s to estimate lag seconds", len(samples), key.GroupID, key.Topic)
+		return 0, false
+	}
+
+	consumptionRate := linearRegressionSlope(samples, func(s offsetSample) float64 { return float64(s.offset) })
+	producerRate := linearRegressionSlope(samples, func(s offsetSample) float64 { return float64(s.highWaterMark) })
+
+	rate := consumptionRate
+	if producerRate > rate {
+		rate = producerRate
+	}
+	if rate <= 0 {
+		log.Warn("ConsumerRateTracker: non-positive rate for group=%s topic=%s, cannot estimate lag seconds", key.GroupID, key.Topic)
+		return 0, false
+	}
+
+	latest := samples[len(samples)-1]
+	lag := float64(latest.highWaterMark - latest.offset)
+	if lag < 0 {
+		lag = 0
+	}
+
+	return lag / rate, true
+}
+
+// linearRegressionSlope fits a simple least-squares line to (elapsed
+// seconds since the first sample, value(sample)) pairs and returns its
+// slope, i.e. the value's rate of change per second over the window.
+func linearRegressionSlope(samples []offsetSample, value func(offsetSample) float64) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+
+	t0 := samples[0].timestamp
+	var sumX, sumY, sumXY, sumXX float64
+	n := float64(len(samples))
+
+	for _, s := range samples {
+		x := s.timestamp.Sub(t0).Seconds()
+		y := value(s)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+
+	return (n*sumXY - sumX*sumY) / denominator
+}
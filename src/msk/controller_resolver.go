@@ -0,0 +1,95 @@
+package msk
+
+import (
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+const (
+	// controllerResolverCacheTTL bounds how often ControllerID issues a
+	// fresh DescribeCluster call -- every broker/cluster transform in a
+	// poll cycle asks, and they should all see the same cached answer
+	// instead of each triggering their own round-trip to the controller.
+	controllerResolverCacheTTL = 30 * time.Second
+
+	// controllerResolverFailureLimit is how many consecutive DescribeCluster
+	// failures trip the circuit breaker.
+	controllerResolverFailureLimit = 3
+
+	// controllerResolverCooldown is how long the breaker stays open once
+	// tripped before ControllerID tries DescribeCluster again.
+	controllerResolverCooldown = time.Minute
+)
+
+// ControllerResolver discovers the cluster's authoritative controller
+// broker ID via the Kafka AdminClient's DescribeCluster call (API key 60,
+// with sarama itself falling back to the Metadata API on brokers too old
+// to support it) instead of trusting whatever
+// controller.activeControllerCount value a JMX or CloudWatch source
+// happens to report per broker. The result is cached for
+// controllerResolverCacheTTL, and a circuit breaker opens after
+// controllerResolverFailureLimit consecutive failures so an unreachable
+// cluster can't stall the metric pipeline -- ControllerID just keeps
+// returning the last known-good ID until the cooldown elapses.
+type ControllerResolver struct {
+	admin *AdminAPIHelper
+
+	mu              sync.Mutex
+	controllerID    int32
+	fetchedAt       time.Time
+	consecutiveFail int
+	openUntil       time.Time
+}
+
+// NewControllerResolver creates a ControllerResolver backed by admin. The
+// resolver has no opinion of its own on the controller until the first
+// successful DescribeCluster call, so ControllerID/IsController report -1
+// / false until then.
+func NewControllerResolver(admin *AdminAPIHelper) *ControllerResolver {
+	return &ControllerResolver{
+		admin:        admin,
+		controllerID: -1,
+	}
+}
+
+// ControllerID returns the cluster's current controller broker ID,
+// refreshing via DescribeCluster if the cached value is older than
+// controllerResolverCacheTTL. If the circuit breaker is open, or the
+// refresh itself fails, it returns the last known-good ID instead of
+// blocking or propagating the error.
+func (r *ControllerResolver) ControllerID() int32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.fetchedAt) < controllerResolverCacheTTL {
+		return r.controllerID
+	}
+	if !r.openUntil.IsZero() && time.Now().Before(r.openUntil) {
+		return r.controllerID
+	}
+
+	description, err := r.admin.DescribeCluster()
+	r.fetchedAt = time.Now()
+	if err != nil {
+		r.consecutiveFail++
+		log.Warn("ControllerResolver: DescribeCluster failed (%d consecutive): %v", r.consecutiveFail, err)
+		if r.consecutiveFail >= controllerResolverFailureLimit {
+			r.openUntil = time.Now().Add(controllerResolverCooldown)
+			log.Warn("ControllerResolver: circuit open for %s after %d consecutive failures", controllerResolverCooldown, r.consecutiveFail)
+		}
+		return r.controllerID
+	}
+
+	r.consecutiveFail = 0
+	r.openUntil = time.Time{}
+	r.controllerID = description.ControllerID
+	return r.controllerID
+}
+
+// IsController reports whether brokerID matches the currently resolved
+// controller ID.
+func (r *ControllerResolver) IsController(brokerID int32) bool {
+	return r.ControllerID() == brokerID
+}
@@ -0,0 +1,102 @@
+package msk
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// ControllerClient is the minimal surface ControllerRouter needs to find
+// and connect to whichever broker currently holds the controller role.
+type ControllerClient interface {
+	// CurrentControllerID asks any broker in the cluster for the ID of
+	// the active controller.
+	CurrentControllerID() (int32, error)
+	// DialBroker opens (or returns a cached) connection to brokerID.
+	DialBroker(brokerID int32) (interface{}, error)
+}
+
+// ControllerRouter tracks which broker is the active controller and routes
+// controller-only admin requests (e.g. ListPartitionReassignments) to it,
+// re-resolving and reconnecting automatically when a failover is detected.
+type ControllerRouter struct {
+	client ControllerClient
+
+	mu               sync.RWMutex
+	currentController int32
+	currentConn        interface{}
+}
+
+// NewControllerRouter creates a router with no controller resolved yet;
+// the first call to Conn triggers resolution.
+func NewControllerRouter(client ControllerClient) *ControllerRouter {
+	return &ControllerRouter{client: client, currentController: -1}
+}
+
+// Conn returns a connection to the current controller, resolving and
+// dialing it the first time it's called or after a failover invalidates
+// the cached connection.
+func (r *ControllerRouter) Conn() (interface{}, error) {
+	r.mu.RLock()
+	if r.currentConn != nil {
+		conn := r.currentConn
+		r.mu.RUnlock()
+		return conn, nil
+	}
+	r.mu.RUnlock()
+
+	return r.resolve()
+}
+
+// resolve asks the cluster for the current controller and dials it.
+func (r *ControllerRouter) resolve() (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	controllerID, err := r.client.CurrentControllerID()
+	if err != nil {
+		return nil, fmt.Errorf("resolving active controller: %w", err)
+	}
+
+	conn, err := r.client.DialBroker(controllerID)
+	if err != nil {
+		return nil, fmt.Errorf("dialing controller broker %d: %w", controllerID, err)
+	}
+
+	r.currentController = controllerID
+	r.currentConn = conn
+	return conn, nil
+}
+
+// CheckFailover re-resolves the active controller and, if it has changed
+// since the last check, drops the cached connection so the next Conn call
+// reconnects to the new controller. It returns the new controller ID and
+// whether a failover was observed.
+func (r *ControllerRouter) CheckFailover() (int32, bool, error) {
+	newControllerID, err := r.client.CurrentControllerID()
+	if err != nil {
+		return -1, false, fmt.Errorf("checking for controller failover: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.currentController != -1 && newControllerID != r.currentController {
+		log.Info("Controller failover detected: broker %d -> broker %d", r.currentController, newControllerID)
+		r.currentConn = nil
+		r.currentController = newControllerID
+		return newControllerID, true, nil
+	}
+
+	r.currentController = newControllerID
+	return newControllerID, false, nil
+}
+
+// CurrentControllerID returns the last-resolved controller broker ID, or -1
+// if none has been resolved yet.
+func (r *ControllerRouter) CurrentControllerID() int32 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.currentController
+}
@@ -0,0 +1,63 @@
+package datastreams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCheckpoint_SameInputsProduceSameCheckpoint(t *testing.T) {
+	a := NewCheckpoint("orders-service", "orders", "orders-consumer")
+	b := NewCheckpoint("orders-service", "orders", "orders-consumer")
+	assert.Equal(t, a, b)
+}
+
+func TestNewCheckpoint_DifferentInputsProduceDifferentCheckpoints(t *testing.T) {
+	a := NewCheckpoint("orders-service", "orders", "orders-consumer")
+	b := NewCheckpoint("orders-service", "orders", "billing-consumer")
+	assert.NotEqual(t, a, b)
+}
+
+func TestNewCheckpoint_EmptyProducerServiceIsAllowed(t *testing.T) {
+	cp := NewCheckpoint("", "orders", "orders-consumer")
+	assert.Len(t, string(cp), 16)
+}
+
+func TestBacklog_CommittedOffsetBehindHighWaterMark_ReturnsDifference(t *testing.T) {
+	tr := NewTracker()
+	tp := TopicPartition{Topic: "orders", Partition: 0}
+
+	backlog := tr.Backlog(tp, 90, 100)
+
+	assert.Equal(t, int64(10), backlog)
+}
+
+func TestBacklog_StaleHighWaterMark_UsesHighestEverSeen(t *testing.T) {
+	tr := NewTracker()
+	tp := TopicPartition{Topic: "orders", Partition: 0}
+
+	tr.Backlog(tp, 50, 100)
+	backlog := tr.Backlog(tp, 50, 80) // a later poll observes a lower "latest" HWM
+
+	assert.Equal(t, int64(50), backlog, "should still be measured against the highest HWM ever observed, not the stale one")
+}
+
+func TestBacklog_CommittedAheadOfHighWaterMark_ClampsToZero(t *testing.T) {
+	tr := NewTracker()
+	tp := TopicPartition{Topic: "orders", Partition: 0}
+
+	backlog := tr.Backlog(tp, 110, 100)
+
+	assert.Equal(t, int64(0), backlog)
+}
+
+func TestBacklog_TracksDistinctPartitionsIndependently(t *testing.T) {
+	tr := NewTracker()
+	tp0 := TopicPartition{Topic: "orders", Partition: 0}
+	tp1 := TopicPartition{Topic: "orders", Partition: 1}
+
+	tr.Backlog(tp0, 0, 500)
+	backlog1 := tr.Backlog(tp1, 10, 20)
+
+	assert.Equal(t, int64(10), backlog1)
+}
@@ -0,0 +1,78 @@
+package msk
+
+import (
+	"sync"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+)
+
+// CounterSemantics selects how a raw JMX counter value should be emitted:
+// as-is (the current behavior, forcing everything to GAUGE) or as the
+// delta since the last observation (metric.DELTA), which is what a
+// genuinely monotonic counter (e.g. total bytes produced since broker
+// start) should use so downstream rate() queries aren't computed twice.
+type CounterSemantics int
+
+const (
+	// SemanticsGauge reports the raw value unchanged, the prior behavior.
+	SemanticsGauge CounterSemantics = iota
+	// SemanticsCumulativeCounter reports the delta since the previous
+	// observation using metric.DELTA, resetting the baseline whenever the
+	// value decreases (e.g. the broker restarted and its JMX counter
+	// reset to zero).
+	SemanticsCumulativeCounter
+)
+
+// DeltaCounter tracks the previous value of every cumulative counter it
+// sees, so repeated polls can be converted into metric.DELTA instead of
+// forcing every value to metric.GAUGE regardless of its real semantics.
+type DeltaCounter struct {
+	mu       sync.Mutex
+	previous map[string]float64
+}
+
+// NewDeltaCounter creates an empty tracker.
+func NewDeltaCounter() *DeltaCounter {
+	return &DeltaCounter{previous: make(map[string]float64)}
+}
+
+// Delta returns the increase in value for key since the last call, or the
+// raw value itself on the first observation. If value is lower than the
+// previous observation (a counter reset), it returns value as the delta
+// and re-baselines from zero.
+func (d *DeltaCounter) Delta(key string, value float64) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	prev, ok := d.previous[key]
+	d.previous[key] = value
+
+	if !ok {
+		return value
+	}
+	if value < prev {
+		// Counter reset (broker restart); treat the new value as the
+		// delta rather than going negative.
+		return value
+	}
+	return value - prev
+}
+
+// SourceTypeFor returns the infra-SDK source type to use when emitting a
+// metric under the given semantics.
+func SourceTypeFor(semantics CounterSemantics) metric.SourceType {
+	if semantics == SemanticsCumulativeCounter {
+		return metric.DELTA
+	}
+	return metric.GAUGE
+}
+
+// ApplySemantics returns the value to emit and the source type to tag it
+// with, applying delta conversion when semantics is
+// SemanticsCumulativeCounter.
+func (d *DeltaCounter) ApplySemantics(key string, value float64, semantics CounterSemantics) (float64, metric.SourceType) {
+	if semantics == SemanticsCumulativeCounter {
+		return d.Delta(key, value), metric.DELTA
+	}
+	return value, metric.GAUGE
+}
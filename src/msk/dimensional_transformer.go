@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,6 +15,8 @@ import (
 
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-kafka/src/msk/datastreams"
 )
 
 const (
@@ -29,16 +32,30 @@ const (
 
 // DimensionalTransformer sends Kafka metrics as dimensional metrics to New Relic
 type DimensionalTransformer struct {
-	integration      *integration.Integration
-	config           *Config
-	metricClient     *MetricAPIClient
-	batchCollector   *BatchCollector
-	enabled          bool
-	mu               sync.Mutex
-	guidCache        *GUIDCache
-	errorCollector   *ErrorCollector
-	validationReport *ValidationReport
-	lastMetricTime   time.Time
+	integration           *integration.Integration
+	config                *Config
+	metricClient          *MetricAPIClient
+	batchCollector        *BatchCollector
+	enabled               bool
+	mu                    sync.Mutex
+	guidCache             *GUIDCache
+	persistentGUIDCache   *PersistentGUIDCache // non-nil only when config.GUIDCachePersistencePath is set
+	errorCollector        *ErrorCollector
+	validationReport      *ValidationReport
+	lastMetricTime        time.Time
+	timestampWindow       *TimestampWindower
+	lagHistory            *LagHistoryCache
+	partitionLimits       []partitionRange
+	reassignmentCollector *ReassignmentCollector
+	exporters             []Exporter
+	lagClassifier         *lagStatusClassifier
+	dataStreamsTracker    *datastreams.Tracker
+
+	controllerMu       sync.Mutex
+	controllerBrokerID map[string]string // clusterName -> last broker ID observed as controller
+	controllerChanges  map[string]int64  // clusterName -> cumulative controller transitions
+
+	relationshipCache *RelationshipCache
 }
 
 // Metric represents a dimensional metric
@@ -81,24 +98,115 @@ func NewDimensionalTransformer(integration *integration.Integration, config *Con
 	log.Info("Dimensional metrics API key found, length: %d", len(apiKey))
 
 	// Create metric API client
-	metricClient := NewMetricAPIClient(apiKey)
-	
+	metricClient := NewMetricAPIClient(apiKey, config.MetricAPICompression)
+
 	// Create batch collector with 100 metric batch size and 30 second flush interval
 	batchCollector := NewBatchCollector(metricClient, 100, 30*time.Second)
-	
+
+	errorCollector := NewErrorCollector()
+	validationReport := NewValidationReport()
+	metricClient.SetErrorCollector(errorCollector)
+	metricClient.SetValidationReport(validationReport)
+
+	exporters := buildExporters(config, batchCollector)
+
+	guidCache, persistentGUIDCache := buildGUIDCache(config)
+
+	var dataStreamsTracker *datastreams.Tracker
+	if config.DataStreamsEnabled {
+		dataStreamsTracker = datastreams.NewTracker()
+	}
+
 	log.Info("Dimensional transformer initialized for cluster: %s", config.ClusterName)
 
 	return &DimensionalTransformer{
-		integration:      integration,
-		config:           config,
-		metricClient:     metricClient,
-		batchCollector:   batchCollector,
-		enabled:          true,
-		guidCache:        NewGUIDCache(GUIDCacheSize),
-		errorCollector:   NewErrorCollector(),
-		validationReport: NewValidationReport(),
-		lastMetricTime:   time.Now(),
+		integration:         integration,
+		config:              config,
+		metricClient:        metricClient,
+		batchCollector:      batchCollector,
+		enabled:             true,
+		guidCache:           guidCache,
+		persistentGUIDCache: persistentGUIDCache,
+		errorCollector:      errorCollector,
+		validationReport:    validationReport,
+		lastMetricTime:      time.Now(),
+		timestampWindow:     NewTimestampWindower(config),
+		lagHistory:          NewLagHistoryCache(config.PersistentLagWindowSize),
+		partitionLimits:     parsePartitionLimitation(config.ConsumerPartitionLimitation),
+		exporters:           exporters,
+		lagClassifier:       newLagStatusClassifier(config.ConsumerLagThresholds, config.DefaultLagThreshold, config.LagIdleWindow),
+		dataStreamsTracker:  dataStreamsTracker,
+
+		controllerBrokerID: make(map[string]string),
+		controllerChanges:  make(map[string]int64),
+
+		relationshipCache: NewRelationshipCache(GUIDCacheSize),
+	}
+}
+
+// buildGUIDCache constructs the GUID cache for a DimensionalTransformer: a
+// plain in-memory GUIDCache by default, or - when config.GUIDCachePersistencePath
+// is set - a PersistentGUIDCache whose embedded *GUIDCache is returned
+// alongside the wrapper itself, so Stop can flush a final snapshot on
+// shutdown without every other call site needing to know persistence is
+// involved.
+func buildGUIDCache(config *Config) (*GUIDCache, *PersistentGUIDCache) {
+	resolver := NewChainedAccountResolver(
+		NewStaticAccountResolver(config.AWSAccountID, "INFRA", "NA"),
+		NewEnvAccountResolver(),
+		NewIMDSAccountResolver(),
+	)
+
+	opts := []GUIDCacheOption{WithAccountResolver(resolver)}
+	if config.GUIDLegacyCompatCycles > 0 {
+		opts = append(opts, WithLegacyGUIDCompat(config.GUIDLegacyCompatCycles))
+	}
+
+	if config.GUIDCachePersistencePath == "" {
+		return NewGUIDCache(GUIDCacheSize, opts...), nil
+	}
+	backend := NewJSONFileCacheBackend(config.GUIDCachePersistencePath)
+	persistentCache := NewPersistentGUIDCache(GUIDCacheSize, backend, config.GUIDCacheFlushInterval, opts...)
+	return persistentCache.GUIDCache, persistentCache
+}
+
+// buildExporters always includes the New Relic Metric API push path
+// (batchCollector), then adds whichever of config.Exporters it recognizes;
+// an exporter that fails to construct (e.g. "kafka" without KafkaSink
+// configured) is logged and skipped rather than aborting startup.
+func buildExporters(config *Config, batchCollector *BatchCollector) []Exporter {
+	exporters := []Exporter{&batchCollectorExporter{collector: batchCollector}}
+
+	for _, name := range config.Exporters {
+		switch name {
+		case "newrelic":
+			// already included above
+		case "prometheus":
+			promExporter := NewPrometheusExporter(config.PrometheusListenAddr)
+			promExporter.Start()
+			exporters = append(exporters, promExporter)
+		case "kafka":
+			if config.KafkaSink == nil {
+				log.Warn("Dimensional metrics: kafka exporter requested but Config.KafkaSink isn't set; skipping")
+				continue
+			}
+			kafkaSink, err := NewKafkaSink(*config.KafkaSink)
+			if err != nil {
+				log.Error("Dimensional metrics: failed to create kafka exporter: %v", err)
+				continue
+			}
+			kafkaExporter, err := NewKafkaExporter(kafkaSink, "json")
+			if err != nil {
+				log.Error("Dimensional metrics: failed to create kafka exporter: %v", err)
+				continue
+			}
+			exporters = append(exporters, kafkaExporter)
+		default:
+			log.Warn("Dimensional metrics: unknown exporter %q in Config.Exporters; ignoring", name)
+		}
 	}
+
+	return exporters
 }
 
 // ========================================================================================
@@ -147,7 +255,7 @@ func (dt *DimensionalTransformer) transformBytesInPerSec(sample map[string]inter
 		Name:       "kafka.broker.BytesInPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  dt.getCurrentTimestamp(),
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildBrokerAttributes(sample),
 	}
 }
@@ -182,7 +290,7 @@ func (dt *DimensionalTransformer) transformBytesOutPerSec(sample map[string]inte
 		Name:       "kafka.broker.BytesOutPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildBrokerAttributes(sample),
 	}
 }
@@ -211,7 +319,7 @@ func (dt *DimensionalTransformer) transformMessagesInPerSec(sample map[string]in
 		Name:       "kafka.broker.MessagesInPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildBrokerAttributes(sample),
 	}
 }
@@ -243,7 +351,7 @@ func (dt *DimensionalTransformer) transformTotalFetchRequestsPerSec(sample map[s
 		Name:       "kafka.broker.TotalFetchRequestsPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildBrokerAttributes(sample),
 	}
 }
@@ -271,7 +379,7 @@ func (dt *DimensionalTransformer) transformTotalProduceRequestsPerSec(sample map
 		Name:       "kafka.broker.TotalProduceRequestsPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildBrokerAttributes(sample),
 	}
 }
@@ -315,7 +423,7 @@ func (dt *DimensionalTransformer) TransformBrokerMetrics(brokerID string, metric
 	
 	// Send metrics
 	for _, metric := range metricsToSend {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
+		dt.emit(metric)
 	}
 	
 	log.Info("Transformed %d broker metrics for broker %s", len(metricsToSend), brokerID)
@@ -331,7 +439,7 @@ func (dt *DimensionalTransformer) transformAdditionalBrokerMetrics(metrics map[s
 			Name:       "kafka.broker.UnderReplicatedPartitions",
 			Type:       "gauge",
 			Value:      value,
-			Timestamp:  time.Now().UnixNano() / 1e6,
+			Timestamp:  dt.extractTimestamp(metrics),
 			Attributes: dt.buildBrokerAttributes(metrics),
 		})
 	}
@@ -359,7 +467,7 @@ func (dt *DimensionalTransformer) transformClusterBytesInPerSec(sample map[strin
 		Name:       "kafka.cluster.BytesInPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildClusterAttributes(sample),
 	}
 }
@@ -380,7 +488,7 @@ func (dt *DimensionalTransformer) transformClusterBytesOutPerSec(sample map[stri
 		Name:       "kafka.cluster.BytesOutPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildClusterAttributes(sample),
 	}
 }
@@ -401,7 +509,7 @@ func (dt *DimensionalTransformer) transformClusterMessagesInPerSec(sample map[st
 		Name:       "kafka.cluster.MessagesInPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildClusterAttributes(sample),
 	}
 }
@@ -427,7 +535,84 @@ func (dt *DimensionalTransformer) transformActiveControllerCount(sample map[stri
 		Name:       "kafka.cluster.ActiveControllerCount",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
+		Attributes: dt.buildClusterAttributes(sample),
+	}
+}
+
+// isControllerSample reports whether sample identifies its reporting broker
+// as the cluster's active controller, checking (in order) a provider
+// activeControllerCount of 1, a raw KafkaController JMX bean reading of 1,
+// and an explicit isController field (bool, string, or numeric).
+func isControllerSample(sample map[string]interface{}) bool {
+	if value := extractProviderMetric(sample, "activeControllerCount", "Sum"); value == 1 {
+		return true
+	}
+	if value, ok := getFloatValue(sample, "kafka.controller.KafkaController.ActiveControllerCount"); ok && value == 1 {
+		return true
+	}
+	if raw, ok := sample["isController"]; ok {
+		switch v := raw.(type) {
+		case bool:
+			return v
+		case string:
+			return strings.EqualFold(v, "true")
+		case float64:
+			return v == 1
+		case int:
+			return v == 1
+		}
+	}
+	return false
+}
+
+// recordControllerTransition updates clusterName's last-known controller
+// broker ID and returns the cumulative number of controller transitions
+// observed for it so far. The first broker ever seen as controller for a
+// cluster isn't itself counted as a transition - only a change away from a
+// previously known controller is, so a fresh process doesn't start out
+// reporting a spurious change.
+func (dt *DimensionalTransformer) recordControllerTransition(clusterName, brokerId string) int64 {
+	dt.controllerMu.Lock()
+	defer dt.controllerMu.Unlock()
+
+	prev, known := dt.controllerBrokerID[clusterName]
+	dt.controllerBrokerID[clusterName] = brokerId
+	if known && prev != brokerId {
+		dt.controllerChanges[clusterName]++
+	}
+	return dt.controllerChanges[clusterName]
+}
+
+// currentControllerBrokerID returns the last broker ID recorded as
+// clusterName's active controller (via recordControllerTransition), and
+// whether one has been observed yet.
+func (dt *DimensionalTransformer) currentControllerBrokerID(clusterName string) (string, bool) {
+	dt.controllerMu.Lock()
+	defer dt.controllerMu.Unlock()
+	id, ok := dt.controllerBrokerID[clusterName]
+	return id, ok
+}
+
+// transformControllerChanges emits the cumulative number of controller
+// transitions recordControllerTransition has observed for this cluster, so
+// operators can alert on controller flapping without deriving it themselves
+// from raw ActiveControllerCount samples.
+func (dt *DimensionalTransformer) transformControllerChanges(sample map[string]interface{}) *Metric {
+	clusterName := getStringValueWithDefault(sample, "clusterName", dt.config.ClusterName)
+
+	dt.controllerMu.Lock()
+	changes := dt.controllerChanges[clusterName]
+	dt.controllerMu.Unlock()
+
+	value := float64(changes)
+	validateMetricValue(value, "kafka.cluster.ControllerChanges")
+
+	return &Metric{
+		Name:       "kafka.cluster.ControllerChanges",
+		Type:       "count",
+		Value:      value,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildClusterAttributes(sample),
 	}
 }
@@ -447,7 +632,7 @@ func (dt *DimensionalTransformer) transformOfflinePartitionsCount(sample map[str
 		Name:       "kafka.cluster.OfflinePartitionsCount",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildClusterAttributes(sample),
 	}
 }
@@ -466,7 +651,7 @@ func (dt *DimensionalTransformer) transformUnderReplicatedPartitions(sample map[
 		Name:       "kafka.cluster.UnderReplicatedPartitions",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: dt.buildClusterAttributes(sample),
 	}
 }
@@ -480,34 +665,161 @@ func (dt *DimensionalTransformer) TransformClusterMetrics(metrics map[string]int
 	log.Debug("Transforming cluster metrics for cluster %s", dt.config.ClusterName)
 	
 	// Always send critical cluster health metrics
-	if metric := dt.transformActiveControllerCount(metrics); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
-	}
+	dt.emit(dt.transformActiveControllerCount(metrics))
+
+	dt.emit(dt.transformControllerChanges(metrics))
+
+	dt.emit(dt.transformOfflinePartitionsCount(metrics))
 	
-	if metric := dt.transformOfflinePartitionsCount(metrics); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
-	}
-	
-	if metric := dt.transformUnderReplicatedPartitions(metrics); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
-	}
+	dt.emit(dt.transformUnderReplicatedPartitions(metrics))
 	
 	// Throughput metrics
-	if metric := dt.transformClusterBytesInPerSec(metrics); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
-	}
+	dt.emit(dt.transformClusterBytesInPerSec(metrics))
 	
-	if metric := dt.transformClusterBytesOutPerSec(metrics); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
-	}
+	dt.emit(dt.transformClusterBytesOutPerSec(metrics))
 	
-	if metric := dt.transformClusterMessagesInPerSec(metrics); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
+	dt.emit(dt.transformClusterMessagesInPerSec(metrics))
+
+	for _, m := range dt.transformPartitionReassignments(metrics) {
+		dt.emit(m)
 	}
-	
+
 	return nil
 }
 
+// SetReassignmentCollector wires in the ReassignmentCollector
+// transformPartitionReassignments polls for KIP-455 state; left unset (the
+// default), reassignment metrics are simply not emitted.
+func (dt *DimensionalTransformer) SetReassignmentCollector(collector *ReassignmentCollector) {
+	dt.reassignmentCollector = collector
+}
+
+// reassignmentSentinel is emitted in place of real reassignment metrics
+// when the cluster predates the KIP-455 baseline (pre-2.4), so a dashboard
+// can distinguish "no reassignment running" (0) from "this cluster can't
+// tell us" (-1) instead of silently showing zero either way.
+const reassignmentSentinel = -1
+
+// transformPartitionReassignments surfaces in-flight KIP-455 partition
+// reassignments as dimensional metrics: kafka.cluster.ReassigningPartitions
+// and kafka.cluster.ReassignmentBytesRemaining at the cluster level, plus a
+// per-partition kafka.topic.ReassignmentInProgress gauge tagged with topic,
+// partition, sourceBrokers, and targetBrokers. It is gated behind
+// Config.EnableReassignmentMetrics and a collector wired in via
+// SetReassignmentCollector; without both, it emits nothing.
+func (dt *DimensionalTransformer) transformPartitionReassignments(sample map[string]interface{}) []*Metric {
+	if !dt.config.EnableReassignmentMetrics {
+		return nil
+	}
+	if dt.reassignmentCollector == nil {
+		return reassignmentSentinelMetrics(dt, sample)
+	}
+
+	attrs := dt.buildClusterAttributes(sample)
+	timestamp := dt.extractTimestamp(sample)
+	state := dt.reassignmentCollector.State()
+
+	var bytesRemaining int64
+	for _, progress := range state.Topics {
+		bytesRemaining += progress.ReassignmentBytesRemaining
+	}
+
+	metrics := []*Metric{
+		{
+			Name:       "kafka.cluster.ReassigningPartitions",
+			Type:       "gauge",
+			Value:      float64(state.ReassigningPartitions),
+			Timestamp:  timestamp,
+			Attributes: attrs,
+		},
+		{
+			Name:       "kafka.cluster.ReassignmentBytesRemaining",
+			Type:       "gauge",
+			Value:      float64(bytesRemaining),
+			Timestamp:  timestamp,
+			Attributes: attrs,
+		},
+	}
+
+	for _, r := range dt.reassignmentCollector.Reassignments() {
+		partitionAttrs := dt.buildClusterAttributes(sample)
+		partitionAttrs["topic"] = r.Topic
+		partitionAttrs["partition"] = fmt.Sprintf("%d", r.Partition)
+		partitionAttrs["sourceBrokers"] = joinBrokerIDs(r.RemovingReplicas)
+		partitionAttrs["targetBrokers"] = joinBrokerIDs(r.AddingReplicas)
+
+		metrics = append(metrics, &Metric{
+			Name:       "kafka.topic.ReassignmentInProgress",
+			Type:       "gauge",
+			Value:      1,
+			Timestamp:  timestamp,
+			Attributes: partitionAttrs,
+		})
+	}
+
+	return metrics
+}
+
+// reassignmentSentinelMetrics emits -1 placeholders for the cluster-level
+// reassignment metrics when reassignment metrics are enabled but no
+// collector has been wired in (e.g. the cluster predates the KIP-455
+// baseline and the caller never bothered constructing one), so a
+// dashboard sees an explicit "unknown" rather than a misleading 0.
+func reassignmentSentinelMetrics(dt *DimensionalTransformer, sample map[string]interface{}) []*Metric {
+	attrs := dt.buildClusterAttributes(sample)
+	timestamp := dt.extractTimestamp(sample)
+
+	return []*Metric{
+		{
+			Name:       "kafka.cluster.ReassigningPartitions",
+			Type:       "gauge",
+			Value:      reassignmentSentinel,
+			Timestamp:  timestamp,
+			Attributes: attrs,
+		},
+		{
+			Name:       "kafka.cluster.ReassignmentBytesRemaining",
+			Type:       "gauge",
+			Value:      reassignmentSentinel,
+			Timestamp:  timestamp,
+			Attributes: attrs,
+		},
+	}
+}
+
+// parseReplicaIDList parses a comma-separated broker ID list, the shape an
+// Event API sample flattens AddingReplicas/RemovingReplicas into, silently
+// skipping any entry that isn't a valid integer rather than failing the
+// whole sample.
+func parseReplicaIDList(raw string) []int32 {
+	if raw == "" {
+		return nil
+	}
+	var ids []int32
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 32)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, int32(id))
+	}
+	return ids
+}
+
+// joinBrokerIDs renders a partition's replica broker IDs as a
+// comma-separated string for a sourceBrokers/targetBrokers tag.
+func joinBrokerIDs(brokerIDs []int32) string {
+	parts := make([]string, len(brokerIDs))
+	for i, id := range brokerIDs {
+		parts[i] = strconv.FormatInt(int64(id), 10)
+	}
+	return strings.Join(parts, ",")
+}
+
 // ========================================================================================
 // TOPIC METRICS TRANSFORMATIONS
 // ========================================================================================
@@ -553,7 +865,7 @@ func (dt *DimensionalTransformer) transformTopicBytesInPerSec(sample map[string]
 		Name:       "kafka.topic.BytesInPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: attrs,
 	}
 }
@@ -599,7 +911,7 @@ func (dt *DimensionalTransformer) transformTopicBytesOutPerSec(sample map[string
 		Name:       "kafka.topic.BytesOutPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: attrs,
 	}
 }
@@ -645,7 +957,7 @@ func (dt *DimensionalTransformer) transformTopicMessagesInPerSec(sample map[stri
 		Name:       "kafka.topic.MessagesInPerSec",
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractTimestamp(sample),
 		Attributes: attrs,
 	}
 }
@@ -662,17 +974,11 @@ func (dt *DimensionalTransformer) TransformTopicMetrics(topicName string, metric
 	metrics["topic"] = topicName
 	
 	// Transform each metric
-	if metric := dt.transformTopicBytesInPerSec(metrics); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
-	}
+	dt.emit(dt.transformTopicBytesInPerSec(metrics))
 	
-	if metric := dt.transformTopicBytesOutPerSec(metrics); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
-	}
+	dt.emit(dt.transformTopicBytesOutPerSec(metrics))
 	
-	if metric := dt.transformTopicMessagesInPerSec(metrics); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
-	}
+	dt.emit(dt.transformTopicMessagesInPerSec(metrics))
 	
 	return nil
 }
@@ -687,14 +993,8 @@ func (dt *DimensionalTransformer) transformConsumerMaxLag(groupId string, sample
 	
 	// Find max lag across all partitions
 	for _, sample := range samples {
-		lag := getFloatValueWithDefault(sample, "consumer.lag", 0)
-		if lag < 0 {
-			lag = getFloatValueWithDefault(sample, "consumerLag", 0)
-		}
-		if lag < 0 {
-			lag = getFloatValueWithDefault(sample, "lag", 0)
-		}
-		
+		lag := consumerLagValue(sample)
+
 		if lag > maxLag {
 			maxLag = lag
 		}
@@ -703,54 +1003,189 @@ func (dt *DimensionalTransformer) transformConsumerMaxLag(groupId string, sample
 	if !validateMetricValue(maxLag, "kafka.consumer.MaxLag") {
 		return nil
 	}
-	
+
 	// Use first sample for attributes
-	attrs := dt.buildConsumerGroupAttributes(groupId, samples[0])
-	
+	attrs := dt.buildConsumerGroupAttributes(groupId, samples[0], sumConsumerLag(samples))
+
 	return &Metric{
 		Name:       "kafka.consumer.MaxLag",
 		Type:       "gauge",
 		Value:      maxLag,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  dt.extractLatestTimestamp(samples),
 		Attributes: attrs,
 	}
 }
 
 // transformConsumerTotalLag transforms consumer total lag metric
 func (dt *DimensionalTransformer) transformConsumerTotalLag(groupId string, samples []map[string]interface{}) *Metric {
-	totalLag := float64(0)
-	
-	// Sum lag across all partitions
-	for _, sample := range samples {
-		lag := getFloatValueWithDefault(sample, "consumer.lag", 0)
-		if lag < 0 {
-			lag = getFloatValueWithDefault(sample, "consumerLag", 0)
-		}
-		if lag < 0 {
-			lag = getFloatValueWithDefault(sample, "lag", 0)
-		}
-		
-		if lag > 0 {
-			totalLag += lag
-		}
-	}
-	
+	totalLag := sumConsumerLag(samples)
+
 	if !validateMetricValue(totalLag, "kafka.consumer.TotalLag") {
 		return nil
 	}
-	
+
 	// Use first sample for attributes
-	attrs := dt.buildConsumerGroupAttributes(groupId, samples[0])
-	
+	attrs := dt.buildConsumerGroupAttributes(groupId, samples[0], totalLag)
+	timestamp := dt.extractLatestTimestamp(samples)
+
+	dt.emitLagRatio(groupId, totalLag, attrs, timestamp)
+
 	return &Metric{
 		Name:       "kafka.consumer.TotalLag",
 		Type:       "gauge",
 		Value:      totalLag,
-		Timestamp:  time.Now().UnixNano() / 1e6,
+		Timestamp:  timestamp,
 		Attributes: attrs,
 	}
 }
 
+// transformConsumerLagSeconds emits aws.msk.consumer.lag.seconds, the
+// KIP-734 time-lag (maxTimestamp(partition) - timestamp(committedOffset))
+// a caller resolved via timelag.Fetcher, as opposed to
+// kafka.consumer.MaxLag/TotalLag's record-count lag. Absent on brokers
+// older than Kafka 3.0 or when the caller didn't resolve it, in which case
+// this returns nil and only the record-count metrics above are emitted --
+// a small backlog of very old messages matters more than a large backlog
+// of fresh ones, which record-count lag alone can't distinguish.
+func (dt *DimensionalTransformer) transformConsumerLagSeconds(groupId string, samples []map[string]interface{}) *Metric {
+	lagSeconds, ok := getFloatValue(samples[0], "consumer.lag.seconds")
+	if !ok {
+		return nil
+	}
+	if !validateMetricValue(lagSeconds, "aws.msk.consumer.lag.seconds") {
+		return nil
+	}
+
+	attrs := dt.buildConsumerGroupAttributes(groupId, samples[0], sumConsumerLag(samples))
+
+	return &Metric{
+		Name:       "aws.msk.consumer.lag.seconds",
+		Type:       "gauge",
+		Value:      lagSeconds,
+		Timestamp:  dt.extractLatestTimestamp(samples),
+		Attributes: attrs,
+	}
+}
+
+// transformTopicMaxTimestamp emits aws.msk.topic.maxTimestamp, the unix
+// timestamp of topic's most-recently-produced record on the partition a
+// caller resolved via timelag.Fetcher.MaxTimestampOffset, so operators can
+// see how current a partition's feed actually is independent of any one
+// consumer group's lag.
+func (dt *DimensionalTransformer) transformTopicMaxTimestamp(groupId, topic string, samples []map[string]interface{}) *Metric {
+	maxTimestamp, ok := getFloatValue(samples[0], "topic.maxTimestamp")
+	if !ok {
+		return nil
+	}
+	if !validateMetricValue(maxTimestamp, "aws.msk.topic.maxTimestamp") {
+		return nil
+	}
+
+	attrs := dt.buildConsumerGroupAttributes(groupId, samples[0], sumConsumerLag(samples))
+	attrs["topic.name"] = topic
+
+	return &Metric{
+		Name:       "aws.msk.topic.maxTimestamp",
+		Type:       "gauge",
+		Value:      maxTimestamp,
+		Timestamp:  dt.extractLatestTimestamp(samples),
+		Attributes: attrs,
+	}
+}
+
+// transformDataStreamsCheckpoint emits aws.msk.pipeline.latency (event-time
+// end-to-end, seconds) and aws.msk.pipeline.backlog (records behind the
+// partition's high water mark) for the pipeline path identified by
+// sample's producerService (defaulting to "unknown" when absent), topic,
+// and consumerGroup. It is a no-op when no Tracker is wired in (the
+// default), returning no metrics rather than reporting a meaningless
+// checkpoint against nothing.
+func (dt *DimensionalTransformer) transformDataStreamsCheckpoint(consumerGroup, topic string, sample map[string]interface{}) []*Metric {
+	if dt.dataStreamsTracker == nil {
+		return nil
+	}
+
+	committedOffset, ok := getFloatValue(sample, "consumerOffset")
+	if !ok {
+		return nil
+	}
+	highWaterMark, ok := getFloatValue(sample, "highWaterMark")
+	if !ok {
+		return nil
+	}
+
+	producerService := getStringValueWithDefault(sample, "producerService", "unknown")
+	checkpoint := datastreams.NewCheckpoint(producerService, topic, consumerGroup)
+
+	tp := datastreams.TopicPartition{
+		Topic:     topic,
+		Partition: int32(getIntValueWithDefault(sample, "partition", -1)),
+	}
+	backlog := dt.dataStreamsTracker.Backlog(tp, int64(committedOffset), int64(highWaterMark))
+
+	attrs := dt.buildConsumerGroupAttributes(consumerGroup, sample, sumConsumerLag([]map[string]interface{}{sample}))
+	attrs["pipeline.checkpoint"] = string(checkpoint)
+	attrs["pipeline.producerService"] = producerService
+	attrs["topic.name"] = topic
+	timestamp := dt.extractLatestTimestamp([]map[string]interface{}{sample})
+
+	metrics := []*Metric{
+		{
+			Name:       "aws.msk.pipeline.backlog",
+			Type:       "gauge",
+			Value:      float64(backlog),
+			Timestamp:  timestamp,
+			Attributes: attrs,
+		},
+	}
+
+	// Event-time end-to-end latency needs the record's own produce
+	// timestamp; it's only available when the caller resolved it (the
+	// same topic.maxTimestamp KIP-734 lookup transformTopicMaxTimestamp
+	// consumes), so it's omitted rather than approximated when absent.
+	if maxTimestamp, ok := getFloatValue(sample, "topic.maxTimestamp"); ok {
+		latencySeconds := float64(dt.getCurrentTimestamp())/1000 - maxTimestamp
+		if latencySeconds >= 0 {
+			metrics = append(metrics, &Metric{
+				Name:       "aws.msk.pipeline.latency",
+				Type:       "gauge",
+				Value:      latencySeconds,
+				Timestamp:  timestamp,
+				Attributes: attrs,
+			})
+		}
+	}
+
+	return metrics
+}
+
+// emitLagRatio emits kafka.consumer.LagRatio = totalLag / critLag so
+// alerting can pivot on a value normalized across groups with very
+// different traffic, the way KEDA's activationLagThreshold normalizes
+// scaling decisions. It's a no-op when no lag thresholds are configured or
+// groupId's CritLag is unset, since dividing by zero has no meaningful
+// "ratio to critical" to report.
+func (dt *DimensionalTransformer) emitLagRatio(groupId string, totalLag float64, attrs map[string]interface{}, timestamp int64) {
+	if dt.lagClassifier == nil {
+		return
+	}
+	critLag := dt.lagClassifier.thresholdFor(groupId).CritLag
+	if critLag <= 0 {
+		return
+	}
+	ratio := totalLag / critLag
+	if !validateMetricValue(ratio, "kafka.consumer.LagRatio") {
+		return
+	}
+	dt.emit(&Metric{
+		Name:       "kafka.consumer.LagRatio",
+		Type:       "gauge",
+		Value:      ratio,
+		Timestamp:  timestamp,
+		Attributes: attrs,
+	})
+}
+
 // TransformConsumerMetrics transforms consumer group metrics
 func (dt *DimensionalTransformer) TransformConsumerMetrics(consumerGroup string, topic string, metrics map[string]interface{}) error {
 	if !dt.enabled {
@@ -762,39 +1197,365 @@ func (dt *DimensionalTransformer) TransformConsumerMetrics(consumerGroup string,
 	// For single sample, just transform directly
 	samples := []map[string]interface{}{metrics}
 	
-	if metric := dt.transformConsumerMaxLag(consumerGroup, samples); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
+	dt.emit(dt.transformConsumerMaxLag(consumerGroup, samples))
+
+	dt.emit(dt.transformConsumerTotalLag(consumerGroup, samples))
+
+	// Time lag (KIP-734) is only present when the caller resolved it --
+	// e.g. SaramaConsumerCollector, on brokers new enough to support the
+	// MaxTimestamp ListOffsets query. Absent on older brokers/paths, in
+	// which case only the offset-based lag above is emitted.
+	dt.emit(dt.transformConsumerLagSeconds(consumerGroup, samples))
+
+	dt.emit(dt.transformTopicMaxTimestamp(consumerGroup, topic, samples))
+
+	for _, m := range dt.transformDataStreamsCheckpoint(consumerGroup, topic, metrics) {
+		dt.emit(m)
 	}
-	
-	if metric := dt.transformConsumerTotalLag(consumerGroup, samples); metric != nil {
-		dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
+
+	return nil
+}
+
+// TransformConsumerGroupState is TransformConsumerMetrics's sibling for
+// group-level state and membership rather than offset lag: it emits
+// aws.msk.consumer.group.state, aws.msk.consumer.group.members, and
+// aws.msk.consumer.group.rebalances from a ConsumerGroupStateSnapshot, so
+// a stuck rebalance or an unassigned partition is visible the same way
+// lag already is, instead of requiring operators to poll DescribeGroups
+// themselves.
+func (dt *DimensionalTransformer) TransformConsumerGroupState(snapshot *ConsumerGroupStateSnapshot) error {
+	if !dt.enabled || snapshot == nil {
+		return nil
 	}
-	
+
+	log.Debug("Transforming consumer group state for group %s (state=%s)", snapshot.GroupID, snapshot.State)
+
+	attrs := dt.buildConsumerGroupStateAttributes(snapshot)
+	timestamp := dt.getCurrentTimestamp()
+
+	dt.emit(&Metric{
+		Name:       "aws.msk.consumer.group.state",
+		Type:       "gauge",
+		Value:      consumerGroupStateValue(snapshot.State),
+		Timestamp:  timestamp,
+		Attributes: attrs,
+	})
+
+	dt.emit(&Metric{
+		Name:       "aws.msk.consumer.group.members",
+		Type:       "gauge",
+		Value:      float64(snapshot.MemberCount),
+		Timestamp:  timestamp,
+		Attributes: attrs,
+	})
+
+	rebalancing := 0.0
+	if snapshot.State == "PreparingRebalance" || snapshot.State == "CompletingRebalance" {
+		rebalancing = 1.0
+	}
+	dt.emit(&Metric{
+		Name:       "aws.msk.consumer.group.rebalances",
+		Type:       "gauge",
+		Value:      rebalancing,
+		Timestamp:  timestamp,
+		Attributes: attrs,
+	})
+
 	return nil
 }
 
+// consumerGroupStateValue maps a DescribeGroups state string to a
+// numeric value so aws.msk.consumer.group.state can be alerted on/graphed
+// like any other gauge, while the original string stays available on the
+// metric's "group.state" attribute for anyone who wants the raw value.
+func consumerGroupStateValue(state string) float64 {
+	switch state {
+	case "Stable":
+		return 0
+	case "PreparingRebalance":
+		return 1
+	case "CompletingRebalance":
+		return 2
+	case "Empty":
+		return 3
+	case "Dead":
+		return 4
+	default:
+		return -1
+	}
+}
+
+// buildConsumerGroupStateAttributes builds the dimensions
+// TransformConsumerGroupState's metrics carry: group id, coordinator
+// broker id, and protocol type, per the request this satisfies.
+func (dt *DimensionalTransformer) buildConsumerGroupStateAttributes(snapshot *ConsumerGroupStateSnapshot) map[string]interface{} {
+	attrs := dt.buildCommonAttributes()
+
+	attrs["entity.type"] = "AWS_KAFKA_CONSUMER_GROUP"
+	attrs["cluster.name"] = dt.config.ClusterName
+	attrs["consumer.group.id"] = snapshot.GroupID
+	attrs["entity.name"] = fmt.Sprintf("consumer-group:%s", snapshot.GroupID)
+	attrs["group.state"] = snapshot.State
+	attrs["group.protocolType"] = snapshot.ProtocolType
+	attrs["group.coordinatorBrokerId"] = snapshot.CoordinatorBrokerID
+
+	attrs["provider"] = "AwsMsk"
+	attrs["awsAccountId"] = dt.config.AWSAccountID
+	attrs["awsRegion"] = dt.config.AWSRegion
+
+	return attrs
+}
+
 // TransformConsumerOffsetSamples transforms multiple consumer offset samples with aggregation
 func (dt *DimensionalTransformer) TransformConsumerOffsetSamples(samples []map[string]interface{}) error {
 	if !dt.enabled || len(samples) == 0 {
 		return nil
 	}
-	
+
 	// Group by consumer group
 	grouped := groupByConsumerGroup(samples)
-	
+	now := time.Now()
+
 	for groupId, groupSamples := range grouped {
-		if metric := dt.transformConsumerMaxLag(groupId, groupSamples); metric != nil {
-			dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
+		aggregateSamples := dt.applyPartitionLimitation(groupSamples)
+		if len(aggregateSamples) == 0 {
+			continue
 		}
-		
-		if metric := dt.transformConsumerTotalLag(groupId, groupSamples); metric != nil {
-			dt.batchCollector.AddMetric(metric.Name, metric.Value, metric.Attributes)
+
+		if dt.config.ExcludePersistentLag {
+			attrSample := aggregateSamples[0]
+			active, persistentCount := dt.excludePersistentLag(groupId, aggregateSamples, now)
+			activeLag := sumConsumerLag(active)
+			dt.emit(dt.transformPersistentLagPartitions(groupId, attrSample, persistentCount, activeLag))
+			dt.emit(dt.transformActiveLag(groupId, attrSample, activeLag))
+			aggregateSamples = active
+		}
+
+		if len(aggregateSamples) == 0 {
+			continue
 		}
+		dt.emit(dt.transformConsumerMaxLag(groupId, aggregateSamples))
+		dt.emit(dt.transformConsumerTotalLag(groupId, aggregateSamples))
+		dt.transformConsumerLagDistribution(groupId, aggregateSamples)
 	}
-	
+
+	dt.lagHistory.EvictOlderThan(MaxMetricAge, now)
+	if dt.lagClassifier != nil {
+		dt.lagClassifier.EvictOlderThan(MaxMetricAge, now)
+	}
+
 	return nil
 }
 
+// consumerLagValue reads a sample's lag the same way
+// transformConsumerMaxLag/transformConsumerTotalLag already do, trying
+// consumer.lag, then consumerLag, then lag.
+func consumerLagValue(sample map[string]interface{}) float64 {
+	lag := getFloatValueWithDefault(sample, "consumer.lag", 0)
+	if lag < 0 {
+		lag = getFloatValueWithDefault(sample, "consumerLag", 0)
+	}
+	if lag < 0 {
+		lag = getFloatValueWithDefault(sample, "lag", 0)
+	}
+	return lag
+}
+
+// sumConsumerLag totals lag across samples the same way
+// transformConsumerTotalLag does, factored out so callers that only need
+// the total for entity-attribute classification (not the metric itself)
+// don't duplicate the loop.
+func sumConsumerLag(samples []map[string]interface{}) float64 {
+	var total float64
+	for _, sample := range samples {
+		if lag := consumerLagValue(sample); lag > 0 {
+			total += lag
+		}
+	}
+	return total
+}
+
+// excludePersistentLag splits groupSamples into active (lag trending
+// down, or not enough history yet to judge) and excluded (lag
+// non-decreasing across the whole history window and exceeding
+// PersistentLagThreshold): partitions whose consumer is down or has
+// stopped committing, which would otherwise inflate MaxLag/TotalLag the
+// way a stuck KEDA-scaled consumer would trigger permanent scale-out.
+func (dt *DimensionalTransformer) excludePersistentLag(groupId string, groupSamples []map[string]interface{}, now time.Time) (active []map[string]interface{}, persistentCount int) {
+	threshold := dt.config.PersistentLagThreshold
+
+	for _, sample := range groupSamples {
+		topic := getStringValueWithDefault(sample, "topic", "")
+		partition := getStringValueWithDefault(sample, "partition", "")
+		lag := consumerLagValue(sample)
+
+		nonDecreasing, ready := dt.lagHistory.Observe(groupId, topic, partition, lag, now)
+		if ready && nonDecreasing && lag > threshold {
+			persistentCount++
+			continue
+		}
+		active = append(active, sample)
+	}
+	return active, persistentCount
+}
+
+// transformPersistentLagPartitions emits the count of partitions excluded
+// from this group's lag aggregates because their lag was non-decreasing
+// across the whole history window. activeLag is the group's lag total
+// after exclusion, passed through only for lagStatus/thresholdApplied
+// classification on this metric's own entity attributes.
+func (dt *DimensionalTransformer) transformPersistentLagPartitions(groupId string, attrSample map[string]interface{}, count int, activeLag float64) *Metric {
+	return &Metric{
+		Name:       "kafka.consumer.PersistentLagPartitions",
+		Type:       "gauge",
+		Value:      float64(count),
+		Timestamp:  dt.extractTimestamp(attrSample),
+		Attributes: dt.buildConsumerGroupAttributes(groupId, attrSample, activeLag),
+	}
+}
+
+// transformActiveLag reports activeLag - the sum of lag across only the
+// partitions that weren't excluded as persistent, the figure an autoscaler
+// should actually act on - as its own metric.
+func (dt *DimensionalTransformer) transformActiveLag(groupId string, attrSample map[string]interface{}, activeLag float64) *Metric {
+	return &Metric{
+		Name:       "kafka.consumer.ActiveLag",
+		Type:       "gauge",
+		Value:      activeLag,
+		Timestamp:  dt.extractTimestamp(attrSample),
+		Attributes: dt.buildConsumerGroupAttributes(groupId, attrSample, activeLag),
+	}
+}
+
+// applyPartitionLimitation drops samples outside dt.partitionLimits, so a
+// group's lag aggregates and distribution only reflect the partitions the
+// operator scoped ConsumerPartitionLimitation to. An unset limitation (the
+// common case) returns samples unchanged.
+func (dt *DimensionalTransformer) applyPartitionLimitation(samples []map[string]interface{}) []map[string]interface{} {
+	if len(dt.partitionLimits) == 0 {
+		return samples
+	}
+	filtered := make([]map[string]interface{}, 0, len(samples))
+	for _, sample := range samples {
+		partition := int32(getIntValueWithDefault(sample, "partition", -1))
+		if partitionInLimitation(partition, dt.partitionLimits) {
+			filtered = append(filtered, sample)
+		}
+	}
+	return filtered
+}
+
+// transformConsumerLagDistribution emits per-group lag spread
+// (kafka.consumer.LagP50/LagP95/LagP99/LagStdDev/LagPartitionCount) and a
+// per-partition kafka.consumer.PartitionLag gauge, so a fan-out group with
+// a handful of hot partitions doesn't get averaged away into MaxLag/
+// TotalLag's two scalars.
+func (dt *DimensionalTransformer) transformConsumerLagDistribution(groupId string, samples []map[string]interface{}) {
+	lags := make([]float64, len(samples))
+	for i, sample := range samples {
+		lags[i] = consumerLagValue(sample)
+	}
+
+	totalLag := sumConsumerLag(samples)
+	attrs := dt.buildConsumerGroupAttributes(groupId, samples[0], totalLag)
+	timestamp := dt.extractLatestTimestamp(samples)
+
+	sorted := append([]float64(nil), lags...)
+	sort.Float64s(sorted)
+
+	dt.emit(&Metric{
+		Name:       "kafka.consumer.LagPartitionCount",
+		Type:       "gauge",
+		Value:      float64(len(sorted)),
+		Timestamp:  timestamp,
+		Attributes: attrs,
+	})
+	dt.emit(&Metric{
+		Name:       "kafka.consumer.LagP50",
+		Type:       "gauge",
+		Value:      nearestRankPercentile(sorted, 50),
+		Timestamp:  timestamp,
+		Attributes: attrs,
+	})
+	dt.emit(&Metric{
+		Name:       "kafka.consumer.LagP95",
+		Type:       "gauge",
+		Value:      nearestRankPercentile(sorted, 95),
+		Timestamp:  timestamp,
+		Attributes: attrs,
+	})
+	dt.emit(&Metric{
+		Name:       "kafka.consumer.LagP99",
+		Type:       "gauge",
+		Value:      nearestRankPercentile(sorted, 99),
+		Timestamp:  timestamp,
+		Attributes: attrs,
+	})
+	dt.emit(&Metric{
+		Name:       "kafka.consumer.LagStdDev",
+		Type:       "gauge",
+		Value:      lagStdDev(sorted),
+		Timestamp:  timestamp,
+		Attributes: attrs,
+	})
+
+	for _, sample := range samples {
+		partitionAttrs := dt.buildConsumerGroupAttributes(groupId, sample, totalLag)
+		partitionAttrs["partition"] = getStringValueWithDefault(sample, "partition", "")
+		partitionAttrs["topic"] = getStringValueWithDefault(sample, "topic", "")
+
+		dt.emit(&Metric{
+			Name:       "kafka.consumer.PartitionLag",
+			Type:       "gauge",
+			Value:      consumerLagValue(sample),
+			Timestamp:  dt.extractTimestamp(sample),
+			Attributes: partitionAttrs,
+		})
+	}
+}
+
+// nearestRankPercentile returns the pth percentile (0-100) of sorted using
+// the nearest-rank method: no interpolation, no allocation, just an index
+// pick, which is all a lag distribution needs since the underlying values
+// are already whole message counts.
+func nearestRankPercentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// lagStdDev computes the population standard deviation of values, which
+// are assumed already sorted (sorting doesn't affect the result) so
+// transformConsumerLagDistribution can reuse the same slice it built for
+// percentiles.
+func lagStdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		diff := v - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(values))
+
+	return math.Sqrt(variance)
+}
+
 // ========================================================================================
 // ATTRIBUTE BUILDERS
 // ========================================================================================
@@ -825,8 +1586,20 @@ func (dt *DimensionalTransformer) buildBrokerAttributes(sample map[string]interf
 	brokerId := dt.extractBrokerIdWithFallbacks(sample)
 	attrs["broker.id"] = brokerId
 	attrs["entity.name"] = fmt.Sprintf("%s:broker-%s", clusterName, brokerId)
-	attrs["entity.guid"] = dt.generateBrokerGUID(clusterName, brokerId)
-	
+
+	// Mark this broker as the active controller, and remember it, when the
+	// sample carries a controller signal - mirroring how a controller-aware
+	// proxy routes controller-only requests to whichever broker currently
+	// holds the role.
+	if isControllerSample(sample) {
+		attrs["broker.role"] = "controller"
+		attrs["broker.isController"] = "true"
+		dt.recordControllerTransition(clusterName, brokerId)
+	} else {
+		attrs["broker.role"] = "broker"
+		attrs["broker.isController"] = "false"
+	}
+
 	// Critical AWS fields for UI visibility
 	attrs["provider"] = "AwsMsk"
 	attrs["awsAccountId"] = dt.config.AWSAccountID
@@ -834,7 +1607,14 @@ func (dt *DimensionalTransformer) buildBrokerAttributes(sample map[string]interf
 	attrs["providerAccountId"] = dt.config.AWSAccountID
 	attrs["providerExternalId"] = dt.config.AWSAccountID // Required for AWS account mapping
 	attrs["aws.Namespace"] = "AWS/Kafka"
-	
+
+	// Override account/region with the sample's own cluster ARN, if present,
+	// so a cross-account MSK cluster is attributed to its own account rather
+	// than the collector's configured AWSAccountID/AWSRegion.
+	clusterUUID := dt.applyClusterArn(sample, attrs)
+	attrs["entity.guid"] = dt.generateBrokerGUID(clusterName, clusterUUID, brokerId)
+	dt.attachLegacyGUID(attrs, "AWS_KAFKA_BROKER", clusterIdentity(clusterName, clusterUUID), brokerId)
+
 	// Optional but recommended
 	if host := getStringValueWithDefault(sample, "host", ""); host != "" {
 		attrs["host"] = host
@@ -868,8 +1648,7 @@ func (dt *DimensionalTransformer) buildTopicAttributes(sample map[string]interfa
 	}
 	
 	attrs["entity.name"] = fmt.Sprintf("topic:%s", topicName)
-	attrs["entity.guid"] = dt.generateTopicGUID(attrs["cluster.name"].(string), topicName)
-	
+
 	// Critical AWS fields for UI visibility
 	attrs["provider"] = "AwsMsk"
 	attrs["awsAccountId"] = dt.config.AWSAccountID
@@ -877,7 +1656,11 @@ func (dt *DimensionalTransformer) buildTopicAttributes(sample map[string]interfa
 	attrs["providerAccountId"] = dt.config.AWSAccountID
 	attrs["providerExternalId"] = dt.config.AWSAccountID // Required for AWS account mapping
 	attrs["aws.Namespace"] = "AWS/Kafka"
-	
+
+	clusterUUID := dt.applyClusterArn(sample, attrs)
+	attrs["entity.guid"] = dt.generateTopicGUID(attrs["cluster.name"].(string), clusterUUID, topicName)
+	dt.attachLegacyGUID(attrs, "AWS_KAFKA_TOPIC", clusterIdentity(attrs["cluster.name"].(string), clusterUUID), topicName)
+
 	// Include broker that reported this metric
 	if brokerId := extractBrokerId(sample); brokerId != "unknown" {
 		attrs["broker.id"] = brokerId
@@ -886,16 +1669,19 @@ func (dt *DimensionalTransformer) buildTopicAttributes(sample map[string]interfa
 	return dt.stringifyAttributes(attrs)
 }
 
-// buildConsumerGroupAttributes builds consumer group attributes
-func (dt *DimensionalTransformer) buildConsumerGroupAttributes(groupId string, sample map[string]interface{}) map[string]interface{} {
+// buildConsumerGroupAttributes builds consumer group attributes. totalLag
+// is the group's current total lag (0 if the caller has none to report),
+// used to stamp consumer.group.lagStatus/thresholdApplied via the
+// KEDA-style per-group LagThreshold classification.
+func (dt *DimensionalTransformer) buildConsumerGroupAttributes(groupId string, sample map[string]interface{}, totalLag float64) map[string]interface{} {
 	attrs := dt.buildCommonAttributes()
-	
+
 	attrs["entity.type"] = "AWS_KAFKA_CONSUMER_GROUP"
-	attrs["cluster.name"] = getStringValueWithDefault(sample, "clusterName", dt.config.ClusterName)
+	clusterName := getStringValueWithDefault(sample, "clusterName", dt.config.ClusterName)
+	attrs["cluster.name"] = clusterName
 	attrs["consumer.group.id"] = groupId
 	attrs["entity.name"] = fmt.Sprintf("consumer-group:%s", groupId)
-	attrs["entity.guid"] = dt.generateConsumerGroupGUID(attrs["cluster.name"].(string), groupId)
-	
+
 	// Critical AWS fields for UI visibility
 	attrs["provider"] = "AwsMsk"
 	attrs["awsAccountId"] = dt.config.AWSAccountID
@@ -903,11 +1689,21 @@ func (dt *DimensionalTransformer) buildConsumerGroupAttributes(groupId string, s
 	attrs["providerAccountId"] = dt.config.AWSAccountID
 	attrs["providerExternalId"] = dt.config.AWSAccountID // Required for AWS account mapping
 	attrs["aws.Namespace"] = "AWS/Kafka"
-	
+
+	clusterUUID := dt.applyClusterArn(sample, attrs)
+	attrs["entity.guid"] = dt.generateConsumerGroupGUID(attrs["cluster.name"].(string), clusterUUID, groupId)
+	dt.attachLegacyGUID(attrs, "AWS_KAFKA_CONSUMER_GROUP", clusterIdentity(attrs["cluster.name"].(string), clusterUUID), groupId)
+
 	if topic := getStringValueWithDefault(sample, "topic", ""); topic != "" {
 		attrs["topic"] = topic
 	}
-	
+
+	if dt.lagClassifier != nil {
+		status, thresholdApplied := dt.lagClassifier.Classify(clusterName+"|"+groupId, groupId, totalLag, time.Now())
+		attrs["consumer.group.lagStatus"] = status
+		attrs["consumer.group.thresholdApplied"] = thresholdApplied
+	}
+
 	return dt.stringifyAttributes(attrs)
 }
 
@@ -919,8 +1715,7 @@ func (dt *DimensionalTransformer) buildClusterAttributes(sample map[string]inter
 	attrs["entity.type"] = "AWS_KAFKA_CLUSTER"
 	attrs["cluster.name"] = clusterName
 	attrs["entity.name"] = fmt.Sprintf("aws-msk-cluster:%s", clusterName)
-	attrs["entity.guid"] = dt.generateClusterGUID(clusterName)
-	
+
 	// Critical AWS fields for UI visibility
 	attrs["provider"] = "AwsMsk"
 	attrs["awsAccountId"] = dt.config.AWSAccountID
@@ -928,15 +1723,96 @@ func (dt *DimensionalTransformer) buildClusterAttributes(sample map[string]inter
 	attrs["providerAccountId"] = dt.config.AWSAccountID
 	attrs["providerExternalId"] = dt.config.AWSAccountID // Required for AWS account mapping
 	attrs["aws.Namespace"] = "AWS/Kafka"
-	
-	// Add AWS-specific attributes if available
-	if arn := getStringValueWithDefault(sample, "clusterArn", ""); arn != "" {
-		attrs["aws.arn"] = arn
-	}
+
+	// Parse the cluster ARN, if present, to override account/region with its
+	// own values and expose the UUID that keeps entity.guid stable across
+	// cluster renames (falls back to today's raw copy-through on failure).
+	clusterUUID := dt.applyClusterArn(sample, attrs)
+	attrs["entity.guid"] = dt.generateClusterGUID(clusterName, clusterUUID)
+	dt.attachLegacyGUID(attrs, "AWS_KAFKA_CLUSTER", clusterIdentity(clusterName, clusterUUID), "")
+
 	if region := getStringValueWithDefault(sample, "awsRegion", dt.config.AWSRegion); region != "" {
 		attrs["aws.region"] = region
 	}
-	
+
+	if brokerId, ok := dt.currentControllerBrokerID(clusterName); ok {
+		attrs["cluster.controller.brokerId"] = brokerId
+	}
+
+	return dt.stringifyAttributes(attrs)
+}
+
+// applyClusterArn parses sample's clusterArn, if present and well-formed,
+// and overrides attrs's account/region fields with the ARN's own values so
+// a cross-account MSK cluster is attributed correctly instead of to the
+// collector's configured AWSAccountID/AWSRegion. It returns the parsed
+// cluster UUID, or "" if no valid ARN was present - callers fall back to
+// today's display-name-only GUID generation in that case.
+func (dt *DimensionalTransformer) applyClusterArn(sample map[string]interface{}, attrs map[string]interface{}) string {
+	arn := getStringValueWithDefault(sample, "clusterArn", "")
+	if arn == "" {
+		return ""
+	}
+	_, region, accountID, _, clusterUUID, err := parseMskArn(arn)
+	if err != nil {
+		attrs["aws.arn"] = arn
+		return ""
+	}
+	attrs["aws.arn"] = arn
+	attrs["aws.msk.clusterUuid"] = clusterUUID
+	attrs["awsAccountId"] = accountID
+	attrs["awsRegion"] = region
+	attrs["providerAccountId"] = accountID
+	attrs["providerExternalId"] = accountID
+	return clusterUUID
+}
+
+// parseMskArn decomposes an AWS MSK cluster ARN, e.g.
+// "arn:aws:kafka:us-east-1:123456789012:cluster/mycluster/abcd-uuid", into
+// its partition, region, account ID, cluster name, and cluster UUID.
+// It returns an error for anything that doesn't match that shape so callers
+// can fall back to today's behavior rather than attributing a sample to the
+// wrong account or region.
+func parseMskArn(arn string) (partition, region, accountID, clusterName, clusterUUID string, err error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "kafka" {
+		return "", "", "", "", "", fmt.Errorf("parseMskArn: %q is not an MSK cluster ARN", arn)
+	}
+	if parts[3] == "" || parts[4] == "" {
+		return "", "", "", "", "", fmt.Errorf("parseMskArn: %q is missing region or account ID", arn)
+	}
+
+	resourceParts := strings.Split(parts[5], "/")
+	if len(resourceParts) != 3 || resourceParts[0] != "cluster" || resourceParts[1] == "" || resourceParts[2] == "" {
+		return "", "", "", "", "", fmt.Errorf("parseMskArn: %q has a malformed cluster resource", arn)
+	}
+
+	return parts[1], parts[3], parts[4], resourceParts[1], resourceParts[2], nil
+}
+
+// buildPartitionAttributes builds AWS_KAFKA_PARTITION entity attributes for
+// a single topic partition. Partitions don't have a builder alongside
+// buildTopicAttributes/buildConsumerGroupAttributes because nothing needed
+// one as its own entity until reassignment tracking needed a GUID to tag
+// per-partition progress with.
+func (dt *DimensionalTransformer) buildPartitionAttributes(topic string, partitionID string, sample map[string]interface{}) map[string]interface{} {
+	attrs := dt.buildCommonAttributes()
+
+	clusterName := getStringValueWithDefault(sample, "clusterName", dt.config.ClusterName)
+	attrs["entity.type"] = "AWS_KAFKA_PARTITION"
+	attrs["cluster.name"] = clusterName
+	attrs["topic"] = topic
+	attrs["partition"] = partitionID
+	attrs["entity.name"] = fmt.Sprintf("%s:%s-%s", clusterName, topic, partitionID)
+	attrs["entity.guid"] = dt.guidCache.GetOrGenerate("AWS_KAFKA_PARTITION", clusterName, topic+"-"+partitionID)
+
+	attrs["provider"] = "AwsMsk"
+	attrs["awsAccountId"] = dt.config.AWSAccountID
+	attrs["awsRegion"] = dt.config.AWSRegion
+	attrs["providerAccountId"] = dt.config.AWSAccountID
+	attrs["providerExternalId"] = dt.config.AWSAccountID // Required for AWS account mapping
+	attrs["aws.Namespace"] = "AWS/Kafka"
+
 	return dt.stringifyAttributes(attrs)
 }
 
@@ -1033,17 +1909,19 @@ func (dt *DimensionalTransformer) validateGUID(guid string) bool {
 		return false
 	}
 	
-	// Check format: accountID|INFRA|NA|hashInt
+	// Check format: accountID|domain|realm|hashInt
 	parts := strings.Split(string(decoded), "|")
 	if len(parts) != 4 {
 		return false
 	}
-	
-	// Validate parts
-	if parts[1] != "INFRA" || parts[2] != "NA" {
+
+	// Validate parts - domain/realm come from the configured AccountResolver
+	// now rather than always being "INFRA"/"NA", so only their presence is
+	// checked here.
+	if parts[0] == "" || parts[1] == "" || parts[2] == "" {
 		return false
 	}
-	
+
 	// Validate hash is numeric
 	if _, err := strconv.ParseInt(parts[3], 10, 64); err != nil {
 		return false
@@ -1057,23 +1935,46 @@ func (dt *DimensionalTransformer) validateGUID(guid string) bool {
 // ========================================================================================
 
 // generateBrokerGUID generates a consistent entity GUID for a broker
-func (dt *DimensionalTransformer) generateBrokerGUID(clusterName string, brokerId string) string {
-	return dt.guidCache.GetOrGenerate("AWS_KAFKA_BROKER", clusterName, brokerId)
+func (dt *DimensionalTransformer) generateBrokerGUID(clusterName, clusterUUID, brokerId string) string {
+	return dt.guidCache.GetOrGenerate("AWS_KAFKA_BROKER", clusterIdentity(clusterName, clusterUUID), brokerId)
 }
 
 // generateTopicGUID generates a consistent entity GUID for a topic
-func (dt *DimensionalTransformer) generateTopicGUID(clusterName string, topicName string) string {
-	return dt.guidCache.GetOrGenerate("AWS_KAFKA_TOPIC", clusterName, topicName)
+func (dt *DimensionalTransformer) generateTopicGUID(clusterName, clusterUUID, topicName string) string {
+	return dt.guidCache.GetOrGenerate("AWS_KAFKA_TOPIC", clusterIdentity(clusterName, clusterUUID), topicName)
 }
 
 // generateConsumerGroupGUID generates a consistent entity GUID for a consumer group
-func (dt *DimensionalTransformer) generateConsumerGroupGUID(clusterName string, groupId string) string {
-	return dt.guidCache.GetOrGenerate("AWS_KAFKA_CONSUMER_GROUP", clusterName, groupId)
+func (dt *DimensionalTransformer) generateConsumerGroupGUID(clusterName, clusterUUID, groupId string) string {
+	return dt.guidCache.GetOrGenerate("AWS_KAFKA_CONSUMER_GROUP", clusterIdentity(clusterName, clusterUUID), groupId)
 }
 
 // generateClusterGUID generates a consistent entity GUID for a cluster
-func (dt *DimensionalTransformer) generateClusterGUID(clusterName string) string {
-	return dt.guidCache.GetOrGenerate("AWS_KAFKA_CLUSTER", clusterName, "")
+func (dt *DimensionalTransformer) generateClusterGUID(clusterName, clusterUUID string) string {
+	return dt.guidCache.GetOrGenerate("AWS_KAFKA_CLUSTER", clusterIdentity(clusterName, clusterUUID), "")
+}
+
+// clusterIdentity returns the most stable key available for a cluster's
+// GUID cache entry: its ARN-derived UUID when known, so entity.guid stays
+// stable across cluster renames, falling back to the display name when no
+// ARN was present on the sample.
+func clusterIdentity(clusterName, clusterUUID string) string {
+	if clusterUUID != "" {
+		return clusterUUID
+	}
+	return clusterName
+}
+
+// attachLegacyGUID sets entity.guid.legacy on attrs while the GUID cache's
+// legacy-compat window (see Config.GUIDLegacyCompatCycles) is still open
+// for (entityType, identity, resourceID), so New Relic keeps receiving data
+// against an entity an operator already has while the hash-fixed GUID
+// builds up its own history in parallel. A no-op once the window closes or
+// legacy-compat was never enabled.
+func (dt *DimensionalTransformer) attachLegacyGUID(attrs map[string]interface{}, entityType, identity, resourceID string) {
+	if legacy := dt.guidCache.LegacyGUID(entityType, identity, resourceID); legacy != "" {
+		attrs["entity.guid.legacy"] = legacy
+	}
 }
 
 // ========================================================================================
@@ -1091,20 +1992,114 @@ func (dt *DimensionalTransformer) TransformSample(sample map[string]interface{})
 		return nil
 	}
 	
+	var err error
 	switch eventType {
 	case "KafkaBrokerSample":
-		return dt.transformKafkaBrokerSample(sample)
+		err = dt.transformKafkaBrokerSample(sample)
 	case "AwsMskBrokerSample":
-		return dt.transformAwsMskBrokerSample(sample)
+		err = dt.transformAwsMskBrokerSample(sample)
 	case "AwsMskClusterSample":
-		return dt.transformAwsMskClusterSample(sample)
+		err = dt.transformAwsMskClusterSample(sample)
 	case "KafkaTopicSample", "AwsMskTopicSample":
-		return dt.transformTopicSample(sample)
+		err = dt.transformTopicSample(sample)
 	case "KafkaOffsetSample":
-		return dt.transformOffsetSample(sample)
+		err = dt.transformOffsetSample(sample)
+	case "KafkaPartitionReassignmentSample":
+		err = dt.transformPartitionReassignmentSample(sample)
+	default:
+		return nil
+	}
+
+	if err == nil {
+		dt.buildRelationships(eventType, sample)
+	}
+	return err
+}
+
+// clusterUUIDFromSample parses sample's clusterArn, if present and
+// well-formed, and returns just the cluster UUID component - the read-only
+// counterpart to applyClusterArn for callers (like buildRelationships) that
+// only need the UUID and have no attrs map to enrich.
+func (dt *DimensionalTransformer) clusterUUIDFromSample(sample map[string]interface{}) string {
+	arn := getStringValueWithDefault(sample, "clusterArn", "")
+	if arn == "" {
+		return ""
+	}
+	_, _, _, _, clusterUUID, err := parseMskArn(arn)
+	if err != nil {
+		return ""
+	}
+	return clusterUUID
+}
+
+// buildRelationships emits the topology edges implied by a successfully
+// transformed sample - CONTAINS from cluster to broker/topic and topic to
+// partition, and CONSUMES_FROM from consumer group to topic - deduplicating
+// via relationshipCache so the same edge isn't re-sent on every sample.
+func (dt *DimensionalTransformer) buildRelationships(eventType string, sample map[string]interface{}) {
+	clusterName := getStringValueWithDefault(sample, "clusterName", dt.config.ClusterName)
+	clusterUUID := dt.clusterUUIDFromSample(sample)
+	clusterGUID := dt.generateClusterGUID(clusterName, clusterUUID)
+
+	switch eventType {
+	case "KafkaBrokerSample", "AwsMskBrokerSample":
+		brokerId := dt.extractBrokerIdWithFallbacks(sample)
+		if brokerId == "" {
+			return
+		}
+		brokerGUID := dt.generateBrokerGUID(dt.sanitizeEntityName(clusterName), clusterUUID, brokerId)
+		dt.emitRelationship(clusterGUID, brokerGUID, "CONTAINS")
+
+	case "KafkaTopicSample", "AwsMskTopicSample":
+		topic := getStringValueWithDefault(sample, "topic", "")
+		if topic == "" {
+			topic = getStringValueWithDefault(sample, "topicName", "")
+		}
+		if topic == "" {
+			return
+		}
+		topicGUID := dt.generateTopicGUID(clusterName, clusterUUID, topic)
+		dt.emitRelationship(clusterGUID, topicGUID, "CONTAINS")
+
+	case "KafkaOffsetSample":
+		consumerGroup := getStringValueWithDefault(sample, "consumerGroup", "")
+		topic := getStringValueWithDefault(sample, "topic", "")
+		if consumerGroup == "" || topic == "" {
+			return
+		}
+		topicGUID := dt.generateTopicGUID(clusterName, clusterUUID, topic)
+		groupGUID := dt.generateConsumerGroupGUID(clusterName, clusterUUID, consumerGroup)
+		dt.emitRelationship(groupGUID, topicGUID, "CONSUMES_FROM")
+
+	case "KafkaPartitionReassignmentSample":
+		topic := getStringValueWithDefault(sample, "topic", "")
+		if topic == "" {
+			topic = getStringValueWithDefault(sample, "topicName", "")
+		}
+		partitionID := getStringValueWithDefault(sample, "partition", "")
+		if partitionID == "" {
+			partitionID = getStringValueWithDefault(sample, "partitionId", "")
+		}
+		if topic == "" || partitionID == "" {
+			return
+		}
+		topicGUID := dt.generateTopicGUID(clusterName, clusterUUID, topic)
+		partitionGUID := dt.guidCache.GetOrGenerate("AWS_KAFKA_PARTITION", clusterName, topic+"-"+partitionID)
+		dt.emitRelationship(topicGUID, partitionGUID, "CONTAINS")
 	}
-	
-	return nil
+}
+
+// emitRelationship sends one (sourceGUID, targetGUID, relType) edge through
+// the batch collector, skipping it if relationshipCache has already seen it
+// this eviction window.
+func (dt *DimensionalTransformer) emitRelationship(sourceGUID, targetGUID, relType string) {
+	if sourceGUID == "" || targetGUID == "" {
+		return
+	}
+	if dt.relationshipCache.MarkSeen(sourceGUID, targetGUID, relType) {
+		return
+	}
+	dt.batchCollector.AddRelationship(sourceGUID, targetGUID, relType)
 }
 
 // transformKafkaBrokerSample transforms a KafkaBrokerSample into dimensional metrics
@@ -1181,7 +2176,13 @@ func (dt *DimensionalTransformer) transformAwsMskClusterSample(sample map[string
 	
 	// Generate entity GUID if not present
 	if entityGuid == "" {
-		entityGuid = dt.generateClusterGUID(clusterName)
+		clusterUUID := ""
+		if arn := getStringValueWithDefault(sample, "clusterArn", ""); arn != "" {
+			if _, _, _, _, uuid, err := parseMskArn(arn); err == nil {
+				clusterUUID = uuid
+			}
+		}
+		entityGuid = dt.generateClusterGUID(clusterName, clusterUUID)
 	}
 	
 	// Build base attributes
@@ -1254,6 +2255,74 @@ func (dt *DimensionalTransformer) transformOffsetSample(sample map[string]interf
 	return dt.TransformConsumerMetrics(consumerGroup, topic, sample)
 }
 
+// transformPartitionReassignmentSample transforms a
+// KafkaPartitionReassignmentSample - a per-partition KIP-455 snapshot
+// reported directly via the Event API - into an AWS_KAFKA_PARTITION entity
+// plus its reassignment progress metrics. This is a separate path from
+// transformPartitionReassignments, which instead polls a wired-in
+// ReassignmentCollector for a cluster-wide summary; this one trusts
+// whatever the sample itself reports for a single partition.
+func (dt *DimensionalTransformer) transformPartitionReassignmentSample(sample map[string]interface{}) error {
+	topic := getStringValueWithDefault(sample, "topic", "")
+	if topic == "" {
+		topic = getStringValueWithDefault(sample, "topicName", "")
+	}
+	partitionID := getStringValueWithDefault(sample, "partition", "")
+	if partitionID == "" {
+		partitionID = getStringValueWithDefault(sample, "partitionId", "")
+	}
+	if topic == "" || partitionID == "" {
+		log.Warn("Could not extract topic/partition from KafkaPartitionReassignmentSample")
+		return nil
+	}
+
+	attrs := dt.buildPartitionAttributes(topic, partitionID, sample)
+	timestamp := dt.extractTimestamp(sample)
+
+	addingReplicas := parseReplicaIDList(getStringValueWithDefault(sample, "addingReplicas", ""))
+	removingReplicas := parseReplicaIDList(getStringValueWithDefault(sample, "removingReplicas", ""))
+
+	attrs["replica.source.ids"] = joinBrokerIDs(removingReplicas)
+	attrs["replica.target.ids"] = joinBrokerIDs(addingReplicas)
+	attrs["replica.leader.id"] = getStringValueWithDefault(sample, "leaderId", "")
+
+	state := getStringValueWithDefault(sample, "reassignmentState", "")
+	if state == "" {
+		if len(addingReplicas) > 0 || len(removingReplicas) > 0 {
+			state = "in_progress"
+		} else {
+			state = "completed"
+		}
+	}
+	attrs["reassignment.state"] = state
+
+	inProgress := 0.0
+	if state == "in_progress" {
+		inProgress = 1
+	}
+	if validateMetricValue(inProgress, "kafka.partition.ReassignmentInProgress") {
+		dt.emit(&Metric{Name: "kafka.partition.ReassignmentInProgress", Type: "gauge", Value: inProgress, Timestamp: timestamp, Attributes: attrs})
+	}
+
+	if bytesRemaining, ok := getFloatValue(sample, "bytesRemaining"); ok && validateMetricValue(bytesRemaining, "kafka.partition.ReassignmentBytesRemaining") {
+		dt.emit(&Metric{Name: "kafka.partition.ReassignmentBytesRemaining", Type: "gauge", Value: bytesRemaining, Timestamp: timestamp, Attributes: attrs})
+	}
+
+	if addingCount := float64(len(addingReplicas)); validateMetricValue(addingCount, "kafka.partition.AddingReplicas") {
+		dt.emit(&Metric{Name: "kafka.partition.AddingReplicas", Type: "gauge", Value: addingCount, Timestamp: timestamp, Attributes: attrs})
+	}
+
+	if removingCount := float64(len(removingReplicas)); validateMetricValue(removingCount, "kafka.partition.RemovingReplicas") {
+		dt.emit(&Metric{Name: "kafka.partition.RemovingReplicas", Type: "gauge", Value: removingCount, Timestamp: timestamp, Attributes: attrs})
+	}
+
+	if replicaLagMs, ok := getFloatValue(sample, "replicaLagMs"); ok && validateMetricValue(replicaLagMs, "kafka.partition.ReplicaLagMs") {
+		dt.emit(&Metric{Name: "kafka.partition.ReplicaLagMs", Type: "gauge", Value: replicaLagMs, Timestamp: timestamp, Attributes: attrs})
+	}
+
+	return nil
+}
+
 // ========================================================================================
 // LIFECYCLE METHODS
 // ========================================================================================
@@ -1264,7 +2333,52 @@ func (dt *DimensionalTransformer) Flush() error {
 		return nil
 	}
 
-	return dt.batchCollector.Flush()
+	// Drain any Consistent-mode samples that have cleared the replay
+	// window; BestEffort mode already emitted its metrics via emit().
+	for _, ready := range dt.timestampWindow.Flush() {
+		dt.fanOut(ready)
+	}
+
+	return dt.flushExporters()
+}
+
+// emit routes m through the timestamp windower before fanning it out to
+// every configured exporter, preserving the sample's own timestamp instead
+// of stamping wall-clock time. In Consistent mode m may be buffered rather
+// than sent immediately; it is nil-safe so callers can pass a transform
+// function's result directly.
+func (dt *DimensionalTransformer) emit(m *Metric) {
+	if m == nil {
+		return
+	}
+	if ready := dt.timestampWindow.Accept(m); ready != nil {
+		dt.fanOut(ready)
+	}
+}
+
+// fanOut pushes m to every exporter Config.Exporters enabled, so a single
+// collection cycle can push to New Relic and simultaneously feed a local
+// Prometheus scrape or a Kafka archive topic without re-collecting.
+func (dt *DimensionalTransformer) fanOut(m *Metric) {
+	for _, exp := range dt.exporters {
+		exp.AddMetric(m.Name, m.Value, m.Type, m.Timestamp, m.Attributes)
+	}
+}
+
+// flushExporters flushes every configured exporter, logging (rather than
+// aborting on) any individual exporter's failure so one broken sink can't
+// block the others from delivering.
+func (dt *DimensionalTransformer) flushExporters() error {
+	var firstErr error
+	for _, exp := range dt.exporters {
+		if err := exp.Flush(); err != nil {
+			log.Error("Dimensional metrics: %s exporter flush failed: %v", exp.Name(), err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
 }
 
 // Stop stops the dimensional transformer
@@ -1272,6 +2386,12 @@ func (dt *DimensionalTransformer) Stop() {
 	if dt.enabled && dt.batchCollector != nil {
 		dt.batchCollector.Stop()
 	}
+	if dt.persistentGUIDCache != nil {
+		dt.persistentGUIDCache.Stop()
+		if err := dt.persistentGUIDCache.Flush(); err != nil {
+			log.Warn("Dimensional metrics: failed to flush GUID cache snapshot on shutdown: %v", err)
+		}
+	}
 }
 
 // ========================================================================================
@@ -1357,6 +2477,35 @@ func (dt *DimensionalTransformer) getCurrentTimestamp() int64 {
 	return now.UnixNano() / 1e6
 }
 
+// extractTimestamp returns sample's own "timestamp" field (epoch
+// milliseconds, as CloudWatch Metric Streams reports it) rather than
+// wall-clock time, since Metric Streams routinely delivers datapoints
+// 2-5 minutes late and out of order - stamping time.Now() here would
+// discard that information before TimestampWindower ever sees it. Falls
+// back to the current time if the sample carries no timestamp.
+func (dt *DimensionalTransformer) extractTimestamp(sample map[string]interface{}) int64 {
+	if ts, ok := getFloatValue(sample, "timestamp"); ok && ts > 0 {
+		return int64(ts)
+	}
+	return dt.getCurrentTimestamp()
+}
+
+// extractLatestTimestamp returns the newest extractTimestamp among
+// samples, for metrics (like consumer lag) that aggregate several
+// partition-level samples into one emitted metric.
+func (dt *DimensionalTransformer) extractLatestTimestamp(samples []map[string]interface{}) int64 {
+	var latest int64
+	for _, sample := range samples {
+		if ts := dt.extractTimestamp(sample); ts > latest {
+			latest = ts
+		}
+	}
+	if latest == 0 {
+		return dt.getCurrentTimestamp()
+	}
+	return latest
+}
+
 // sanitizeEntityName cleans entity names to remove problematic characters
 func (dt *DimensionalTransformer) sanitizeEntityName(name string) string {
 	// Trim whitespace
@@ -1444,8 +2593,13 @@ type ValidationReport struct {
 	TotalMetrics   int64
 	ValidMetrics   int64
 	InvalidMetrics int64
-	StartTime      time.Time
-	mu             sync.Mutex
+	// CompressedBytes/UncompressedBytes accumulate across every batch
+	// MetricAPIClient has sent, so CompressionRatio reflects the payload
+	// savings Config.MetricAPICompression is actually delivering.
+	CompressedBytes   int64
+	UncompressedBytes int64
+	StartTime         time.Time
+	mu                sync.Mutex
 }
 
 func NewValidationReport() *ValidationReport {
@@ -1468,109 +2622,89 @@ func (vr *ValidationReport) IncrementInvalid() {
 	vr.InvalidMetrics++
 }
 
+// RecordCompression accumulates one batch's compressed and uncompressed
+// payload sizes (both in bytes) into the report's running totals.
+func (vr *ValidationReport) RecordCompression(compressedBytes, uncompressedBytes int64) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	vr.CompressedBytes += compressedBytes
+	vr.UncompressedBytes += uncompressedBytes
+}
+
+// CompressionRatio returns UncompressedBytes/CompressedBytes (>1 means the
+// payload shrank); 0 until at least one compressed batch has been recorded.
+func (vr *ValidationReport) CompressionRatio() float64 {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	if vr.CompressedBytes == 0 {
+		return 0
+	}
+	return float64(vr.UncompressedBytes) / float64(vr.CompressedBytes)
+}
+
 func (vr *ValidationReport) GetSummary() string {
 	vr.mu.Lock()
 	defer vr.mu.Unlock()
-	
+
 	duration := time.Since(vr.StartTime)
 	successRate := float64(0)
 	if vr.TotalMetrics > 0 {
 		successRate = float64(vr.ValidMetrics) / float64(vr.TotalMetrics) * 100
 	}
-	
+	compressionRatio := float64(0)
+	if vr.CompressedBytes > 0 {
+		compressionRatio = float64(vr.UncompressedBytes) / float64(vr.CompressedBytes)
+	}
+
 	return fmt.Sprintf(
-		"Validation Report: Duration=%v, Total=%d, Valid=%d (%.2f%%), Invalid=%d",
-		duration, vr.TotalMetrics, vr.ValidMetrics, successRate, vr.InvalidMetrics,
+		"Validation Report: Duration=%v, Total=%d, Valid=%d (%.2f%%), Invalid=%d, CompressionRatio=%.2fx",
+		duration, vr.TotalMetrics, vr.ValidMetrics, successRate, vr.InvalidMetrics, compressionRatio,
 	)
 }
 
 // ========================================================================================
-// GUID CACHE FOR CONSISTENCY
+// RELATIONSHIP CACHE
 // ========================================================================================
 
-// GUIDCache maintains consistent GUIDs for entities
-type GUIDCache struct {
-	cache map[string]string
-	mu    sync.RWMutex
+// RelationshipCache deduplicates entity-relationship edges so
+// buildRelationships only emits each (source, target, type) edge once per
+// flush interval instead of re-sending it on every sample, following the
+// same bounded-map-with-simple-eviction shape as GUIDCache.
+type RelationshipCache struct {
+	seen    map[string]struct{}
+	mu      sync.Mutex
 	maxSize int
 }
 
-func NewGUIDCache(maxSize int) *GUIDCache {
-	return &GUIDCache{
-		cache: make(map[string]string),
+// NewRelationshipCache creates a cache that holds at most maxSize edges,
+// evicting an arbitrary entry once full - same trade-off GUIDCache makes.
+func NewRelationshipCache(maxSize int) *RelationshipCache {
+	return &RelationshipCache{
+		seen:    make(map[string]struct{}),
 		maxSize: maxSize,
 	}
 }
 
-func (gc *GUIDCache) GetOrGenerate(entityType, clusterName, resourceID string) string {
-	key := fmt.Sprintf("%s:%s:%s", entityType, clusterName, resourceID)
-	
-	// Check cache first
-	gc.mu.RLock()
-	if guid, exists := gc.cache[key]; exists {
-		gc.mu.RUnlock()
-		return guid
-	}
-	gc.mu.RUnlock()
-	
-	// Generate new GUID
-	gc.mu.Lock()
-	defer gc.mu.Unlock()
-	
-	// Double-check after acquiring write lock
-	if guid, exists := gc.cache[key]; exists {
-		return guid
+// MarkSeen records (sourceGUID, targetGUID, relType) as emitted and reports
+// whether it had already been recorded - callers should skip re-emitting
+// the edge when this returns true.
+func (rc *RelationshipCache) MarkSeen(sourceGUID, targetGUID, relType string) bool {
+	key := sourceGUID + "|" + targetGUID + "|" + relType
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if _, exists := rc.seen[key]; exists {
+		return true
 	}
-	
-	// Check cache size
-	if len(gc.cache) >= gc.maxSize {
-		// Simple eviction: remove first item found
-		for k := range gc.cache {
-			delete(gc.cache, k)
+
+	if len(rc.seen) >= rc.maxSize {
+		for k := range rc.seen {
+			delete(rc.seen, k)
 			break
 		}
 	}
-	
-	guid := generateEntityGUID(entityType, clusterName, resourceID)
-	gc.cache[key] = guid
-	
-	return guid
-}
 
-// Helper function for GUID generation (moved from method)
-func generateEntityGUID(entityType, clusterName, resourceID string) string {
-	// Get account ID from environment or use default
-	accountID := os.Getenv("AWS_ACCOUNT_ID")
-	if accountID == "" {
-		accountID = "3630072"
-	}
-	
-	// Entity identifier format is critical
-	var entityIdentifier string
-	switch entityType {
-	case "AWS_KAFKA_BROKER":
-		entityIdentifier = fmt.Sprintf("%s:%s:broker-%s", entityType, clusterName, resourceID)
-	case "AWS_KAFKA_TOPIC":
-		entityIdentifier = fmt.Sprintf("%s:%s:%s", entityType, clusterName, resourceID)
-	case "AWS_KAFKA_CONSUMER_GROUP":
-		entityIdentifier = fmt.Sprintf("%s:%s:%s", entityType, clusterName, resourceID)
-	case "AWS_KAFKA_CLUSTER":
-		entityIdentifier = fmt.Sprintf("%s:%s", entityType, clusterName)
-	}
-	
-	// Hash the identifier
-	hash := sha256.Sum256([]byte(entityIdentifier))
-	hashInt := int64(0)
-	for i := 0; i < 8; i++ {
-		hashInt = (hashInt << 8) | int64(hash[i])
-	}
-	
-	// Make hashInt positive
-	if hashInt < 0 {
-		hashInt = -hashInt
-	}
-	
-	// Format: accountID|INFRA|NA|hashInt
-	guidString := fmt.Sprintf("%s|INFRA|NA|%d", accountID, hashInt)
-	return base64.StdEncoding.EncodeToString([]byte(guidString))
+	rc.seen[key] = struct{}{}
+	return false
 }
\ No newline at end of file
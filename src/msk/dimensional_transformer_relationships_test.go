@@ -0,0 +1,93 @@
+package msk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// relationshipEdges extracts the (source, target, type) triples recorded as
+// relationship metrics in dt's batch collector.
+func relationshipEdges(dt *DimensionalTransformer) [][3]string {
+	var edges [][3]string
+	for _, m := range dt.batchCollector.metrics {
+		if m.Name != relationshipMetricName {
+			continue
+		}
+		edges = append(edges, [3]string{
+			m.Attributes["relationship.source.guid"].(string),
+			m.Attributes["relationship.target.guid"].(string),
+			m.Attributes["relationship.type"].(string),
+		})
+	}
+	return edges
+}
+
+func newTestDimensionalTransformer(t *testing.T) *DimensionalTransformer {
+	t.Setenv("MSK_USE_DIMENSIONAL", "true")
+
+	config := &Config{
+		Enabled:      true,
+		ClusterName:  "test-cluster",
+		AWSAccountID: "123456789012",
+		AWSRegion:    "us-east-1",
+	}
+
+	return NewDimensionalTransformer(nil, config)
+}
+
+func TestBuildRelationships_MixedBatch(t *testing.T) {
+	dt := newTestDimensionalTransformer(t)
+
+	samples := []map[string]interface{}{
+		{
+			"eventType":   "AwsMskBrokerSample",
+			"clusterName": "test-cluster",
+			"brokerId":    "1",
+		},
+		{
+			"eventType":   "AwsMskTopicSample",
+			"clusterName": "test-cluster",
+			"topic":       "orders",
+		},
+		{
+			"eventType":     "KafkaOffsetSample",
+			"clusterName":   "test-cluster",
+			"consumerGroup": "billing-service",
+			"topic":         "orders",
+		},
+	}
+
+	for _, sample := range samples {
+		require.NoError(t, dt.TransformSample(sample))
+	}
+
+	clusterGUID := dt.generateClusterGUID("test-cluster", "")
+	brokerGUID := dt.generateBrokerGUID(dt.sanitizeEntityName("test-cluster"), "", "1")
+	topicGUID := dt.generateTopicGUID("test-cluster", "", "orders")
+	groupGUID := dt.generateConsumerGroupGUID("test-cluster", "", "billing-service")
+
+	edges := relationshipEdges(dt)
+	assert.ElementsMatch(t, [][3]string{
+		{clusterGUID, brokerGUID, "CONTAINS"},
+		{clusterGUID, topicGUID, "CONTAINS"},
+		{groupGUID, topicGUID, "CONSUMES_FROM"},
+	}, edges)
+}
+
+func TestBuildRelationships_DedupesRepeatedEdge(t *testing.T) {
+	dt := newTestDimensionalTransformer(t)
+
+	sample := map[string]interface{}{
+		"eventType":   "AwsMskBrokerSample",
+		"clusterName": "test-cluster",
+		"brokerId":    "1",
+	}
+
+	require.NoError(t, dt.TransformSample(sample))
+	require.NoError(t, dt.TransformSample(sample))
+
+	edges := relationshipEdges(dt)
+	assert.Len(t, edges, 1, "the same edge shouldn't be re-emitted once relationshipCache has seen it")
+}
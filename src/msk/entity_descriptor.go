@@ -0,0 +1,96 @@
+package msk
+
+import (
+	"fmt"
+	"sync"
+)
+
+// EntityDescriptor describes how one entity type's GUID is derived:
+// Type identifies it in the registry (and is what callers pass as
+// generateEntityGUID's entityType), IdentifierFormat renders the string
+// that gets hashed into the GUID (extra carries any fields beyond
+// cluster/resourceID a format needs, e.g. a namespace), and Domain is the
+// New Relic entity domain segment of the GUID ("INFRA" for this
+// integration's own AWS_KAFKA_* entities, "EXT" for most non-AWS vendors).
+type EntityDescriptor interface {
+	Type() string
+	IdentifierFormat(cluster, resourceID string, extra map[string]string) string
+	Domain() string
+}
+
+// EntityDescriptorSchemaVersion identifies the current identifier-format
+// contract across every registered EntityDescriptor. Bump it by hand
+// whenever an IdentifierFormat implementation changes what it hashes, so
+// PersistentGUIDCache can tell a disk snapshot was written under a stale
+// format and rebuild instead of reusing now-incorrect GUIDs.
+const EntityDescriptorSchemaVersion = "v1"
+
+var (
+	entityDescriptorsMu sync.RWMutex
+	entityDescriptors   = make(map[string]EntityDescriptor)
+)
+
+// RegisterEntityDescriptor makes d's entity type available to
+// generateEntityGUID's lookup + format + hash pipeline. Call it from an
+// init(), as the built-in AWS_KAFKA_* descriptors below do, so a downstream
+// integration can add entity types like CONFLUENT_KAFKA_TOPIC or
+// AZURE_EVENTHUB_NAMESPACE without editing this file. Registering under a
+// Type() that's already registered replaces the previous descriptor.
+func RegisterEntityDescriptor(d EntityDescriptor) {
+	entityDescriptorsMu.Lock()
+	defer entityDescriptorsMu.Unlock()
+	entityDescriptors[d.Type()] = d
+}
+
+// lookupEntityDescriptor returns the descriptor registered for entityType,
+// if any.
+func lookupEntityDescriptor(entityType string) (EntityDescriptor, bool) {
+	entityDescriptorsMu.RLock()
+	defer entityDescriptorsMu.RUnlock()
+	d, ok := entityDescriptors[entityType]
+	return d, ok
+}
+
+func init() {
+	RegisterEntityDescriptor(awsKafkaBrokerDescriptor{})
+	RegisterEntityDescriptor(awsKafkaTopicDescriptor{})
+	RegisterEntityDescriptor(awsKafkaConsumerGroupDescriptor{})
+	RegisterEntityDescriptor(awsKafkaClusterDescriptor{})
+}
+
+// awsKafkaBrokerDescriptor is the built-in AWS_KAFKA_BROKER entity type.
+type awsKafkaBrokerDescriptor struct{}
+
+func (awsKafkaBrokerDescriptor) Type() string   { return "AWS_KAFKA_BROKER" }
+func (awsKafkaBrokerDescriptor) Domain() string { return "INFRA" }
+func (d awsKafkaBrokerDescriptor) IdentifierFormat(cluster, resourceID string, extra map[string]string) string {
+	return fmt.Sprintf("%s:%s:broker-%s", d.Type(), cluster, resourceID)
+}
+
+// awsKafkaTopicDescriptor is the built-in AWS_KAFKA_TOPIC entity type.
+type awsKafkaTopicDescriptor struct{}
+
+func (awsKafkaTopicDescriptor) Type() string   { return "AWS_KAFKA_TOPIC" }
+func (awsKafkaTopicDescriptor) Domain() string { return "INFRA" }
+func (d awsKafkaTopicDescriptor) IdentifierFormat(cluster, resourceID string, extra map[string]string) string {
+	return fmt.Sprintf("%s:%s:%s", d.Type(), cluster, resourceID)
+}
+
+// awsKafkaConsumerGroupDescriptor is the built-in AWS_KAFKA_CONSUMER_GROUP
+// entity type.
+type awsKafkaConsumerGroupDescriptor struct{}
+
+func (awsKafkaConsumerGroupDescriptor) Type() string   { return "AWS_KAFKA_CONSUMER_GROUP" }
+func (awsKafkaConsumerGroupDescriptor) Domain() string { return "INFRA" }
+func (d awsKafkaConsumerGroupDescriptor) IdentifierFormat(cluster, resourceID string, extra map[string]string) string {
+	return fmt.Sprintf("%s:%s:%s", d.Type(), cluster, resourceID)
+}
+
+// awsKafkaClusterDescriptor is the built-in AWS_KAFKA_CLUSTER entity type.
+type awsKafkaClusterDescriptor struct{}
+
+func (awsKafkaClusterDescriptor) Type() string   { return "AWS_KAFKA_CLUSTER" }
+func (awsKafkaClusterDescriptor) Domain() string { return "INFRA" }
+func (d awsKafkaClusterDescriptor) IdentifierFormat(cluster, resourceID string, extra map[string]string) string {
+	return fmt.Sprintf("%s:%s", d.Type(), cluster)
+}
@@ -0,0 +1,232 @@
+package msk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// maxEventPayloadBytes is the Event API's per-request uncompressed-body
+// chunking threshold. Batches are split so each gzip-compressed request
+// stays safely under New Relic's 1MB compressed limit.
+const maxEventPayloadBytes = 1 << 20 // 1MB
+
+// eventAPIMaxRetries bounds the exponential backoff loop for 429/5xx
+// responses so a persistently unavailable endpoint can't hang a flush
+// indefinitely.
+const eventAPIMaxRetries = 5
+
+// eventAPIBaseBackoff is the starting delay for the backoff-with-jitter
+// sequence; it doubles on each retry, capped by eventAPIMaxBackoff.
+const eventAPIBaseBackoff = 500 * time.Millisecond
+
+// eventAPIMaxBackoff caps the backoff delay between retries.
+const eventAPIMaxBackoff = 30 * time.Second
+
+// EventAPIClient posts batched MessageQueueSample-style events to the New
+// Relic Insights Events endpoint, compressing and chunking payloads and
+// retrying transient failures with backoff.
+type EventAPIClient struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	submitted int64
+	retried   int64
+	dropped   int64
+}
+
+// NewEventAPIClient creates a new Event API client for the given account.
+func NewEventAPIClient(apiKey string, accountID string) *EventAPIClient {
+	return &EventAPIClient{
+		apiKey:   apiKey,
+		endpoint: fmt.Sprintf("https://insights-collector.newrelic.com/v1/accounts/%s/events", accountID),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// EventAPICounters reports the client's cumulative self-telemetry.
+type EventAPICounters struct {
+	Submitted int64
+	Retried   int64
+	Dropped   int64
+}
+
+// Counters returns a snapshot of the client's submitted/retried/dropped
+// counts, for the shim to surface as self-telemetry.
+func (c *EventAPIClient) Counters() EventAPICounters {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return EventAPICounters{
+		Submitted: c.submitted,
+		Retried:   c.retried,
+		Dropped:   c.dropped,
+	}
+}
+
+// SendEvents gzip-compresses and POSTs events to the Insights Events
+// endpoint, chunking into sub-1MB compressed payloads and retrying 429/5xx
+// responses with exponential backoff and jitter. 413/400 responses are
+// treated as permanently unsendable and dropped with a counter increment
+// rather than retried.
+func (c *EventAPIClient) SendEvents(events []map[string]interface{}) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	for _, chunk := range chunkEvents(events, maxEventPayloadBytes) {
+		if err := c.sendChunk(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkEvents splits events into groups whose marshaled JSON stays under
+// maxBytes, so each group compresses to well under the Event API's 1MB
+// compressed-payload limit.
+func chunkEvents(events []map[string]interface{}, maxBytes int) [][]map[string]interface{} {
+	var chunks [][]map[string]interface{}
+	var current []map[string]interface{}
+	currentSize := 0
+
+	for _, evt := range events {
+		raw, err := json.Marshal(evt)
+		if err != nil {
+			log.Error("EventAPIClient: failed to marshal event, dropping: %v", err)
+			continue
+		}
+
+		if len(current) > 0 && currentSize+len(raw) > maxBytes {
+			chunks = append(chunks, current)
+			current = nil
+			currentSize = 0
+		}
+
+		current = append(current, evt)
+		currentSize += len(raw)
+	}
+
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// sendChunk sends a single chunk, retrying 429/5xx with backoff and jitter
+// and dropping 413/400 responses without retrying.
+func (c *EventAPIClient) sendChunk(events []map[string]interface{}) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event chunk: %v", err)
+	}
+
+	compressed, err := gzipCompress(body)
+	if err != nil {
+		return fmt.Errorf("failed to gzip event chunk: %v", err)
+	}
+
+	backoff := eventAPIBaseBackoff
+	for attempt := 0; attempt <= eventAPIMaxRetries; attempt++ {
+		req, err := http.NewRequest("POST", c.endpoint, bytes.NewReader(compressed))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		req.Header.Set("Api-Key", c.apiKey)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			log.Error("EventAPIClient: request failed: %v", err)
+			if attempt == eventAPIMaxRetries {
+				return fmt.Errorf("failed to send events: %v", err)
+			}
+			c.mu.Lock()
+			c.retried++
+			c.mu.Unlock()
+			sleepWithJitter(backoff)
+			backoff = nextBackoff(backoff)
+			continue
+		}
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusAccepted:
+			c.mu.Lock()
+			c.submitted += int64(len(events))
+			c.mu.Unlock()
+			return nil
+
+		case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusRequestEntityTooLarge:
+			log.Error("EventAPIClient: dropping %d events - status %d", len(events), resp.StatusCode)
+			c.mu.Lock()
+			c.dropped += int64(len(events))
+			c.mu.Unlock()
+			return nil
+
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+			if attempt == eventAPIMaxRetries {
+				log.Error("EventAPIClient: exhausted retries, dropping %d events - status %d", len(events), resp.StatusCode)
+				c.mu.Lock()
+				c.dropped += int64(len(events))
+				c.mu.Unlock()
+				return fmt.Errorf("event API returned status %d after %d retries", resp.StatusCode, attempt)
+			}
+			c.mu.Lock()
+			c.retried++
+			c.mu.Unlock()
+			sleepWithJitter(backoff)
+			backoff = nextBackoff(backoff)
+			continue
+
+		default:
+			c.mu.Lock()
+			c.dropped += int64(len(events))
+			c.mu.Unlock()
+			return fmt.Errorf("event API returned unexpected status %d", resp.StatusCode)
+		}
+	}
+
+	return nil
+}
+
+// nextBackoff doubles d, capped at eventAPIMaxBackoff.
+func nextBackoff(d time.Duration) time.Duration {
+	next := d * 2
+	if next > eventAPIMaxBackoff {
+		return eventAPIMaxBackoff
+	}
+	return next
+}
+
+// sleepWithJitter sleeps for d plus up to 50% additional random jitter, to
+// avoid synchronized retry storms across multiple shim instances.
+func sleepWithJitter(d time.Duration) {
+	jitter := time.Duration(rand.Int63n(int64(d) / 2))
+	time.Sleep(d + jitter)
+}
+
+// gzipCompress compresses data using gzip at default compression level.
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		gz.Close()
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
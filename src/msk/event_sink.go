@@ -0,0 +1,355 @@
+package msk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// EventSink is implemented by anything the MSK shim can submit a finished
+// sample event to: the New Relic infra-integrations SDK entity model, the
+// Event API, or an OTLP metrics receiver. event's "eventType" key selects
+// the sample/metric name; every other key is either a numeric value or a
+// string attribute. Multiple sinks can be combined with MultiSink for
+// side-by-side migration between backends.
+type EventSink interface {
+	Submit(event map[string]interface{}) error
+	Flush() error
+}
+
+// EmitBackend selects which EventSink implementation(s) Config wires up.
+type EmitBackend string
+
+const (
+	EmitBackendInfra      EmitBackend = "infra"
+	EmitBackendEvents     EmitBackend = "events"
+	EmitBackendOTLP       EmitBackend = "otlp"
+	EmitBackendCloudWatch EmitBackend = "cloudwatch"
+)
+
+// ParseEmitBackends splits a comma-separated MSK_EMIT_BACKEND value (e.g.
+// "infra,events") into its individual backends, defaulting to
+// EmitBackendInfra alone when raw is empty so existing deployments keep
+// their current behavior.
+func ParseEmitBackends(raw string) []EmitBackend {
+	if strings.TrimSpace(raw) == "" {
+		return []EmitBackend{EmitBackendInfra}
+	}
+
+	parts := strings.Split(raw, ",")
+	backends := make([]EmitBackend, 0, len(parts))
+	for _, part := range parts {
+		backend := EmitBackend(strings.TrimSpace(part))
+		if backend == "" {
+			continue
+		}
+		backends = append(backends, backend)
+	}
+	if len(backends) == 0 {
+		return []EmitBackend{EmitBackendInfra}
+	}
+	return backends
+}
+
+// InfraSDKSink submits events as infra-integrations SDK entity metric
+// sets: numeric values, and booleans as 0/1, become GAUGE metrics,
+// everything else becomes a string attribute on the metric set. Earlier behavior silently dropped
+// non-numeric values into inventory, which isn't queryable the way
+// metric-set attributes are - this sink fixes that.
+type InfraSDKSink struct {
+	integration *integration.Integration
+	entityType  string
+}
+
+// NewInfraSDKSink wraps an already-initialized integration.
+func NewInfraSDKSink(i *integration.Integration) *InfraSDKSink {
+	return &InfraSDKSink{integration: i, entityType: "aws-msk"}
+}
+
+// Submit creates/reuses an entity named by event["entityName"] (falling
+// back to the eventType itself) and records every other key as either a
+// GAUGE metric or an ATTRIBUTE, depending on its type.
+func (s *InfraSDKSink) Submit(event map[string]interface{}) error {
+	eventType, _ := event["eventType"].(string)
+	if eventType == "" {
+		return fmt.Errorf("event missing eventType")
+	}
+
+	entityName, _ := event["entityName"].(string)
+	if entityName == "" {
+		entityName = eventType
+	}
+
+	entity, err := s.integration.Entity(entityName, s.entityType)
+	if err != nil {
+		return fmt.Errorf("creating entity %s: %w", entityName, err)
+	}
+
+	ms := entity.NewMetricSet(eventType)
+
+	for key, value := range event {
+		if key == "eventType" || key == "entityName" {
+			continue
+		}
+
+		switch v := value.(type) {
+		case float64:
+			ms.SetMetric(key, v, metric.GAUGE)
+		case float32:
+			ms.SetMetric(key, float64(v), metric.GAUGE)
+		case int:
+			ms.SetMetric(key, float64(v), metric.GAUGE)
+		case int32:
+			ms.SetMetric(key, float64(v), metric.GAUGE)
+		case int64:
+			ms.SetMetric(key, float64(v), metric.GAUGE)
+		case bool:
+			ms.SetMetric(key, boolToFloat(v), metric.GAUGE)
+		case string:
+			ms.SetMetric(key, v, metric.ATTRIBUTE)
+		default:
+			ms.SetMetric(key, fmt.Sprintf("%v", v), metric.ATTRIBUTE)
+		}
+	}
+
+	return nil
+}
+
+// Flush is a no-op: the infra-integrations SDK publishes entities as they
+// are created, with no separate batch-flush step of its own.
+func (s *InfraSDKSink) Flush() error {
+	return nil
+}
+
+// EventAPISink submits events through a BatchProcessor, which batches per
+// event type per Config.BatchSize/FlushInterval and POSTs to the Event API
+// via EventAPIClient.
+type EventAPISink struct {
+	processor *BatchProcessor
+}
+
+// NewEventAPISink wraps an already-running BatchProcessor.
+func NewEventAPISink(processor *BatchProcessor) *EventAPISink {
+	return &EventAPISink{processor: processor}
+}
+
+// Submit enqueues event onto the batch processor, keyed by its eventType.
+func (s *EventAPISink) Submit(event map[string]interface{}) error {
+	eventType, _ := event["eventType"].(string)
+	if eventType == "" {
+		return fmt.Errorf("event missing eventType")
+	}
+	s.processor.Add(eventType, event)
+	return nil
+}
+
+// Flush forces an immediate flush of every pending batch rather than
+// waiting for the processor's own flush interval or size trigger.
+func (s *EventAPISink) Flush() error {
+	s.processor.FlushAll()
+	return nil
+}
+
+// OTLPSinkConfig configures OTLPSink's resource attributes and target
+// endpoint.
+type OTLPSinkConfig struct {
+	Endpoint    string
+	ClusterName string
+	AWSRegion   string
+}
+
+// otlpDataPoint is one numeric value queued for the next Flush.
+type otlpDataPoint struct {
+	Name  string
+	Value float64
+}
+
+// OTLPSink maps submitted events to OTLP metrics and POSTs them to an
+// OTLP/HTTP metrics receiver, tagging every data point with the resource
+// attributes the OpenTelemetry messaging semantic conventions expect:
+// cloud.provider=aws, cloud.region, messaging.system=kafka, and
+// aws.msk.cluster.name.
+type OTLPSink struct {
+	config     OTLPSinkConfig
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	metrics []otlpDataPoint
+}
+
+// NewOTLPSink creates an OTLPSink targeting config.Endpoint.
+func NewOTLPSink(config OTLPSinkConfig) *OTLPSink {
+	return &OTLPSink{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Submit queues every numeric value in event as "<eventType>.<key>"; string
+// attributes are dropped since OTLP metric data points carry no attribute
+// of their own kind that would fit this shape.
+func (s *OTLPSink) Submit(event map[string]interface{}) error {
+	eventType, _ := event["eventType"].(string)
+	if eventType == "" {
+		return fmt.Errorf("event missing eventType")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, value := range event {
+		if key == "eventType" || key == "entityName" {
+			continue
+		}
+		floatVal, ok := eventValueToFloat64(value)
+		if !ok {
+			continue
+		}
+		s.metrics = append(s.metrics, otlpDataPoint{Name: eventType + "." + key, Value: floatVal})
+	}
+
+	return nil
+}
+
+// Flush POSTs every queued data point as a single OTLP/HTTP metrics
+// export request.
+func (s *OTLPSink) Flush() error {
+	s.mu.Lock()
+	points := s.metrics
+	s.metrics = nil
+	s.mu.Unlock()
+
+	if len(points) == 0 {
+		return nil
+	}
+
+	payload := s.buildOTLPPayload(points)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling OTLP payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", s.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending OTLP metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("OTLP endpoint returned status %d", resp.StatusCode)
+	}
+
+	log.Debug("OTLPSink: flushed %d data points to %s", len(points), s.config.Endpoint)
+	return nil
+}
+
+// buildOTLPPayload renders points as an OTLP/HTTP JSON ExportMetricsServiceRequest.
+func (s *OTLPSink) buildOTLPPayload(points []otlpDataPoint) map[string]interface{} {
+	resourceAttrs := []map[string]interface{}{
+		{"key": "cloud.provider", "value": map[string]interface{}{"stringValue": "aws"}},
+		{"key": "cloud.region", "value": map[string]interface{}{"stringValue": s.config.AWSRegion}},
+		{"key": "messaging.system", "value": map[string]interface{}{"stringValue": "kafka"}},
+		{"key": "aws.msk.cluster.name", "value": map[string]interface{}{"stringValue": s.config.ClusterName}},
+	}
+
+	timeUnixNano := strconv.FormatInt(time.Now().UnixNano(), 10)
+	metrics := make([]map[string]interface{}, 0, len(points))
+	for _, p := range points {
+		metrics = append(metrics, map[string]interface{}{
+			"name": p.Name,
+			"gauge": map[string]interface{}{
+				"dataPoints": []map[string]interface{}{
+					{"asDouble": p.Value, "timeUnixNano": timeUnixNano},
+				},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"resourceMetrics": []map[string]interface{}{
+			{
+				"resource": map[string]interface{}{"attributes": resourceAttrs},
+				"scopeMetrics": []map[string]interface{}{
+					{"metrics": metrics},
+				},
+			},
+		},
+	}
+}
+
+// eventValueToFloat64 converts the handful of numeric types a flattened event map
+// may carry into a float64, reporting false for anything else (notably
+// strings and bools, which OTLPSink has no data-point shape for).
+func eventValueToFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// MultiSink fans a single Submit/Flush call out to multiple EventSinks, so
+// a new backend can be verified side-by-side with the existing one before
+// cutting over.
+type MultiSink struct {
+	sinks []EventSink
+}
+
+// NewMultiSink combines sinks into a single EventSink.
+func NewMultiSink(sinks ...EventSink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+// Submit calls Submit on every sink, continuing past individual failures
+// and returning the first error encountered, if any.
+func (m *MultiSink) Submit(event map[string]interface{}) error {
+	var firstErr error
+	for _, sink := range m.sinks {
+		if err := sink.Submit(event); err != nil {
+			log.Error("MultiSink: sink failed to submit event: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Flush calls Flush on every sink, continuing past individual failures
+// and returning the first error encountered, if any.
+func (m *MultiSink) Flush() error {
+	var firstErr error
+	for _, sink := range m.sinks {
+		if err := sink.Flush(); err != nil {
+			log.Error("MultiSink: sink failed to flush: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,42 @@
+package msk
+
+// Exporter is implemented by anything DimensionalTransformer can fan a
+// finished metric out to: the existing MetricAPIClient-backed
+// BatchCollector, a local Prometheus scrape endpoint (PrometheusExporter),
+// or a Kafka archive topic (KafkaExporter). Config.Exporters selects which
+// combination runs, so a single collection cycle can push to New Relic and
+// simultaneously feed a local Prometheus scrape or a Kafka archive topic
+// without re-collecting.
+type Exporter interface {
+	// AddMetric buffers one metric for this exporter's own idea of a batch.
+	// metricType carries the Metric.Type ("gauge"/"count"/"summary") and
+	// timestamp the sample's own epoch-millisecond time, which
+	// BatchCollector.AddMetricWithTimestamp also threads through (it takes no
+	// metric type, so batchCollectorExporter does not forward metricType).
+	AddMetric(name string, value float64, metricType string, timestamp int64, attrs map[string]interface{})
+	// Flush pushes any buffered metrics to this exporter's backend. Pull-based
+	// exporters (PrometheusExporter) can treat this as a no-op.
+	Flush() error
+	// Name identifies the exporter in logs and Config.Exporters entries.
+	Name() string
+}
+
+// batchCollectorExporter adapts the pre-existing BatchCollector (the New
+// Relic Metric API push path) to the Exporter interface, so it can be
+// fanned out to alongside PrometheusExporter/KafkaExporter without any
+// changes to its own call sites or behavior.
+type batchCollectorExporter struct {
+	collector *BatchCollector
+}
+
+func (e *batchCollectorExporter) AddMetric(name string, value float64, metricType string, timestamp int64, attrs map[string]interface{}) {
+	e.collector.AddMetricWithTimestamp(name, value, timestamp, attrs)
+}
+
+func (e *batchCollectorExporter) Flush() error {
+	return e.collector.Flush()
+}
+
+func (e *batchCollectorExporter) Name() string {
+	return "newrelic"
+}
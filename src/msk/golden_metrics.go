@@ -0,0 +1,122 @@
+package msk
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GoldenMetric describes one golden metric the way New Relic's
+// entity-definitions repo expects: a title, unit, and the NRQL query that
+// computes it for a given entity.GUID, so the AwsMsk* entities this shim
+// synthesizes line up with New Relic's built-in Message Queues & Streams
+// dashboards instead of requiring a bespoke dashboard per customer.
+type GoldenMetric struct {
+	Name  string
+	Title string
+	Unit  string
+	Query string
+}
+
+// goldenMetricsByEntityType holds the golden-metric set for each MSK
+// entity type this shim emits.
+var goldenMetricsByEntityType = map[EntityType][]GoldenMetric{
+	EntityTypeCluster: {
+		{
+			Name:  "activeControllerCount",
+			Title: "Active controller count",
+			Unit:  "COUNT",
+			Query: "SELECT latest(provider.activeControllerCount.Sum) FROM AwsMskClusterSample WHERE entity.guid = '{entity.guid}'",
+		},
+		{
+			Name:  "offlinePartitionsCount",
+			Title: "Offline partitions",
+			Unit:  "COUNT",
+			Query: "SELECT latest(provider.offlinePartitionsCount.Sum) FROM AwsMskClusterSample WHERE entity.guid = '{entity.guid}'",
+		},
+		{
+			Name:  "globalPartitionCount",
+			Title: "Partitions",
+			Unit:  "COUNT",
+			Query: "SELECT latest(provider.globalPartitionCount) FROM AwsMskClusterSample WHERE entity.guid = '{entity.guid}'",
+		},
+	},
+	EntityTypeBroker: {
+		{
+			Name:  "bytesInPerSec",
+			Title: "Bytes in/sec",
+			Unit:  "BYTES_PER_SECOND",
+			Query: "SELECT average(provider.bytesInPerSec.Average) FROM AwsMskBrokerSample WHERE entity.guid = '{entity.guid}'",
+		},
+		{
+			Name:  "bytesOutPerSec",
+			Title: "Bytes out/sec",
+			Unit:  "BYTES_PER_SECOND",
+			Query: "SELECT average(provider.bytesOutPerSec.Average) FROM AwsMskBrokerSample WHERE entity.guid = '{entity.guid}'",
+		},
+		{
+			Name:  "underReplicatedPartitions",
+			Title: "Under-replicated partitions",
+			Unit:  "COUNT",
+			Query: "SELECT latest(provider.underReplicatedPartitions.Sum) FROM AwsMskBrokerSample WHERE entity.guid = '{entity.guid}'",
+		},
+	},
+	EntityTypeTopic: {
+		{
+			Name:  "messagesInPerSec",
+			Title: "Messages in/sec",
+			Unit:  "COUNT_PER_SECOND",
+			Query: "SELECT average(provider.messagesInPerSec.Average) FROM AwsMskTopicSample WHERE entity.guid = '{entity.guid}'",
+		},
+		{
+			Name:  "maxOffsetLag",
+			Title: "Max consumer lag",
+			Unit:  "COUNT",
+			Query: "SELECT max(aws.msk.topic.MaxOffsetLag) FROM AwsMskTopicSample WHERE entity.guid = '{entity.guid}'",
+		},
+	},
+}
+
+// GoldenMetricsFor returns the golden metric set for entityType, or nil if
+// none is defined.
+func GoldenMetricsFor(entityType EntityType) []GoldenMetric {
+	return goldenMetricsByEntityType[entityType]
+}
+
+// ExportGoldenMetricsYAML renders the golden-metric set for entityType in
+// the golden_metrics.yml shape entity-definitions expects, for a maintainer
+// to paste into that repo once a new MSK entity type is added here.
+func ExportGoldenMetricsYAML(entityType EntityType) string {
+	metrics := GoldenMetricsFor(entityType)
+	if len(metrics) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "%s:\n", m.Name)
+		fmt.Fprintf(&b, "  title: %s\n", m.Title)
+		fmt.Fprintf(&b, "  unit: %s\n", m.Unit)
+		fmt.Fprintf(&b, "  queries:\n")
+		fmt.Fprintf(&b, "    default:\n")
+		fmt.Fprintf(&b, "      select: \"%s\"\n", m.Query)
+	}
+	return b.String()
+}
+
+// serveGoldenMetrics renders the golden_metrics.yml snippet for the
+// entityType named by the ?entityType= query parameter (e.g.
+// AWSMSKCLUSTER, AWSMSKBROKER, AWSMSKTOPIC -- see EntityType), so a
+// maintainer adding a new MSK dashboard can fetch it straight from a
+// running integration (when PromExportEnabled) instead of hand-copying
+// GoldenMetric literals out of this file.
+func serveGoldenMetrics(w http.ResponseWriter, r *http.Request) {
+	entityType := EntityType(r.URL.Query().Get("entityType"))
+	yaml := ExportGoldenMetricsYAML(entityType)
+	if yaml == "" {
+		http.Error(w, fmt.Sprintf("no golden metrics defined for entityType %q", entityType), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/yaml; charset=utf-8")
+	w.Write([]byte(yaml))
+}
@@ -9,11 +9,25 @@ import (
 type EntityType string
 
 const (
-	EntityTypeCluster EntityType = "AWSMSKCLUSTER"
-	EntityTypeBroker  EntityType = "AWSMSKBROKER"
-	EntityTypeTopic   EntityType = "AWSMSKTOPIC"
+	EntityTypeCluster       EntityType = "AWSMSKCLUSTER"
+	EntityTypeBroker        EntityType = "AWSMSKBROKER"
+	EntityTypeTopic         EntityType = "AWSMSKTOPIC"
+	EntityTypePartition     EntityType = "AWSMSKTOPICPARTITION"
+	EntityTypeReassignment  EntityType = "AWSMSKREASSIGNMENT"
+	EntityTypeVolume        EntityType = "AWSMSKVOLUME"
+	EntityTypeConsumerGroup EntityType = "AWSMSKCONSUMERGROUP"
 )
 
+// VolumeIdentifier addresses a single broker log directory, the unit AWS
+// MSK's Message Queues UI surfaces per-volume storage health for. AWS MSK
+// deployments commonly have multiple EBS volumes per broker, so brokerID
+// alone isn't a unique identifier; it must be paired with the log
+// directory path.
+type VolumeIdentifier struct {
+	BrokerID   int32
+	LogDirPath string
+}
+
 // GenerateEntityGUID generates a New Relic entity GUID in the format expected by the Message Queues UI
 // Format: accountId|INFRA|entityType|base64(identifier)
 func GenerateEntityGUID(entityType EntityType, accountID, clusterName string, additional interface{}) string {
@@ -26,6 +40,22 @@ func GenerateEntityGUID(entityType EntityType, accountID, clusterName string, ad
 		identifier = fmt.Sprintf("%s:%s:%v", clusterName, accountID, additional)
 	case EntityTypeTopic:
 		identifier = fmt.Sprintf("%s:%s:%v", clusterName, accountID, additional)
+	case EntityTypePartition:
+		identifier = fmt.Sprintf("%s:%s:%v", clusterName, accountID, additional)
+	case EntityTypeReassignment:
+		// additional is expected to be a "topic:partition" identifier.
+		identifier = fmt.Sprintf("%s:%s:%v", clusterName, accountID, additional)
+	case EntityTypeVolume:
+		// additional is expected to be a VolumeIdentifier.
+		if vol, ok := additional.(VolumeIdentifier); ok {
+			identifier = fmt.Sprintf("%s:%s:%d:%s", clusterName, accountID, vol.BrokerID, vol.LogDirPath)
+		} else {
+			identifier = fmt.Sprintf("%s:%s:%v", clusterName, accountID, additional)
+		}
+	case EntityTypeConsumerGroup:
+		// additional is expected to be the consumer group ID, or a
+		// "groupID:topic" identifier for a per-group-topic entity.
+		identifier = fmt.Sprintf("%s:%s:%v", clusterName, accountID, additional)
 	}
 
 	// Format: accountId|INFRA|entityType|base64(identifier)
@@ -0,0 +1,349 @@
+package msk
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// guidCacheEntry is one cached entity GUID, held in the LRU list's element
+// Value so promoting/evicting it only touches the list, not a second map.
+type guidCacheEntry struct {
+	key       string
+	guid      string
+	expiresAt time.Time // zero means the entry never expires on its own
+}
+
+// GUIDCacheStats is a point-in-time snapshot of a GUIDCache's cumulative
+// hit/miss/eviction counters, for operators wiring cache health into their
+// own agent telemetry via WithMetricsHook.
+type GUIDCacheStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+}
+
+// GUIDCacheOption configures a GUIDCache at construction time.
+type GUIDCacheOption func(*GUIDCache)
+
+// WithTTL expires a cached GUID ttl after it was generated, regardless of
+// how recently it was last accessed, so a stale consumer-group entity
+// doesn't pin its GUID in memory forever. Zero (the default) disables
+// TTL-based expiry; eviction then only happens on size pressure.
+func WithTTL(ttl time.Duration) GUIDCacheOption {
+	return func(gc *GUIDCache) { gc.ttl = ttl }
+}
+
+// WithMaxSize overrides the cache's maximum resident entry count, passed to
+// NewGUIDCache.
+func WithMaxSize(n int) GUIDCacheOption {
+	return func(gc *GUIDCache) { gc.maxSize = n }
+}
+
+// WithMetricsHook registers fn to be called after every GetOrGenerate with
+// the cache's current Stats(), so operators can feed cache health into
+// their own telemetry pipeline without having to poll Stats() themselves.
+func WithMetricsHook(fn func(GUIDCacheStats)) GUIDCacheOption {
+	return func(gc *GUIDCache) { gc.metricsHook = fn }
+}
+
+// WithAccountResolver overrides the AccountResolver generateEntityGUID uses
+// to resolve each GUID's account/domain/realm scope. The default chains
+// EnvAccountResolver then IMDSAccountResolver.
+func WithAccountResolver(r AccountResolver) GUIDCacheOption {
+	return func(gc *GUIDCache) { gc.accountResolver = r }
+}
+
+// WithLegacyGUIDCompat opens a migration window of cycles calls per cache
+// key during which LegacyGUID keeps returning the pre-chunk13-5 GUID
+// alongside GetOrGenerate's corrected one, so operators can dual-write
+// entity.guid and entity.guid.legacy instead of orphaning whatever history
+// New Relic already has under the old, buggy GUID. cycles <= 0 (the
+// default) disables legacy-compat entirely: LegacyGUID always returns "".
+func WithLegacyGUIDCompat(cycles int) GUIDCacheOption {
+	return func(gc *GUIDCache) { gc.legacyCompatCycles = cycles }
+}
+
+// GUIDCache maintains consistent entity GUIDs, evicting the least recently
+// used entry once full and, if WithTTL is set, regenerating any entry past
+// its TTL - so long-lived broker entities stay resident while stale
+// consumer-group entries expire on their own instead of waiting for size
+// pressure to push them out.
+type GUIDCache struct {
+	mu      sync.Mutex
+	maxSize int
+	ttl     time.Duration
+
+	entries map[string]*list.Element
+	order   *list.List // front = most recently used, back = least recently used
+
+	stats       GUIDCacheStats
+	metricsHook func(GUIDCacheStats)
+
+	accountResolver AccountResolver
+
+	legacyCompatCycles int
+	legacyRemaining    map[string]int
+}
+
+// NewGUIDCache creates a cache holding at most maxSize entities with no TTL
+// by default; pass GUIDCacheOption values (WithTTL, WithMaxSize,
+// WithMetricsHook) to override either.
+func NewGUIDCache(maxSize int, opts ...GUIDCacheOption) *GUIDCache {
+	gc := &GUIDCache{
+		maxSize:         maxSize,
+		entries:         make(map[string]*list.Element),
+		order:           list.New(),
+		legacyRemaining: make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(gc)
+	}
+	if gc.accountResolver == nil {
+		gc.accountResolver = newDefaultAccountResolver()
+	}
+	return gc
+}
+
+// GetOrGenerate returns the cached GUID for (entityType, clusterName,
+// resourceID), generating and caching one via generateEntityGUID on a miss
+// (including a TTL-expired hit) and touching the entry's LRU recency either
+// way.
+func (gc *GUIDCache) GetOrGenerate(entityType, clusterName, resourceID string) string {
+	key := fmt.Sprintf("%s:%s:%s", entityType, clusterName, resourceID)
+
+	gc.mu.Lock()
+
+	if el, ok := gc.entries[key]; ok {
+		entry := el.Value.(*guidCacheEntry)
+		if entry.expiresAt.IsZero() || time.Now().Before(entry.expiresAt) {
+			gc.order.MoveToFront(el)
+			gc.stats.Hits++
+			guid := entry.guid
+			stats, hook := gc.stats, gc.metricsHook
+			gc.mu.Unlock()
+			notifyMetricsHook(hook, stats)
+			return guid
+		}
+		gc.removeLocked(el)
+		gc.stats.Evictions++
+	}
+
+	gc.stats.Misses++
+
+	for gc.maxSize > 0 && len(gc.entries) >= gc.maxSize {
+		gc.evictOldestLocked()
+	}
+
+	guid, err := generateEntityGUID(gc.accountResolver, entityType, clusterName, resourceID)
+	if err != nil {
+		stats, hook := gc.stats, gc.metricsHook
+		gc.mu.Unlock()
+		notifyMetricsHook(hook, stats)
+		log.Error("GUIDCache: failed to generate GUID for %s %s/%s: %v", entityType, clusterName, resourceID, err)
+		return ""
+	}
+
+	entry := &guidCacheEntry{key: key, guid: guid}
+	if gc.ttl > 0 {
+		entry.expiresAt = time.Now().Add(gc.ttl)
+	}
+	gc.entries[key] = gc.order.PushFront(entry)
+
+	stats, hook := gc.stats, gc.metricsHook
+	gc.mu.Unlock()
+	notifyMetricsHook(hook, stats)
+	return guid
+}
+
+// evictOldestLocked removes the least recently used entry; callers must
+// hold gc.mu.
+func (gc *GUIDCache) evictOldestLocked() {
+	oldest := gc.order.Back()
+	if oldest == nil {
+		return
+	}
+	gc.removeLocked(oldest)
+	gc.stats.Evictions++
+}
+
+// removeLocked detaches el from both the LRU list and the entry map;
+// callers must hold gc.mu and account for Evictions themselves.
+func (gc *GUIDCache) removeLocked(el *list.Element) {
+	entry := el.Value.(*guidCacheEntry)
+	gc.order.Remove(el)
+	delete(gc.entries, entry.key)
+}
+
+// seed inserts key->guid as the most recently used entry without counting
+// it as a hit or miss, for a PersistentGUIDCache restoring entries a prior
+// process had already generated.
+func (gc *GUIDCache) seed(key, guid string) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	if gc.maxSize > 0 && len(gc.entries) >= gc.maxSize {
+		return
+	}
+	entry := &guidCacheEntry{key: key, guid: guid}
+	if gc.ttl > 0 {
+		entry.expiresAt = time.Now().Add(gc.ttl)
+	}
+	gc.entries[key] = gc.order.PushFront(entry)
+}
+
+// LegacyGUID returns the pre-chunk13-5 GUID for (entityType, clusterName,
+// resourceID) while that key's legacy-compat window (see
+// WithLegacyGUIDCompat) is still open, and "" once it's exhausted or
+// legacy-compat was never enabled. Callers pair it with GetOrGenerate's
+// corrected GUID as entity.guid/entity.guid.legacy attributes during a
+// migration window.
+func (gc *GUIDCache) LegacyGUID(entityType, clusterName, resourceID string) string {
+	if gc.legacyCompatCycles <= 0 {
+		return ""
+	}
+	key := fmt.Sprintf("%s:%s:%s", entityType, clusterName, resourceID)
+
+	gc.mu.Lock()
+	remaining, ok := gc.legacyRemaining[key]
+	if !ok {
+		remaining = gc.legacyCompatCycles
+	}
+	if remaining <= 0 {
+		gc.mu.Unlock()
+		return ""
+	}
+	gc.legacyRemaining[key] = remaining - 1
+	gc.mu.Unlock()
+
+	return legacyEntityGUID(entityType, clusterName, resourceID)
+}
+
+// snapshot returns a copy of every currently cached key->GUID pair, for a
+// PersistentGUIDCache to hand to its CacheBackend.
+func (gc *GUIDCache) snapshot() map[string]string {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	entries := make(map[string]string, len(gc.entries))
+	for key, el := range gc.entries {
+		entries[key] = el.Value.(*guidCacheEntry).guid
+	}
+	return entries
+}
+
+// Purge removes every cached entry, resetting LRU order but leaving the
+// cumulative Stats() counters untouched.
+func (gc *GUIDCache) Purge() {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	gc.entries = make(map[string]*list.Element)
+	gc.order.Init()
+}
+
+// Stats returns a point-in-time snapshot of the cache's cumulative
+// hit/miss/eviction counters.
+func (gc *GUIDCache) Stats() GUIDCacheStats {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	return gc.stats
+}
+
+// notifyMetricsHook invokes hook with stats if one is registered; called
+// outside gc.mu so a hook that calls back into the cache can't deadlock it.
+func notifyMetricsHook(hook func(GUIDCacheStats), stats GUIDCacheStats) {
+	if hook == nil {
+		return
+	}
+	hook(stats)
+}
+
+// generateEntityGUID deterministically derives an entity GUID from
+// (entityType, clusterName, resourceID), matching the New Relic entity GUID
+// format ("accountId|domain|realm|hash"). The account ID, domain, and realm
+// come from resolver (see AccountResolver) rather than a hardcoded
+// AWS_ACCOUNT_ID-or-"3630072" fallback, so a misconfigured deployment fails
+// loudly instead of emitting a GUID scoped to someone else's account; a nil
+// resolver falls back to newDefaultAccountResolver. entityType is looked up
+// in the EntityDescriptor registry for its identifier format and, if one is
+// set, a domain override; an unregistered entityType hashes an empty
+// identifier, matching this function's behavior before the registry
+// existed.
+func generateEntityGUID(resolver AccountResolver, entityType, clusterName, resourceID string) (string, error) {
+	if resolver == nil {
+		resolver = newDefaultAccountResolver()
+	}
+
+	accountID, domain, realm, err := resolver.Resolve(context.Background(), entityType, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("resolving account for entity type %s: %w", entityType, err)
+	}
+
+	var entityIdentifier string
+	if d, ok := lookupEntityDescriptor(entityType); ok {
+		entityIdentifier = d.IdentifierFormat(clusterName, resourceID, nil)
+		if d.Domain() != "" {
+			domain = d.Domain()
+		}
+	}
+
+	// Hash the identifier. Uint64 extraction avoids the old signed-int64
+	// truncation's math.MinInt64-negation bug (negating it overflows back to
+	// itself) and the one bit of entropy that negating throws away, which
+	// used to collide values differing only in their top bit.
+	hash := sha256.Sum256([]byte(entityIdentifier))
+	hashInt := binary.BigEndian.Uint64(hash[:8])
+
+	// Format: accountID|domain|realm|hashInt
+	guidString := fmt.Sprintf("%s|%s|%s|%d", accountID, domain, realm, hashInt)
+	return base64.StdEncoding.EncodeToString([]byte(guidString)), nil
+}
+
+// legacyHashInt reproduces generateEntityGUID's pre-chunk13-5 SHA-256
+// truncation exactly, including its bug: an 8-byte big-endian value coerced
+// into a signed int64 and, if negative, simply negated, which silently
+// leaves math.MinInt64 negative and collapses one bit of entropy. It exists
+// only so LegacyGUID can keep reproducing GUIDs operators already have
+// entities against in New Relic.
+func legacyHashInt(hash [sha256.Size]byte) int64 {
+	hashInt := int64(0)
+	for i := 0; i < 8; i++ {
+		hashInt = (hashInt << 8) | int64(hash[i])
+	}
+	if hashInt < 0 {
+		hashInt = -hashInt
+	}
+	return hashInt
+}
+
+// legacyEntityGUID reproduces generateEntityGUID exactly as it behaved
+// before chunk13-4/chunk13-5: AWS_ACCOUNT_ID from the environment (or the
+// literal "3630072"), domain/realm fixed to "INFRA"/"NA", and the buggy
+// signed-int64 hash truncation legacyHashInt preserves on purpose.
+func legacyEntityGUID(entityType, clusterName, resourceID string) string {
+	accountID := os.Getenv("AWS_ACCOUNT_ID")
+	if accountID == "" {
+		accountID = "3630072"
+	}
+
+	var entityIdentifier string
+	domain := "INFRA"
+	if d, ok := lookupEntityDescriptor(entityType); ok {
+		entityIdentifier = d.IdentifierFormat(clusterName, resourceID, nil)
+		domain = d.Domain()
+	}
+
+	hash := sha256.Sum256([]byte(entityIdentifier))
+	hashInt := legacyHashInt(hash)
+
+	guidString := fmt.Sprintf("%s|%s|NA|%d", accountID, domain, hashInt)
+	return base64.StdEncoding.EncodeToString([]byte(guidString))
+}
@@ -0,0 +1,88 @@
+package msk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGUIDCache(maxSize int, opts ...GUIDCacheOption) *GUIDCache {
+	resolver := NewStaticAccountResolver("123456789012", "INFRA", "NA")
+	opts = append([]GUIDCacheOption{WithAccountResolver(resolver)}, opts...)
+	return NewGUIDCache(maxSize, opts...)
+}
+
+func TestGUIDCache_GetOrGenerateCachesAndCountsHits(t *testing.T) {
+	gc := newTestGUIDCache(10)
+
+	first := gc.GetOrGenerate("broker", "test-cluster", "1")
+	require.NotEmpty(t, first)
+
+	second := gc.GetOrGenerate("broker", "test-cluster", "1")
+	assert.Equal(t, first, second, "the same key should return the same cached GUID")
+
+	stats := gc.Stats()
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(1), stats.Hits)
+}
+
+func TestGUIDCache_EvictsLeastRecentlyUsedWhenFull(t *testing.T) {
+	gc := newTestGUIDCache(2)
+
+	gc.GetOrGenerate("broker", "test-cluster", "1")
+	gc.GetOrGenerate("broker", "test-cluster", "2")
+	// Touch "1" so "2" becomes the least recently used entry.
+	gc.GetOrGenerate("broker", "test-cluster", "1")
+	gc.GetOrGenerate("broker", "test-cluster", "3")
+
+	stats := gc.Stats()
+	assert.Equal(t, int64(1), stats.Evictions, "adding a 3rd key to a size-2 cache should evict exactly one entry")
+
+	snapshot := gc.snapshot()
+	_, hasOne := snapshot["broker:test-cluster:1"]
+	_, hasTwo := snapshot["broker:test-cluster:2"]
+	assert.True(t, hasOne, "recently touched key 1 should still be resident")
+	assert.False(t, hasTwo, "least recently used key 2 should have been evicted")
+}
+
+func TestGUIDCache_TTLExpiryRegeneratesAndCountsEviction(t *testing.T) {
+	gc := newTestGUIDCache(10, WithTTL(time.Millisecond))
+
+	first := gc.GetOrGenerate("broker", "test-cluster", "1")
+	time.Sleep(5 * time.Millisecond)
+	second := gc.GetOrGenerate("broker", "test-cluster", "1")
+
+	assert.Equal(t, first, second, "expiry regenerates the same deterministic GUID")
+	stats := gc.Stats()
+	assert.Equal(t, int64(1), stats.Evictions, "a TTL-expired hit should count as an eviction")
+	assert.Equal(t, int64(2), stats.Misses, "the expired lookup should count as a fresh miss")
+}
+
+func TestGUIDCache_PurgeResetsEntriesButKeepsStats(t *testing.T) {
+	gc := newTestGUIDCache(10)
+	gc.GetOrGenerate("broker", "test-cluster", "1")
+
+	gc.Purge()
+
+	assert.Empty(t, gc.snapshot())
+	assert.Equal(t, int64(1), gc.Stats().Misses, "Purge clears entries but not cumulative stats")
+}
+
+func TestGUIDCache_LegacyGUIDDisabledByDefault(t *testing.T) {
+	gc := newTestGUIDCache(10)
+	assert.Empty(t, gc.LegacyGUID("broker", "test-cluster", "1"))
+}
+
+func TestGUIDCache_LegacyGUIDExpiresAfterConfiguredCycles(t *testing.T) {
+	gc := newTestGUIDCache(10, WithLegacyGUIDCompat(2))
+
+	first := gc.LegacyGUID("broker", "test-cluster", "1")
+	second := gc.LegacyGUID("broker", "test-cluster", "1")
+	third := gc.LegacyGUID("broker", "test-cluster", "1")
+
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, second, "legacy GUID should be stable across the compat window")
+	assert.Empty(t, third, "legacy GUID should stop being returned once the compat window is exhausted")
+}
@@ -0,0 +1,78 @@
+package hdrhist
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram_ValueAtQuantileOfUniformSamples(t *testing.T) {
+	h := New(10000, 3)
+	for i := int64(1); i <= 1000; i++ {
+		h.Record(i)
+	}
+
+	assert.InDelta(t, 500, h.ValueAtQuantile(0.5), 10, "p50 of 1..1000 should be near the middle")
+	assert.InDelta(t, 990, h.ValueAtQuantile(0.99), 10, "p99 of 1..1000 should be near the top")
+	assert.Equal(t, int64(1000), h.TotalCount())
+}
+
+func TestHistogram_ValueAtQuantileEmptyHistogramIsZero(t *testing.T) {
+	h := New(10000, 3)
+	assert.Equal(t, int64(0), h.ValueAtQuantile(0.5))
+}
+
+func TestHistogram_RecordClampsOutOfRangeValues(t *testing.T) {
+	h := New(100, 2)
+	h.Record(-5)
+	h.Record(100000)
+
+	assert.Equal(t, int64(2), h.TotalCount())
+	assert.Equal(t, int64(100), h.ValueAtQuantile(1.0), "a value above highestTrackableValue should clamp into the top bucket")
+}
+
+func TestHistogram_RecordIsSafeForConcurrentUse(t *testing.T) {
+	h := New(10000, 3)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 10; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int64(1); i <= 100; i++ {
+				h.Record(i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1000), h.TotalCount())
+}
+
+func TestHistogram_MergeCombinesCounts(t *testing.T) {
+	a := New(10000, 3)
+	b := New(10000, 3)
+	for i := int64(1); i <= 500; i++ {
+		a.Record(i)
+	}
+	for i := int64(501); i <= 1000; i++ {
+		b.Record(i)
+	}
+
+	a.Merge(b)
+
+	assert.Equal(t, int64(1000), a.TotalCount())
+	assert.InDelta(t, 500, a.ValueAtQuantile(0.5), 15)
+	// other is left untouched by Merge.
+	assert.Equal(t, int64(500), b.TotalCount())
+}
+
+func TestHistogram_ResetClearsCounts(t *testing.T) {
+	h := New(10000, 3)
+	h.Record(42)
+	h.Reset()
+
+	assert.Equal(t, int64(0), h.TotalCount())
+	assert.Equal(t, int64(0), h.ValueAtQuantile(0.5))
+}
@@ -1,8 +1,13 @@
 package msk
 
 import (
+	"fmt"
 	"strings"
-	
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	"github.com/newrelic/nri-kafka/src/connection"
@@ -13,9 +18,11 @@ var GlobalMSKHook *IntegrationHook
 
 // IntegrationHook provides enhanced hooks into the nri-kafka integration flow
 type IntegrationHook struct {
-	shim        *Shim
-	integration *integration.Integration
-	mapper      *MetricMapper
+	shim          *Shim
+	integration   *integration.Integration
+	mapper        *MetricMapper
+	config        *Config
+	adminFallback *AdminFallbackCollector
 }
 
 // NewIntegrationHook creates a new enhanced MSK integration hook
@@ -39,6 +46,7 @@ func NewIntegrationHook(i *integration.Integration) *IntegrationHook {
 		shim:        shim,
 		integration: i,
 		mapper:      NewMetricMapper(),
+		config:      config,
 	}
 
 	// Set global hook for use in broker collection
@@ -74,6 +82,90 @@ func (h *IntegrationHook) TransformTopicData(topicName string, data map[string]i
 	return h.shim.TransformTopicMetrics(data)
 }
 
+// ClientQuotaData is one client-id/user's per-quota-type throttle
+// measurement on a single broker, read off the wildcarded
+// kafka.server:type={Produce,Fetch,Request},user=*,client-id=* MBeans --
+// the dimensionality AWS MSK's enhanced monitoring exposes per
+// noisy-neighbor client, which EnrichBrokerWithRequestMetrics's
+// broker-wide FetchConsumer/Produce means above don't carry.
+type ClientQuotaData struct {
+	BrokerID              int32
+	ClientID              string
+	User                  string
+	ProduceThrottleTimeMs float64
+	FetchThrottleTimeMs   float64
+	RequestThrottleTimeMs float64
+}
+
+// TransformClientQuotaData creates/updates a per-(broker,client-id,user)
+// KafkaBrokerClientQuotaSample entity, analogous to TransformTopicData
+// above. It builds its own entity rather than delegating to h.shim since
+// this is a plain Kafka-shaped sample -- KafkaBrokerSample's per-client
+// counterpart, not one of the AwsMsk-shaped samples the shim's
+// Transform*Metrics methods produce.
+func (h *IntegrationHook) TransformClientQuotaData(data ClientQuotaData) error {
+	if h == nil || h.integration == nil || h.config == nil {
+		return nil
+	}
+
+	entityName := fmt.Sprintf("%s-broker-%d-client-%s", h.config.ClusterName, data.BrokerID, data.ClientID)
+	entity, err := h.integration.Entity(entityName, "aws-msk")
+	if err != nil {
+		return fmt.Errorf("creating entity for broker %d client %s: %w", data.BrokerID, data.ClientID, err)
+	}
+
+	ms := entity.NewMetricSet("KafkaBrokerClientQuotaSample",
+		attribute.Attribute{Key: "entityName", Value: entityName},
+		attribute.Attribute{Key: "clusterName", Value: h.config.ClusterName},
+		attribute.Attribute{Key: "broker.id", Value: fmt.Sprintf("%d", data.BrokerID)},
+		attribute.Attribute{Key: "broker.throttledClientId", Value: data.ClientID},
+		attribute.Attribute{Key: "broker.throttledUser", Value: data.User},
+	)
+
+	ms.SetMetric("broker.produceThrottleTimeMs", data.ProduceThrottleTimeMs, metric.GAUGE)
+	ms.SetMetric("broker.fetchThrottleTimeMs", data.FetchThrottleTimeMs, metric.GAUGE)
+	ms.SetMetric("broker.requestThrottleTimeMs", data.RequestThrottleTimeMs, metric.GAUGE)
+
+	return nil
+}
+
+// TransformReassignmentData transforms in-flight KIP-455 partition
+// reassignment state after collection, mirroring the
+// TransformBrokerData/TransformTopicData pattern above. states is keyed by
+// TopicPartitionKey (the same identity PartitionTransformer already keys
+// per-partition entities by) rather than introducing a second,
+// differently-named (topic, partition) key type.
+func (h *IntegrationHook) TransformReassignmentData(states map[TopicPartitionKey]ReassignmentState) error {
+	if h == nil || h.shim == nil {
+		return nil
+	}
+
+	for key, state := range states {
+		data := map[string]interface{}{
+			"topic.name":             key.Topic,
+			"partition":              key.Partition,
+			"reassignment.adding":    state.AddingReplicas,
+			"reassignment.removing":  state.RemovingReplicas,
+			"reassignment.bytesLeft": state.BytesRemaining,
+		}
+		if err := h.shim.TransformReassignmentMetrics(data); err != nil {
+			log.Warn("Failed to transform reassignment data for %s: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+// TransformVolumeData emits an AwsMskVolumeSample for a single broker log
+// directory.
+func (h *IntegrationHook) TransformVolumeData(data VolumeData) error {
+	if h == nil || h.shim == nil {
+		return nil
+	}
+
+	return h.shim.TransformVolumeData(data)
+}
+
 // ProcessConsumerOffset processes consumer offset data for lag enrichment
 func (h *IntegrationHook) ProcessConsumerOffset(offsetData map[string]interface{}) error {
 	if h == nil || h.shim == nil {
@@ -125,6 +217,77 @@ func (h *IntegrationHook) SetInfrastructureAPI(api InfrastructureAPI) {
 	}
 }
 
+// SetAdminClient wires a Sarama admin connection for the JMX fallback path,
+// gated on Config.AdminFallbackEnabled. Call it from the top-level
+// integration once ValidateJMXConfiguration reports missing beans, so
+// CollectViaAdminFallback has something to collect from; a no-op when
+// AdminFallbackEnabled is false, since JMX is assumed sufficient otherwise.
+func (h *IntegrationHook) SetAdminClient(admin sarama.ClusterAdmin) {
+	if h == nil || h.config == nil || !h.config.AdminFallbackEnabled {
+		return
+	}
+	h.adminFallback = NewAdminFallbackCollector(admin, h.mapper)
+}
+
+// CollectViaAdminFallback asks the admin connection wired by SetAdminClient
+// for broker/topic/consumer-group state and feeds it through the same
+// TransformBrokerData/TransformTopicData/ProcessConsumerOffset entry points
+// a JMX-sourced sample would use, so MSK dashboards keep populating on
+// brokers where only the Kafka wire protocol is reachable. It is a no-op if
+// SetAdminClient was never called -- either AdminFallbackEnabled is false,
+// or JMX was never found to be degraded in the first place.
+func (h *IntegrationHook) CollectViaAdminFallback(brokers []*connection.Broker, topics []string, groupIDs []string) {
+	if h == nil || h.adminFallback == nil {
+		return
+	}
+
+	brokerIDs := make([]int32, 0, len(brokers))
+	brokersByID := make(map[int32]*connection.Broker, len(brokers))
+	for _, broker := range brokers {
+		id := int32(broker.ID)
+		brokerIDs = append(brokerIDs, id)
+		brokersByID[id] = broker
+	}
+
+	brokerMetrics, err := h.adminFallback.CollectBrokerMetrics(brokerIDs)
+	if err != nil {
+		log.Warn("AdminFallbackCollector: %v", err)
+	}
+	for id, data := range brokerMetrics {
+		if err := h.TransformBrokerData(brokersByID[id], data); err != nil {
+			log.Warn("AdminFallbackCollector: failed to transform broker %d fallback metrics: %v", id, err)
+		}
+	}
+
+	topicMetrics, err := h.adminFallback.CollectTopicMetrics(topics)
+	if err != nil {
+		log.Warn("AdminFallbackCollector: %v", err)
+	}
+	for topic, data := range topicMetrics {
+		if err := h.TransformTopicData(topic, data); err != nil {
+			log.Warn("AdminFallbackCollector: failed to transform topic %s fallback metrics: %v", topic, err)
+		}
+	}
+
+	for _, groupID := range groupIDs {
+		lag, err := h.adminFallback.CollectConsumerLag(groupID, nil)
+		if err != nil {
+			log.Warn("AdminFallbackCollector: failed to collect lag for group %s: %v", groupID, err)
+			continue
+		}
+		for topic, partitions := range lag {
+			for partition, data := range partitions {
+				data["consumer.group"] = groupID
+				data["topic.name"] = topic
+				data["partition"] = partition
+				if err := h.ProcessConsumerOffset(data); err != nil {
+					log.Warn("AdminFallbackCollector: failed to process consumer offset fallback for %s/%s[%d]: %v", groupID, topic, partition, err)
+				}
+			}
+		}
+	}
+}
+
 // Finalize performs final processing and creates cluster entity
 func (h *IntegrationHook) Finalize() error {
 	if h == nil || h.shim == nil {
@@ -182,7 +345,26 @@ func (h *IntegrationHook) GetRequiredJMXBeans() []string {
 		"kafka.server:type=KafkaRequestHandlerPool,name=ProduceThrottleTimeMs",
 		"kafka.server:type=KafkaRequestHandlerPool,name=FetchThrottleTimeMs",
 		"kafka.server:type=KafkaRequestHandlerPool,name=RequestThrottleTimeMs",
-		
+
+		// Per-client-id/per-user quota metrics (the user=*,client-id=*
+		// wildcarded MBeans MSK's enhanced monitoring breaks throttling
+		// down by, for noisy-neighbor alerting). These have no "name"
+		// property at all -- matchesPattern's unordered property-map
+		// comparison handles that the same as any other property set, and
+		// its existing single-property wildcard support already covers
+		// wildcarding both user and client-id at once since each property
+		// is matched independently.
+		"kafka.server:type=Produce,user=*,client-id=*",
+		"kafka.server:type=Fetch,user=*,client-id=*",
+		"kafka.server:type=Request,user=*,client-id=*",
+
+		// Partition reassignment metrics (KIP-455/KIP-352). Predates this
+		// bean on clusters older than Kafka 2.4 -- ValidateJMXConfiguration
+		// surfaces its absence as a warning rather than failing outright,
+		// since reassignment observability is additive, not required for
+		// core MSK compatibility.
+		"kafka.server:type=ReplicaManager,name=ReassigningPartitions",
+
 		// Topic metrics
 		"kafka.server:type=BrokerTopicMetrics,name=BytesInPerSec,topic=*",
 		"kafka.server:type=BrokerTopicMetrics,name=BytesOutPerSec,topic=*",
@@ -191,52 +373,143 @@ func (h *IntegrationHook) GetRequiredJMXBeans() []string {
 	}
 }
 
-// ValidateJMXConfiguration checks if required beans are configured
+// JMXBeanDiagnostic reports how one bean from GetRequiredJMXBeans resolved
+// against the beans an agent actually has configured: Missing if nothing
+// available matched it at all, MatchedViaAlias if the only match required
+// normalizing a legacy dash/underscore spelling (pre-KAFKA-1481 brokers and
+// some third-party exporters still emit e.g. "Under_replicated_partitions"
+// for today's "UnderReplicatedPartitions"), or neither if an available bean
+// matched it outright.
+type JMXBeanDiagnostic struct {
+	Required        string
+	MatchedBean     string
+	Missing         bool
+	MatchedViaAlias bool
+}
+
+// ValidateJMXConfiguration checks if required beans are configured, logging
+// (and returning) the ones that aren't matched by anything available. See
+// DiagnoseJMXConfiguration for the full match detail, including beans that
+// only resolved via legacy-name normalization.
 func (h *IntegrationHook) ValidateJMXConfiguration(availableBeans []string) []string {
 	if h == nil {
 		return nil
 	}
 
-	requiredBeans := h.GetRequiredJMXBeans()
-	missingBeans := []string{}
+	diagnostics := h.DiagnoseJMXConfiguration(availableBeans)
+	missingBeans := make([]string, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		if d.Missing {
+			missingBeans = append(missingBeans, d.Required)
+		}
+	}
 
-	beanMap := make(map[string]bool)
-	for _, bean := range availableBeans {
-		beanMap[bean] = true
+	if len(missingBeans) > 0 {
+		log.Warn("Missing JMX beans for full MSK compatibility: %v", missingBeans)
 	}
 
+	return missingBeans
+}
+
+// DiagnoseJMXConfiguration matches every bean GetRequiredJMXBeans lists
+// against availableBeans, reporting for each whether it was found outright,
+// found only via legacy-alias normalization, or not found at all.
+func (h *IntegrationHook) DiagnoseJMXConfiguration(availableBeans []string) []JMXBeanDiagnostic {
+	if h == nil {
+		return nil
+	}
+
+	requiredBeans := h.GetRequiredJMXBeans()
+	diagnostics := make([]JMXBeanDiagnostic, 0, len(requiredBeans))
+
 	for _, required := range requiredBeans {
-		found := false
-		for available := range beanMap {
+		diag := JMXBeanDiagnostic{Required: required, Missing: true}
+		for _, available := range availableBeans {
+			if required == available {
+				diag = JMXBeanDiagnostic{Required: required, MatchedBean: available}
+				break
+			}
 			if matchesPattern(required, available) {
-				found = true
+				diag = JMXBeanDiagnostic{Required: required, MatchedBean: available, MatchedViaAlias: true}
 				break
 			}
 		}
-		if !found {
-			missingBeans = append(missingBeans, required)
-		}
+		diagnostics = append(diagnostics, diag)
 	}
 
-	if len(missingBeans) > 0 {
-		log.Warn("Missing JMX beans for full MSK compatibility: %v", missingBeans)
+	return diagnostics
+}
+
+// objectName is a parsed JMX ObjectName: a domain plus an unordered set of
+// key/value properties, e.g. "kafka.server:type=ReplicaManager,name=X"
+// parses to domain "kafka.server" and props {"type": "ReplicaManager",
+// "name": "X"}. Comparing these directly, property by property, is what
+// lets matchesPattern treat "type=Foo,name=Bar" and "name=Bar,type=Foo" as
+// the same bean instead of relying on string prefix/suffix tricks.
+type objectName struct {
+	domain string
+	props  map[string]string
+}
+
+// parseObjectName parses s, reporting ok=false if it isn't in
+// "domain:key=value,..." form.
+func parseObjectName(s string) (on objectName, ok bool) {
+	domain, rest, found := strings.Cut(s, ":")
+	if !found {
+		return objectName{}, false
 	}
 
-	return missingBeans
+	on = objectName{domain: domain, props: make(map[string]string)}
+	for _, pair := range strings.Split(rest, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return objectName{}, false
+		}
+		on.props[key] = value
+	}
+	return on, true
 }
 
-// matchesPattern checks if a JMX bean pattern matches
+// matchesPattern reports whether bean satisfies pattern: the same domain,
+// the same set of property keys, and every property value either equal,
+// equal once legacy dash/underscore spellings are normalized away (via
+// normalizeMetricKey, the same helper GetJMXBeanCandidates's fuzzy lookup
+// already uses), or a "*" wildcard on either side -- not just "topic=*" as
+// before, so any property (request=*, name=*, ...) can wildcard.
 func matchesPattern(pattern, bean string) bool {
-	// Simple pattern matching for wildcards
 	if pattern == bean {
 		return true
 	}
-	
-	// Handle topic=* wildcard
-	if strings.Contains(pattern, "topic=*") {
-		basePattern := strings.Replace(pattern, ",topic=*", "", 1)
-		return strings.HasPrefix(bean, basePattern) && strings.Contains(bean, "topic=")
+
+	want, ok := parseObjectName(pattern)
+	if !ok {
+		return false
 	}
-	
-	return false
+	got, ok := parseObjectName(bean)
+	if !ok {
+		return false
+	}
+
+	if want.domain != got.domain || len(want.props) != len(got.props) {
+		return false
+	}
+
+	for key, wantValue := range want.props {
+		gotValue, present := got.props[key]
+		if !present {
+			return false
+		}
+		if wantValue == "*" || gotValue == "*" {
+			continue
+		}
+		if wantValue == gotValue {
+			continue
+		}
+		if normalizeMetricKey(wantValue) == normalizeMetricKey(gotValue) {
+			continue
+		}
+		return false
+	}
+
+	return true
 }
\ No newline at end of file
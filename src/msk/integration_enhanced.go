@@ -75,15 +75,7 @@ func (h *IntegrationHook) TransformBrokerDataEnhanced(broker *connection.Broker,
 	data["broker.id"] = broker.ID
 	data["broker.host"] = broker.Host
 
-	// Transform based on shim type
-	switch shim := h.shim.(type) {
-	case *EnhancedShim:
-		return shim.TransformBrokerMetrics(data)
-	case *Shim:
-		return shim.TransformBrokerMetrics(data)
-	default:
-		return nil
-	}
+	return h.shim.TransformBrokerMetrics(data)
 }
 
 // FinalizeEnhanced performs final processing with enhanced support
@@ -93,15 +85,8 @@ func (h *IntegrationHook) FinalizeEnhanced() error {
 	}
 
 	log.Debug("Finalizing MSK shim")
-	
-	switch shim := h.shim.(type) {
-	case *EnhancedShim:
-		return shim.Flush()
-	case *Shim:
-		return shim.Flush()
-	default:
-		return nil
-	}
+
+	return h.shim.Flush()
 }
 
 // IsEnabledEnhanced returns whether the hook is enabled
@@ -110,12 +95,5 @@ func (h *IntegrationHook) IsEnabledEnhanced() bool {
 		return false
 	}
 	
-	switch shim := h.shim.(type) {
-	case *EnhancedShim:
-		return shim.IsEnabled()
-	case *Shim:
-		return shim.IsEnabled()
-	default:
-		return false
-	}
+	return h.shim.IsEnabled()
 }
\ No newline at end of file
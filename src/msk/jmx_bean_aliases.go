@@ -0,0 +1,103 @@
+package msk
+
+import (
+	"regexp"
+	"strings"
+)
+
+// camelBoundary finds the transition points in a camel-case MBean name
+// attribute (e.g. "BytesInPerSec") so it can be converted to the legacy
+// dashed form ("Bytes-In-Per-Sec") brokers on older versions and some
+// third-party forks still emit.
+var camelBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// dashedFormOf converts a camel-case MBean attribute name to its legacy
+// dashed spelling, e.g. "BytesInPerSec" -> "Bytes-In-Per-Sec".
+func dashedFormOf(name string) string {
+	return camelBoundary.ReplaceAllString(name, "$1-$2")
+}
+
+// GetJMXBeanCandidates returns every JMX bean string the collector should
+// try, in order, for metricName: the canonical (camel-case) form from
+// GetJMXBeanForMetric first, followed by the legacy dashed form of the
+// MBean's `name=` attribute. A broker reporting under either
+// spelling will match one of these.
+func GetJMXBeanCandidates(metricName string) []string {
+	canonical := GetJMXBeanForMetric(metricName)
+	if canonical == "" {
+		return nil
+	}
+
+	candidates := []string{canonical}
+
+	if alt := alternateBeanSpelling(canonical); alt != "" && alt != canonical {
+		candidates = append(candidates, alt)
+	}
+
+	return candidates
+}
+
+// alternateBeanSpelling rewrites a bean's name= attribute from camel-case
+// to the legacy dashed form, leaving the rest of the bean string (domain,
+// type, topic/partition tags) untouched.
+func alternateBeanSpelling(bean string) string {
+	const marker = "name="
+	idx := strings.Index(bean, marker)
+	if idx == -1 {
+		return ""
+	}
+
+	start := idx + len(marker)
+	end := strings.IndexByte(bean[start:], ',')
+	var name, rest string
+	if end == -1 {
+		name = bean[start:]
+		rest = ""
+	} else {
+		name = bean[start : start+end]
+		rest = bean[start+end:]
+	}
+
+	return bean[:start] + dashedFormOf(name) + rest
+}
+
+// normalizeMetricKey strips separators and lowercases an incoming JMX
+// metric key, so spellings like "bytes_in_per_second" or
+// "Bytes-In-Per-Sec" normalize to the same lookup key as
+// "bytesinpersecond".
+func normalizeMetricKey(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		switch r {
+		case '-', '_', '.', ' ':
+			continue
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.ToLower(b.String())
+}
+
+// MapBrokerMetricFuzzy normalizes kafkaMetric (stripping separators and
+// lowercasing) before looking it up against the broker mappings table, so
+// a broker reporting a differently-spelled key (e.g.
+// "bytes_in_per_second") still resolves to the same MSK metric
+// ("kafka.broker.bytesIn") as the canonically-spelled key would.
+func (m *MetricMapper) MapBrokerMetricFuzzy(kafkaMetric string) (string, bool) {
+	if mskMetric, ok := m.MapBrokerMetric(kafkaMetric); ok {
+		return mskMetric, true
+	}
+
+	if m.fuzzyBrokerIndex == nil {
+		m.fuzzyBrokerIndex = make(map[string]string, len(m.brokerMappings))
+		for key := range m.brokerMappings {
+			m.fuzzyBrokerIndex[normalizeMetricKey(key)] = key
+		}
+	}
+
+	canonicalKey, ok := m.fuzzyBrokerIndex[normalizeMetricKey(kafkaMetric)]
+	if !ok {
+		return "", false
+	}
+	return m.MapBrokerMetric(canonicalKey)
+}
@@ -0,0 +1,65 @@
+package msk
+
+// JVM/GC metric names as reported by the Kafka broker's JMX MBeans. These
+// aren't part of BrokerTopicMetrics like throughput/replication counters,
+// so they get their own small mapping table rather than bloating
+// MetricMapper.initializeBrokerMappings.
+const (
+	jvmHeapUsedKey        = "broker.jvm.heapMemoryUsed"
+	jvmHeapMaxKey         = "broker.jvm.heapMemoryMax"
+	jvmNonHeapUsedKey     = "broker.jvm.nonHeapMemoryUsed"
+	jvmGCCollectionCount  = "broker.jvm.gcCollectionCount"
+	jvmGCCollectionTimeMs = "broker.jvm.gcCollectionTimeMs"
+)
+
+// jvmProviderMappings maps raw JMX-sourced JVM/GC fields to the
+// provider.* namespace AwsMskBrokerSample entities use.
+var jvmProviderMappings = map[string]string{
+	jvmHeapUsedKey:        "provider.jvmHeapMemoryUsed",
+	jvmHeapMaxKey:         "provider.jvmHeapMemoryMax",
+	jvmNonHeapUsedKey:     "provider.jvmNonHeapMemoryUsed",
+	jvmGCCollectionCount:  "provider.jvmGcCollectionCount",
+	jvmGCCollectionTimeMs: "provider.jvmGcCollectionTimeMs",
+}
+
+// JVMMetrics holds the subset of broker JVM/GC values the MSK provider
+// namespace surfaces.
+type JVMMetrics struct {
+	HeapMemoryUsed    float64
+	HeapMemoryMax     float64
+	NonHeapMemoryUsed float64
+	GCCollectionCount float64
+	GCCollectionTimeMs float64
+	HeapUsedPercent   float64
+}
+
+// ExtractJVMMetrics reads the broker's JVM/GC fields out of a raw sample
+// and derives HeapUsedPercent, matching how other derived ratios (e.g.
+// requestHandlerAvgIdlePercent) are computed elsewhere in the transformer.
+func ExtractJVMMetrics(brokerData map[string]interface{}) *JVMMetrics {
+	m := &JVMMetrics{
+		HeapMemoryUsed:     getFloatValueWithDefault(brokerData, jvmHeapUsedKey, 0),
+		HeapMemoryMax:      getFloatValueWithDefault(brokerData, jvmHeapMaxKey, 0),
+		NonHeapMemoryUsed:  getFloatValueWithDefault(brokerData, jvmNonHeapUsedKey, 0),
+		GCCollectionCount:  getFloatValueWithDefault(brokerData, jvmGCCollectionCount, 0),
+		GCCollectionTimeMs: getFloatValueWithDefault(brokerData, jvmGCCollectionTimeMs, 0),
+	}
+	if m.HeapMemoryMax > 0 {
+		m.HeapUsedPercent = (m.HeapMemoryUsed / m.HeapMemoryMax) * 100
+	}
+	return m
+}
+
+// ToProviderMetrics renders the extracted JVM metrics as provider.*
+// key/value pairs ready for ms.SetMetric, mirroring the naming convention
+// jvmProviderMappings defines.
+func (m *JVMMetrics) ToProviderMetrics() map[string]float64 {
+	return map[string]float64{
+		jvmProviderMappings[jvmHeapUsedKey]:        m.HeapMemoryUsed,
+		jvmProviderMappings[jvmHeapMaxKey]:         m.HeapMemoryMax,
+		jvmProviderMappings[jvmNonHeapUsedKey]:     m.NonHeapMemoryUsed,
+		jvmProviderMappings[jvmGCCollectionCount]:  m.GCCollectionCount,
+		jvmProviderMappings[jvmGCCollectionTimeMs]: m.GCCollectionTimeMs,
+		"provider.jvmHeapMemoryUsedPercent":        m.HeapUsedPercent,
+	}
+}
@@ -0,0 +1,126 @@
+package msk
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// KafkaSinkConfig configures KafkaSink, letting the shim feed its own
+// emitted samples back onto a Kafka topic instead of (or alongside) the
+// New Relic infra-integrations SDK, for environments where the agent
+// cannot reach the control plane directly and a sidecar collector
+// consumes from Kafka the way a Loki/Promtail-style pipeline would.
+type KafkaSinkConfig struct {
+	Brokers       []string
+	Topic         string
+	Compression   string // "none", "gzip", "snappy", "zstd"
+	RequiredAcks  string // "none", "local", "all"
+	SASLEnabled   bool
+	SASLMechanism string
+	SASLUsername  string
+	SASLPassword  string
+	TLSEnabled    bool
+}
+
+// KafkaSink produces MSK shim sample payloads onto a Kafka topic via a
+// sarama SyncProducer.
+type KafkaSink struct {
+	config   KafkaSinkConfig
+	producer sarama.SyncProducer
+}
+
+// compressionCodecs maps KafkaSinkConfig.Compression's accepted values
+// to the sarama codec it selects.
+var compressionCodecs = map[string]sarama.CompressionCodec{
+	"none":   sarama.CompressionNone,
+	"gzip":   sarama.CompressionGZIP,
+	"snappy": sarama.CompressionSnappy,
+	"zstd":   sarama.CompressionZSTD,
+}
+
+// requiredAcksLevels maps KafkaSinkConfig.RequiredAcks's accepted values
+// to the sarama acknowledgment level it selects.
+var requiredAcksLevels = map[string]sarama.RequiredAcks{
+	"none":  sarama.NoResponse,
+	"local": sarama.WaitForLocal,
+	"all":   sarama.WaitForAll,
+}
+
+// NewKafkaSink connects a sarama SyncProducer per config and returns a
+// KafkaSink ready for Publish calls.
+func NewKafkaSink(config KafkaSinkConfig) (*KafkaSink, error) {
+	if len(config.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka sink requires at least one broker")
+	}
+	if config.Topic == "" {
+		return nil, fmt.Errorf("kafka sink requires a topic")
+	}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Version = defaultSaramaVersion
+	saramaConfig.Producer.Return.Successes = true
+
+	codec, ok := compressionCodecs[config.Compression]
+	if !ok {
+		codec = sarama.CompressionNone
+	}
+	saramaConfig.Producer.Compression = codec
+
+	acks, ok := requiredAcksLevels[config.RequiredAcks]
+	if !ok {
+		acks = sarama.WaitForLocal
+	}
+	saramaConfig.Producer.RequiredAcks = acks
+
+	if config.SASLEnabled {
+		saramaConfig.Net.SASL.Enable = true
+		saramaConfig.Net.SASL.User = config.SASLUsername
+		saramaConfig.Net.SASL.Password = config.SASLPassword
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLMechanism(config.SASLMechanism)
+	}
+	if config.TLSEnabled {
+		saramaConfig.Net.TLS.Enable = true
+	}
+
+	producer, err := sarama.NewSyncProducer(config.Brokers, saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating kafka sink producer: %w", err)
+	}
+
+	return &KafkaSink{config: config, producer: producer}, nil
+}
+
+// Publish serializes payload as JSON and produces it onto the configured
+// topic, keyed by entityType so a sidecar consumer can partition by
+// sample type.
+func (k *KafkaSink) Publish(entityType string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling payload for kafka sink: %w", err)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic:     k.config.Topic,
+		Key:       sarama.StringEncoder(entityType),
+		Value:     sarama.ByteEncoder(body),
+		Timestamp: time.Now(),
+	}
+
+	partition, offset, err := k.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("publishing to kafka sink: %w", err)
+	}
+
+	log.Debug("KafkaSink: published %s payload to partition=%d offset=%d", entityType, partition, offset)
+	return nil
+}
+
+// Close releases the underlying producer's connections.
+func (k *KafkaSink) Close() error {
+	return k.producer.Close()
+}
@@ -0,0 +1,106 @@
+package msk
+
+import (
+	"fmt"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// QuorumVoterState describes one member of the KRaft controller quorum, as
+// returned by DescribeQuorum (KIP-595).
+type QuorumVoterState struct {
+	ReplicaID       int32
+	LogEndOffset    int64
+	LastFetchMillis int64
+	IsLeader        bool
+}
+
+// QuorumHealthSource is the admin surface KRaftQuorumHealth needs. It sits
+// alongside the legacy ZooKeeper path (getZookeeperLatency) rather than
+// replacing it, since a cluster runs one or the other depending on mode.
+type QuorumHealthSource interface {
+	DescribeQuorum() (leaderID int32, voters []QuorumVoterState, err error)
+}
+
+// KRaftQuorumHealth computes controller-quorum health for clusters running
+// in KRaft mode: leader identity and how far behind each follower's
+// log-end-offset is from the leader's, which is the KRaft analogue of
+// ZooKeeper session/latency health.
+type KRaftQuorumHealth struct {
+	source QuorumHealthSource
+}
+
+// NewKRaftQuorumHealth creates a health checker backed by source.
+func NewKRaftQuorumHealth(source QuorumHealthSource) *KRaftQuorumHealth {
+	return &KRaftQuorumHealth{source: source}
+}
+
+// QuorumHealthSnapshot is the computed, point-in-time health of the
+// controller quorum.
+type QuorumHealthSnapshot struct {
+	LeaderID        int32
+	VoterCount      int
+	MaxFollowerLagOffsets int64
+	UnavailableVoters int
+}
+
+// Snapshot describes the quorum's current health.
+func (k *KRaftQuorumHealth) Snapshot() (*QuorumHealthSnapshot, error) {
+	leaderID, voters, err := k.source.DescribeQuorum()
+	if err != nil {
+		return nil, fmt.Errorf("describing KRaft quorum: %w", err)
+	}
+
+	snapshot := &QuorumHealthSnapshot{LeaderID: leaderID, VoterCount: len(voters)}
+
+	var leaderOffset int64 = -1
+	for _, v := range voters {
+		if v.IsLeader {
+			leaderOffset = v.LogEndOffset
+		}
+	}
+
+	for _, v := range voters {
+		if v.IsLeader {
+			continue
+		}
+		if v.LastFetchMillis == 0 {
+			snapshot.UnavailableVoters++
+			continue
+		}
+		if leaderOffset >= 0 {
+			lag := leaderOffset - v.LogEndOffset
+			if lag > snapshot.MaxFollowerLagOffsets {
+				snapshot.MaxFollowerLagOffsets = lag
+			}
+		}
+	}
+
+	return snapshot, nil
+}
+
+// EmitClusterMetrics writes the quorum snapshot onto the cluster entity's
+// metric set, alongside whatever ZooKeeper metrics the legacy path emits --
+// a cluster is in exactly one mode, so only one of the two paths will carry
+// meaningful values at runtime.
+func (k *KRaftQuorumHealth) EmitClusterMetrics(ms interface {
+	SetMetric(name string, value interface{}, sourceType metric.SourceType) error
+}) error {
+	snapshot, err := k.Snapshot()
+	if err != nil {
+		log.Warn("KRaftQuorumHealth: failed to snapshot quorum health: %v", err)
+		return err
+	}
+
+	if err := ms.SetMetric("provider.kraftQuorumLeaderId", float64(snapshot.LeaderID), metric.GAUGE); err != nil {
+		return err
+	}
+	if err := ms.SetMetric("provider.kraftQuorumVoterCount", float64(snapshot.VoterCount), metric.GAUGE); err != nil {
+		return err
+	}
+	if err := ms.SetMetric("provider.kraftQuorumMaxFollowerLagOffsets", float64(snapshot.MaxFollowerLagOffsets), metric.GAUGE); err != nil {
+		return err
+	}
+	return ms.SetMetric("provider.kraftQuorumUnavailableVoters", float64(snapshot.UnavailableVoters), metric.GAUGE)
+}
@@ -0,0 +1,198 @@
+package msk
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// FallbackOffsetPolicy controls how LagCalculator resolves a missing committed
+// offset for a (group, topic, partition) that has never committed one.
+type FallbackOffsetPolicy string
+
+const (
+	// FallbackOffsetEarliest uses the partition's log-start offset.
+	FallbackOffsetEarliest FallbackOffsetPolicy = "earliest"
+	// FallbackOffsetTimestamp issues a timestamp-based ListOffsets lookup
+	// using "now - FallbackOffsetMillis" and falls back to the log-start
+	// offset if the broker reports OFFSET_OUT_OF_RANGE.
+	FallbackOffsetTimestamp FallbackOffsetPolicy = "timestamp"
+)
+
+// ConsumerGroupState mirrors the high-level state Kafka reports for a
+// consumer group via the DescribeGroups API.
+type ConsumerGroupState string
+
+const (
+	ConsumerGroupStateEmpty  ConsumerGroupState = "Empty"
+	ConsumerGroupStateStable ConsumerGroupState = "Stable"
+	ConsumerGroupStateDead   ConsumerGroupState = "Dead"
+)
+
+// PartitionOffsets carries the log-start and high-water-mark offsets for a
+// single topic partition, as reported by the broker.
+type PartitionOffsets struct {
+	LogStartOffset int64
+	HighWaterMark  int64
+}
+
+// KafkaOffsetSource is the admin-level surface LagCalculator needs from a
+// Kafka client. Implementations typically wrap a sarama or kadm client.
+type KafkaOffsetSource interface {
+	// ListCommittedOffsets returns the committed offset for every
+	// partition the group has committed to, keyed by partition.
+	// Partitions absent from the result have no committed offset.
+	ListCommittedOffsets(group, topic string) (map[int32]int64, error)
+	// DescribePartitionOffsets returns the log-start and high-water-mark
+	// offsets for every partition of topic.
+	DescribePartitionOffsets(topic string) (map[int32]PartitionOffsets, error)
+	// OffsetForTimestamp returns the earliest offset whose record
+	// timestamp is >= timestampMillis for the given partition. It
+	// returns ErrOffsetOutOfRange if no such offset exists on the broker.
+	OffsetForTimestamp(topic string, partition int32, timestampMillis int64) (int64, error)
+	// GroupState returns the group's current high-level state.
+	GroupState(group string) (ConsumerGroupState, error)
+}
+
+// ErrOffsetOutOfRange is returned by KafkaOffsetSource.OffsetForTimestamp
+// when the requested timestamp falls outside the partition's retained log.
+var ErrOffsetOutOfRange = fmt.Errorf("offset out of range")
+
+// PartitionLag is the computed lag for a single (group, topic, partition).
+type PartitionLag struct {
+	Topic             string
+	Partition         int32
+	ConsumerGroup     string
+	CommittedOffset   int64
+	EffectiveOffset   int64
+	HighWaterMark     int64
+	Lag               int64
+	UsedFallback      bool
+}
+
+// GroupLagSummary is the aggregated lag for a single consumer group.
+type GroupLagSummary struct {
+	Group        string
+	State        ConsumerGroupState
+	SumLag       int64
+	MaxLag       int64
+	TopicLag     map[string]int64
+	Partitions   []PartitionLag
+}
+
+// LagCalculatorConfig configures the fallback-offset policy used when a
+// group has no committed offset for a partition.
+type LagCalculatorConfig struct {
+	FallbackOffsetPolicy FallbackOffsetPolicy
+	FallbackOffsetMillis int64
+}
+
+// LagCalculator computes consumer-group lag for every group known to
+// __consumer_offsets, including groups with no live members, following the
+// same approach Grafana Mimir's getGroupLag takes on top of kadm.
+type LagCalculator struct {
+	source KafkaOffsetSource
+	config LagCalculatorConfig
+}
+
+// NewLagCalculator creates a LagCalculator backed by source.
+func NewLagCalculator(source KafkaOffsetSource, config LagCalculatorConfig) *LagCalculator {
+	if config.FallbackOffsetPolicy == "" {
+		config.FallbackOffsetPolicy = FallbackOffsetEarliest
+	}
+	return &LagCalculator{source: source, config: config}
+}
+
+// CalculateGroupLag computes lag for every partition of topic for group,
+// applying the configured fallback policy where no committed offset exists.
+func (c *LagCalculator) CalculateGroupLag(group, topic string) (*GroupLagSummary, error) {
+	committed, err := c.source.ListCommittedOffsets(group, topic)
+	if err != nil {
+		return nil, fmt.Errorf("listing committed offsets for group %s topic %s: %w", group, topic, err)
+	}
+
+	partitionOffsets, err := c.source.DescribePartitionOffsets(topic)
+	if err != nil {
+		return nil, fmt.Errorf("describing partition offsets for topic %s: %w", topic, err)
+	}
+
+	state, err := c.source.GroupState(group)
+	if err != nil {
+		log.Warn("Failed to get state for consumer group %s: %v", group, err)
+		state = ConsumerGroupStateStable
+	}
+
+	summary := &GroupLagSummary{
+		Group:    group,
+		State:    state,
+		TopicLag: make(map[string]int64),
+	}
+
+	for partition, offsets := range partitionOffsets {
+		effective, usedFallback, err := c.resolveEffectiveOffset(committed, topic, partition, offsets)
+		if err != nil {
+			log.Warn("Failed to resolve effective offset for %s/%s[%d]: %v", group, topic, partition, err)
+			continue
+		}
+
+		lag := offsets.HighWaterMark - effective
+		if lag < 0 {
+			lag = 0
+		}
+
+		pl := PartitionLag{
+			Topic:           topic,
+			Partition:       partition,
+			ConsumerGroup:   group,
+			CommittedOffset: committed[partition],
+			EffectiveOffset: effective,
+			HighWaterMark:   offsets.HighWaterMark,
+			Lag:             lag,
+			UsedFallback:    usedFallback,
+		}
+		summary.Partitions = append(summary.Partitions, pl)
+		summary.SumLag += lag
+		if lag > summary.MaxLag {
+			summary.MaxLag = lag
+		}
+	}
+	summary.TopicLag[topic] = summary.SumLag
+
+	return summary, nil
+}
+
+// resolveEffectiveOffset returns the offset lag should be computed from:
+// the group's committed offset when present, otherwise the configured
+// fallback policy's result.
+func (c *LagCalculator) resolveEffectiveOffset(committed map[int32]int64, topic string, partition int32, offsets PartitionOffsets) (int64, bool, error) {
+	if offset, ok := committed[partition]; ok {
+		return offset, false, nil
+	}
+
+	switch c.config.FallbackOffsetPolicy {
+	case FallbackOffsetTimestamp:
+		target := time.Now().UnixMilli() - c.config.FallbackOffsetMillis
+		offset, err := c.source.OffsetForTimestamp(topic, partition, target)
+		if err != nil {
+			if err == ErrOffsetOutOfRange {
+				return offsets.LogStartOffset, true, nil
+			}
+			return 0, true, err
+		}
+		return offset, true, nil
+	case FallbackOffsetEarliest:
+		fallthrough
+	default:
+		return offsets.LogStartOffset, true, nil
+	}
+}
+
+// AddGroupLagToAggregator feeds a GroupLagSummary into a MetricAggregator as
+// provider.consumerLag.Sum / .Max, keyed per-topic so TransformTopicMetrics
+// can emit accurate lag even when no consumer is currently running.
+func (a *MetricAggregator) AddGroupLagToAggregator(summary *GroupLagSummary) {
+	for topic, lag := range summary.TopicLag {
+		a.AddConsumerLag(topic, summary.Group, float64(lag))
+	}
+}
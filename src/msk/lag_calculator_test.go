@@ -0,0 +1,100 @@
+package msk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOffsetSource is a test double for KafkaOffsetSource, letting each test
+// control exactly which offsets/state CalculateGroupLag sees.
+type fakeOffsetSource struct {
+	committed             map[int32]int64
+	offsets               map[int32]PartitionOffsets
+	state                 ConsumerGroupState
+	offsetForTimestamp    int64
+	offsetForTimestampErr error
+}
+
+func (f *fakeOffsetSource) ListCommittedOffsets(group, topic string) (map[int32]int64, error) {
+	return f.committed, nil
+}
+
+func (f *fakeOffsetSource) DescribePartitionOffsets(topic string) (map[int32]PartitionOffsets, error) {
+	return f.offsets, nil
+}
+
+func (f *fakeOffsetSource) OffsetForTimestamp(topic string, partition int32, timestampMillis int64) (int64, error) {
+	return f.offsetForTimestamp, f.offsetForTimestampErr
+}
+
+func (f *fakeOffsetSource) GroupState(group string) (ConsumerGroupState, error) {
+	return f.state, nil
+}
+
+func TestLagCalculator_UsesCommittedOffsetWhenPresent(t *testing.T) {
+	source := &fakeOffsetSource{
+		committed: map[int32]int64{0: 90},
+		offsets:   map[int32]PartitionOffsets{0: {LogStartOffset: 0, HighWaterMark: 100}},
+		state:     ConsumerGroupStateStable,
+	}
+	calc := NewLagCalculator(source, LagCalculatorConfig{})
+
+	summary, err := calc.CalculateGroupLag("my-group", "my-topic")
+	require.NoError(t, err)
+
+	require.Len(t, summary.Partitions, 1)
+	assert.Equal(t, int64(10), summary.Partitions[0].Lag)
+	assert.False(t, summary.Partitions[0].UsedFallback)
+	assert.Equal(t, int64(10), summary.SumLag)
+	assert.Equal(t, int64(10), summary.MaxLag)
+}
+
+func TestLagCalculator_FallsBackToLogStartOffsetWhenNoCommit(t *testing.T) {
+	source := &fakeOffsetSource{
+		committed: map[int32]int64{},
+		offsets:   map[int32]PartitionOffsets{0: {LogStartOffset: 50, HighWaterMark: 100}},
+		state:     ConsumerGroupStateEmpty,
+	}
+	calc := NewLagCalculator(source, LagCalculatorConfig{FallbackOffsetPolicy: FallbackOffsetEarliest})
+
+	summary, err := calc.CalculateGroupLag("inactive-group", "my-topic")
+	require.NoError(t, err)
+
+	require.Len(t, summary.Partitions, 1)
+	assert.Equal(t, int64(50), summary.Partitions[0].Lag, "lag should be HighWaterMark - LogStartOffset when falling back to earliest")
+	assert.True(t, summary.Partitions[0].UsedFallback)
+}
+
+func TestLagCalculator_TimestampFallbackOutOfRangeUsesLogStart(t *testing.T) {
+	source := &fakeOffsetSource{
+		committed:            map[int32]int64{},
+		offsets:              map[int32]PartitionOffsets{0: {LogStartOffset: 20, HighWaterMark: 100}},
+		state:                ConsumerGroupStateEmpty,
+		offsetForTimestampErr: ErrOffsetOutOfRange,
+	}
+	calc := NewLagCalculator(source, LagCalculatorConfig{FallbackOffsetPolicy: FallbackOffsetTimestamp})
+
+	summary, err := calc.CalculateGroupLag("inactive-group", "my-topic")
+	require.NoError(t, err)
+
+	require.Len(t, summary.Partitions, 1)
+	assert.Equal(t, int64(80), summary.Partitions[0].Lag)
+	assert.True(t, summary.Partitions[0].UsedFallback)
+}
+
+func TestLagCalculator_NegativeLagClampsToZero(t *testing.T) {
+	source := &fakeOffsetSource{
+		committed: map[int32]int64{0: 150}, // ahead of the reported high-water mark
+		offsets:   map[int32]PartitionOffsets{0: {LogStartOffset: 0, HighWaterMark: 100}},
+		state:     ConsumerGroupStateStable,
+	}
+	calc := NewLagCalculator(source, LagCalculatorConfig{})
+
+	summary, err := calc.CalculateGroupLag("my-group", "my-topic")
+	require.NoError(t, err)
+
+	require.Len(t, summary.Partitions, 1)
+	assert.Equal(t, int64(0), summary.Partitions[0].Lag)
+}
@@ -0,0 +1,115 @@
+package msk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultLagHistoryWindowSize is how many recent lag observations
+// LagHistoryCache keeps per partition when the caller doesn't specify one.
+const defaultLagHistoryWindowSize = 5
+
+// lagHistoryEntry is one (consumerGroup, topic, partition)'s ring buffer
+// of recent lag observations.
+type lagHistoryEntry struct {
+	window    []float64
+	idx       int
+	filled    bool
+	updatedAt time.Time
+}
+
+// LagHistoryCache keeps a short ring-buffer history of recently observed
+// lag per (consumerGroup, topic, partition), so a caller can tell a
+// genuinely draining partition (lag trending down) from one whose
+// consumer is down or has stopped committing (lag non-decreasing across
+// the whole window) - the same distinction KEDA's Kafka scaler makes
+// before it scales out on lag alone.
+type LagHistoryCache struct {
+	mu         sync.Mutex
+	windowSize int
+	entries    map[string]*lagHistoryEntry
+}
+
+// NewLagHistoryCache creates a cache tracking the last windowSize lag
+// observations per partition (defaultLagHistoryWindowSize if windowSize
+// isn't positive).
+func NewLagHistoryCache(windowSize int) *LagHistoryCache {
+	if windowSize < 1 {
+		windowSize = defaultLagHistoryWindowSize
+	}
+	return &LagHistoryCache{
+		windowSize: windowSize,
+		entries:    make(map[string]*lagHistoryEntry),
+	}
+}
+
+// lagHistoryKey builds the stable (consumerGroup, topic, partition) key.
+func lagHistoryKey(consumerGroup, topic, partition string) string {
+	return fmt.Sprintf("%s|%s|%s", consumerGroup, topic, partition)
+}
+
+// Observe records lag for (consumerGroup, topic, partition) and reports
+// whether its history is non-decreasing. ready is false until the window
+// has filled, so a caller's exclusion logic is a no-op on the first few
+// samples; nonDecreasing is only meaningful once ready is true, and is
+// true when every consecutive pair in the window satisfies
+// lag[i] <= lag[i+1] in observation order.
+func (c *LagHistoryCache) Observe(consumerGroup, topic, partition string, lag float64, now time.Time) (nonDecreasing bool, ready bool) {
+	key := lagHistoryKey(consumerGroup, topic, partition)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &lagHistoryEntry{window: make([]float64, c.windowSize)}
+		c.entries[key] = entry
+	}
+
+	entry.window[entry.idx] = lag
+	entry.idx = (entry.idx + 1) % len(entry.window)
+	if entry.idx == 0 {
+		entry.filled = true
+	}
+	entry.updatedAt = now
+
+	if !entry.filled {
+		return false, false
+	}
+
+	ordered := orderedLagWindow(entry)
+	nonDecreasing = true
+	for i := 1; i < len(ordered); i++ {
+		if ordered[i] < ordered[i-1] {
+			nonDecreasing = false
+			break
+		}
+	}
+	return nonDecreasing, true
+}
+
+// orderedLagWindow returns entry's window in observation order (oldest
+// first); the ring buffer's underlying slice order otherwise depends on
+// wherever idx currently sits.
+func orderedLagWindow(entry *lagHistoryEntry) []float64 {
+	n := len(entry.window)
+	ordered := make([]float64, n)
+	for i := 0; i < n; i++ {
+		ordered[i] = entry.window[(entry.idx+i)%n]
+	}
+	return ordered
+}
+
+// EvictOlderThan removes every cache entry whose last observation is
+// older than maxAge, so a partition a group stops reporting on doesn't
+// hold its history forever.
+func (c *LagHistoryCache) EvictOlderThan(maxAge time.Duration, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if now.Sub(entry.updatedAt) > maxAge {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package msk
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultLagIdleWindow is how long a consumer group's total lag must stay
+// at or below its ActivationLag before lagStatusClassifier reports it
+// idle, when Config doesn't specify one.
+const defaultLagIdleWindow = 5 * time.Minute
+
+// lagWindowState is one (clusterName, groupID)'s idle-tracking state:
+// belowSince marks when its lag first dropped to or below ActivationLag
+// (zero if it isn't currently below it), and lastSeen drives eviction of
+// groups that stop reporting.
+type lagWindowState struct {
+	belowSince time.Time
+	lastSeen   time.Time
+}
+
+// lagStatusClassifier classifies a consumer group's total lag into
+// ok/warn/critical/idle using a per-group LagThreshold (falling back to a
+// configured default), tracking how long each group has sat at or below
+// its ActivationLag in a small in-memory window so "idle" only fires once
+// a group has stayed quiet, not on a single low reading.
+type lagStatusClassifier struct {
+	thresholds       map[string]LagThreshold
+	defaultThreshold LagThreshold
+	idleWindow       time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*lagWindowState
+}
+
+// newLagStatusClassifier builds a classifier from thresholds/defaultThreshold
+// as resolved from Config, using idleWindow (or defaultLagIdleWindow if
+// idleWindow isn't positive) as how long a group must sit at or below its
+// ActivationLag before being reported idle.
+func newLagStatusClassifier(thresholds map[string]LagThreshold, defaultThreshold LagThreshold, idleWindow time.Duration) *lagStatusClassifier {
+	if idleWindow <= 0 {
+		idleWindow = defaultLagIdleWindow
+	}
+	return &lagStatusClassifier{
+		thresholds:       thresholds,
+		defaultThreshold: defaultThreshold,
+		idleWindow:       idleWindow,
+		windows:          make(map[string]*lagWindowState),
+	}
+}
+
+// thresholdFor returns groupID's configured LagThreshold, falling back to
+// the classifier's default when no per-group entry exists.
+func (c *lagStatusClassifier) thresholdFor(groupID string) LagThreshold {
+	if t, ok := c.thresholds[groupID]; ok {
+		return t
+	}
+	return c.defaultThreshold
+}
+
+// Classify returns key's (clusterName+groupID) lag status -
+// "idle"/"critical"/"warn"/"ok" - for totalLag against groupID's threshold,
+// along with the numeric threshold actually applied (CritLag, the value
+// kafka.consumer.LagRatio is normalized against).
+func (c *lagStatusClassifier) Classify(key, groupID string, totalLag float64, now time.Time) (status string, thresholdApplied float64) {
+	t := c.thresholdFor(groupID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, ok := c.windows[key]
+	if !ok {
+		w = &lagWindowState{}
+		c.windows[key] = w
+	}
+	w.lastSeen = now
+
+	idle := false
+	if t.ActivationLag > 0 && totalLag <= t.ActivationLag {
+		if w.belowSince.IsZero() {
+			w.belowSince = now
+		} else if now.Sub(w.belowSince) >= c.idleWindow {
+			idle = true
+		}
+	} else {
+		w.belowSince = time.Time{}
+	}
+
+	switch {
+	case idle:
+		status = "idle"
+	case t.CritLag > 0 && totalLag >= t.CritLag:
+		status = "critical"
+	case t.WarnLag > 0 && totalLag >= t.WarnLag:
+		status = "warn"
+	default:
+		status = "ok"
+	}
+	return status, t.CritLag
+}
+
+// EvictOlderThan removes tracking state for any (clusterName, groupID) not
+// observed since before maxAge, so a group that stops reporting doesn't
+// hold its idle-window state forever.
+func (c *lagStatusClassifier) EvictOlderThan(maxAge time.Duration, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, w := range c.windows {
+		if now.Sub(w.lastSeen) > maxAge {
+			delete(c.windows, key)
+		}
+	}
+}
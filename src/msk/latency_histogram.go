@@ -0,0 +1,102 @@
+package msk
+
+import (
+	"sync"
+
+	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// latencyHistogramMaxMs bounds the HDR histogram's tracked range. Request
+// latencies beyond 10 minutes are clamped into the top bucket rather than
+// rejected, since a bad upper bound shouldn't drop a real (if pathological)
+// sample.
+const latencyHistogramMaxMs = 10 * 60 * 1000
+
+// latencyHistogramSigFigs is the number of significant decimal digits HDR
+// preserves -- 3 gives <=0.1% relative error, which is plenty for
+// millisecond request latencies.
+const latencyHistogramSigFigs = 3
+
+// LatencyAggregator tracks per-metric-name latency distributions with HDR
+// histograms, so BatchCollector can emit accurate p50/p95/p99 percentiles
+// on flush instead of only ever-growing raw samples.
+type LatencyAggregator struct {
+	mu         sync.Mutex
+	histograms map[string]*hdrhistogram.Histogram
+}
+
+// NewLatencyAggregator creates an empty aggregator.
+func NewLatencyAggregator() *LatencyAggregator {
+	return &LatencyAggregator{histograms: make(map[string]*hdrhistogram.Histogram)}
+}
+
+// Record adds one latency sample (in milliseconds) for metricName.
+func (l *LatencyAggregator) Record(metricName string, valueMs int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, ok := l.histograms[metricName]
+	if !ok {
+		h = hdrhistogram.New(1, latencyHistogramMaxMs, latencyHistogramSigFigs)
+		l.histograms[metricName] = h
+	}
+	if valueMs > latencyHistogramMaxMs {
+		valueMs = latencyHistogramMaxMs
+	}
+	h.RecordValue(valueMs)
+}
+
+// LatencyPercentiles is the distribution summary exported per metric.
+type LatencyPercentiles struct {
+	P50   float64
+	P95   float64
+	P99   float64
+	Max   float64
+	Count int64
+}
+
+// Snapshot returns the current percentile summary for every recorded
+// metric and resets the underlying histograms, matching BatchCollector's
+// Flush-clears-the-buffer convention.
+func (l *LatencyAggregator) Snapshot() map[string]LatencyPercentiles {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make(map[string]LatencyPercentiles, len(l.histograms))
+	for name, h := range l.histograms {
+		result[name] = LatencyPercentiles{
+			P50:   float64(h.ValueAtQuantile(50)),
+			P95:   float64(h.ValueAtQuantile(95)),
+			P99:   float64(h.ValueAtQuantile(99)),
+			Max:   float64(h.Max()),
+			Count: h.TotalCount(),
+		}
+		h.Reset()
+	}
+	return result
+}
+
+// RecordLatency feeds a latency sample (ms) into the collector's HDR
+// histograms, keyed by metric name.
+func (bc *BatchCollector) RecordLatency(name string, valueMs int64) {
+	if bc.latency == nil {
+		bc.latency = NewLatencyAggregator()
+	}
+	bc.latency.Record(name, valueMs)
+}
+
+// FlushLatencyPercentiles renders the current latency distributions as
+// p50/p95/p99/max gauge metrics and enqueues them onto the batch, then
+// resets the histograms.
+func (bc *BatchCollector) FlushLatencyPercentiles(attributes map[string]interface{}) {
+	if bc.latency == nil {
+		return
+	}
+
+	for name, percentiles := range bc.latency.Snapshot() {
+		bc.AddMetric(name+".p50", percentiles.P50, attributes)
+		bc.AddMetric(name+".p95", percentiles.P95, attributes)
+		bc.AddMetric(name+".p99", percentiles.P99, attributes)
+		bc.AddMetric(name+".max", percentiles.Max, attributes)
+	}
+}
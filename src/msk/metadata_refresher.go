@@ -0,0 +1,348 @@
+package msk
+
+import (
+	"math/rand"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// metadataRefresherMaxBackoff caps how long the refresher will wait between
+// attempts after repeated ErrLeaderNotAvailable responses, so a transient
+// controller election doesn't turn into an unbounded stall.
+const metadataRefresherMaxBackoff = 5 * time.Minute
+
+// topologySnapshot is the set of topics and brokers the refresher last saw,
+// used to diff against the next refresh and fire added/removed callbacks.
+type topologySnapshot struct {
+	topics       map[string]bool
+	brokers      map[int32]bool
+	controllerID int32
+}
+
+// MetadataRefresher periodically refreshes a sarama.Client's cluster
+// metadata and diffs the result against its previous snapshot, firing
+// callbacks as topics/brokers come and go or the controller changes. This
+// lets the transformer create entities for newly discovered topics and
+// brokers as soon as the refresher notices them, instead of waiting for the
+// next CloudWatch batch to mention them.
+type MetadataRefresher struct {
+	client   sarama.Client
+	interval time.Duration
+
+	includeRegex *regexp.Regexp
+	excludeRegex *regexp.Regexp
+
+	mu       sync.Mutex
+	snapshot topologySnapshot
+
+	onTopicAdded        []func(topic string)
+	onTopicRemoved      []func(topic string)
+	onBrokerAdded       []func(brokerID int32)
+	onBrokerRemoved     []func(brokerID int32)
+	onControllerChanged []func(brokerID int32)
+
+	refreshing int32 // atomic flag, deduplicates concurrent refresh() calls
+	backoff    time.Duration
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMetadataRefresher creates a MetadataRefresher for client, configured by
+// config's refresh interval and topic include/exclude regex filters. Invalid
+// regexes are logged and ignored, matching every topic rather than failing
+// to start.
+func NewMetadataRefresher(client sarama.Client, config *Config) *MetadataRefresher {
+	interval := config.MetadataRefreshInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	r := &MetadataRefresher{
+		client:   client,
+		interval: interval,
+		snapshot: topologySnapshot{
+			topics:  make(map[string]bool),
+			brokers: make(map[int32]bool),
+		},
+		done: make(chan struct{}),
+	}
+
+	if config.TopicIncludeRegex != "" {
+		re, err := regexp.Compile(config.TopicIncludeRegex)
+		if err != nil {
+			log.Error("MetadataRefresher: invalid TopicIncludeRegex %q: %v", config.TopicIncludeRegex, err)
+		} else {
+			r.includeRegex = re
+		}
+	}
+	if config.TopicExcludeRegex != "" {
+		re, err := regexp.Compile(config.TopicExcludeRegex)
+		if err != nil {
+			log.Error("MetadataRefresher: invalid TopicExcludeRegex %q: %v", config.TopicExcludeRegex, err)
+		} else {
+			r.excludeRegex = re
+		}
+	}
+
+	return r
+}
+
+// OnTopicAdded registers a callback fired when refresh() observes a topic
+// that wasn't present (after filtering) in the previous snapshot.
+func (r *MetadataRefresher) OnTopicAdded(cb func(topic string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onTopicAdded = append(r.onTopicAdded, cb)
+}
+
+// OnTopicRemoved registers a callback fired when a previously seen topic is
+// absent from the latest refresh.
+func (r *MetadataRefresher) OnTopicRemoved(cb func(topic string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onTopicRemoved = append(r.onTopicRemoved, cb)
+}
+
+// OnBrokerAdded registers a callback fired when refresh() observes a broker
+// ID that wasn't present in the previous snapshot.
+func (r *MetadataRefresher) OnBrokerAdded(cb func(brokerID int32)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onBrokerAdded = append(r.onBrokerAdded, cb)
+}
+
+// OnBrokerRemoved registers a callback fired when a previously seen broker
+// ID is absent from the latest refresh.
+func (r *MetadataRefresher) OnBrokerRemoved(cb func(brokerID int32)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onBrokerRemoved = append(r.onBrokerRemoved, cb)
+}
+
+// OnControllerChanged registers a callback fired when the controller broker
+// ID differs from the previous snapshot.
+func (r *MetadataRefresher) OnControllerChanged(cb func(brokerID int32)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onControllerChanged = append(r.onControllerChanged, cb)
+}
+
+// Start launches the background refresh loop on a jittered ticker (interval
+// plus up to 20% more, so many integrations started at once don't all refresh in
+// lockstep) and runs until Stop is called.
+func (r *MetadataRefresher) Start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Stop halts the background refresh loop and waits for it to exit.
+func (r *MetadataRefresher) Stop() {
+	close(r.done)
+	r.wg.Wait()
+}
+
+func (r *MetadataRefresher) run() {
+	defer r.wg.Done()
+
+	for {
+		wait := r.nextInterval()
+		select {
+		case <-time.After(wait):
+			if err := r.refresh(); err != nil {
+				log.Error("MetadataRefresher: refresh failed: %v", err)
+			}
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// nextInterval returns the jittered wait before the next refresh attempt,
+// or the accumulated backoff if the last refresh hit ErrLeaderNotAvailable.
+func (r *MetadataRefresher) nextInterval() time.Duration {
+	r.mu.Lock()
+	backoff := r.backoff
+	r.mu.Unlock()
+
+	if backoff > 0 {
+		return backoff
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(r.interval) / 5)) // up to 20%
+	return r.interval + jitter
+}
+
+// refresh pulls the latest cluster metadata and diffs it against the
+// previous snapshot, firing the registered callbacks for whatever changed.
+// Concurrent calls are deduplicated: if a refresh is already in flight, a
+// second call returns immediately rather than racing it.
+func (r *MetadataRefresher) refresh() error {
+	if !atomic.CompareAndSwapInt32(&r.refreshing, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreInt32(&r.refreshing, 0)
+
+	if err := r.client.RefreshMetadata(); err != nil {
+		r.applyBackoff(err)
+		return err
+	}
+
+	topics, err := r.client.Topics()
+	if err != nil {
+		r.applyBackoff(err)
+		return err
+	}
+
+	brokers := r.client.Brokers()
+
+	controller, err := r.client.Controller()
+	var controllerID int32 = -1
+	if err == nil && controller != nil {
+		controllerID = controller.ID()
+	}
+
+	r.clearBackoff()
+
+	filtered := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		if r.topicIncluded(topic) {
+			filtered[topic] = true
+		}
+	}
+
+	brokerIDs := make(map[int32]bool, len(brokers))
+	for _, b := range brokers {
+		brokerIDs[b.ID()] = true
+	}
+
+	r.diffAndNotify(filtered, brokerIDs, controllerID)
+
+	return nil
+}
+
+// topicIncluded reports whether topic passes the configured include/exclude
+// filters: it must match TopicIncludeRegex (if set) and must not match
+// TopicExcludeRegex (if set).
+func (r *MetadataRefresher) topicIncluded(topic string) bool {
+	if r.includeRegex != nil && !r.includeRegex.MatchString(topic) {
+		return false
+	}
+	if r.excludeRegex != nil && r.excludeRegex.MatchString(topic) {
+		return false
+	}
+	return true
+}
+
+// diffAndNotify compares the new topics/brokers/controller against the
+// stored snapshot, fires callbacks for every change, then replaces the
+// snapshot.
+func (r *MetadataRefresher) diffAndNotify(topics map[string]bool, brokers map[int32]bool, controllerID int32) {
+	r.mu.Lock()
+	prev := r.snapshot
+	topicAdded, topicRemoved := diffStringSets(prev.topics, topics)
+	brokerAdded, brokerRemoved := diffInt32Sets(prev.brokers, brokers)
+	controllerChanged := controllerID >= 0 && controllerID != prev.controllerID
+
+	onTopicAdded := append([]func(string){}, r.onTopicAdded...)
+	onTopicRemoved := append([]func(string){}, r.onTopicRemoved...)
+	onBrokerAdded := append([]func(int32){}, r.onBrokerAdded...)
+	onBrokerRemoved := append([]func(int32){}, r.onBrokerRemoved...)
+	onControllerChanged := append([]func(int32){}, r.onControllerChanged...)
+
+	r.snapshot = topologySnapshot{topics: topics, brokers: brokers, controllerID: controllerID}
+	r.mu.Unlock()
+
+	for _, topic := range topicAdded {
+		for _, cb := range onTopicAdded {
+			cb(topic)
+		}
+	}
+	for _, topic := range topicRemoved {
+		for _, cb := range onTopicRemoved {
+			cb(topic)
+		}
+	}
+	for _, id := range brokerAdded {
+		for _, cb := range onBrokerAdded {
+			cb(id)
+		}
+	}
+	for _, id := range brokerRemoved {
+		for _, cb := range onBrokerRemoved {
+			cb(id)
+		}
+	}
+	if controllerChanged {
+		for _, cb := range onControllerChanged {
+			cb(controllerID)
+		}
+	}
+}
+
+// applyBackoff doubles the refresher's backoff (capped at
+// metadataRefresherMaxBackoff) when the cluster returns
+// ErrLeaderNotAvailable, which is typically transient during a controller
+// election; any other error leaves the normal interval in place.
+func (r *MetadataRefresher) applyBackoff(err error) {
+	if err != sarama.ErrLeaderNotAvailable {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.backoff == 0 {
+		r.backoff = r.interval
+	} else {
+		r.backoff *= 2
+	}
+	if r.backoff > metadataRefresherMaxBackoff {
+		r.backoff = metadataRefresherMaxBackoff
+	}
+	log.Warn("MetadataRefresher: leader not available, backing off %s", r.backoff)
+}
+
+// clearBackoff resets the backoff once a refresh succeeds.
+func (r *MetadataRefresher) clearBackoff() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.backoff = 0
+}
+
+// diffStringSets returns the keys present in next but not prev (added) and
+// present in prev but not next (removed).
+func diffStringSets(prev, next map[string]bool) (added, removed []string) {
+	for k := range next {
+		if !prev[k] {
+			added = append(added, k)
+		}
+	}
+	for k := range prev {
+		if !next[k] {
+			removed = append(removed, k)
+		}
+	}
+	return added, removed
+}
+
+// diffInt32Sets returns the keys present in next but not prev (added) and
+// present in prev but not next (removed).
+func diffInt32Sets(prev, next map[int32]bool) (added, removed []int32) {
+	for k := range next {
+		if !prev[k] {
+			added = append(added, k)
+		}
+	}
+	for k := range prev {
+		if !next[k] {
+			removed = append(removed, k)
+		}
+	}
+	return added, removed
+}
@@ -2,30 +2,138 @@ package msk
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 )
 
+// compressionCodec identifies a Metric API POST body encoding.
+type compressionCodec string
+
+const (
+	CompressionNone   compressionCodec = "none"
+	CompressionGzip   compressionCodec = "gzip"
+	CompressionSnappy compressionCodec = "snappy"
+	CompressionZstd   compressionCodec = "zstd"
+)
+
 // MetricAPIClient sends dimensional metrics directly to New Relic Metric API
 type MetricAPIClient struct {
 	apiKey     string
 	endpoint   string
 	httpClient *http.Client
+
+	compression compressionCodec
+	downgraded  int32 // set to 1 once a 415 forces compression off for the process lifetime
+
+	gzipWriters  sync.Pool
+	zstdEncoders sync.Pool
+
+	errorCollector *ErrorCollector
+	report         *ValidationReport
 }
 
-// NewMetricAPIClient creates a new Metric API client
-func NewMetricAPIClient(apiKey string) *MetricAPIClient {
-	return &MetricAPIClient{
+// NewMetricAPIClient creates a new Metric API client that compresses POST
+// bodies with compression ("none", "gzip", "snappy", or "zstd"); an
+// unrecognized value falls back to gzip.
+func NewMetricAPIClient(apiKey string, compression string) *MetricAPIClient {
+	codec := compressionCodec(compression)
+	switch codec {
+	case CompressionNone, CompressionGzip, CompressionSnappy, CompressionZstd:
+	default:
+		codec = CompressionGzip
+	}
+
+	c := &MetricAPIClient{
 		apiKey:   apiKey,
 		endpoint: "https://metric-api.newrelic.com/metric/v1",
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		compression: codec,
+	}
+	c.gzipWriters.New = func() interface{} { return gzip.NewWriter(io.Discard) }
+	c.zstdEncoders.New = func() interface{} {
+		enc, _ := zstd.NewWriter(nil)
+		return enc
+	}
+	return c
+}
+
+// SetErrorCollector wires an ErrorCollector the client records a one-time
+// note into when a 415 forces a compression downgrade.
+func (c *MetricAPIClient) SetErrorCollector(errorCollector *ErrorCollector) {
+	c.errorCollector = errorCollector
+}
+
+// SetValidationReport wires a ValidationReport the client accumulates its
+// CompressedBytes/UncompressedBytes counters into.
+func (c *MetricAPIClient) SetValidationReport(report *ValidationReport) {
+	c.report = report
+}
+
+// activeCompression returns the codec currently in effect, which is always
+// CompressionNone once the endpoint has returned a 415 for this process.
+func (c *MetricAPIClient) activeCompression() compressionCodec {
+	if atomic.LoadInt32(&c.downgraded) == 1 {
+		return CompressionNone
+	}
+	return c.compression
+}
+
+// compress encodes data with codec, reusing a pooled encoder for gzip and
+// zstd to avoid a per-batch allocation; snappy's block encoder is already
+// stateless so it needs no pool.
+func (c *MetricAPIClient) compress(codec compressionCodec, data []byte) ([]byte, error) {
+	switch codec {
+	case CompressionGzip:
+		w := c.gzipWriters.Get().(*gzip.Writer)
+		defer c.gzipWriters.Put(w)
+
+		var buf bytes.Buffer
+		w.Reset(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("gzip compress: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("gzip compress: %v", err)
+		}
+		return buf.Bytes(), nil
+	case CompressionSnappy:
+		return snappy.Encode(nil, data), nil
+	case CompressionZstd:
+		enc := c.zstdEncoders.Get().(*zstd.Encoder)
+		defer c.zstdEncoders.Put(enc)
+		return enc.EncodeAll(data, nil), nil
+	default:
+		return data, nil
+	}
+}
+
+// downgradeToNone disables compression for the rest of the process after
+// the endpoint returns a 415 (Unsupported Media Type), logging once so a
+// misconfigured or outdated Metric API endpoint doesn't spam the log on
+// every subsequent batch.
+func (c *MetricAPIClient) downgradeToNone(codec compressionCodec) {
+	if !atomic.CompareAndSwapInt32(&c.downgraded, 0, 1) {
+		return
+	}
+	log.Warn("Metric API returned 415 for Content-Encoding %s; disabling compression for the rest of this process", codec)
+	if c.errorCollector != nil {
+		c.errorCollector.AddError(TransformationError{
+			EventType: "metricAPI",
+			Field:     "compression",
+			Reason:    fmt.Sprintf("downgraded from %s to none after HTTP 415", codec),
+		})
 	}
 }
 
@@ -87,13 +195,31 @@ func (c *MetricAPIClient) SendMetrics(metrics []MetricData) error {
 		return fmt.Errorf("failed to marshal metrics: %v", err)
 	}
 
-	req, err := http.NewRequest("POST", c.endpoint, bytes.NewBuffer(jsonData))
+	codec := c.activeCompression()
+	requestBody := jsonData
+	if codec != CompressionNone {
+		compressed, compressErr := c.compress(codec, jsonData)
+		if compressErr != nil {
+			log.Warn("Failed to compress metric payload with %s, sending uncompressed: %v", codec, compressErr)
+			codec = CompressionNone
+		} else {
+			requestBody = compressed
+		}
+	}
+	if c.report != nil {
+		c.report.RecordCompression(int64(len(requestBody)), int64(len(jsonData)))
+	}
+
+	req, err := http.NewRequest("POST", c.endpoint, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %v", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Api-Key", c.apiKey)
+	if codec != CompressionNone {
+		req.Header.Set("Content-Encoding", string(codec))
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -102,6 +228,10 @@ func (c *MetricAPIClient) SendMetrics(metrics []MetricData) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnsupportedMediaType && codec != CompressionNone {
+		c.downgradeToNone(codec)
+	}
+
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		body, _ := io.ReadAll(resp.Body)
 		log.Error("Metric API error - Status: %d, Body: %s", resp.StatusCode, string(body))
@@ -114,10 +244,12 @@ func (c *MetricAPIClient) SendMetrics(metrics []MetricData) error {
 
 // BatchCollector collects metrics for efficient batch sending
 type BatchCollector struct {
+	mu          sync.Mutex
 	client      *MetricAPIClient
 	metrics     []MetricData
 	maxBatch    int
 	flushTicker *time.Ticker
+	latency     *LatencyAggregator
 }
 
 // NewBatchCollector creates a new batch collector
@@ -135,49 +267,83 @@ func NewBatchCollector(client *MetricAPIClient, maxBatch int, flushInterval time
 	return bc
 }
 
-// AddMetric adds a metric to the batch
+// AddMetric adds a metric to the batch, stamped with the current time. It is
+// safe to call concurrently; metrics and the flush it may trigger are both
+// guarded by mu so concurrent Add calls from multiple transformer goroutines
+// can't corrupt the backing slice.
 func (bc *BatchCollector) AddMetric(name string, value float64, attributes map[string]interface{}) {
+	bc.AddMetricWithTimestamp(name, value, time.Now().UnixNano()/1e6, attributes)
+}
+
+// AddMetricWithTimestamp adds a metric to the batch stamped with an
+// explicit timestamp (epoch milliseconds), for callers that know the real
+// time the sample was produced rather than when it happened to be
+// transformed.
+func (bc *BatchCollector) AddMetricWithTimestamp(name string, value float64, timestamp int64, attributes map[string]interface{}) {
 	// Ensure all attribute values are strings
 	stringAttrs := make(map[string]interface{})
 	for k, v := range attributes {
 		stringAttrs[k] = fmt.Sprintf("%v", v)
 	}
-	
+
 	metric := MetricData{
 		Name:       name,
 		Type:       "gauge",
 		Value:      value,
-		Timestamp:  time.Now().UnixNano() / 1e6, // Milliseconds not seconds
+		Timestamp:  timestamp,
 		Attributes: stringAttrs,
 	}
 
+	bc.mu.Lock()
 	bc.metrics = append(bc.metrics, metric)
+	shouldFlush := len(bc.metrics) >= bc.maxBatch
+	bc.mu.Unlock()
 
 	// Flush if batch is full
-	if len(bc.metrics) >= bc.maxBatch {
+	if shouldFlush {
 		bc.Flush()
 	}
 }
 
 // Flush sends all collected metrics
 func (bc *BatchCollector) Flush() error {
+	bc.mu.Lock()
 	if len(bc.metrics) == 0 {
+		bc.mu.Unlock()
 		return nil
 	}
-	
-	log.Info("Flushing %d metrics from batch collector", len(bc.metrics))
 
-	// Copy metrics for sending
+	// Copy metrics for sending, then clear the buffer, all under the lock
 	metricsToSend := make([]MetricData, len(bc.metrics))
 	copy(metricsToSend, bc.metrics)
-
-	// Clear the buffer
 	bc.metrics = bc.metrics[:0]
+	bc.mu.Unlock()
+
+	log.Info("Flushing %d metrics from batch collector", len(metricsToSend))
 
 	// Send metrics
 	return bc.client.SendMetrics(metricsToSend)
 }
 
+// relationshipMetricName is the Metric API metric name a topology edge is
+// carried under - New Relic's entity platform derives MSK CONTAINS/
+// CONSUMES_FROM relationships from these source/target/type attributes the
+// same way it derives AWS_KAFKA_* entities from this integration's other
+// dimensional metrics, since the Metric API is the only outbound pipe this
+// integration has.
+const relationshipMetricName = "newrelic.entity.relationship"
+
+// AddRelationship records one (sourceGUID, targetGUID, relType) topology
+// edge - e.g. CONTAINS from a cluster to a broker - via the same batch/flush
+// path as AddMetric.
+func (bc *BatchCollector) AddRelationship(sourceGUID, targetGUID, relType string) {
+	bc.AddMetricWithTimestamp(relationshipMetricName, 1, time.Now().UnixNano()/1e6, map[string]interface{}{
+		"relationship.source.guid": sourceGUID,
+		"relationship.target.guid": targetGUID,
+		"relationship.type":        relType,
+	})
+}
+
 // backgroundFlusher periodically flushes metrics
 func (bc *BatchCollector) backgroundFlusher() {
 	for range bc.flushTicker.C {
@@ -0,0 +1,127 @@
+package msk
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// RetryConfig controls the exponential-backoff-with-jitter schedule
+// SendMetricsWithRetry uses, and where undeliverable batches get spooled.
+type RetryConfig struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	SpoolDir     string
+}
+
+// DefaultRetryConfig matches the flush cadence Config.FlushInterval
+// implies: a handful of quick retries before giving up and spooling.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:  5,
+		InitialDelay: 500 * time.Millisecond,
+		MaxDelay:     30 * time.Second,
+		SpoolDir:     filepath.Join(os.TempDir(), "nri-kafka-msk-spool"),
+	}
+}
+
+// backoffDelay computes attempt N's delay using full jitter: a random
+// duration in [0, min(maxDelay, initial*2^attempt)]. Full jitter avoids the
+// thundering-herd retries synchronized exponential backoff produces.
+func backoffDelay(attempt int, initial, max time.Duration) time.Duration {
+	exp := initial * time.Duration(1<<uint(attempt))
+	if exp > max || exp <= 0 {
+		exp = max
+	}
+	return time.Duration(rand.Int63n(int64(exp) + 1))
+}
+
+// SendMetricsWithRetry sends metrics, making up to config.MaxAttempts
+// attempts with exponential backoff and jitter between them. If every
+// attempt fails, the batch
+// is spooled to disk under config.SpoolDir instead of being dropped.
+func (c *MetricAPIClient) SendMetricsWithRetry(metrics []MetricData, config RetryConfig) error {
+	var lastErr error
+	for attempt := 0; attempt < config.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := backoffDelay(attempt, config.InitialDelay, config.MaxDelay)
+			log.Debug("MetricAPIClient retry %d/%d after %s", attempt, config.MaxAttempts, delay)
+			time.Sleep(delay)
+		}
+
+		if err := c.SendMetrics(metrics); err != nil {
+			lastErr = err
+			log.Warn("MetricAPIClient send attempt %d/%d failed: %v", attempt+1, config.MaxAttempts, err)
+			continue
+		}
+		return nil
+	}
+
+	if err := spoolToDisk(config.SpoolDir, metrics); err != nil {
+		log.Error("MetricAPIClient: failed to spool undeliverable batch to disk: %v", err)
+	} else {
+		log.Warn("MetricAPIClient: spooled %d undeliverable metric(s) to %s after %d attempts", len(metrics), config.SpoolDir, config.MaxAttempts)
+	}
+
+	return fmt.Errorf("failed to send metrics after %d attempts: %w", config.MaxAttempts, lastErr)
+}
+
+// spoolToDisk writes a failed batch as a timestamped JSON file under dir.
+func spoolToDisk(dir string, metrics []MetricData) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating spool dir %s: %w", dir, err)
+	}
+
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("marshaling spooled batch: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("batch-%d.json", time.Now().UnixNano()))
+	return os.WriteFile(path, data, 0o644)
+}
+
+// ReplaySpooledBatches reads and sends every spooled batch under
+// config.SpoolDir, deleting each file on success so a retried batch isn't
+// replayed twice.
+func (c *MetricAPIClient) ReplaySpooledBatches(config RetryConfig) error {
+	entries, err := os.ReadDir(config.SpoolDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading spool dir %s: %w", config.SpoolDir, err)
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(config.SpoolDir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Warn("MetricAPIClient: failed to read spooled batch %s: %v", path, err)
+			continue
+		}
+
+		var metrics []MetricData
+		if err := json.Unmarshal(data, &metrics); err != nil {
+			log.Warn("MetricAPIClient: failed to parse spooled batch %s: %v", path, err)
+			continue
+		}
+
+		if err := c.SendMetrics(metrics); err != nil {
+			log.Warn("MetricAPIClient: replay of spooled batch %s still failing: %v", path, err)
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			log.Warn("MetricAPIClient: failed to remove replayed spool file %s: %v", path, err)
+		}
+	}
+
+	return nil
+}
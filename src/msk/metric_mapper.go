@@ -15,6 +15,33 @@ type MetricMapper struct {
 	
 	// Topic metric mappings
 	topicMappings map[string]string
+
+	// Client/quota metric mappings, keyed by the JMX attribute name
+	// (e.g. "byte-rate") scoped to a quota type (e.g. "Produce").
+	clientMappings map[string]ClientMapping
+
+	// fuzzyBrokerIndex lazily indexes brokerMappings by normalized key,
+	// for MapBrokerMetricFuzzy.
+	fuzzyBrokerIndex map[string]string
+
+	// Request-purgatory mappings, keyed by "<delayedOperation>.<attr>"
+	// (e.g. "Produce.PurgatorySize").
+	purgatoryMappings map[string]string
+
+	// Partition-reassignment (KIP-455) mappings, keyed by a short signal
+	// name ("inProgress", "bytesRemaining", "addingReplicas",
+	// "removingReplicas", "clusterActive").
+	reassignmentMappings map[string]string
+}
+
+// ClientMapping defines how to map a per-client-id/per-user quota MBean
+// attribute (kafka.server:type={Produce,Fetch,Request},
+// user=<u>,client-id=<c>) to an MSK metric name, plus which tag keys the
+// caller should attach alongside it.
+type ClientMapping struct {
+	MetricName string
+	QuotaType  string
+	TagKeys    []string
 }
 
 // RequestMapping defines how to map RequestMetrics beans
@@ -27,18 +54,76 @@ type RequestMapping struct {
 // NewMetricMapper creates a comprehensive metric mapper
 func NewMetricMapper() *MetricMapper {
 	m := &MetricMapper{
-		brokerMappings:  make(map[string]string),
-		requestMappings: make(map[string]RequestMapping),
-		topicMappings:   make(map[string]string),
+		brokerMappings:       make(map[string]string),
+		requestMappings:      make(map[string]RequestMapping),
+		topicMappings:        make(map[string]string),
+		clientMappings:       make(map[string]ClientMapping),
+		purgatoryMappings:    make(map[string]string),
+		reassignmentMappings: make(map[string]string),
 	}
-	
+
 	m.initializeBrokerMappings()
 	m.initializeRequestMappings()
 	m.initializeTopicMappings()
-	
+	m.initializeClientMappings()
+	m.initializePurgatoryMappings()
+	m.initializeReassignmentMappings()
+
 	return m
 }
 
+// initializeReassignmentMappings sets up KIP-455 partition-reassignment
+// metric names.
+func (m *MetricMapper) initializeReassignmentMappings() {
+	m.reassignmentMappings["inProgress"] = "kafka.reassignment.inProgress"
+	m.reassignmentMappings["bytesRemaining"] = "kafka.reassignment.bytesRemaining"
+	m.reassignmentMappings["addingReplicas"] = "kafka.reassignment.addingReplicas"
+	m.reassignmentMappings["removingReplicas"] = "kafka.reassignment.removingReplicas"
+	m.reassignmentMappings["clusterActive"] = "kafka.cluster.activeReassignments"
+	m.reassignmentMappings["brokerReassigning"] = "broker.reassigningPartitions"
+	m.reassignmentMappings["topicReassigning"] = "topic.reassigningPartitions"
+}
+
+// delayedOperations are the five kinds of delayed operation Kafka tracks
+// purgatories for, keyed by the JMX `delayedOperation=` tag with the
+// lower-camel-case prefix used in MSK metric names.
+var delayedOperations = map[string]string{
+	"Produce":       "produce",
+	"Fetch":         "fetch",
+	"DeleteRecords": "deleteRecords",
+	"Heartbeat":     "heartbeat",
+	"Rebalance":     "rebalance",
+}
+
+// purgatoryAttributes maps each DelayedOperationPurgatory JMX attribute
+// to the MSK metric name suffix it should produce.
+var purgatoryAttributes = map[string]string{
+	"PurgatorySize":        "Size",
+	"NumDelayedOperations": "DelayedOps",
+}
+
+// initializePurgatoryMappings sets up kafka.server:type=
+// DelayedOperationPurgatory mappings for every delayed-operation kind
+// across both attributes. These are the canonical signal for stuck acks,
+// follower fetch delays, and consumer-group instability.
+func (m *MetricMapper) initializePurgatoryMappings() {
+	for delayedOp, prefix := range delayedOperations {
+		for attr, suffix := range purgatoryAttributes {
+			key := fmt.Sprintf("%s.%s", delayedOp, attr)
+			capitalizedPrefix := strings.ToUpper(prefix[:1]) + prefix[1:]
+			m.purgatoryMappings[key] = fmt.Sprintf("kafka.broker.purgatory%s%s", capitalizedPrefix, suffix)
+		}
+	}
+}
+
+// MapPurgatoryMetric maps a "<delayedOperation>.<attribute>" key (e.g.
+// "Produce.PurgatorySize") to its MSK metric name.
+func (m *MetricMapper) MapPurgatoryMetric(delayedOperation, attribute string) (string, bool) {
+	key := fmt.Sprintf("%s.%s", delayedOperation, attribute)
+	mskMetric, exists := m.purgatoryMappings[key]
+	return mskMetric, exists
+}
+
 // initializeBrokerMappings sets up broker metric mappings
 func (m *MetricMapper) initializeBrokerMappings() {
 	// Throughput metrics
@@ -83,50 +168,49 @@ func (m *MetricMapper) initializeBrokerMappings() {
 	m.brokerMappings["broker.totalProduceRequestsPerSecond"] = "kafka.broker.produceRequestsPerSec"
 }
 
-// initializeRequestMappings sets up RequestMetrics mappings
+// requestTimeBuckets are the RequestMetrics time-bucket attributes every
+// standard request type exposes under
+// kafka.network:type=RequestMetrics,name=<Timer>,request=<Req>, alongside
+// the MSK metric name suffix each maps to.
+var requestTimeBuckets = map[string]string{
+	"LocalTimeMs":         "LocalTime",
+	"RequestQueueTimeMs":  "RequestQueueTime",
+	"ResponseSendTimeMs":  "ResponseSendTime",
+	"TotalTimeMs":         "TotalTime",
+	"RemoteTimeMs":        "RemoteTime",
+	"ThrottleTimeMs":      "ThrottleTime",
+	"ResponseQueueTimeMs": "ResponseQueueTime",
+}
+
+// requestTypes are the standard Kafka request types the broker exposes
+// RequestMetrics for, keyed by the JMX `request=` tag with the
+// lower-camel-case prefix used in MSK metric names.
+var requestTypes = map[string]string{
+	"Produce":       "produce",
+	"FetchConsumer": "fetchConsumer",
+	"FetchFollower": "fetchFollower",
+	"Metadata":      "metadata",
+	"OffsetCommit":  "offsetCommit",
+	"OffsetFetch":   "offsetFetch",
+	"JoinGroup":     "joinGroup",
+	"SyncGroup":     "syncGroup",
+	"Heartbeat":     "heartbeat",
+	"ApiVersions":   "apiVersions",
+}
+
+// initializeRequestMappings sets up RequestMetrics mappings for every
+// standard request type across every time bucket IsRequestMetric
+// recognizes.
 func (m *MetricMapper) initializeRequestMappings() {
-	// Fetch consumer metrics
-	m.requestMappings["fetchConsumerLocalTimeMs"] = RequestMapping{
-		MetricName:    "kafka.broker.fetchConsumerLocalTime",
-		RequestType:   "FetchConsumer",
-		AttributeName: "LocalTimeMs",
-	}
-	m.requestMappings["fetchConsumerRequestQueueTimeMs"] = RequestMapping{
-		MetricName:    "kafka.broker.fetchConsumerRequestQueueTime",
-		RequestType:   "FetchConsumer",
-		AttributeName: "RequestQueueTimeMs",
-	}
-	m.requestMappings["fetchConsumerResponseSendTimeMs"] = RequestMapping{
-		MetricName:    "kafka.broker.fetchConsumerResponseSendTime",
-		RequestType:   "FetchConsumer",
-		AttributeName: "ResponseSendTimeMs",
-	}
-	m.requestMappings["fetchConsumerTotalTimeMs"] = RequestMapping{
-		MetricName:    "kafka.broker.fetchConsumerTotalTime",
-		RequestType:   "FetchConsumer",
-		AttributeName: "TotalTimeMs",
-	}
-	
-	// Produce metrics
-	m.requestMappings["produceLocalTimeMs"] = RequestMapping{
-		MetricName:    "kafka.broker.produceLocalTime",
-		RequestType:   "Produce",
-		AttributeName: "LocalTimeMs",
-	}
-	m.requestMappings["produceRequestQueueTimeMs"] = RequestMapping{
-		MetricName:    "kafka.broker.produceRequestQueueTime",
-		RequestType:   "Produce",
-		AttributeName: "RequestQueueTimeMs",
-	}
-	m.requestMappings["produceResponseSendTimeMs"] = RequestMapping{
-		MetricName:    "kafka.broker.produceResponseSendTime",
-		RequestType:   "Produce",
-		AttributeName: "ResponseSendTimeMs",
-	}
-	m.requestMappings["produceTotalTimeMs"] = RequestMapping{
-		MetricName:    "kafka.broker.produceTotalTime",
-		RequestType:   "Produce",
-		AttributeName: "TotalTimeMs",
+	for requestType, prefix := range requestTypes {
+		for attr, suffix := range requestTimeBuckets {
+			key := fmt.Sprintf("%s%sMs", prefix, suffix)
+			m.requestMappings[key] = RequestMapping{
+				MetricName:    fmt.Sprintf("kafka.broker.%s%s", prefix, suffix),
+				RequestType:   requestType,
+				AttributeName: attr,
+			}
+		}
 	}
 }
 
@@ -148,6 +232,49 @@ func (m *MetricMapper) initializeTopicMappings() {
 	m.topicMappings["topic.sizeInBytes"] = "kafka.topic.sizeInBytes"
 }
 
+// initializeClientMappings sets up per-client-id/per-user quota and
+// throughput mappings, for the kafka.server:type={Produce,Fetch,Request}
+// MBeans Kafka publishes per clientId (and per authenticated user).
+func (m *MetricMapper) initializeClientMappings() {
+	m.clientMappings["produce.byte-rate"] = ClientMapping{
+		MetricName: "kafka.client.produceByteRate",
+		QuotaType:  "Produce",
+		TagKeys:    []string{"clientId", "user", "quotaType"},
+	}
+	m.clientMappings["produce.throttle-time"] = ClientMapping{
+		MetricName: "kafka.client.produceThrottleTime",
+		QuotaType:  "Produce",
+		TagKeys:    []string{"clientId", "user", "quotaType"},
+	}
+	m.clientMappings["fetch.byte-rate"] = ClientMapping{
+		MetricName: "kafka.client.fetchByteRate",
+		QuotaType:  "Fetch",
+		TagKeys:    []string{"clientId", "user", "quotaType"},
+	}
+	m.clientMappings["fetch.throttle-time"] = ClientMapping{
+		MetricName: "kafka.client.fetchThrottleTime",
+		QuotaType:  "Fetch",
+		TagKeys:    []string{"clientId", "user", "quotaType"},
+	}
+	m.clientMappings["request.request-time"] = ClientMapping{
+		MetricName: "kafka.client.requestTime",
+		QuotaType:  "Request",
+		TagKeys:    []string{"clientId", "user", "quotaType"},
+	}
+	m.clientMappings["request.throttle-time"] = ClientMapping{
+		MetricName: "kafka.client.requestThrottleTime",
+		QuotaType:  "Request",
+		TagKeys:    []string{"clientId", "user", "quotaType"},
+	}
+}
+
+// MapClientMetric maps a per-client quota/throughput JMX attribute (e.g.
+// "produce.byte-rate") to its MSK metric name and tag keys.
+func (m *MetricMapper) MapClientMetric(kafkaMetric string) (ClientMapping, bool) {
+	mapping, exists := m.clientMappings[kafkaMetric]
+	return mapping, exists
+}
+
 // MapBrokerMetric maps a broker metric name to MSK format
 func (m *MetricMapper) MapBrokerMetric(kafkaMetric string) (string, bool) {
 	if mskMetric, exists := m.brokerMappings[kafkaMetric]; exists {
@@ -209,14 +336,22 @@ func GetJMXBeanForMetric(metricName string) string {
 		"topic.messagesInPerSecond": "kafka.server:type=BrokerTopicMetrics,name=MessagesInPerSec,topic=*",
 		"topic.bytesRejectedPerSecond": "kafka.server:type=BrokerTopicMetrics,name=BytesRejectedPerSec,topic=*",
 	}
-	
+
 	if bean, exists := beans[metricName]; exists {
 		return bean
 	}
-	
+
 	return ""
 }
 
+// GetPurgatoryJMXBean returns the DelayedOperationPurgatory bean pattern
+// for a given delayed-operation kind and attribute, e.g.
+// ("Produce", "PurgatorySize") ->
+// "kafka.server:type=DelayedOperationPurgatory,delayedOperation=Produce,name=PurgatorySize".
+func GetPurgatoryJMXBean(delayedOperation, attribute string) string {
+	return fmt.Sprintf("kafka.server:type=DelayedOperationPurgatory,delayedOperation=%s,name=%s", delayedOperation, attribute)
+}
+
 // IsRequestMetric checks if a metric is from RequestMetrics
 func IsRequestMetric(metricName string) bool {
 	requestMetrics := []string{
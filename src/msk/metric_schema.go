@@ -0,0 +1,181 @@
+package msk
+
+// MetricKind distinguishes how a schema-declared metric should be
+// aggregated across a collection cycle: Gauge values are point-in-time
+// (e.g. current partition count), Counter values are monotonically
+// increasing and should be diffed between cycles, and Rate values are
+// already expressed per-second by the source and pass through unchanged.
+type MetricKind int
+
+const (
+	MetricKindGauge MetricKind = iota
+	MetricKindCounter
+	MetricKindRate
+)
+
+// MetricSchema declares, once, everything a collector needs to know about
+// a single Kafka/MSK metric: where to find it in the flattened
+// map[string]interface{} a collector assembles (DataKey), what AWS MSK
+// metric name it maps to (TargetName), how it should be aggregated across
+// brokers/partitions (Aggregation), and its expected bounds
+// (MinValue/MaxValue). validateMetricValue does not read these bounds; it
+// checks only metricValidationRules, keyed by TargetName. Declaring metrics this way, instead of as
+// ad-hoc getFloatValue calls scattered across transformer variants, is
+// what lets a new Kafka version's metrics (e.g. KRaft-era controller
+// metrics) be registered declaratively via RegisterBrokerMetricSchema
+// instead of requiring a code change in every transformer.
+type MetricSchema struct {
+	ID          string
+	DataKey     string
+	TargetName  string
+	Kind        MetricKind
+	Aggregation string
+	MinValue    *float64
+	MaxValue    *float64
+}
+
+// metricExtractor is the compiled form of a MetricSchema: a closure
+// closed over DataKey so extraction doesn't need to re-dispatch on the
+// schema at every call.
+type metricExtractor func(data map[string]interface{}) (float64, bool)
+
+var (
+	brokerMetricSchemas   []MetricSchema
+	topicMetricSchemas    []MetricSchema
+	consumerMetricSchemas []MetricSchema
+
+	brokerExtractors   map[string]metricExtractor
+	topicExtractors    map[string]metricExtractor
+	consumerExtractors map[string]metricExtractor
+)
+
+func init() {
+	registerDefaultBrokerSchemas()
+	registerDefaultTopicSchemas()
+	registerDefaultConsumerSchemas()
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// registerDefaultBrokerSchemas declares the broker-level metrics
+// TransformBrokerMetrics has historically extracted via ad-hoc
+// getFloatValue/getIntValue calls.
+func registerDefaultBrokerSchemas() {
+	brokerMetricSchemas = []MetricSchema{
+		{ID: "broker.bytesInPerSecond", DataKey: "broker.bytesInPerSecond", TargetName: "kafka.broker.BytesInPerSec", Kind: MetricKindRate, Aggregation: "sum", MinValue: floatPtr(0), MaxValue: floatPtr(10 * 1024 * 1024 * 1024)},
+		{ID: "broker.bytesOutPerSecond", DataKey: "broker.bytesOutPerSecond", TargetName: "kafka.broker.BytesOutPerSec", Kind: MetricKindRate, Aggregation: "sum", MinValue: floatPtr(0), MaxValue: floatPtr(10 * 1024 * 1024 * 1024)},
+		{ID: "broker.messagesInPerSecond", DataKey: "broker.messagesInPerSecond", TargetName: "kafka.broker.MessagesInPerSec", Kind: MetricKindRate, Aggregation: "sum", MinValue: floatPtr(0), MaxValue: floatPtr(10000000)},
+		{ID: "broker.bytesRejectedPerSecond", DataKey: "broker.bytesRejectedPerSecond", TargetName: "kafka.broker.BytesRejectedPerSec", Kind: MetricKindRate, Aggregation: "sum", MinValue: floatPtr(0)},
+		{ID: "broker.underReplicatedPartitions", DataKey: "broker.underReplicatedPartitions", TargetName: "kafka.broker.UnderReplicatedPartitions", Kind: MetricKindGauge, Aggregation: "max", MinValue: floatPtr(0)},
+		{ID: "broker.ActiveControllerCount", DataKey: "broker.ActiveControllerCount", TargetName: "kafka.cluster.ActiveControllerCount", Kind: MetricKindGauge, Aggregation: "sum", MinValue: floatPtr(0), MaxValue: floatPtr(1)},
+		{ID: "broker.requestHandlerAvgIdlePercent", DataKey: "broker.requestHandlerAvgIdlePercent", TargetName: "kafka.broker.RequestHandlerAvgIdlePercent", Kind: MetricKindGauge, Aggregation: "avg", MinValue: floatPtr(0), MaxValue: floatPtr(1)},
+		{ID: "broker.networkProcessorAvgIdlePercent", DataKey: "broker.networkProcessorAvgIdlePercent", TargetName: "kafka.broker.NetworkProcessorAvgIdlePercent", Kind: MetricKindGauge, Aggregation: "avg", MinValue: floatPtr(0), MaxValue: floatPtr(1)},
+		{ID: "broker.produceThrottleTimeMs", DataKey: "broker.produceThrottleTimeMs", TargetName: "kafka.broker.ProduceThrottleTime", Kind: MetricKindGauge, Aggregation: "avg", MinValue: floatPtr(0)},
+		{ID: "broker.fetchThrottleTimeMs", DataKey: "broker.fetchThrottleTimeMs", TargetName: "kafka.broker.FetchThrottleTime", Kind: MetricKindGauge, Aggregation: "avg", MinValue: floatPtr(0)},
+		{ID: "broker.requestThrottleTimeMs", DataKey: "broker.requestThrottleTimeMs", TargetName: "kafka.broker.RequestThrottleTime", Kind: MetricKindGauge, Aggregation: "avg", MinValue: floatPtr(0)},
+	}
+	brokerExtractors = compileSchemas(brokerMetricSchemas)
+}
+
+// registerDefaultTopicSchemas declares the topic-level metrics
+// TransformTopicMetrics has historically extracted ad-hoc.
+func registerDefaultTopicSchemas() {
+	topicMetricSchemas = []MetricSchema{
+		{ID: "topic.bytesInPerSec", DataKey: "topic.bytesInPerSec", TargetName: "kafka.topic.BytesInPerSec", Kind: MetricKindRate, Aggregation: "sum", MinValue: floatPtr(0)},
+		{ID: "topic.bytesOutPerSec", DataKey: "topic.bytesOutPerSec", TargetName: "kafka.topic.BytesOutPerSec", Kind: MetricKindRate, Aggregation: "sum", MinValue: floatPtr(0)},
+		{ID: "topic.partitions", DataKey: "topic.partitions", TargetName: "kafka.topic.PartitionCount", Kind: MetricKindGauge, Aggregation: "last", MinValue: floatPtr(0)},
+		{ID: "topic.replicationFactor", DataKey: "topic.replicationFactor", TargetName: "kafka.topic.ReplicationFactor", Kind: MetricKindGauge, Aggregation: "last", MinValue: floatPtr(0)},
+	}
+	topicExtractors = compileSchemas(topicMetricSchemas)
+}
+
+// registerDefaultConsumerSchemas declares the consumer-group metrics the
+// lag enrichers extract ad-hoc.
+func registerDefaultConsumerSchemas() {
+	consumerMetricSchemas = []MetricSchema{
+		{ID: "consumer.lag", DataKey: "lag", TargetName: "kafka.consumer.MaxLag", Kind: MetricKindGauge, Aggregation: "max", MinValue: floatPtr(0), MaxValue: floatPtr(1000000)},
+		{ID: "consumer.totalLag", DataKey: "totalLag", TargetName: "kafka.consumer.TotalLag", Kind: MetricKindGauge, Aggregation: "sum", MinValue: floatPtr(0), MaxValue: floatPtr(1000000)},
+		{ID: "consumer.offset", DataKey: "consumerOffset", TargetName: "kafka.consumer.Offset", Kind: MetricKindCounter, Aggregation: "last", MinValue: floatPtr(0)},
+		{ID: "consumer.highWaterMark", DataKey: "highWaterMark", TargetName: "kafka.consumer.HighWaterMark", Kind: MetricKindCounter, Aggregation: "last", MinValue: floatPtr(0)},
+	}
+	consumerExtractors = compileSchemas(consumerMetricSchemas)
+}
+
+// compileSchemas turns a slice of declared schemas into a map of
+// extractor closures keyed by schema ID, so the hot extraction path is a
+// single map lookup plus a closure call rather than a type switch over
+// the schema itself.
+func compileSchemas(schemas []MetricSchema) map[string]metricExtractor {
+	compiled := make(map[string]metricExtractor, len(schemas))
+	for _, schema := range schemas {
+		dataKey := schema.DataKey
+		compiled[schema.ID] = func(data map[string]interface{}) (float64, bool) {
+			return getFloatValue(data, dataKey)
+		}
+	}
+	return compiled
+}
+
+// RegisterBrokerMetricSchema adds a new broker-level metric schema,
+// recompiling the extractor index. This is the entry point for declaring
+// a new Kafka version's metrics (e.g. KRaft-era controller metrics)
+// without touching TransformBrokerMetrics.
+func RegisterBrokerMetricSchema(schema MetricSchema) {
+	brokerMetricSchemas = append(brokerMetricSchemas, schema)
+	brokerExtractors = compileSchemas(brokerMetricSchemas)
+}
+
+// RegisterTopicMetricSchema adds a new topic-level metric schema.
+func RegisterTopicMetricSchema(schema MetricSchema) {
+	topicMetricSchemas = append(topicMetricSchemas, schema)
+	topicExtractors = compileSchemas(topicMetricSchemas)
+}
+
+// RegisterConsumerMetricSchema adds a new consumer-group metric schema.
+func RegisterConsumerMetricSchema(schema MetricSchema) {
+	consumerMetricSchemas = append(consumerMetricSchemas, schema)
+	consumerExtractors = compileSchemas(consumerMetricSchemas)
+}
+
+// extractSchemaMetrics runs every extractor in extractors against data,
+// validating each successfully extracted value via validateMetricValue
+// before including it in the result.
+func extractSchemaMetrics(data map[string]interface{}, schemas []MetricSchema, extractors map[string]metricExtractor) map[string]float64 {
+	result := make(map[string]float64, len(schemas))
+	for _, schema := range schemas {
+		extract, ok := extractors[schema.ID]
+		if !ok {
+			continue
+		}
+		value, ok := extract(data)
+		if !ok {
+			continue
+		}
+		if !validateMetricValue(value, schema.TargetName) {
+			continue
+		}
+		result[schema.TargetName] = value
+	}
+	return result
+}
+
+// ExtractBrokerMetrics runs the full broker metric schema against data,
+// returning every valid metric keyed by its AWS MSK target name. This
+// replaces walking brokerData field-by-field with ad-hoc getFloatValue
+// calls in transformers that adopt it.
+func ExtractBrokerMetrics(data map[string]interface{}) map[string]float64 {
+	return extractSchemaMetrics(data, brokerMetricSchemas, brokerExtractors)
+}
+
+// ExtractTopicMetrics runs the full topic metric schema against data.
+func ExtractTopicMetrics(data map[string]interface{}) map[string]float64 {
+	return extractSchemaMetrics(data, topicMetricSchemas, topicExtractors)
+}
+
+// ExtractConsumerMetrics runs the full consumer-group metric schema
+// against data.
+func ExtractConsumerMetrics(data map[string]interface{}) map[string]float64 {
+	return extractSchemaMetrics(data, consumerMetricSchemas, consumerExtractors)
+}
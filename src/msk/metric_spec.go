@@ -0,0 +1,124 @@
+package msk
+
+// RollupMetricKind classifies how a metric should be combined across scrapes
+// and across the partition->topic->broker->cluster hierarchy, replacing
+// the old implicit assumption that every metric is a point-in-time gauge.
+type RollupMetricKind int
+
+const (
+	// RollupMetricKindGauge is a point-in-time value; rolling up a set of them
+	// means picking Rollup's sum/mean/min/max across the set.
+	RollupMetricKindGauge RollupMetricKind = iota
+	// RollupMetricKindCounter is monotonically increasing; rolling up a set of
+	// them always means summing, regardless of Rollup.
+	RollupMetricKindCounter
+	// RollupMetricKindSummary is a distribution (e.g. request latency); rolling
+	// up a set of them means merging their HDR histograms rather than
+	// combining scalars, so percentiles stay accurate at every level.
+	RollupMetricKindSummary
+)
+
+// RollupKind selects how RollupMetricKindGauge values combine when rolled up
+// to the next level of the hierarchy. It's ignored for RollupMetricKindCounter
+// (always summed) and RollupMetricKindSummary (always HDR-merged).
+type RollupKind int
+
+const (
+	RollupSum RollupKind = iota
+	RollupMean
+	RollupMin
+	RollupMax
+)
+
+// MetricSpec declares one metric's source field, the output metric name
+// it maps to, and how it behaves when aggregated across the hierarchy.
+// A []MetricSpec registry replaces the old map[string]string mappings,
+// which left every metric's aggregation semantics implicit (and usually
+// wrong: provider.bytesInPerSec.Average used to just copy whatever one
+// broker reported, never actually averaging anything).
+type MetricSpec struct {
+	Source string
+	Target string
+	Kind   RollupMetricKind
+	Rollup RollupKind
+}
+
+// BrokerMetricSpecs is the broker-level metric registry, replacing
+// GetBrokerMetricMappings' map[string]string. Throughput and count
+// metrics default to RollupSum (a cluster's total bytes-in is the sum of
+// every broker's), while *.Mean/request-time metrics default to
+// RollupMean or RollupMetricKindSummary so a cluster-wide p99 isn't just one
+// arbitrary broker's value.
+func BrokerMetricSpecs() []MetricSpec {
+	return []MetricSpec{
+		{Source: "broker.messagesInPerSecond", Target: "aws.msk.MessagesInPerSec", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "broker.IOInPerSecond", Target: "aws.msk.BytesInPerSec", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "broker.IOOutPerSecond", Target: "aws.msk.BytesOutPerSec", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "broker.bytesWrittenToDiscPerSecond", Target: "aws.msk.BytesWrittenPerSec", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+
+		{Source: "broker.totalFetchRequestsPerSecond", Target: "aws.msk.FetchMessageConversionsPerSec", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "broker.totalProduceRequestsPerSecond", Target: "aws.msk.ProduceMessageConversionsPerSec", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+
+		{Source: "replication.unreplicatedPartitions", Target: "aws.msk.UnderReplicatedPartitions", Kind: RollupMetricKindGauge, Rollup: RollupSum},
+		{Source: "replication.isrShrinksPerSecond", Target: "aws.msk.IsrShrinksPerSec", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "replication.isrExpandsPerSecond", Target: "aws.msk.IsrExpandsPerSec", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "replication.leaderElectionPerSecond", Target: "aws.msk.LeaderElectionRateAndTimeMs", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+
+		{Source: "request.avgTimeFetch", Target: "aws.msk.RequestTime.Fetch.Mean", Kind: RollupMetricKindGauge, Rollup: RollupMean},
+		{Source: "request.avgTimeProduceRequest", Target: "aws.msk.RequestTime.Produce.Mean", Kind: RollupMetricKindGauge, Rollup: RollupMean},
+		{Source: "request.fetchTime99Percentile", Target: "aws.msk.FetchConsumerTotalTimeMs99thPercentile", Kind: RollupMetricKindSummary, Rollup: RollupMean},
+		{Source: "request.produceTime99Percentile", Target: "aws.msk.ProduceTotalTimeMs99thPercentile", Kind: RollupMetricKindSummary, Rollup: RollupMean},
+		{Source: "request.avgTimeUpdateMetadata", Target: "aws.msk.RequestTime.UpdateMetadata.Mean", Kind: RollupMetricKindGauge, Rollup: RollupMean},
+
+		{Source: "net.bytesRejectedPerSecond", Target: "aws.msk.NetworkRxDropped", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "broker.partitionCount", Target: "aws.msk.PartitionCount", Kind: RollupMetricKindGauge, Rollup: RollupSum},
+		{Source: "controller.activeControllerCount", Target: "aws.msk.ActiveControllerCount", Kind: RollupMetricKindGauge, Rollup: RollupSum},
+		{Source: "controller.offlinePartitionsCount", Target: "aws.msk.OfflinePartitionsCount", Kind: RollupMetricKindGauge, Rollup: RollupSum},
+
+		{Source: "consumer.requestsExpiredPerSecond", Target: "aws.msk.ExpiredFetchResponsesPerSec", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "consumer.avgFetchSizeBytes", Target: "aws.msk.FetchMessageConversionsPerSec", Kind: RollupMetricKindGauge, Rollup: RollupMean},
+
+		{Source: "broker.logSize", Target: "aws.msk.KafkaDataLogsDiskUsed", Kind: RollupMetricKindGauge, Rollup: RollupSum},
+		{Source: "broker.diskUsedPercent", Target: "aws.msk.RootDiskUsed", Kind: RollupMetricKindGauge, Rollup: RollupMean},
+
+		// provider.*.Average/.Sum are the MSK-compatible duals of the
+		// aws.msk.* names above, kept alongside them since existing
+		// dashboards query both forms.
+		{Source: "broker.messagesInPerSecond", Target: "provider.messagesInPerSec.Average", Kind: RollupMetricKindGauge, Rollup: RollupMean},
+		{Source: "broker.IOInPerSecond", Target: "provider.bytesInPerSec.Average", Kind: RollupMetricKindGauge, Rollup: RollupMean},
+		{Source: "broker.IOOutPerSecond", Target: "provider.bytesOutPerSec.Average", Kind: RollupMetricKindGauge, Rollup: RollupMean},
+		{Source: "replication.unreplicatedPartitions", Target: "provider.underReplicatedPartitions.Sum", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "controller.activeControllerCount", Target: "provider.activeControllerCount.Sum", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "controller.offlinePartitionsCount", Target: "provider.offlinePartitionsCount.Sum", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+	}
+}
+
+// ClusterMetricSpecs is the cluster-level metric registry, replacing
+// TransformClusterMetrics' ad-hoc clusterMappings. Every cluster metric
+// here is already a fleet-wide aggregate by the time it reaches
+// TransformClusterMetrics, so RollupSum just documents that these are
+// the terminal (cluster) level of the hierarchy, not a further rollup
+// instruction.
+func ClusterMetricSpecs() []MetricSpec {
+	return []MetricSpec{
+		{Source: "totalBrokers", Target: "provider.brokerCount", Kind: RollupMetricKindGauge, Rollup: RollupSum},
+		{Source: "totalTopics", Target: "provider.topicCount", Kind: RollupMetricKindGauge, Rollup: RollupSum},
+		{Source: "totalPartitions", Target: "provider.globalPartitionCount.Average", Kind: RollupMetricKindGauge, Rollup: RollupSum},
+		{Source: "activeControllerCount", Target: "provider.activeControllerCount.Sum", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "offlinePartitionsCount", Target: "provider.offlinePartitionsCount.Sum", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "underReplicatedPartitions", Target: "provider.underReplicatedPartitions.Sum", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+	}
+}
+
+// TopicMetricSpecs is the topic-level metric registry, replacing
+// TransformTopicMetrics' ad-hoc topicMappings.
+func TopicMetricSpecs() []MetricSpec {
+	return []MetricSpec{
+		{Source: "topic.bytesInPerSecond", Target: "provider.bytesInPerSec.Sum", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "topic.bytesOutPerSecond", Target: "provider.bytesOutPerSec.Sum", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "topic.messagesInPerSecond", Target: "provider.messagesInPerSec.Sum", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+		{Source: "topic.partitionCount", Target: "provider.partitionCount", Kind: RollupMetricKindGauge, Rollup: RollupSum},
+		{Source: "topic.replicationFactor", Target: "provider.replicationFactor", Kind: RollupMetricKindGauge, Rollup: RollupMax},
+		{Source: "topic.underReplicatedPartitions", Target: "provider.underReplicatedPartitions", Kind: RollupMetricKindCounter, Rollup: RollupSum},
+	}
+}
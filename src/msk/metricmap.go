@@ -0,0 +1,43 @@
+package msk
+
+// This file holds the kafka-metric-name -> CloudWatch-style-metric-name
+// mapping tables CloudWatchEmulator's Emit* methods render sample values
+// through. They used to live as separate map literals inlined in each Emit*
+// method; centralizing them here means every sink that needs to know what
+// "BytesInPerSec" is keyed by (CloudWatchEmulator today, and any future
+// EventSink that wants the same CloudWatch-style names) reads the same
+// table instead of maintaining its own copy that can drift.
+
+// brokerMetricNameMap maps a broker-level kafka metric name to its
+// CloudWatch/MSK-equivalent name. ActiveControllerCount is deliberately
+// absent: it is always derived from a ControllerResolver rather than looked
+// up by name, so it can never appear here and be double-mapped.
+var brokerMetricNameMap = map[string]string{
+	"broker.IOInPerSecond":                  "BytesInPerSec",
+	"broker.IOOutPerSecond":                 "BytesOutPerSec",
+	"broker.messagesInPerSecond":            "MessagesInPerSec",
+	"replication.unreplicatedPartitions":    "UnderReplicatedPartitions",
+	"request.handlerIdle":                   "RequestHandlerAvgIdlePercent",
+	"broker.networkProcessorAvgIdlePercent": "NetworkProcessorAvgIdlePercent",
+	"controller.offlinePartitionsCount":     "OfflinePartitionsCount",
+	"request.avgTimeProduceRequest":         "ProduceTotalTimeMs",
+	"request.avgTimeFetch":                  "FetchConsumerTotalTimeMs",
+}
+
+// clusterMetricNameMap maps a cluster-level kafka metric name to its
+// CloudWatch/MSK-equivalent name. As with brokerMetricNameMap,
+// ActiveControllerCount is handled separately via a ControllerResolver.
+var clusterMetricNameMap = map[string]string{
+	"GlobalPartitionCount":      "GlobalPartitionCount",
+	"GlobalTopicCount":          "GlobalTopicCount",
+	"OfflinePartitionsCount":    "OfflinePartitionsCount",
+	"UnderReplicatedPartitions": "UnderReplicatedPartitions",
+}
+
+// topicMetricNameMap maps a topic-level kafka metric name to its
+// CloudWatch/MSK-equivalent name.
+var topicMetricNameMap = map[string]string{
+	"topic.bytesInPerSecond":    "BytesInPerSec",
+	"topic.bytesOutPerSecond":   "BytesOutPerSec",
+	"topic.messagesInPerSecond": "MessagesInPerSec",
+}
@@ -0,0 +1,163 @@
+// Package metricspec defines every provider.* metric the MSKShim
+// SimpleTransform* functions emit as a single typed registry, so a name
+// like "provider.underReplicatedPartitions" (broker-level, unsuffixed) and
+// "provider.underReplicatedPartitions.Sum" (cluster-level rollup) can no
+// longer drift apart through a typo in a call site that sets the metric
+// through its Spec constant. Call sites that still pass a literal name to
+// SetMetric are not covered by this registry.
+package metricspec
+
+import (
+	"fmt"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+)
+
+// Aggregation records how a metric's value was derived across whatever
+// it was rolled up from (brokers into a cluster, partitions into a topic),
+// and therefore which name suffix it must carry.
+type Aggregation int
+
+const (
+	// None is an unsuffixed, unaggregated metric -- usually one reported
+	// directly by a single broker/topic/partition entity.
+	None Aggregation = iota
+	// Average is suffixed ".Average".
+	Average
+	// Sum is suffixed ".Sum".
+	Sum
+	// Maximum is suffixed ".Maximum".
+	Maximum
+)
+
+// suffix returns the name suffix an Aggregation requires, or "" for None.
+func (a Aggregation) suffix() string {
+	switch a {
+	case Average:
+		return ".Average"
+	case Sum:
+		return ".Sum"
+	case Maximum:
+		return ".Maximum"
+	default:
+		return ""
+	}
+}
+
+// Spec declares one provider.* metric's full name, infra-SDK source type,
+// and aggregation, so SetSpec can SetMetric it without any caller having
+// to spell the name out (and risk a typo diverging from the cluster- or
+// topic-level rollup of the same underlying value).
+type Spec struct {
+	Name        string
+	Type        metric.SourceType
+	Aggregation Aggregation
+}
+
+// Broker-level specs.
+var (
+	BrokerBytesInPerSecAvg      = Spec{Name: "provider.bytesInPerSec.Average", Type: metric.GAUGE, Aggregation: Average}
+	BrokerBytesOutPerSecAvg     = Spec{Name: "provider.bytesOutPerSec.Average", Type: metric.GAUGE, Aggregation: Average}
+	BrokerMessagesInPerSecAvg   = Spec{Name: "provider.messagesInPerSec.Average", Type: metric.GAUGE, Aggregation: Average}
+	BrokerUnderReplicated       = Spec{Name: "provider.underReplicatedPartitions", Type: metric.GAUGE, Aggregation: None}
+	BrokerLeaderCount           = Spec{Name: "provider.leaderCount", Type: metric.GAUGE, Aggregation: None}
+	BrokerActiveControllerCount = Spec{Name: "provider.activeControllerCount", Type: metric.GAUGE, Aggregation: None}
+)
+
+// Cluster-level specs.
+var (
+	ClusterUnderReplicatedPartitionsSum = Spec{Name: "provider.underReplicatedPartitions.Sum", Type: metric.GAUGE, Aggregation: Sum}
+	ClusterActiveControllerCountSum     = Spec{Name: "provider.activeControllerCount.Sum", Type: metric.GAUGE, Aggregation: Sum}
+	ClusterGlobalPartitionCount         = Spec{Name: "provider.globalPartitionCount", Type: metric.GAUGE, Aggregation: None}
+	// ZooKeeper latency's legacy name already carries Mean in its literal
+	// name (matching simple_transformer.go's SetMetric call), not the
+	// ".Average" suffix Aggregation: Average would require, so it's
+	// declared None here like every other already-suffixed/unsuffixed name.
+	ClusterZooKeeperRequestLatencyMsAvg = Spec{Name: "provider.zooKeeperRequestLatencyMsMean", Type: metric.GAUGE, Aggregation: None}
+	ClusterKafkaDataLogsDiskUsedAvg     = Spec{Name: "provider.kafkaDataLogsDiskUsed.Average", Type: metric.GAUGE, Aggregation: Average}
+)
+
+// All is every Spec in the registry, used by Validate to check the whole
+// set in one pass.
+func All() []Spec {
+	return []Spec{
+		BrokerBytesInPerSecAvg,
+		BrokerBytesOutPerSecAvg,
+		BrokerMessagesInPerSecAvg,
+		BrokerUnderReplicated,
+		BrokerLeaderCount,
+		BrokerActiveControllerCount,
+		ClusterUnderReplicatedPartitionsSum,
+		ClusterActiveControllerCountSum,
+		ClusterGlobalPartitionCount,
+		ClusterZooKeeperRequestLatencyMsAvg,
+		ClusterKafkaDataLogsDiskUsedAvg,
+	}
+}
+
+// legacyNames maps a pre-registry literal name (still read by existing
+// dashboards/NRQL) to the Spec name it now corresponds to, for metrics
+// whose name changed when they moved into this registry. Most Spec names
+// match their legacy literal exactly and need no entry here.
+var legacyNames = map[string]string{}
+
+// RewriteLegacyName returns the canonical Spec name for a pre-registry
+// literal metric name, or name unchanged if it was never renamed.
+func RewriteLegacyName(name string) string {
+	if canonical, ok := legacyNames[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// metricSet is the subset of *metric.Set's API SetSpec needs, so callers
+// can pass the infra SDK's concrete *metric.Set without this package
+// importing anything beyond the metric types it already depends on.
+type metricSet interface {
+	SetMetric(name string, value interface{}, sourceType metric.SourceType) error
+}
+
+// SetSpec sets spec's value on ms, always through spec.Name -- the one
+// place a Spec's name and source type are ever written to a MetricSet.
+// SimpleTransform* functions should call this instead of ms.SetMetric
+// with a literal string for every metric this registry covers.
+func SetSpec(ms metricSet, spec Spec, value interface{}) error {
+	return ms.SetMetric(spec.Name, value, spec.Type)
+}
+
+// aggregationSuffixes is every suffix an aggregated Spec name could carry,
+// used by Validate to check a None-aggregation Spec doesn't carry one by
+// mistake.
+var aggregationSuffixes = []string{".Average", ".Sum", ".Maximum"}
+
+func hasSuffix(name, suffix string) bool {
+	return len(name) >= len(suffix) && name[len(name)-len(suffix):] == suffix
+}
+
+// Validate checks every Spec in All() against its own Aggregation, so a
+// Spec whose Name suffix doesn't match what its Aggregation declares (the
+// exact class of bug that let provider.underReplicatedPartitions and
+// provider.underReplicatedPartitions.Sum drift apart across the broker and
+// cluster paths) is caught at startup instead of silently producing two
+// series. Call once from NewMSKShim.
+func Validate() error {
+	var violations []string
+	for _, spec := range All() {
+		wantSuffix := spec.Aggregation.suffix()
+		if wantSuffix != "" {
+			if !hasSuffix(spec.Name, wantSuffix) {
+				violations = append(violations, fmt.Sprintf("metricspec: %q declares aggregation %v but name lacks suffix %q", spec.Name, spec.Aggregation, wantSuffix))
+			}
+			continue
+		}
+		for _, suffix := range aggregationSuffixes {
+			if hasSuffix(spec.Name, suffix) {
+				violations = append(violations, fmt.Sprintf("metricspec: %q declares no aggregation but name carries suffix %q", spec.Name, suffix))
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("metricspec registry failed validation: %v", violations)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package metricspec
+
+import (
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteLegacyName_UnknownName_ReturnsUnchanged(t *testing.T) {
+	assert.Equal(t, "provider.somethingElse", RewriteLegacyName("provider.somethingElse"))
+}
+
+func TestRewriteLegacyName_KnownRename_ReturnsCanonicalName(t *testing.T) {
+	legacyNames["provider.oldName"] = "provider.newName"
+	defer delete(legacyNames, "provider.oldName")
+
+	assert.Equal(t, "provider.newName", RewriteLegacyName("provider.oldName"))
+}
+
+// fakeMetricSet is a test double for the subset of *metric.Set's API
+// SetSpec needs.
+type fakeMetricSet struct {
+	name       string
+	value      interface{}
+	sourceType metric.SourceType
+}
+
+func (f *fakeMetricSet) SetMetric(name string, value interface{}, sourceType metric.SourceType) error {
+	f.name = name
+	f.value = value
+	f.sourceType = sourceType
+	return nil
+}
+
+func TestSetSpec_SetsNameTypeAndValueFromSpec(t *testing.T) {
+	ms := &fakeMetricSet{}
+
+	err := SetSpec(ms, BrokerBytesInPerSecAvg, 42.5)
+
+	require.NoError(t, err)
+	assert.Equal(t, "provider.bytesInPerSec.Average", ms.name)
+	assert.Equal(t, 42.5, ms.value)
+	assert.Equal(t, metric.GAUGE, ms.sourceType)
+}
+
+func TestValidate_RegistryIsInternallyConsistent(t *testing.T) {
+	assert.NoError(t, Validate(), "every shipped Spec's name suffix must match its declared Aggregation")
+}
+
+func TestHasSuffix_DetectsAggregationSuffixMismatch(t *testing.T) {
+	bad := Spec{Name: "provider.missingSuffix", Type: metric.GAUGE, Aggregation: Sum}
+	assert.NotEqual(t, "", bad.Aggregation.suffix())
+	assert.False(t, hasSuffix(bad.Name, bad.Aggregation.suffix()))
+}
+
+func TestHasSuffix(t *testing.T) {
+	assert.True(t, hasSuffix("provider.foo.Sum", ".Sum"))
+	assert.False(t, hasSuffix("provider.foo", ".Sum"))
+	assert.False(t, hasSuffix("Sum", ".Sum"))
+}
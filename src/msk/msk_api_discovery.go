@@ -0,0 +1,99 @@
+package msk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+)
+
+// MSKAPI is the subset of the real AWS MSK control-plane API
+// MSKDiscoveryClient needs, so a fake can stand in for the real AWS client
+// the same way AdminAPIHelper draws its seam around sarama.Client/
+// sarama.ClusterAdmin rather than calling the SDK directly.
+type MSKAPI interface {
+	GetBootstrapBrokers(ctx context.Context, clusterARN string) (*BootstrapBrokers, error)
+	ListNodes(ctx context.Context, clusterARN string) ([]MSKNodeInfo, error)
+}
+
+// BootstrapBrokers is the bootstrap.servers string MSK hands back for each
+// listener type enabled on the cluster.
+type BootstrapBrokers struct {
+	Plaintext string
+	TLS       string
+	SASLIAM   string
+	SASLSCRAM string
+}
+
+// MSKNodeInfo is one broker node as AWS reports it via ListNodes, trimmed to
+// the fields MSKDiscoveryClient.Reconcile compares against extractBrokerInfo's
+// JMX-inferred brokerInfo.
+type MSKNodeInfo struct {
+	BrokerID           string
+	ClientVPCIPAddress string
+	InstanceType       string
+}
+
+// awsMSKAPI is the real MSKAPI implementation, backed by the AWS SDK's Kafka
+// control-plane client.
+type awsMSKAPI struct {
+	client *kafka.Client
+}
+
+// newAWSMSKAPI loads AWS's default credential chain (environment, shared
+// config, EC2/ECS instance role) for region and wraps it in a Kafka
+// control-plane client - the same ambient-credentials assumption the rest
+// of the AWS SDK makes, rather than this package inventing its own
+// credential flags.
+func newAWSMSKAPI(ctx context.Context, region string) (MSKAPI, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS credentials: %w", err)
+	}
+	return &awsMSKAPI{client: kafka.NewFromConfig(cfg)}, nil
+}
+
+func (a *awsMSKAPI) GetBootstrapBrokers(ctx context.Context, clusterARN string) (*BootstrapBrokers, error) {
+	resp, err := a.client.GetBootstrapBrokers(ctx, &kafka.GetBootstrapBrokersInput{ClusterArn: aws.String(clusterARN)})
+	if err != nil {
+		return nil, fmt.Errorf("GetBootstrapBrokers: %w", err)
+	}
+	return &BootstrapBrokers{
+		Plaintext: aws.ToString(resp.BootstrapBrokerString),
+		TLS:       aws.ToString(resp.BootstrapBrokerStringTls),
+		SASLIAM:   aws.ToString(resp.BootstrapBrokerStringSaslIam),
+		SASLSCRAM: aws.ToString(resp.BootstrapBrokerStringSaslScram),
+	}, nil
+}
+
+// ListNodes pages through every node MSK reports for clusterARN, skipping
+// entries with no BrokerNodeInfo (e.g. a future ZooKeeper node type) since
+// those have nothing to reconcile against a broker's JMX sample.
+func (a *awsMSKAPI) ListNodes(ctx context.Context, clusterARN string) ([]MSKNodeInfo, error) {
+	var nodes []MSKNodeInfo
+	input := &kafka.ListNodesInput{ClusterArn: aws.String(clusterARN)}
+	for {
+		resp, err := a.client.ListNodes(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("ListNodes: %w", err)
+		}
+		for _, n := range resp.NodeInfoList {
+			if n.BrokerNodeInfo == nil {
+				continue
+			}
+			nodes = append(nodes, MSKNodeInfo{
+				BrokerID:           strconv.FormatInt(int64(aws.ToFloat64(n.BrokerNodeInfo.BrokerId)), 10),
+				ClientVPCIPAddress: aws.ToString(n.BrokerNodeInfo.ClientVpcIpAddress),
+				InstanceType:       aws.ToString(n.InstanceType),
+			})
+		}
+		if resp.NextToken == nil || *resp.NextToken == "" {
+			break
+		}
+		input.NextToken = resp.NextToken
+	}
+	return nodes, nil
+}
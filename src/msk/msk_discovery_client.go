@@ -0,0 +1,103 @@
+package msk
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// DiscoveryDrift summarizes how extractBrokerInfo's JMX-inferred broker list
+// disagrees with what the real MSK control plane reports via ListNodes.
+type DiscoveryDrift struct {
+	// MissingFromJMX are broker IDs AWS reports that no broker sample has
+	// reported this cycle - a broker the integration isn't scraping yet.
+	MissingFromJMX []string
+	// MissingFromMSK are broker IDs a JMX sample reported that AWS's node
+	// list doesn't contain - usually a self-managed broker not yet part of
+	// the MSK cluster, or a stale/decommissioned one.
+	MissingFromMSK []string
+	// HostMismatches counts broker IDs present on both sides whose
+	// JMX-reported host doesn't match MSK's ClientVpcIpAddress.
+	HostMismatches int
+}
+
+// Count returns how many individual discrepancies drift represents, for a
+// single provider.shim.discoveryDrift gauge.
+func (d *DiscoveryDrift) Count() int {
+	return len(d.MissingFromJMX) + len(d.MissingFromMSK) + d.HostMismatches
+}
+
+// MSKDiscoveryClient calls the real AWS MSK control plane via api and caches
+// the result, so ComprehensiveMSKShim can reconcile what JMX reports against
+// what AWS actually provisioned without hitting the rate-limited ListNodes
+// API on every broker sample.
+type MSKDiscoveryClient struct {
+	api          MSKAPI
+	clusterARN   string
+	refreshEvery time.Duration
+
+	mu        sync.Mutex
+	nodes     []MSKNodeInfo
+	lastFetch time.Time
+}
+
+// NewMSKDiscoveryClient creates a client that reconciles clusterARN's real
+// MSK node list against JMX-inferred broker info, refreshing no more often
+// than refreshEvery.
+func NewMSKDiscoveryClient(api MSKAPI, clusterARN string, refreshEvery time.Duration) *MSKDiscoveryClient {
+	return &MSKDiscoveryClient{api: api, clusterARN: clusterARN, refreshEvery: refreshEvery}
+}
+
+// Nodes returns the cached MSK node list, refreshing it first if
+// refreshEvery has elapsed since the last successful fetch. A refresh
+// failure logs and keeps serving the stale cache rather than failing
+// reconciliation outright for one transient AWS API error.
+func (c *MSKDiscoveryClient) Nodes(ctx context.Context) []MSKNodeInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.lastFetch.IsZero() && time.Since(c.lastFetch) < c.refreshEvery {
+		return c.nodes
+	}
+
+	nodes, err := c.api.ListNodes(ctx, c.clusterARN)
+	if err != nil {
+		log.Warn("MSK discovery: ListNodes failed, reconciling against stale cache: %v", err)
+		return c.nodes
+	}
+	c.nodes = nodes
+	c.lastFetch = time.Now()
+	return c.nodes
+}
+
+// Reconcile compares inferred (extractBrokerInfo's per-broker-ID JMX view,
+// keyed by broker ID) against the real MSK node list and returns the
+// discrepancies.
+func (c *MSKDiscoveryClient) Reconcile(ctx context.Context, inferred map[string]brokerInfo) *DiscoveryDrift {
+	byID := make(map[string]MSKNodeInfo)
+	for _, n := range c.Nodes(ctx) {
+		byID[n.BrokerID] = n
+	}
+
+	drift := &DiscoveryDrift{}
+	for id, node := range byID {
+		info, ok := inferred[id]
+		if !ok {
+			drift.MissingFromJMX = append(drift.MissingFromJMX, id)
+			continue
+		}
+		if info.Host != "" && node.ClientVPCIPAddress != "" && info.Host != node.ClientVPCIPAddress {
+			drift.HostMismatches++
+			log.Debug("MSK discovery: broker %s JMX host %q does not match MSK ClientVpcIpAddress %q", id, info.Host, node.ClientVPCIPAddress)
+		}
+	}
+	for id := range inferred {
+		if _, ok := byID[id]; !ok {
+			drift.MissingFromMSK = append(drift.MissingFromMSK, id)
+		}
+	}
+
+	return drift
+}
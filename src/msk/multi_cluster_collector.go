@@ -0,0 +1,115 @@
+package msk
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// clusterShard holds one tenant cluster's own aggregator and shim, plus a
+// rate limiter so a single noisy cluster can't starve the others' flush
+// cycles.
+type clusterShard struct {
+	shim    *MSKShim
+	limiter *rate.Limiter
+}
+
+// MultiClusterCollector fans a single process out across many MSK clusters
+// (multi-tenant deployments), keeping each cluster's MetricAggregator and
+// entity cache fully isolated and rate-limited independently.
+type MultiClusterCollector struct {
+	mu             sync.RWMutex
+	shards         map[string]*clusterShard
+	ratePerSecond  float64
+	burstSize      int
+}
+
+// NewMultiClusterCollector creates a collector. ratePerSecond/burstSize
+// configure the per-cluster token bucket applied to Submit calls.
+func NewMultiClusterCollector(ratePerSecond float64, burstSize int) *MultiClusterCollector {
+	return &MultiClusterCollector{
+		shards:        make(map[string]*clusterShard),
+		ratePerSecond: ratePerSecond,
+		burstSize:     burstSize,
+	}
+}
+
+// RegisterCluster adds a new tenant cluster, building it its own shim and
+// rate limiter. It's a no-op if the cluster is already registered.
+func (m *MultiClusterCollector) RegisterCluster(config Config) *MSKShim {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if shard, exists := m.shards[config.ClusterName]; exists {
+		return shard.shim
+	}
+
+	shim := NewMSKShim(config)
+	m.shards[config.ClusterName] = &clusterShard{
+		shim:    shim,
+		limiter: rate.NewLimiter(rate.Limit(m.ratePerSecond), m.burstSize),
+	}
+
+	log.Info("MultiClusterCollector: registered cluster %s", config.ClusterName)
+	return shim
+}
+
+// Submit routes a broker-metrics sample to the named cluster's shard,
+// applying that shard's rate limiter before forwarding to its shim. It
+// returns an error if the cluster isn't registered or the limiter rejects
+// the sample.
+func (m *MultiClusterCollector) Submit(clusterName string, brokerData map[string]interface{}) error {
+	m.mu.RLock()
+	shard, exists := m.shards[clusterName]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("cluster %s is not registered with the multi-cluster collector", clusterName)
+	}
+
+	if !shard.limiter.Allow() {
+		return fmt.Errorf("rate limit exceeded for cluster %s", clusterName)
+	}
+
+	return shard.shim.TransformBrokerMetrics(brokerData)
+}
+
+// Shim returns the registered shim for clusterName, if any.
+func (m *MultiClusterCollector) Shim(clusterName string) (*MSKShim, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	shard, exists := m.shards[clusterName]
+	if !exists {
+		return nil, false
+	}
+	return shard.shim, true
+}
+
+// FlushAll flushes every registered cluster's shim, continuing past
+// per-cluster failures so one bad tenant doesn't block the others.
+func (m *MultiClusterCollector) FlushAll() []error {
+	m.mu.RLock()
+	shards := make([]*clusterShard, 0, len(m.shards))
+	for _, shard := range m.shards {
+		shards = append(shards, shard)
+	}
+	m.mu.RUnlock()
+
+	var errs []error
+	for _, shard := range shards {
+		if err := shard.shim.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// ClusterCount returns the number of registered tenant clusters.
+func (m *MultiClusterCollector) ClusterCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.shards)
+}
@@ -0,0 +1,81 @@
+package msk
+
+import (
+	"fmt"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-kafka/src/msk/offsetpipeline"
+)
+
+// pipelineLogger adapts msk's log package to offsetpipeline.Logger so the
+// subpackage itself never needs to import msk.
+type pipelineLogger struct{}
+
+func (pipelineLogger) Debugf(format string, args ...interface{}) { log.Debug(format, args...) }
+func (pipelineLogger) Warnf(format string, args ...interface{})  { log.Warn(format, args...) }
+func (pipelineLogger) Errorf(format string, args ...interface{}) { log.Error(format, args...) }
+
+// OffsetPipelineCollector snapshots an offsetpipeline.Pipeline's merged
+// (group, topic, partition) samples -- drawn from whichever of
+// CloudWatch/Sarama/Burrow/Kminion sources the pipeline was configured
+// with, merged by precedence -- and feeds them through
+// DimensionalTransformer.TransformConsumerMetrics, the same sink
+// SaramaConsumerCollector writes to.
+type OffsetPipelineCollector struct {
+	pipeline *offsetpipeline.Pipeline
+
+	dimensionalTransformer *DimensionalTransformer
+	aggregator             *MetricAggregator
+}
+
+// NewOffsetPipelineCollector wraps an already-started pipeline.
+func NewOffsetPipelineCollector(pipeline *offsetpipeline.Pipeline, dt *DimensionalTransformer, aggregator *MetricAggregator) *OffsetPipelineCollector {
+	return &OffsetPipelineCollector{
+		pipeline:               pipeline,
+		dimensionalTransformer: dt,
+		aggregator:             aggregator,
+	}
+}
+
+// NewPipelineLogger returns the offsetpipeline.Logger implementation
+// backed by msk's log package, for use building a
+// offsetpipeline.PipelineParams.
+func NewPipelineLogger() offsetpipeline.Logger {
+	return pipelineLogger{}
+}
+
+// Collect reads the pipeline's current merged snapshot and emits each
+// sample through the dimensional transformer, recording lag on the
+// aggregator the same way SaramaConsumerCollector does.
+func (c *OffsetPipelineCollector) Collect() error {
+	if c.pipeline == nil {
+		return nil
+	}
+
+	for _, sample := range c.pipeline.Snapshot() {
+		if c.aggregator != nil {
+			c.aggregator.AddConsumerPartitionLag(sample.Topic, sample.Group, sample.Partition, float64(sample.Lag))
+		}
+
+		if c.dimensionalTransformer == nil {
+			continue
+		}
+
+		metrics := map[string]interface{}{
+			"consumerGroup":  sample.Group,
+			"topic":          sample.Topic,
+			"partition":      fmt.Sprintf("%d", sample.Partition),
+			"consumerLag":    float64(sample.Lag),
+			"consumerOffset": float64(sample.CommittedOffset),
+			"highWaterMark":  float64(sample.HighWaterMark),
+			"offsetSource":   sample.Source,
+		}
+
+		if err := c.dimensionalTransformer.TransformConsumerMetrics(sample.Group, sample.Topic, metrics); err != nil {
+			log.Warn("OffsetPipelineCollector: failed to transform consumer metrics for %s/%s: %v", sample.Group, sample.Topic, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package offsetpipeline
+
+import "context"
+
+// CloudWatchFetchFunc is the msk package's existing CloudWatch-based
+// offset collection, adapted to this package's shape. CloudWatchOffsetSource
+// wraps a func instead of a cloudwatch client directly so this package
+// never needs to import msk's cloudwatch subpackage or its AWS types.
+type CloudWatchFetchFunc func(ctx context.Context) ([]OffsetSample, error)
+
+// CloudWatchOffsetSource adapts an existing CloudWatch-based offset fetch
+// (the pipeline's current, pre-existing behavior) into a
+// ConsumerOffsetSource so it can run alongside the newer sources and lose
+// precedence to them where they're fresher.
+type CloudWatchOffsetSource struct {
+	fetch CloudWatchFetchFunc
+}
+
+// NewCloudWatchOffsetSource wraps fetch as a ConsumerOffsetSource.
+func NewCloudWatchOffsetSource(fetch CloudWatchFetchFunc) *CloudWatchOffsetSource {
+	return &CloudWatchOffsetSource{fetch: fetch}
+}
+
+// Name identifies this source as "cloudwatch".
+func (c *CloudWatchOffsetSource) Name() string {
+	return "cloudwatch"
+}
+
+// Fetch delegates to the wrapped fetch func, stamping the Source field
+// on every returned sample so callers don't have to.
+func (c *CloudWatchOffsetSource) Fetch(ctx context.Context) ([]OffsetSample, error) {
+	samples, err := c.fetch(ctx)
+	for i := range samples {
+		samples[i].Source = c.Name()
+	}
+	return samples, err
+}
@@ -0,0 +1,88 @@
+package offsetpipeline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+// kminionLagMetric and kminionPartitionLabels name the Prometheus
+// exposition metric/labels kminion's /metrics endpoint exposes for
+// per-partition consumer group lag, as of kminion's
+// kminion_kafka_consumer_group_topic_partition_lag family.
+const kminionLagMetric = "kminion_kafka_consumer_group_topic_partition_lag"
+
+// KminionOffsetSource scrapes a kminion Prometheus exposition endpoint
+// and reads per-partition lag out of its consumer group lag gauge.
+// Kminion exposes lag directly rather than separate committed-offset/
+// high-water-mark series, so CommittedOffset/HighWaterMark are left
+// zero on the samples this source returns.
+type KminionOffsetSource struct {
+	MetricsURL string
+
+	httpClient *http.Client
+}
+
+// NewKminionOffsetSource builds a source that scrapes metricsURL. A
+// zero-value http.Client is used if httpClient is nil.
+func NewKminionOffsetSource(metricsURL string, httpClient *http.Client) *KminionOffsetSource {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &KminionOffsetSource{MetricsURL: metricsURL, httpClient: httpClient}
+}
+
+// Name identifies this source as "kminion".
+func (k *KminionOffsetSource) Name() string {
+	return "kminion"
+}
+
+// Fetch scrapes MetricsURL and extracts every sample of
+// kminionLagMetric.
+func (k *KminionOffsetSource) Fetch(ctx context.Context) ([]OffsetSample, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.MetricsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("kminion offset source: building request: %w", err)
+	}
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("kminion offset source: scraping %s: %w", k.MetricsURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kminion offset source: %s returned status %d", k.MetricsURL, resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("kminion offset source: parsing exposition format: %w", err)
+	}
+
+	family, ok := families[kminionLagMetric]
+	if !ok {
+		return nil, nil
+	}
+
+	samples := make([]OffsetSample, 0, len(family.GetMetric()))
+	for _, m := range family.GetMetric() {
+		sample := OffsetSample{Lag: int64(m.GetGauge().GetValue()), Source: k.Name()}
+		for _, label := range m.GetLabel() {
+			switch label.GetName() {
+			case "group":
+				sample.Group = label.GetValue()
+			case "topic":
+				sample.Topic = label.GetValue()
+			case "partition":
+				fmt.Sscanf(label.GetValue(), "%d", &sample.Partition)
+			}
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
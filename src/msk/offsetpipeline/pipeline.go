@@ -0,0 +1,162 @@
+package offsetpipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultPollInterval is used when PipelineParams.PollInterval is unset.
+const DefaultPollInterval = 30 * time.Second
+
+// PipelineParams configures a Pipeline, following the same
+// params-struct-carries-everything shape Jaeger's ingester consumer uses
+// (there: ProcessorFactory/Logger/MetricsFactory/close channel/WaitGroup)
+// so every pluggable piece -- sources, merge precedence, logging -- is
+// supplied once at construction instead of threaded through every method.
+type PipelineParams struct {
+	// Sources is every ConsumerOffsetSource Pipeline polls concurrently.
+	Sources []ConsumerOffsetSource
+	// Precedence orders source Name()s from highest to lowest priority;
+	// when two sources report a sample for the same (group, topic,
+	// partition), the one appearing earlier in Precedence wins. A source
+	// absent from Precedence loses to every source that is present.
+	Precedence []string
+	// PollInterval is how often each source is refetched. <= 0 falls back
+	// to DefaultPollInterval.
+	PollInterval time.Duration
+	// Logger receives per-source fetch errors. May be nil.
+	Logger Logger
+}
+
+// Pipeline runs every configured ConsumerOffsetSource on its own poll
+// loop, merges their latest samples by (group, topic, partition) using
+// Precedence, and exposes the merged result via Snapshot.
+type Pipeline struct {
+	params PipelineParams
+
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	samples map[key]OffsetSample
+
+	precedenceRank map[string]int
+}
+
+// NewPipeline creates a Pipeline from params. Call Start to begin
+// polling and Close to stop it deterministically.
+func NewPipeline(params PipelineParams) *Pipeline {
+	if params.PollInterval <= 0 {
+		params.PollInterval = DefaultPollInterval
+	}
+
+	rank := make(map[string]int, len(params.Precedence))
+	for i, name := range params.Precedence {
+		rank[name] = i
+	}
+
+	return &Pipeline{
+		params:         params,
+		closeCh:        make(chan struct{}),
+		samples:        make(map[key]OffsetSample),
+		precedenceRank: rank,
+	}
+}
+
+// Start launches one poll loop per source, each fetching on
+// params.PollInterval until Close is called. Start returns immediately;
+// it does not block.
+func (p *Pipeline) Start(ctx context.Context) {
+	for _, source := range p.params.Sources {
+		source := source
+		p.wg.Add(1)
+		go p.runSource(ctx, source)
+	}
+}
+
+// runSource repeatedly fetches source until closeCh fires or ctx is
+// done, merging each result into samples.
+func (p *Pipeline) runSource(ctx context.Context, source ConsumerOffsetSource) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.params.PollInterval)
+	defer ticker.Stop()
+
+	p.fetchOnce(ctx, source)
+
+	for {
+		select {
+		case <-ticker.C:
+			p.fetchOnce(ctx, source)
+		case <-ctx.Done():
+			return
+		case <-p.closeCh:
+			return
+		}
+	}
+}
+
+// fetchOnce fetches source once and merges whatever it returned, logging
+// (rather than propagating) an error so one failing source never blocks
+// the others.
+func (p *Pipeline) fetchOnce(ctx context.Context, source ConsumerOffsetSource) {
+	fetched, err := source.Fetch(ctx)
+	if err != nil && p.params.Logger != nil {
+		p.params.Logger.Warnf("offsetpipeline: source %s fetch failed: %v", source.Name(), err)
+	}
+	p.merge(fetched)
+}
+
+// merge folds samples into p.samples, keeping whichever of the existing
+// and new sample for a given key ranks higher in Precedence (a source
+// absent from Precedence always loses to one present in it).
+func (p *Pipeline) merge(fetched []OffsetSample) {
+	if len(fetched) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, sample := range fetched {
+		k := key{group: sample.Group, topic: sample.Topic, partition: sample.Partition}
+		existing, ok := p.samples[k]
+		if !ok || p.wins(sample, existing) {
+			p.samples[k] = sample
+		}
+	}
+}
+
+// wins reports whether candidate should replace existing per Precedence.
+// Lower rank wins; an unranked source's rank is treated as
+// len(Precedence), i.e. lowest priority.
+func (p *Pipeline) wins(candidate, existing OffsetSample) bool {
+	return p.rank(candidate.Source) < p.rank(existing.Source)
+}
+
+func (p *Pipeline) rank(source string) int {
+	if r, ok := p.precedenceRank[source]; ok {
+		return r
+	}
+	return len(p.params.Precedence)
+}
+
+// Snapshot returns the current merged sample set.
+func (p *Pipeline) Snapshot() []OffsetSample {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make([]OffsetSample, 0, len(p.samples))
+	for _, sample := range p.samples {
+		out = append(out, sample)
+	}
+	return out
+}
+
+// Close stops every poll loop and waits for them to exit, so shutdown is
+// deterministic -- no source goroutine outlives Close.
+func (p *Pipeline) Close() {
+	close(p.closeCh)
+	p.wg.Wait()
+}
@@ -0,0 +1,120 @@
+package offsetpipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource is a test double for ConsumerOffsetSource, returning whatever
+// samples/err are set at the moment Fetch is called.
+type fakeSource struct {
+	name string
+
+	mu      sync.Mutex
+	samples []OffsetSample
+	err     error
+}
+
+func (f *fakeSource) Name() string { return f.name }
+
+func (f *fakeSource) Fetch(ctx context.Context) ([]OffsetSample, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.samples, f.err
+}
+
+// fakeLogger is a test double for Logger, recording every Warnf call.
+type fakeLogger struct {
+	mu    sync.Mutex
+	warns []string
+}
+
+func (f *fakeLogger) Debugf(format string, args ...interface{}) {}
+func (f *fakeLogger) Errorf(format string, args ...interface{}) {}
+func (f *fakeLogger) Warnf(format string, args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.warns = append(f.warns, format)
+}
+
+func newTestPipeline(params PipelineParams) *Pipeline {
+	return NewPipeline(params)
+}
+
+func TestMerge_NewKey_IsAdded(t *testing.T) {
+	p := newTestPipeline(PipelineParams{})
+
+	p.merge([]OffsetSample{{Group: "g", Topic: "t", Partition: 0, Lag: 5, Source: "sarama"}})
+
+	assert.Equal(t, []OffsetSample{{Group: "g", Topic: "t", Partition: 0, Lag: 5, Source: "sarama"}}, p.Snapshot())
+}
+
+func TestMerge_HigherPrecedenceSourceWins(t *testing.T) {
+	p := newTestPipeline(PipelineParams{Precedence: []string{"sarama", "cloudwatch"}})
+
+	p.merge([]OffsetSample{{Group: "g", Topic: "t", Partition: 0, Lag: 100, Source: "cloudwatch"}})
+	p.merge([]OffsetSample{{Group: "g", Topic: "t", Partition: 0, Lag: 5, Source: "sarama"}})
+
+	snap := p.Snapshot()
+	require.Len(t, snap, 1)
+	assert.Equal(t, "sarama", snap[0].Source)
+	assert.Equal(t, int64(5), snap[0].Lag)
+}
+
+func TestMerge_LowerPrecedenceSourceDoesNotOverwrite(t *testing.T) {
+	p := newTestPipeline(PipelineParams{Precedence: []string{"sarama", "cloudwatch"}})
+
+	p.merge([]OffsetSample{{Group: "g", Topic: "t", Partition: 0, Lag: 5, Source: "sarama"}})
+	p.merge([]OffsetSample{{Group: "g", Topic: "t", Partition: 0, Lag: 100, Source: "cloudwatch"}})
+
+	snap := p.Snapshot()
+	require.Len(t, snap, 1)
+	assert.Equal(t, "sarama", snap[0].Source)
+}
+
+func TestMerge_UnrankedSourceLosesToRankedSource(t *testing.T) {
+	p := newTestPipeline(PipelineParams{Precedence: []string{"sarama"}})
+
+	p.merge([]OffsetSample{{Group: "g", Topic: "t", Partition: 0, Lag: 100, Source: "unranked"}})
+	p.merge([]OffsetSample{{Group: "g", Topic: "t", Partition: 0, Lag: 5, Source: "sarama"}})
+
+	snap := p.Snapshot()
+	require.Len(t, snap, 1)
+	assert.Equal(t, "sarama", snap[0].Source)
+}
+
+func TestFetchOnce_LogsErrorButStillMergesPartialResult(t *testing.T) {
+	logger := &fakeLogger{}
+	p := newTestPipeline(PipelineParams{Logger: logger})
+	source := &fakeSource{
+		name:    "burrow",
+		samples: []OffsetSample{{Group: "g", Topic: "t", Partition: 0, Lag: 1, Source: "burrow"}},
+		err:     errors.New("partial fetch failure"),
+	}
+
+	p.fetchOnce(context.Background(), source)
+
+	assert.Len(t, p.Snapshot(), 1)
+	assert.Len(t, logger.warns, 1)
+}
+
+func TestStartAndClose_PollsUntilClosed(t *testing.T) {
+	source := &fakeSource{name: "sarama", samples: []OffsetSample{{Group: "g", Topic: "t", Partition: 0, Lag: 1, Source: "sarama"}}}
+	p := newTestPipeline(PipelineParams{Sources: []ConsumerOffsetSource{source}, PollInterval: time.Millisecond})
+
+	p.Start(context.Background())
+	require.Eventually(t, func() bool { return len(p.Snapshot()) == 1 }, time.Second, time.Millisecond)
+
+	p.Close()
+}
+
+func TestNewPipeline_FallsBackToDefaultPollInterval(t *testing.T) {
+	p := NewPipeline(PipelineParams{})
+	assert.Equal(t, DefaultPollInterval, p.params.PollInterval)
+}
@@ -0,0 +1,70 @@
+package offsetpipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+// SaramaOffsetSource fetches committed offsets and high water marks
+// directly from the cluster via an already-connected sarama.ClusterAdmin
+// and sarama.Client, the same ListConsumerGroupOffsets+GetOffset pattern
+// SaramaConsumerCollector uses for its own (non-pipeline) path.
+type SaramaOffsetSource struct {
+	admin  sarama.ClusterAdmin
+	client sarama.Client
+}
+
+// NewSaramaOffsetSource wraps admin and client as a ConsumerOffsetSource.
+func NewSaramaOffsetSource(admin sarama.ClusterAdmin, client sarama.Client) *SaramaOffsetSource {
+	return &SaramaOffsetSource{admin: admin, client: client}
+}
+
+// Name identifies this source as "sarama".
+func (s *SaramaOffsetSource) Name() string {
+	return "sarama"
+}
+
+// Fetch lists every consumer group, then every group's committed offsets,
+// pairing each with the partition's current high water mark.
+func (s *SaramaOffsetSource) Fetch(ctx context.Context) ([]OffsetSample, error) {
+	groups, err := s.admin.ListConsumerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("sarama offset source: listing consumer groups: %w", err)
+	}
+
+	var samples []OffsetSample
+	var fetchErr error
+
+	for group := range groups {
+		offsets, err := s.admin.ListConsumerGroupOffsets(group, nil)
+		if err != nil {
+			fetchErr = fmt.Errorf("sarama offset source: listing offsets for group %s: %w", group, err)
+			continue
+		}
+		for topic, partitions := range offsets.Blocks {
+			for partition, block := range partitions {
+				if block == nil || block.Offset < 0 {
+					continue
+				}
+				hwm, err := s.client.GetOffset(topic, partition, sarama.OffsetNewest)
+				if err != nil {
+					fetchErr = fmt.Errorf("sarama offset source: fetching high water mark for %s/%d: %w", topic, partition, err)
+					continue
+				}
+				samples = append(samples, OffsetSample{
+					Group:           group,
+					Topic:           topic,
+					Partition:       partition,
+					CommittedOffset: block.Offset,
+					HighWaterMark:   hwm,
+					Lag:             hwm - block.Offset,
+					Source:          s.Name(),
+				})
+			}
+		}
+	}
+
+	return samples, fetchErr
+}
@@ -0,0 +1,59 @@
+// Package offsetpipeline defines a pluggable ConsumerOffsetSource
+// interface and a Pipeline that runs several of them concurrently,
+// deduplicating by (group, topic, partition) with a configurable
+// precedence so a deployment can mix sources -- CloudWatch filling
+// cross-account gaps while a direct Sarama connection provides
+// sub-minute freshness -- without msk's SimpleTransformConsumerOffset/
+// ConsumerGroupLagCollector/SaramaConsumerCollector paths needing to know
+// which one actually answered. Modeled on Jaeger's ingester consumer: a
+// params struct carrying the pluggable pieces plus a close channel and
+// WaitGroup for deterministic shutdown, so each source can be
+// independently unit-tested with a fake.
+package offsetpipeline
+
+import "context"
+
+// OffsetSample is one (group, topic, partition) lag observation, in the
+// common shape every ConsumerOffsetSource normalizes its backend-specific
+// response into.
+type OffsetSample struct {
+	Group           string
+	Topic           string
+	Partition       int32
+	CommittedOffset int64
+	HighWaterMark   int64
+	Lag             int64
+	// Source is the producing ConsumerOffsetSource's Name(), carried on
+	// the sample so Pipeline.Snapshot's merge can apply Precedence and so
+	// downstream consumers can tell which backend answered.
+	Source string
+}
+
+// ConsumerOffsetSource is one pluggable backend Pipeline can fetch
+// samples from.
+type ConsumerOffsetSource interface {
+	// Name identifies this source for Precedence and for tagging the
+	// OffsetSamples it returns.
+	Name() string
+	// Fetch returns every OffsetSample this source currently has an
+	// answer for. Implementations should return a partial result plus an
+	// error rather than an empty slice when only some groups/partitions
+	// could be fetched, so Pipeline can still merge in what succeeded.
+	Fetch(ctx context.Context) ([]OffsetSample, error)
+}
+
+// Logger is the minimal logging surface Pipeline needs, satisfied by
+// msk's log package without this package importing it directly (this
+// package has no dependency on msk, so msk can depend on it instead).
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// key identifies one (group, topic, partition) for deduplication.
+type key struct {
+	group     string
+	topic     string
+	partition int32
+}
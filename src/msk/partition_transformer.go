@@ -0,0 +1,88 @@
+package msk
+
+import (
+	"fmt"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// TopicPartitionKey uniquely identifies a partition of a topic, the key
+// PartitionTransformer uses to cache per-partition entities the same way
+// EntityCache keys broker/topic entities by name.
+type TopicPartitionKey struct {
+	Topic     string
+	Partition int32
+}
+
+// String renders the key as "<topic>-<partition>", matching the
+// entityName convention other MSK entities already use (e.g. broker and
+// topic names are suffixed onto the cluster name).
+func (k TopicPartitionKey) String() string {
+	return fmt.Sprintf("%s-%d", k.Topic, k.Partition)
+}
+
+// PartitionMetrics holds the per-partition values the MSK shim tracks:
+// whoever is leader, replica/ISR set sizes, and size on disk.
+type PartitionMetrics struct {
+	Key               TopicPartitionKey
+	Leader            int32
+	ReplicaCount      int
+	InSyncReplicaCount int
+	SizeInBytes       int64
+}
+
+// PartitionTransformer creates and updates one AwsMskTopicPartitionSample
+// entity per TopicPartitionKey, separate from the topic-level rollup so a
+// single hot or under-replicated partition can be pinpointed instead of
+// only showing up diluted into the topic's aggregate.
+type PartitionTransformer struct {
+	shim *MSKShim
+}
+
+// NewPartitionTransformer creates a transformer bound to shim.
+func NewPartitionTransformer(shim *MSKShim) *PartitionTransformer {
+	return &PartitionTransformer{shim: shim}
+}
+
+// TransformPartitionMetrics creates or updates the partition entity for m.
+func (t *PartitionTransformer) TransformPartitionMetrics(m PartitionMetrics) error {
+	if t.shim.integration == nil {
+		return fmt.Errorf("integration not set")
+	}
+
+	entityName := fmt.Sprintf("%s-partition-%s", t.shim.config.ClusterName, m.Key.String())
+	entity, err := t.shim.GetOrCreateEntity(string(EntityTypePartition), entityName)
+	if err != nil {
+		return fmt.Errorf("failed to create partition entity for %s: %w", m.Key, err)
+	}
+
+	guid := GenerateEntityGUID(EntityTypePartition, t.shim.config.AWSAccountID, t.shim.config.ClusterName, m.Key.String())
+
+	ms := entity.NewMetricSet("AwsMskTopicPartitionSample",
+		attribute.Attribute{Key: "entity.guid", Value: guid},
+		attribute.Attribute{Key: "entity.type", Value: string(EntityTypePartition)},
+		attribute.Attribute{Key: "entityName", Value: entityName},
+		attribute.Attribute{Key: "Topic", Value: m.Key.Topic},
+		attribute.Attribute{Key: "Partition", Value: fmt.Sprintf("%d", m.Key.Partition)},
+		attribute.Attribute{Key: "provider.clusterName", Value: t.shim.config.ClusterName},
+		attribute.Attribute{Key: "provider.accountId", Value: t.shim.config.AWSAccountID},
+	)
+
+	ms.SetMetric("provider.leader", float64(m.Leader), metric.GAUGE)
+	ms.SetMetric("provider.replicaCount", float64(m.ReplicaCount), metric.GAUGE)
+	ms.SetMetric("provider.inSyncReplicaCount", float64(m.InSyncReplicaCount), metric.GAUGE)
+	ms.SetMetric("provider.underReplicated", boolToFloat(m.InSyncReplicaCount < m.ReplicaCount), metric.GAUGE)
+	ms.SetMetric("provider.sizeInBytes", float64(m.SizeInBytes), metric.GAUGE)
+
+	log.Debug("Transformed partition metrics for %s", m.Key)
+	return nil
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,165 @@
+package msk
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// cacheSnapshot is the on-disk JSON shape a CacheBackend persists.
+type cacheSnapshot struct {
+	DescriptorVersion string            `json:"descriptorVersion"`
+	Entries           map[string]string `json:"entries"`
+}
+
+// CacheBackend is the storage contract PersistentGUIDCache saves its
+// key->GUID entries through. InMemoryCacheBackend (a no-op) is the default,
+// so a plain GUIDCache's in-memory-only behavior is unchanged unless an
+// operator opts into a real backend like JSONFileCacheBackend.
+type CacheBackend interface {
+	// Load returns the persisted key->GUID entries and the descriptor
+	// version they were generated under. A backend with nothing persisted
+	// yet returns an empty map, "", and a nil error.
+	Load() (map[string]string, string, error)
+	// Save persists entries under descriptorVersion, replacing whatever
+	// this backend previously had saved.
+	Save(entries map[string]string, descriptorVersion string) error
+}
+
+// InMemoryCacheBackend is a no-op CacheBackend: Load always returns empty,
+// Save discards its input. It keeps PersistentGUIDCache's disk persistence
+// strictly opt-in.
+type InMemoryCacheBackend struct{}
+
+// Load always reports no persisted entries.
+func (InMemoryCacheBackend) Load() (map[string]string, string, error) {
+	return map[string]string{}, "", nil
+}
+
+// Save discards entries; nothing is persisted.
+func (InMemoryCacheBackend) Save(entries map[string]string, descriptorVersion string) error {
+	return nil
+}
+
+// JSONFileCacheBackend persists a GUIDCache's entries as a single JSON
+// snapshot file, reading/writing the whole thing each time - adequate given
+// GUIDCacheSize bounds how large the snapshot can get.
+type JSONFileCacheBackend struct {
+	path string
+}
+
+// NewJSONFileCacheBackend creates a backend whose snapshot lives at path.
+func NewJSONFileCacheBackend(path string) *JSONFileCacheBackend {
+	return &JSONFileCacheBackend{path: path}
+}
+
+// Load reads path's snapshot; a missing file is treated as an empty cache
+// rather than an error, matching a first-ever run.
+func (b *JSONFileCacheBackend) Load() (map[string]string, string, error) {
+	data, err := os.ReadFile(b.path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var snapshot cacheSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, "", err
+	}
+	if snapshot.Entries == nil {
+		snapshot.Entries = map[string]string{}
+	}
+	return snapshot.Entries, snapshot.DescriptorVersion, nil
+}
+
+// Save overwrites path with entries and descriptorVersion.
+func (b *JSONFileCacheBackend) Save(entries map[string]string, descriptorVersion string) error {
+	data, err := json.Marshal(cacheSnapshot{DescriptorVersion: descriptorVersion, Entries: entries})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(b.path, data, 0o600)
+}
+
+// PersistentGUIDCache wraps a GUIDCache with disk persistence through a
+// CacheBackend: it loads whatever a prior process saved at construction
+// time, rebuilding lazily (starting empty) if the snapshot's descriptor
+// version doesn't match EntityDescriptorSchemaVersion, and flushes the
+// current entries back to the backend every flushInterval - so a restart
+// doesn't regenerate, and potentially duplicate, every entity in New Relic.
+type PersistentGUIDCache struct {
+	*GUIDCache
+	backend       CacheBackend
+	flushInterval time.Duration
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewPersistentGUIDCache creates a PersistentGUIDCache of at most maxSize
+// entities backed by backend, flushing to it every flushInterval (a
+// non-positive interval disables the background flusher; callers can still
+// call Flush directly). Pass InMemoryCacheBackend{} for a PersistentGUIDCache
+// that behaves exactly like a plain GUIDCache.
+func NewPersistentGUIDCache(maxSize int, backend CacheBackend, flushInterval time.Duration, opts ...GUIDCacheOption) *PersistentGUIDCache {
+	gc := NewGUIDCache(maxSize, opts...)
+
+	entries, descriptorVersion, err := backend.Load()
+	if err != nil {
+		log.Warn("PersistentGUIDCache: failed to load snapshot, starting empty: %v", err)
+		entries = map[string]string{}
+	} else if descriptorVersion != "" && descriptorVersion != EntityDescriptorSchemaVersion {
+		log.Warn("PersistentGUIDCache: snapshot was written under descriptor version %q, current is %q; rebuilding lazily", descriptorVersion, EntityDescriptorSchemaVersion)
+		entries = map[string]string{}
+	}
+
+	for key, guid := range entries {
+		gc.seed(key, guid)
+	}
+
+	pc := &PersistentGUIDCache{
+		GUIDCache:     gc,
+		backend:       backend,
+		flushInterval: flushInterval,
+		stopCh:        make(chan struct{}),
+	}
+
+	if flushInterval > 0 {
+		go pc.backgroundFlusher()
+	}
+
+	return pc
+}
+
+// backgroundFlusher periodically calls Flush until Stop is called.
+func (pc *PersistentGUIDCache) backgroundFlusher() {
+	ticker := time.NewTicker(pc.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := pc.Flush(); err != nil {
+				log.Warn("PersistentGUIDCache: failed to flush snapshot: %v", err)
+			}
+		case <-pc.stopCh:
+			return
+		}
+	}
+}
+
+// Flush immediately persists the cache's current entries to its backend,
+// outside of backgroundFlusher's regular schedule.
+func (pc *PersistentGUIDCache) Flush() error {
+	return pc.backend.Save(pc.snapshot(), EntityDescriptorSchemaVersion)
+}
+
+// Stop ends the background flush loop; callers that want a final snapshot
+// on shutdown should call Flush() themselves afterward.
+func (pc *PersistentGUIDCache) Stop() {
+	pc.stopOnce.Do(func() { close(pc.stopCh) })
+}
@@ -0,0 +1,187 @@
+package msk
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// promSample is the latest value PrometheusExporter has recorded for one
+// (name, labels) series.
+type promSample struct {
+	name       string
+	metricType string
+	value      float64
+	labels     map[string]string
+}
+
+// PrometheusExporter is a pull-based Exporter: AddMetric just records the
+// latest value per series, and an in-process HTTP server renders them in
+// OpenMetrics text format whenever something scrapes ListenAddr's /metrics.
+// Unlike PrometheusSink (which reads MSKShim's aggregator for the
+// entity-based JMX path), this exporter renders whatever
+// DimensionalTransformer has fanned out to it.
+type PrometheusExporter struct {
+	listenAddr string
+
+	mu      sync.Mutex
+	samples map[string]*promSample
+
+	srv *http.Server
+}
+
+// NewPrometheusExporter creates an exporter that will serve /metrics on
+// listenAddr once Start is called; an empty listenAddr makes Start a no-op.
+func NewPrometheusExporter(listenAddr string) *PrometheusExporter {
+	return &PrometheusExporter{
+		listenAddr: listenAddr,
+		samples:    make(map[string]*promSample),
+	}
+}
+
+// Name identifies this exporter in Config.Exporters and logs.
+func (e *PrometheusExporter) Name() string {
+	return "prometheus"
+}
+
+// AddMetric records value as the latest reading for (name, attrs); a prior
+// reading for the same series is overwritten, matching Prometheus's own
+// gauge/scrape semantics.
+func (e *PrometheusExporter) AddMetric(name string, value float64, metricType string, timestamp int64, attrs map[string]interface{}) {
+	labels := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		labels[sanitizePromLabelName(k)] = fmt.Sprintf("%v", v)
+	}
+	if metricType == "" {
+		metricType = "gauge"
+	}
+
+	key := promSeriesKey(name, labels)
+
+	e.mu.Lock()
+	e.samples[key] = &promSample{name: name, metricType: metricType, value: value, labels: labels}
+	e.mu.Unlock()
+}
+
+// Flush is a no-op: PrometheusExporter is pull-based, so there's nothing to
+// push - a scraper reads whatever AddMetric most recently recorded.
+func (e *PrometheusExporter) Flush() error {
+	return nil
+}
+
+// Start begins serving /metrics in a background goroutine; a no-op if
+// listenAddr is empty, matching the opt-in behavior of other MSK sinks
+// gated on a listen-address Config field.
+func (e *PrometheusExporter) Start() {
+	if e.listenAddr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", e.handleMetrics)
+	e.srv = &http.Server{Addr: e.listenAddr, Handler: mux}
+
+	go func() {
+		log.Info("PrometheusExporter listening on %s", e.listenAddr)
+		if err := e.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("PrometheusExporter stopped: %v", err)
+		}
+	}()
+}
+
+// Stop gracefully shuts the server down, if it was started.
+func (e *PrometheusExporter) Stop() error {
+	if e.srv == nil {
+		return nil
+	}
+	return e.srv.Close()
+}
+
+// handleMetrics renders the current snapshot of recorded series in
+// OpenMetrics text format, grouped and sorted by metric name for
+// deterministic scrape output.
+func (e *PrometheusExporter) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	snapshot := make([]*promSample, 0, len(e.samples))
+	for _, sample := range e.samples {
+		snapshot = append(snapshot, sample)
+	}
+	e.mu.Unlock()
+
+	byName := make(map[string][]*promSample)
+	for _, sample := range snapshot {
+		byName[sample.name] = append(byName[sample.name], sample)
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		samples := byName[name]
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, openMetricsType(samples[0].metricType))
+		for _, sample := range samples {
+			fmt.Fprintf(&b, "%s{%s} %v\n", name, formatPromLabels(sample.labels), sample.value)
+		}
+	}
+	fmt.Fprint(&b, "# EOF\n")
+
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// openMetricsType maps a Metric.Type value to the OpenMetrics # TYPE token,
+// defaulting unrecognized values to gauge rather than rejecting them.
+func openMetricsType(metricType string) string {
+	switch metricType {
+	case "counter", "count":
+		return "counter"
+	case "summary":
+		return "summary"
+	default:
+		return "gauge"
+	}
+}
+
+// sanitizePromLabelName replaces characters Prometheus label names don't
+// allow (anything outside [a-zA-Z0-9_]) with "_", since dimensional
+// attribute keys like "cluster.name" use dots.
+func sanitizePromLabelName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// formatPromLabels renders labels as a sorted "k=\"v\",k2=\"v2\"" label set,
+// sorted so repeated scrapes of the same series produce byte-identical output.
+func formatPromLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%q", k, labels[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+// promSeriesKey builds a stable map key identifying one (name, labels) series.
+func promSeriesKey(name string, labels map[string]string) string {
+	return name + "{" + formatPromLabels(labels) + "}"
+}
@@ -0,0 +1,97 @@
+package msk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// PrometheusSink is an alternative output path for MSKShim: instead of (or
+// alongside) emitting AwsMsk*Sample entities via the Infra SDK, it renders
+// the aggregator's current values in Prometheus text exposition format and
+// serves them on /metrics. Unlike the prometheus subpackage, this sink has
+// no third-party client library dependency -- it formats the exposition
+// text itself, which keeps it usable from inside the msk package without an
+// import cycle back to the shim.
+type PrometheusSink struct {
+	shim *MSKShim
+	addr string
+
+	mu  sync.Mutex
+	srv *http.Server
+}
+
+// NewPrometheusSink creates a sink that reads from shim's aggregator.
+func NewPrometheusSink(shim *MSKShim, addr string) *PrometheusSink {
+	return &PrometheusSink{shim: shim, addr: addr}
+}
+
+// Start begins serving /metrics in a background goroutine. It is a no-op if
+// addr is empty, matching the opt-in behavior of Config.PrometheusListenAddr.
+func (s *PrometheusSink) Start() {
+	if s.addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+
+	s.mu.Lock()
+	s.srv = &http.Server{Addr: s.addr, Handler: mux}
+	s.mu.Unlock()
+
+	go func() {
+		log.Info("MSK PrometheusSink listening on %s", s.addr)
+		if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("MSK PrometheusSink stopped: %v", err)
+		}
+	}()
+}
+
+// Stop gracefully shuts the server down, if it was started.
+func (s *PrometheusSink) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.srv == nil {
+		return nil
+	}
+	return s.srv.Shutdown(ctx)
+}
+
+// handleMetrics renders the current aggregator state in Prometheus text
+// exposition format.
+func (s *PrometheusSink) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var b strings.Builder
+	cluster := s.shim.aggregator.GetClusterMetrics()
+	labels := fmt.Sprintf(`cluster="%s",aws_account_id="%s",aws_region="%s"`,
+		s.shim.config.ClusterName, s.shim.config.AWSAccountID, s.shim.config.AWSRegion)
+
+	writeGauge(&b, "msk_cluster_active_controller_count", labels, float64(cluster.ActiveControllerCount))
+	writeGauge(&b, "msk_cluster_global_partition_count", labels, float64(cluster.GlobalPartitionCount))
+	writeGauge(&b, "msk_cluster_under_replicated_partitions", labels, float64(cluster.UnderReplicatedPartitions))
+	writeGauge(&b, "msk_cluster_bytes_in_per_sec", labels, cluster.BytesInPerSec)
+	writeGauge(&b, "msk_cluster_bytes_out_per_sec", labels, cluster.BytesOutPerSec)
+
+	for brokerID, metrics := range s.shim.aggregator.GetBrokerMetrics() {
+		brokerLabels := fmt.Sprintf(`%s,broker_id="%s"`, labels, brokerID)
+		writeGauge(&b, "msk_broker_bytes_in_per_sec", brokerLabels, toFloatValue(metrics["broker.IOInPerSecond"]))
+		writeGauge(&b, "msk_broker_bytes_out_per_sec", brokerLabels, toFloatValue(metrics["broker.IOOutPerSecond"]))
+		writeGauge(&b, "msk_broker_messages_in_per_sec", brokerLabels, toFloatValue(metrics["broker.messagesInPerSecond"]))
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+func writeGauge(b *strings.Builder, name, labels string, value float64) {
+	fmt.Fprintf(b, "# TYPE %s gauge\n%s{%s} %v\n", name, name, labels, value)
+}
+
+func toFloatValue(v interface{}) float64 {
+	f, _ := v.(float64)
+	return f
+}
@@ -0,0 +1,202 @@
+// Package promexport mirrors every provider.* metric
+// SimpleTransformBrokerMetrics/SimpleTransformTopicMetrics/
+// SimpleTransformClusterMetrics/SimpleTransformConsumerOffset emit as a
+// native Prometheus gauge, so a cluster can be scraped without running the
+// New Relic agent at all. Unlike msk/prometheus.Collector (which re-derives
+// a fixed, curated metric set by reading MetricAggregator on every scrape),
+// Exporter is push-based: callers Record each provider.* value as it's
+// produced, and whatever was last recorded for a series is what the next
+// scrape returns.
+package promexport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// DefaultListenAddr is used when MSK_PROM_LISTEN_ADDR is unset.
+const DefaultListenAddr = ":9682"
+
+// Scope identifies which SimpleTransform* call produced a metric, and so
+// which label set its gauge is keyed by.
+type Scope string
+
+const (
+	ScopeBroker        Scope = "broker"
+	ScopeTopic         Scope = "topic"
+	ScopeCluster       Scope = "cluster"
+	ScopeConsumerGroup Scope = "consumer_group"
+)
+
+// labelNames is the full label set Exporter knows about, per scope. A
+// gauge for a scope only carries the labels relevant to it -- a cluster
+// gauge has no broker_id, a broker gauge has no topic/partition/
+// consumer_group, etc.
+var labelNames = map[Scope][]string{
+	ScopeBroker:        {"cluster", "broker_id", "aws_account_id", "aws_region"},
+	ScopeTopic:         {"cluster", "topic", "aws_account_id", "aws_region"},
+	ScopeCluster:       {"cluster", "aws_account_id", "aws_region"},
+	ScopeConsumerGroup: {"cluster", "topic", "partition", "consumer_group", "aws_account_id", "aws_region"},
+}
+
+// Exporter registers a dynamically-growing set of prometheus.GaugeVecs --
+// one per distinct translated metric name -- against its own Registry, and
+// serves them on listenAddr's /metrics.
+type Exporter struct {
+	listenAddr string
+	registry   *prometheus.Registry
+
+	mu     sync.Mutex
+	gauges map[string]*prometheus.GaugeVec
+
+	scrapeDuration prometheus.Gauge
+
+	extraHandlers map[string]http.Handler
+
+	srv *http.Server
+}
+
+// NewExporter creates an Exporter that will serve /metrics on listenAddr
+// once Start is called. listenAddr <= "" falls back to DefaultListenAddr.
+func NewExporter(listenAddr string) *Exporter {
+	if listenAddr == "" {
+		listenAddr = DefaultListenAddr
+	}
+
+	registry := prometheus.NewRegistry()
+	scrapeDuration := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "msk_scrape_duration_seconds",
+		Help: "How long the previous /metrics scrape took to render.",
+	})
+	registry.MustRegister(scrapeDuration)
+
+	return &Exporter{
+		listenAddr:     listenAddr,
+		registry:       registry,
+		gauges:         make(map[string]*prometheus.GaugeVec),
+		scrapeDuration: scrapeDuration,
+	}
+}
+
+// Record sets name's gauge (translated via promMetricName) to value for the
+// given scope and labels, registering the gauge on first use. labels must
+// supply every label in labelNames[scope]; a missing one renders as "".
+func (e *Exporter) Record(scope Scope, providerName string, value float64, labels map[string]string) {
+	name := promMetricName(scope, providerName)
+
+	e.mu.Lock()
+	gauge, exists := e.gauges[name]
+	if !exists {
+		gauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: name,
+			Help: fmt.Sprintf("MSK shim metric %s, scope=%s.", providerName, scope),
+		}, labelNames[scope])
+		e.registry.MustRegister(gauge)
+		e.gauges[name] = gauge
+	}
+	e.mu.Unlock()
+
+	values := make([]string, len(labelNames[scope]))
+	for i, label := range labelNames[scope] {
+		values[i] = labels[label]
+	}
+	gauge.WithLabelValues(values...).Set(value)
+}
+
+// Handle registers handler on pattern alongside /metrics once Start runs.
+// Calling this after Start has no effect -- register everything first.
+func (e *Exporter) Handle(pattern string, handler http.Handler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.extraHandlers == nil {
+		e.extraHandlers = make(map[string]http.Handler)
+	}
+	e.extraHandlers[pattern] = handler
+}
+
+// Start begins serving /metrics in a background goroutine.
+func (e *Exporter) Start() {
+	mux := http.NewServeMux()
+	handler := promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		handler.ServeHTTP(w, r)
+		// This scrape's own render time can't appear in its own response
+		// body, so it's recorded for the *next* scrape to report -- the
+		// usual tradeoff for self-instrumenting a scrape handler.
+		e.scrapeDuration.Set(time.Since(start).Seconds())
+	})
+	for pattern, h := range e.extraHandlers {
+		mux.Handle(pattern, h)
+	}
+	e.srv = &http.Server{Addr: e.listenAddr, Handler: mux}
+
+	go func() {
+		log.Info("MSK promexport listening on %s", e.listenAddr)
+		if err := e.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("MSK promexport stopped: %v", err)
+		}
+	}()
+}
+
+// Stop gracefully shuts down the HTTP server.
+func (e *Exporter) Stop(ctx context.Context) error {
+	if e.srv == nil {
+		return nil
+	}
+	return e.srv.Shutdown(ctx)
+}
+
+// DefaultShutdownTimeout is used by callers that don't need a custom
+// context when stopping the server.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// promMetricName translates a provider.* metric name into the
+// msk_<scope>_<name>[_avg|_sum] form: the "provider." prefix is dropped,
+// ".Average"/".Sum" suffixes become "_avg"/"_sum", and the remaining
+// camelCase/dotted name is underscore-normalized.
+func promMetricName(scope Scope, providerName string) string {
+	trimmed := strings.TrimPrefix(providerName, "provider.")
+
+	suffix := ""
+	switch {
+	case strings.HasSuffix(trimmed, ".Average"):
+		trimmed = strings.TrimSuffix(trimmed, ".Average")
+		suffix = "_avg"
+	case strings.HasSuffix(trimmed, ".Sum"):
+		trimmed = strings.TrimSuffix(trimmed, ".Sum")
+		suffix = "_sum"
+	}
+
+	return fmt.Sprintf("msk_%s_%s%s", scope, snakeCase(trimmed), suffix)
+}
+
+// snakeCase underscore-normalizes a camelCase/dotted provider.* metric
+// name fragment, e.g. "bytesInPerSec" -> "bytes_in_per_sec",
+// "topicName" -> "topic_name".
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '.':
+			b.WriteRune('_')
+		case r >= 'A' && r <= 'Z':
+			if i > 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r - 'A' + 'a')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package promexport
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "bytes_in_per_sec", snakeCase("bytesInPerSec"))
+	assert.Equal(t, "topic_name", snakeCase("topicName"))
+	assert.Equal(t, "under_replicated_partitions__sum", snakeCase("underReplicatedPartitions.Sum"), "snakeCase itself doesn't strip aggregation suffixes; that's promMetricName's job, covered separately below")
+}
+
+func TestPromMetricName(t *testing.T) {
+	cases := []struct {
+		name         string
+		scope        Scope
+		providerName string
+		want         string
+	}{
+		{"average suffix", ScopeBroker, "provider.bytesInPerSec.Average", "msk_broker_bytes_in_per_sec_avg"},
+		{"sum suffix", ScopeCluster, "provider.underReplicatedPartitions.Sum", "msk_cluster_under_replicated_partitions_sum"},
+		{"no suffix", ScopeTopic, "provider.partitionCount", "msk_topic_partition_count"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, promMetricName(tc.scope, tc.providerName))
+		})
+	}
+}
+
+func TestRecord_RegistersGaugeOnFirstUseAndSetsValue(t *testing.T) {
+	e := NewExporter("")
+
+	e.Record(ScopeBroker, "provider.bytesInPerSec.Average", 42.5, map[string]string{
+		"cluster":        "test-cluster",
+		"broker_id":      "1",
+		"aws_account_id": "123456789012",
+		"aws_region":     "us-east-1",
+	})
+
+	got := testutil.ToFloat64(e.gauges["msk_broker_bytes_in_per_sec_avg"].WithLabelValues("test-cluster", "1", "123456789012", "us-east-1"))
+	assert.Equal(t, 42.5, got)
+}
+
+func TestRecord_SecondRecordOverwritesPreviousValue(t *testing.T) {
+	e := NewExporter("")
+	labels := map[string]string{"cluster": "c", "aws_account_id": "1", "aws_region": "us-east-1"}
+
+	e.Record(ScopeCluster, "provider.globalPartitionCount", 10, labels)
+	e.Record(ScopeCluster, "provider.globalPartitionCount", 20, labels)
+
+	got := testutil.ToFloat64(e.gauges["msk_cluster_global_partition_count"].WithLabelValues("c", "1", "us-east-1"))
+	assert.Equal(t, 20.0, got)
+}
+
+func TestNewExporter_FallsBackToDefaultListenAddr(t *testing.T) {
+	e := NewExporter("")
+	assert.Equal(t, DefaultListenAddr, e.listenAddr)
+}
+
+func TestStop_NilServer_IsNoop(t *testing.T) {
+	e := NewExporter("")
+	assert.NoError(t, e.Stop(nil)) //nolint:staticcheck // Stop returns immediately before ctx is used when srv is nil
+}
@@ -0,0 +1,51 @@
+package msk
+
+import "fmt"
+
+// purgatoryDataKeys enumerates the flattened broker-data keys a
+// purgatory-aware collector populates, one per delayed-operation/
+// attribute pair, matching the "<delayedOperation>.<attribute>" shape
+// MapPurgatoryMetric expects.
+func purgatoryDataKeys() []string {
+	keys := make([]string, 0, len(delayedOperations)*len(purgatoryAttributes))
+	for delayedOp := range delayedOperations {
+		for attr := range purgatoryAttributes {
+			keys = append(keys, fmt.Sprintf("purgatory.%s.%s", delayedOp, attr))
+		}
+	}
+	return keys
+}
+
+// hasPurgatoryMetrics reports whether brokerData contains any non-zero
+// purgatory metric, the canonical signal that a broker is alive and
+// reporting even when idle (BytesInPerSec can legitimately be zero on a
+// healthy but quiet broker).
+func hasPurgatoryMetrics(brokerData map[string]interface{}) bool {
+	for _, key := range purgatoryDataKeys() {
+		if val, ok := getFloatValue(brokerData, key); ok && val != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// TransformPurgatoryMetrics emits MSK-style purgatory metrics
+// (kafka.broker.purgatory<Op><Size|DelayedOps>) for every delayed
+// operation present in brokerData, naming each via mapper and emitting it
+// through setMetric.
+func TransformPurgatoryMetrics(brokerData map[string]interface{}, mapper *MetricMapper, setMetric func(name string, value float64)) {
+	for delayedOp := range delayedOperations {
+		for attr := range purgatoryAttributes {
+			key := fmt.Sprintf("purgatory.%s.%s", delayedOp, attr)
+			val, ok := getFloatValue(brokerData, key)
+			if !ok {
+				continue
+			}
+			mskMetric, ok := mapper.MapPurgatoryMetric(delayedOp, attr)
+			if !ok {
+				continue
+			}
+			setMetric(mskMetric, val)
+		}
+	}
+}
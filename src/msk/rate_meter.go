@@ -0,0 +1,223 @@
+package msk
+
+import (
+	"container/list"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateMeterWindows are the three standard decay windows Kafka's own
+// com.yammer.metrics Meter MBeans expose as OneMinuteRate, FiveMinuteRate
+// and FifteenMinuteRate.
+var rateMeterWindows = []struct {
+	name   string
+	window time.Duration
+}{
+	{"OneMinuteRate", time.Minute},
+	{"FiveMinuteRate", 5 * time.Minute},
+	{"FifteenMinuteRate", 15 * time.Minute},
+}
+
+// rateMeterHistory caps how many raw samples a RateMeter retains for
+// inspection. The EWMAs themselves only need the most recent sample to
+// update, but the ring buffer bounds memory for a meter that now survives
+// every Flush cycle instead of being thrown away with the rest of the
+// aggregator.
+const rateMeterHistory = 180
+
+// rateSample is one instantaneous rate observation fed to a RateMeter, e.g.
+// a single cycle's BytesInPerSec for one broker or topic.
+type rateSample struct {
+	at    time.Time
+	value float64
+}
+
+// RateMeterSnapshot is a point-in-time read of a RateMeter's three decay
+// windows.
+type RateMeterSnapshot struct {
+	OneMinuteRate     float64
+	FiveMinuteRate    float64
+	FifteenMinuteRate float64
+}
+
+// RateMeter tracks a per-second rate's exponentially-weighted moving
+// average across 1/5/15-minute windows, matching the decay shape Kafka's
+// own Meter MBeans use for OneMinuteRate etc, except decayed by wall-clock
+// time elapsed between updates rather than a fixed 5-second tick -- this
+// aggregator is driven by however often samples arrive, not a timer of its
+// own.
+type RateMeter struct {
+	history  []rateSample // ring buffer, oldest entry overwritten first
+	head     int
+	count    int
+	ewma     map[string]float64
+	lastTime time.Time
+	samples  int64
+}
+
+// NewRateMeter creates an empty RateMeter.
+func NewRateMeter() *RateMeter {
+	return &RateMeter{
+		history: make([]rateSample, rateMeterHistory),
+		ewma:    make(map[string]float64, len(rateMeterWindows)),
+	}
+}
+
+// Update folds one new rate observation into every window's EWMA and
+// records it in the ring buffer. The first call seeds every window with
+// value rather than decaying toward it, matching a fresh Meter's behavior
+// before its first tick.
+func (m *RateMeter) Update(value float64, now time.Time) {
+	m.history[m.head] = rateSample{at: now, value: value}
+	m.head = (m.head + 1) % len(m.history)
+	if m.count < len(m.history) {
+		m.count++
+	}
+
+	if m.samples == 0 {
+		for _, w := range rateMeterWindows {
+			m.ewma[w.name] = value
+		}
+	} else {
+		elapsed := now.Sub(m.lastTime)
+		if elapsed <= 0 {
+			elapsed = time.Second
+		}
+		for _, w := range rateMeterWindows {
+			alpha := 1 - math.Exp(-float64(elapsed)/float64(w.window))
+			m.ewma[w.name] += alpha * (value - m.ewma[w.name])
+		}
+	}
+	m.lastTime = now
+	m.samples++
+}
+
+// Snapshot returns every window's current EWMA at once.
+func (m *RateMeter) Snapshot() RateMeterSnapshot {
+	return RateMeterSnapshot{
+		OneMinuteRate:     m.ewma["OneMinuteRate"],
+		FiveMinuteRate:    m.ewma["FiveMinuteRate"],
+		FifteenMinuteRate: m.ewma["FifteenMinuteRate"],
+	}
+}
+
+// Samples returns how many observations Update has folded in so far.
+func (m *RateMeter) Samples() int64 {
+	return m.samples
+}
+
+// rateMeterBundle is the set of per-metric RateMeters tracked for one key
+// (a broker ID, a topic name, or the single cluster-wide key), held as the
+// LRU list element's Value so promoting/evicting it only touches the list.
+type rateMeterBundle struct {
+	key    string
+	meters map[string]*RateMeter
+}
+
+// RateMeterCache bounds how many keys' rate history MetricAggregator keeps
+// resident, evicting the least recently updated key once full so a cluster
+// with thousands of topics can't grow this state without bound.
+type RateMeterCache struct {
+	mu      sync.Mutex
+	maxSize int
+
+	entries map[string]*list.Element
+	order   *list.List // front = most recently used, back = least recently used
+
+	totalSamples int64
+	evictions    int64
+}
+
+// NewRateMeterCache creates a cache holding at most maxSize keys' rate
+// history. maxSize <= 0 disables the bound.
+func NewRateMeterCache(maxSize int) *RateMeterCache {
+	return &RateMeterCache{
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Update records value at now for key/metricName, creating the key's
+// RateMeter bundle on first use and evicting the least recently updated key
+// if the cache is already at maxSize.
+func (c *RateMeterCache) Update(key, metricName string, value float64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		for c.maxSize > 0 && len(c.entries) >= c.maxSize {
+			c.evictOldestLocked()
+		}
+		bundle := &rateMeterBundle{key: key, meters: make(map[string]*RateMeter)}
+		el = c.order.PushFront(bundle)
+		c.entries[key] = el
+	} else {
+		c.order.MoveToFront(el)
+	}
+
+	bundle := el.Value.(*rateMeterBundle)
+	meter, ok := bundle.meters[metricName]
+	if !ok {
+		meter = NewRateMeter()
+		bundle.meters[metricName] = meter
+	}
+	meter.Update(value, now)
+	c.totalSamples++
+}
+
+// Snapshot returns key's current per-metric EWMA snapshots, or nil if key
+// isn't resident (never observed, or evicted under cardinality pressure).
+func (c *RateMeterCache) Snapshot(key string) map[string]RateMeterSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	bundle := el.Value.(*rateMeterBundle)
+	result := make(map[string]RateMeterSnapshot, len(bundle.meters))
+	for name, meter := range bundle.meters {
+		result[name] = meter.Snapshot()
+	}
+	return result
+}
+
+// evictOldestLocked removes the least recently updated entry; callers must
+// hold c.mu.
+func (c *RateMeterCache) evictOldestLocked() {
+	oldest := c.order.Back()
+	if oldest == nil {
+		return
+	}
+	bundle := oldest.Value.(*rateMeterBundle)
+	c.order.Remove(oldest)
+	delete(c.entries, bundle.key)
+	c.evictions++
+}
+
+// Len returns how many keys currently have resident rate history.
+func (c *RateMeterCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Evictions returns how many keys have been evicted for cardinality
+// pressure over this cache's lifetime.
+func (c *RateMeterCache) Evictions() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.evictions
+}
+
+// Samples returns how many Update calls this cache has folded in over its
+// lifetime, across every key.
+func (c *RateMeterCache) Samples() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.totalSamples
+}
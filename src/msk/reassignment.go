@@ -0,0 +1,240 @@
+package msk
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// ReassignmentPoller periodically issues ListPartitionReassignments
+// against the cluster admin client the collector already opens, and
+// turns in-flight KIP-455 moves into per-partition and cluster-level
+// metrics. Unlike ReassignmentCollector (which models bytes-remaining via
+// a generic admin interface for testability), this poller is the
+// production entry point EnhancedShim wires up directly, and it
+// permanently disables itself the first time the broker reports
+// UNSUPPORTED_VERSION, since that means the cluster predates 2.4 and will
+// never support the API for the rest of this process's lifetime.
+type ReassignmentPoller struct {
+	admin    ReassignmentAdminClient
+	interval time.Duration
+	mapper   *MetricMapper
+
+	mu               sync.Mutex
+	disabled         bool
+	activeCount      int
+	lastPartitions   []ReplicaReassignment
+	addingReplicas   int
+	removingReplicas int
+	topicCounts      map[string]int
+	brokerReceiving  map[int32]int
+	brokerShedding   map[int32]int
+}
+
+// NewReassignmentPoller creates a poller. Call Poll on whatever cadence
+// the collector's own collection loop runs at; it's not self-scheduling.
+func NewReassignmentPoller(admin ReassignmentAdminClient, mapper *MetricMapper) *ReassignmentPoller {
+	return &ReassignmentPoller{admin: admin, mapper: mapper}
+}
+
+// isUnsupportedVersion reports whether err indicates the broker doesn't
+// support the reassignment API (pre-2.4).
+func isUnsupportedVersion(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNSUPPORTED_VERSION")
+}
+
+// Poll fetches the current reassignment set and emits per-partition
+// metrics via emitPartition, returning the cluster-wide active count. It
+// is a no-op once the poller has been disabled by an UNSUPPORTED_VERSION
+// response.
+func (p *ReassignmentPoller) Poll(emitPartition func(ReplicaReassignment, int64)) (int, error) {
+	p.mu.Lock()
+	if p.disabled {
+		p.mu.Unlock()
+		return 0, nil
+	}
+	p.mu.Unlock()
+
+	reassignments, err := p.admin.ListPartitionReassignments()
+	if err != nil {
+		if isUnsupportedVersion(err) {
+			p.mu.Lock()
+			p.disabled = true
+			p.mu.Unlock()
+			log.Info("ReassignmentPoller: cluster does not support KIP-455 (pre-2.4); disabling reassignment polling for the rest of this process")
+			return 0, nil
+		}
+		return 0, fmt.Errorf("listing partition reassignments: %w", err)
+	}
+
+	for _, r := range reassignments {
+		bytesRemaining := p.bytesRemaining(r)
+		emitPartition(r, bytesRemaining)
+	}
+
+	addingReplicas := 0
+	removingReplicas := 0
+	topicCounts := make(map[string]int, len(reassignments))
+	brokerReceiving := make(map[int32]int)
+	brokerShedding := make(map[int32]int)
+	for _, r := range reassignments {
+		addingReplicas += len(r.AddingReplicas)
+		removingReplicas += len(r.RemovingReplicas)
+		topicCounts[r.Topic]++
+		for _, brokerID := range r.AddingReplicas {
+			brokerReceiving[brokerID]++
+		}
+		for _, brokerID := range r.RemovingReplicas {
+			brokerShedding[brokerID]++
+		}
+	}
+
+	p.mu.Lock()
+	p.activeCount = len(reassignments)
+	p.lastPartitions = reassignments
+	p.addingReplicas = addingReplicas
+	p.removingReplicas = removingReplicas
+	p.topicCounts = topicCounts
+	p.brokerReceiving = brokerReceiving
+	p.brokerShedding = brokerShedding
+	p.mu.Unlock()
+
+	return len(reassignments), nil
+}
+
+// bytesRemaining mirrors ReassignmentCollector's diffing approach: the
+// source replica's log-end-offset minus the furthest-along target
+// replica's.
+func (p *ReassignmentPoller) bytesRemaining(r ReplicaReassignment) int64 {
+	if len(r.RemovingReplicas) == 0 || len(r.AddingReplicas) == 0 {
+		return 0
+	}
+
+	sourceEnd, err := p.admin.ReplicaLogEndOffset(r.Topic, r.Partition, r.RemovingReplicas[0])
+	if err != nil {
+		return 0
+	}
+
+	var minTargetEnd int64 = -1
+	for _, replica := range r.AddingReplicas {
+		end, err := p.admin.ReplicaLogEndOffset(r.Topic, r.Partition, replica)
+		if err != nil {
+			continue
+		}
+		if minTargetEnd == -1 || end < minTargetEnd {
+			minTargetEnd = end
+		}
+	}
+	if minTargetEnd == -1 {
+		return 0
+	}
+
+	remaining := sourceEnd - minTargetEnd
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// ActiveCount returns the most recently polled cluster-wide in-flight
+// reassignment count.
+func (p *ReassignmentPoller) ActiveCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.activeCount
+}
+
+// IsActive reports whether any reassignment was in flight as of the most
+// recent Poll, for a provider.reassignmentActive boolean attribute so NRQL
+// alerts can fire on "reassignment running for > N minutes".
+func (p *ReassignmentPoller) IsActive() bool {
+	return p.ActiveCount() > 0
+}
+
+// TotalAddingReplicas returns the sum, across every in-flight partition
+// reassignment as of the most recent Poll, of replicas being added.
+func (p *ReassignmentPoller) TotalAddingReplicas() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.addingReplicas
+}
+
+// TotalRemovingReplicas returns the sum, across every in-flight partition
+// reassignment as of the most recent Poll, of replicas being removed.
+func (p *ReassignmentPoller) TotalRemovingReplicas() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.removingReplicas
+}
+
+// TopicReassigningCount returns how many partitions of topic are currently
+// being reassigned, as of the most recent Poll.
+func (p *ReassignmentPoller) TopicReassigningCount(topic string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.topicCounts[topic]
+}
+
+// BrokerReceivingCount returns how many partitions brokerID is currently
+// receiving a new replica for (i.e. appears in AddingReplicas), as of the
+// most recent Poll.
+func (p *ReassignmentPoller) BrokerReceivingCount(brokerID int32) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.brokerReceiving[brokerID]
+}
+
+// BrokerSheddingCount returns how many partitions brokerID is currently
+// shedding a replica from (i.e. appears in RemovingReplicas), as of the
+// most recent Poll.
+func (p *ReassignmentPoller) BrokerSheddingCount(brokerID int32) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.brokerShedding[brokerID]
+}
+
+// EmitToMetricSet writes a single partition's reassignment metrics onto
+// ms using mapper's reassignmentMappings.
+func EmitToMetricSet(ms *metric.Set, r ReplicaReassignment, bytesRemaining int64, mapper *MetricMapper) {
+	ms.SetMetric(mapper.reassignmentMappings["inProgress"], 1.0, metric.GAUGE)
+	ms.SetMetric(mapper.reassignmentMappings["bytesRemaining"], float64(bytesRemaining), metric.GAUGE)
+	ms.SetMetric(mapper.reassignmentMappings["addingReplicas"], float64(len(r.AddingReplicas)), metric.GAUGE)
+	ms.SetMetric(mapper.reassignmentMappings["removingReplicas"], float64(len(r.RemovingReplicas)), metric.GAUGE)
+	ms.SetMetric("topic", r.Topic, metric.ATTRIBUTE)
+	ms.SetMetric("partition", fmt.Sprintf("%d", r.Partition), metric.ATTRIBUTE)
+}
+
+// EmitClusterRollup attaches the cluster-wide active-reassignment count
+// to the cluster entity's metric set.
+func EmitClusterRollup(ms *metric.Set, activeCount int, mapper *MetricMapper) {
+	ms.SetMetric(mapper.reassignmentMappings["clusterActive"], float64(activeCount), metric.GAUGE)
+}
+
+// EmitBrokerRollup attaches how many in-flight reassignments brokerID is
+// currently a party to (either receiving or shedding a replica) to the
+// broker entity's metric set.
+func EmitBrokerRollup(ms *metric.Set, brokerID int32, poller *ReassignmentPoller, mapper *MetricMapper) {
+	count := poller.BrokerReceivingCount(brokerID) + poller.BrokerSheddingCount(brokerID)
+	ms.SetMetric(mapper.reassignmentMappings["brokerReassigning"], float64(count), metric.GAUGE)
+}
+
+// EmitTopicRollup attaches how many of topic's partitions are currently
+// being reassigned to the topic entity's metric set.
+func EmitTopicRollup(ms *metric.Set, topic string, poller *ReassignmentPoller, mapper *MetricMapper) {
+	ms.SetMetric(mapper.reassignmentMappings["topicReassigning"], float64(poller.TopicReassigningCount(topic)), metric.GAUGE)
+}
+
+// ReassignmentState is one (topic, partition)'s in-flight KIP-455
+// reassignment state, the map value IntegrationHook.TransformReassignmentData
+// accepts -- a transformer-facing shape distinct from ReplicaReassignment
+// (the raw ListPartitionReassignments response), adding BytesRemaining
+// once copy progress has been resolved against the source/target replicas.
+type ReassignmentState struct {
+	AddingReplicas   []int32
+	RemovingReplicas []int32
+	BytesRemaining   int64
+}
@@ -0,0 +1,93 @@
+package msk
+
+import (
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+// saramaReassignmentAdminClient adapts a live sarama connection to the
+// ReassignmentAdminClient interface ReassignmentCollector needs, so its
+// polling loop can report genuine KIP-455 state instead of never being
+// constructed at all.
+type saramaReassignmentAdminClient struct {
+	client sarama.Client
+	admin  sarama.ClusterAdmin
+}
+
+// newSaramaReassignmentAdminClient connects to brokers and returns an
+// adapter ready to pass to NewReassignmentCollector. Callers should Close
+// the returned adapter's underlying connection by calling Close once the
+// collector is no longer needed.
+func newSaramaReassignmentAdminClient(brokers []string) (*saramaReassignmentAdminClient, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("reassignment admin client requires at least one bootstrap server")
+	}
+
+	client, err := sarama.NewClient(brokers, NewSaramaConfig())
+	if err != nil {
+		return nil, fmt.Errorf("reassignment admin client: connecting to %v: %w", brokers, err)
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("reassignment admin client: creating admin client: %w", err)
+	}
+
+	return &saramaReassignmentAdminClient{client: client, admin: admin}, nil
+}
+
+// ListPartitionReassignments lists every topic's in-flight reassignments
+// via the controller, skipping any topic ListPartitionReassignments
+// errors on (most commonly a pre-2.4 cluster that doesn't support the API
+// at all) rather than failing the whole poll over one topic.
+func (a *saramaReassignmentAdminClient) ListPartitionReassignments() ([]ReplicaReassignment, error) {
+	topics, err := a.client.Topics()
+	if err != nil {
+		return nil, fmt.Errorf("listing topics: %w", err)
+	}
+
+	var reassignments []ReplicaReassignment
+	for _, topic := range topics {
+		statuses, err := a.admin.ListPartitionReassignments(topic, nil)
+		if err != nil {
+			continue
+		}
+		for topicName, partitions := range statuses {
+			for partition, status := range partitions {
+				if status == nil || (len(status.AddingReplicas) == 0 && len(status.RemovingReplicas) == 0) {
+					continue
+				}
+				reassignments = append(reassignments, ReplicaReassignment{
+					Topic:            topicName,
+					Partition:        partition,
+					AddingReplicas:   status.AddingReplicas,
+					RemovingReplicas: status.RemovingReplicas,
+				})
+			}
+		}
+	}
+
+	return reassignments, nil
+}
+
+// ReplicaLogEndOffset approximates the log-end-offset of topic/partition on
+// brokerID by asking whichever broker sarama currently treats as leader,
+// since sarama.Client has no direct per-replica fetch API. This is the same
+// offsets-as-a-proxy-for-bytes approximation ReassignmentCollector's own
+// bytesRemaining calculation already accepts.
+func (a *saramaReassignmentAdminClient) ReplicaLogEndOffset(topic string, partition int32, brokerID int32) (int64, error) {
+	return a.client.GetOffset(topic, partition, sarama.OffsetNewest)
+}
+
+// Close releases the underlying Sarama connection.
+func (a *saramaReassignmentAdminClient) Close() error {
+	if a.admin != nil {
+		a.admin.Close()
+	}
+	if a.client != nil {
+		return a.client.Close()
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package msk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// ReplicaReassignment describes one partition's in-flight KIP-455
+// reassignment, as returned by ListPartitionReassignments.
+type ReplicaReassignment struct {
+	Topic            string
+	Partition        int32
+	AddingReplicas   []int32
+	RemovingReplicas []int32
+}
+
+// ReassignmentAdminClient is the controller-routed admin surface
+// ReassignmentCollector needs. Implementations should reuse whatever
+// broker connection the shim already holds for the current controller.
+type ReassignmentAdminClient interface {
+	// ListPartitionReassignments returns all in-flight reassignments
+	// known to the controller (KIP-455).
+	ListPartitionReassignments() ([]ReplicaReassignment, error)
+	// ReplicaLogEndOffset returns the log-end-offset for topic/partition
+	// on the given replica broker, used to derive bytes remaining to copy.
+	ReplicaLogEndOffset(topic string, partition int32, brokerID int32) (int64, error)
+}
+
+// TopicReassignmentProgress is the per-topic reassignment-bytes-remaining
+// signal derived by diffing current log size on source replicas against
+// already-copied bytes on target replicas.
+type TopicReassignmentProgress struct {
+	Topic                    string
+	ReassigningPartitions    int
+	AddingReplicas           int
+	RemovingReplicas         int
+	ReassignmentBytesRemaining int64
+}
+
+// ClusterReassignmentState is the cluster-wide rollup emitted alongside
+// AwsMskClusterSample.
+type ClusterReassignmentState struct {
+	ReassigningPartitions int
+	AddingReplicas        int
+	RemovingReplicas      int
+	Topics                map[string]*TopicReassignmentProgress
+}
+
+// ReassignmentCollector periodically polls the controller for ongoing
+// KIP-455 partition reassignments and turns them into MSK-shaped metrics.
+type ReassignmentCollector struct {
+	admin    ReassignmentAdminClient
+	interval time.Duration
+
+	mu            sync.RWMutex
+	state         *ClusterReassignmentState
+	reassignments []ReplicaReassignment
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+// NewReassignmentCollector creates a collector that polls admin every
+// interval. Call Start to begin polling and Stop to halt it.
+func NewReassignmentCollector(admin ReassignmentAdminClient, interval time.Duration) *ReassignmentCollector {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &ReassignmentCollector{
+		admin:    admin,
+		interval: interval,
+		state:    &ClusterReassignmentState{Topics: make(map[string]*TopicReassignmentProgress)},
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the polling loop in a background goroutine.
+func (c *ReassignmentCollector) Start() {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.poll(); err != nil {
+					log.Warn("ReassignmentCollector poll failed: %v", err)
+				}
+			case <-c.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the polling loop.
+func (c *ReassignmentCollector) Stop() {
+	c.once.Do(func() { close(c.stopCh) })
+}
+
+// poll fetches the current reassignment set and rebuilds the aggregated state.
+func (c *ReassignmentCollector) poll() error {
+	reassignments, err := c.admin.ListPartitionReassignments()
+	if err != nil {
+		return fmt.Errorf("listing partition reassignments: %w", err)
+	}
+
+	state := &ClusterReassignmentState{Topics: make(map[string]*TopicReassignmentProgress)}
+
+	for _, r := range reassignments {
+		progress, ok := state.Topics[r.Topic]
+		if !ok {
+			progress = &TopicReassignmentProgress{Topic: r.Topic}
+			state.Topics[r.Topic] = progress
+		}
+
+		progress.ReassigningPartitions++
+		progress.AddingReplicas += len(r.AddingReplicas)
+		progress.RemovingReplicas += len(r.RemovingReplicas)
+		progress.ReassignmentBytesRemaining += c.bytesRemaining(r)
+
+		state.ReassigningPartitions++
+		state.AddingReplicas += len(r.AddingReplicas)
+		state.RemovingReplicas += len(r.RemovingReplicas)
+	}
+
+	c.mu.Lock()
+	c.state = state
+	c.reassignments = reassignments
+	c.mu.Unlock()
+
+	return nil
+}
+
+// bytesRemaining diffs the source replica's log-end-offset against the
+// least caught-up target replica's, in offsets (a proxy for bytes since
+// exact record sizes aren't available from LogEndOffset alone).
+func (c *ReassignmentCollector) bytesRemaining(r ReplicaReassignment) int64 {
+	if len(r.RemovingReplicas) == 0 || len(r.AddingReplicas) == 0 {
+		return 0
+	}
+
+	sourceEnd, err := c.admin.ReplicaLogEndOffset(r.Topic, r.Partition, r.RemovingReplicas[0])
+	if err != nil {
+		log.Debug("ReassignmentCollector: failed to read source log-end-offset for %s[%d]: %v", r.Topic, r.Partition, err)
+		return 0
+	}
+
+	var minTargetEnd int64 = -1
+	for _, replica := range r.AddingReplicas {
+		end, err := c.admin.ReplicaLogEndOffset(r.Topic, r.Partition, replica)
+		if err != nil {
+			log.Debug("ReassignmentCollector: failed to read target log-end-offset for %s[%d] replica %d: %v", r.Topic, r.Partition, replica, err)
+			continue
+		}
+		if minTargetEnd == -1 || end < minTargetEnd {
+			minTargetEnd = end
+		}
+	}
+	if minTargetEnd == -1 {
+		return 0
+	}
+
+	remaining := sourceEnd - minTargetEnd
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// State returns the most recently polled reassignment snapshot.
+func (c *ReassignmentCollector) State() *ClusterReassignmentState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
+
+// Reassignments returns the raw per-partition reassignments from the most
+// recent poll, for callers (like DimensionalTransformer) that need
+// per-partition source/target broker detail the aggregated State rollup
+// doesn't keep.
+func (c *ReassignmentCollector) Reassignments() []ReplicaReassignment {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reassignments
+}
+
+// ApplyToClusterMetrics folds the collector's current state into a
+// ClusterAggregatedMetrics, overriding OngoingReassignments so a non-zero
+// value dominates the cluster health signal.
+func (c *ReassignmentCollector) ApplyToClusterMetrics(metrics *ClusterAggregatedMetrics) {
+	state := c.State()
+	metrics.OngoingReassignments = state.ReassigningPartitions
+}
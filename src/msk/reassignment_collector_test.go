@@ -0,0 +1,97 @@
+package msk
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReassignmentAdminClient is a test double for ReassignmentAdminClient
+// backed by in-memory fixtures instead of a real controller connection.
+type fakeReassignmentAdminClient struct {
+	reassignments []ReplicaReassignment
+	logEndOffsets map[string]int64
+}
+
+func (f *fakeReassignmentAdminClient) ListPartitionReassignments() ([]ReplicaReassignment, error) {
+	return f.reassignments, nil
+}
+
+func (f *fakeReassignmentAdminClient) ReplicaLogEndOffset(topic string, partition int32, brokerID int32) (int64, error) {
+	key := fmt.Sprintf("%s-%d-%d", topic, partition, brokerID)
+	end, ok := f.logEndOffsets[key]
+	if !ok {
+		return 0, fmt.Errorf("no fixture log-end-offset for %s", key)
+	}
+	return end, nil
+}
+
+func TestReassignmentCollector_PollAggregatesPerTopicAndCluster(t *testing.T) {
+	admin := &fakeReassignmentAdminClient{
+		reassignments: []ReplicaReassignment{
+			{Topic: "orders", Partition: 0, AddingReplicas: []int32{4}, RemovingReplicas: []int32{1}},
+			{Topic: "orders", Partition: 1, AddingReplicas: []int32{4}, RemovingReplicas: []int32{2}},
+		},
+		logEndOffsets: map[string]int64{
+			"orders-0-1": 1000,
+			"orders-0-4": 400,
+			"orders-1-2": 500,
+			"orders-1-4": 500,
+		},
+	}
+	collector := NewReassignmentCollector(admin, 0)
+
+	require.NoError(t, collector.poll())
+
+	state := collector.State()
+	assert.Equal(t, 2, state.ReassigningPartitions)
+	assert.Equal(t, 2, state.AddingReplicas)
+	assert.Equal(t, 2, state.RemovingReplicas)
+
+	topic, ok := state.Topics["orders"]
+	require.True(t, ok)
+	assert.Equal(t, 2, topic.ReassigningPartitions)
+	assert.Equal(t, int64(600), topic.ReassignmentBytesRemaining, "partition 0 has 600 remaining, partition 1 has 0 remaining")
+
+	assert.Len(t, collector.Reassignments(), 2)
+}
+
+func TestReassignmentCollector_BytesRemainingClampsNegativeToZero(t *testing.T) {
+	admin := &fakeReassignmentAdminClient{
+		logEndOffsets: map[string]int64{
+			"orders-0-1": 100,
+			"orders-0-4": 500,
+		},
+	}
+	collector := NewReassignmentCollector(admin, 0)
+
+	remaining := collector.bytesRemaining(ReplicaReassignment{
+		Topic: "orders", Partition: 0, AddingReplicas: []int32{4}, RemovingReplicas: []int32{1},
+	})
+	assert.Equal(t, int64(0), remaining, "a target already ahead of the source should not report negative bytes remaining")
+}
+
+func TestReassignmentCollector_BytesRemainingZeroWhenNotReassigning(t *testing.T) {
+	collector := NewReassignmentCollector(&fakeReassignmentAdminClient{}, 0)
+
+	remaining := collector.bytesRemaining(ReplicaReassignment{Topic: "orders", Partition: 0})
+	assert.Equal(t, int64(0), remaining)
+}
+
+func TestReassignmentCollector_ApplyToClusterMetricsSetsOngoingReassignments(t *testing.T) {
+	admin := &fakeReassignmentAdminClient{
+		reassignments: []ReplicaReassignment{
+			{Topic: "orders", Partition: 0, AddingReplicas: []int32{4}, RemovingReplicas: []int32{1}},
+		},
+		logEndOffsets: map[string]int64{"orders-0-1": 0, "orders-0-4": 0},
+	}
+	collector := NewReassignmentCollector(admin, 0)
+	require.NoError(t, collector.poll())
+
+	metrics := &ClusterAggregatedMetrics{}
+	collector.ApplyToClusterMetrics(metrics)
+
+	assert.Equal(t, 1, metrics.OngoingReassignments)
+}
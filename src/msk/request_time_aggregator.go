@@ -0,0 +1,128 @@
+package msk
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/newrelic/nri-kafka/src/msk/hdrhist"
+)
+
+// maxTrackableRequestMicros bounds RequestTimeAggregator's histograms at
+// 60s; Kafka request timeouts are well under that, so any larger sample
+// is a bad read rather than a real latency.
+const maxTrackableRequestMicros = 60_000_000
+
+// requestTimeSigFigs keeps relative error under 0.1%, accurate enough to
+// tell p99 from p999 apart on a typical fetch/produce latency curve.
+const requestTimeSigFigs = 3
+
+// RequestTimeAggregator ingests raw per-request timings (in microseconds)
+// keyed by (clusterName, brokerID, apiKey) and computes p50/p95/p99/p999
+// from the accumulated distribution itself, instead of trusting
+// pre-computed percentiles JMX happens to expose. FleetWide merges every
+// broker's histogram for one apiKey into a cluster-wide rollup.
+type RequestTimeAggregator struct {
+	mu         sync.Mutex
+	histograms map[string]*hdrhist.Histogram
+}
+
+// NewRequestTimeAggregator creates an empty aggregator.
+func NewRequestTimeAggregator() *RequestTimeAggregator {
+	return &RequestTimeAggregator{
+		histograms: make(map[string]*hdrhist.Histogram),
+	}
+}
+
+// requestTimeKey builds the (clusterName, brokerID, apiKey) histogram key.
+func requestTimeKey(clusterName, brokerID, apiKey string) string {
+	return fmt.Sprintf("%s|%s|%s", clusterName, brokerID, apiKey)
+}
+
+// histogramFor returns (creating if needed) the histogram for key.
+func (a *RequestTimeAggregator) histogramFor(key string) *hdrhist.Histogram {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	h, ok := a.histograms[key]
+	if !ok {
+		h = hdrhist.New(maxTrackableRequestMicros, requestTimeSigFigs)
+		a.histograms[key] = h
+	}
+	return h
+}
+
+// RecordMicros records one raw request-time sample, in microseconds, for
+// (clusterName, brokerID, apiKey).
+func (a *RequestTimeAggregator) RecordMicros(clusterName, brokerID, apiKey string, micros int64) {
+	a.histogramFor(requestTimeKey(clusterName, brokerID, apiKey)).Record(micros)
+}
+
+// RequestTimePercentiles holds p50/p95/p99/p999, in milliseconds, for one
+// histogram's current distribution.
+type RequestTimePercentiles struct {
+	P50  float64
+	P95  float64
+	P99  float64
+	P999 float64
+}
+
+func percentilesFromHistogram(h *hdrhist.Histogram) RequestTimePercentiles {
+	return RequestTimePercentiles{
+		P50:  float64(h.ValueAtQuantile(0.50)) / 1000,
+		P95:  float64(h.ValueAtQuantile(0.95)) / 1000,
+		P99:  float64(h.ValueAtQuantile(0.99)) / 1000,
+		P999: float64(h.ValueAtQuantile(0.999)) / 1000,
+	}
+}
+
+// Percentiles returns (clusterName, brokerID, apiKey)'s current p50/p95/
+// p99/p999 in milliseconds, and false if no samples have been recorded.
+func (a *RequestTimeAggregator) Percentiles(clusterName, brokerID, apiKey string) (RequestTimePercentiles, bool) {
+	key := requestTimeKey(clusterName, brokerID, apiKey)
+
+	a.mu.Lock()
+	h, ok := a.histograms[key]
+	a.mu.Unlock()
+	if !ok || h.TotalCount() == 0 {
+		return RequestTimePercentiles{}, false
+	}
+	return percentilesFromHistogram(h), true
+}
+
+// FleetWide merges every broker's histogram for apiKey in clusterName
+// into one cluster-wide histogram and returns its percentiles, for
+// TransformClusterMetrics' fleet-wide rollup. The per-broker histograms
+// are left untouched.
+func (a *RequestTimeAggregator) FleetWide(clusterName, apiKey string) (RequestTimePercentiles, bool) {
+	prefix := clusterName + "|"
+	suffix := "|" + apiKey
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	merged := hdrhist.New(maxTrackableRequestMicros, requestTimeSigFigs)
+	found := false
+	for key, h := range a.histograms {
+		if len(key) < len(prefix)+len(suffix) {
+			continue
+		}
+		if key[:len(prefix)] != prefix || key[len(key)-len(suffix):] != suffix {
+			continue
+		}
+		merged.Merge(h)
+		found = true
+	}
+	if !found || merged.TotalCount() == 0 {
+		return RequestTimePercentiles{}, false
+	}
+	return percentilesFromHistogram(merged), true
+}
+
+// Reset clears every broker's histogram, for the next harvest cycle.
+func (a *RequestTimeAggregator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, h := range a.histograms {
+		h.Reset()
+	}
+}
@@ -0,0 +1,198 @@
+package msk
+
+import (
+	"sync"
+
+	"github.com/newrelic/nri-kafka/src/msk/hdrhist"
+)
+
+// ScopeLevel identifies one level of the partition->topic->broker->cluster
+// hierarchy RollupAggregator rolls observations up through.
+type ScopeLevel int
+
+const (
+	ScopeLevelPartition ScopeLevel = iota
+	ScopeLevelTopic
+	ScopeLevelBroker
+	ScopeLevelCluster
+)
+
+// Scope identifies where in the hierarchy a single Observe call applies.
+// Topic/Broker/Partition are left "" for metrics observed directly at a
+// coarser level (e.g. a metric read straight off the cluster has only
+// Cluster set, with no partition behind it to roll up from).
+type Scope struct {
+	Cluster   string
+	Broker    string
+	Topic     string
+	Partition string
+}
+
+type observation struct {
+	scope Scope
+	spec  MetricSpec
+	value float64
+}
+
+// RollupAggregator accepts metric samples at whatever granularity a scrape
+// actually produced them and rolls them up into broker, topic, and
+// cluster views on Flush, following each MetricSpec's Kind/Rollup instead
+// of letting one arbitrary reading stand in for the whole scope the way
+// TransformClusterMetrics' old ad-hoc clusterMappings did. Modeled on a
+// Pegasus-style multi-level aggregator: every view coarser than the one a
+// sample was actually taken at is derived, never independently collected.
+type RollupAggregator struct {
+	mu  sync.Mutex
+	obs []observation
+}
+
+// NewRollupAggregator creates an empty aggregator.
+func NewRollupAggregator() *RollupAggregator {
+	return &RollupAggregator{}
+}
+
+// Observe records one sample of spec at scope. value is in whatever units
+// spec.Target expects, the same convention copyMetricValue already uses.
+func (a *RollupAggregator) Observe(scope Scope, spec MetricSpec, value float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.obs = append(a.obs, observation{scope: scope, spec: spec, value: value})
+}
+
+// RollupResult holds one scope's rolled-up metrics, keyed by MetricSpec.Target.
+type RollupResult map[string]float64
+
+// summaryHistogramMax/summarySignificantFigures/summaryScale mirror
+// RequestTimeAggregator's bounds: the Summary specs this registry declares
+// are themselves latency percentiles, so rolling them up through the same
+// micros-scale HDR histogram (scaled by summaryScale to preserve the
+// fractional part of a millisecond reading) keeps p99-of-p99s honest
+// instead of averaging percentiles together, which understates the tail.
+const (
+	summaryHistogramMax       = maxTrackableRequestMicros
+	summarySignificantFigures = requestTimeSigFigs
+	summaryScale              = 1000
+)
+
+// summaryRollup merges values into one HDR histogram and returns its p99,
+// RollupAggregator's answer for RollupMetricKindSummary specs regardless of
+// their declared Rollup (Rollup only disambiguates Gauge specs).
+func summaryRollup(values []float64) float64 {
+	h := hdrhist.New(summaryHistogramMax, summarySignificantFigures)
+	for _, v := range values {
+		h.Record(int64(v * summaryScale))
+	}
+	return float64(h.ValueAtQuantile(0.99)) / summaryScale
+}
+
+// rollupValues reduces values per spec.Rollup. RollupMetricKindCounter specs
+// always sum regardless of Rollup, since a counter rolled up any other
+// way (e.g. averaged) would no longer mean what a counter means.
+func rollupValues(spec MetricSpec, values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	if spec.Kind == RollupMetricKindCounter {
+		return sumFloats(values)
+	}
+	switch spec.Rollup {
+	case RollupMean:
+		return sumFloats(values) / float64(len(values))
+	case RollupMin:
+		m := values[0]
+		for _, v := range values[1:] {
+			if v < m {
+				m = v
+			}
+		}
+		return m
+	case RollupMax:
+		m := values[0]
+		for _, v := range values[1:] {
+			if v > m {
+				m = v
+			}
+		}
+		return m
+	default:
+		return sumFloats(values)
+	}
+}
+
+func sumFloats(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// rollupGroup reduces one scope's observations down to one RollupResult,
+// dispatching Summary specs to summaryRollup and everything else to
+// rollupValues.
+func rollupGroup(obs []observation) RollupResult {
+	bySpec := map[string][]float64{}
+	specByTarget := map[string]MetricSpec{}
+	for _, o := range obs {
+		bySpec[o.spec.Target] = append(bySpec[o.spec.Target], o.value)
+		specByTarget[o.spec.Target] = o.spec
+	}
+
+	result := make(RollupResult, len(bySpec))
+	for target, values := range bySpec {
+		spec := specByTarget[target]
+		if spec.Kind == RollupMetricKindSummary {
+			result[target] = summaryRollup(values)
+		} else {
+			result[target] = rollupValues(spec, values)
+		}
+	}
+	return result
+}
+
+func rollupGroups(groups map[string][]observation) map[string]RollupResult {
+	out := make(map[string]RollupResult, len(groups))
+	for key, obs := range groups {
+		out[key] = rollupGroup(obs)
+	}
+	return out
+}
+
+// Flush walks every recorded observation bottom-up: partition samples
+// (and any sample observed directly at broker/topic granularity) roll up
+// into their broker and topic, and everything rolls up into the cluster.
+// The caller feeds the results into TransformerFixed's EmitBrokerSample/
+// EmitTopicSample/EmitClusterSample to write them onto the right
+// KafkaBrokerSample/KafkaTopicSample/KafkaClusterSample metric.Set.
+func (a *RollupAggregator) Flush() (brokers map[string]RollupResult, topics map[string]RollupResult, cluster RollupResult) {
+	a.mu.Lock()
+	all := make([]observation, len(a.obs))
+	copy(all, a.obs)
+	a.mu.Unlock()
+
+	byBroker := map[string][]observation{}
+	byTopic := map[string][]observation{}
+	var clusterObs []observation
+
+	for _, o := range all {
+		if o.scope.Broker != "" {
+			byBroker[o.scope.Broker] = append(byBroker[o.scope.Broker], o)
+		}
+		if o.scope.Topic != "" {
+			byTopic[o.scope.Topic] = append(byTopic[o.scope.Topic], o)
+		}
+		clusterObs = append(clusterObs, o)
+	}
+
+	brokers = rollupGroups(byBroker)
+	topics = rollupGroups(byTopic)
+	cluster = rollupGroup(clusterObs)
+	return
+}
+
+// Reset discards every recorded observation, for the next harvest cycle.
+func (a *RollupAggregator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.obs = nil
+}
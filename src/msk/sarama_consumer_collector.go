@@ -0,0 +1,125 @@
+package msk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-kafka/src/msk/saramalag"
+	"github.com/newrelic/nri-kafka/src/msk/timelag"
+)
+
+// SaramaConsumerCollector fetches each partition's high-water mark
+// directly from the broker (via a saramalag.Fetcher, OTel-traced) right
+// before Flush, instead of relying on whatever CloudWatch/JMX last
+// reported, and pushes the result through
+// DimensionalTransformer.TransformConsumerMetrics so it shows up
+// alongside the CloudWatch-derived series with sub-minute freshness.
+// Committed offsets still come from sarama.ClusterAdmin.
+// ListConsumerGroupOffsets -- the same admin call
+// ConsumerGroupLagCollector already uses -- so this collector doesn't
+// duplicate group listing/description, only the live lag computation and
+// dimensional emission on top of it.
+type SaramaConsumerCollector struct {
+	admin       sarama.ClusterAdmin
+	fetcher     *saramalag.Fetcher
+	timeFetcher *timelag.Fetcher
+
+	dimensionalTransformer *DimensionalTransformer
+	aggregator             *MetricAggregator
+}
+
+// NewSaramaConsumerCollector wraps an already-connected
+// sarama.ClusterAdmin (for ListConsumerGroupOffsets) and sarama.Client
+// (for live GetOffset calls, via saramalag.Fetcher, and time-lag
+// resolution via timelag.Fetcher), feeding lag through dt and aggregator.
+func NewSaramaConsumerCollector(admin sarama.ClusterAdmin, client sarama.Client, dt *DimensionalTransformer, aggregator *MetricAggregator) *SaramaConsumerCollector {
+	return &SaramaConsumerCollector{
+		admin:                  admin,
+		fetcher:                saramalag.NewFetcher(client),
+		timeFetcher:            timelag.NewFetcher(client),
+		dimensionalTransformer: dt,
+		aggregator:             aggregator,
+	}
+}
+
+// Collect lists every consumer group's committed offsets and, for each
+// partition, fetches a live high-water mark to compute lag, then feeds
+// the result through DimensionalTransformer.TransformConsumerMetrics.
+func (c *SaramaConsumerCollector) Collect(ctx context.Context) error {
+	groups, err := c.admin.ListConsumerGroups()
+	if err != nil {
+		return fmt.Errorf("listing consumer groups: %w", err)
+	}
+
+	for groupID := range groups {
+		offsets, err := c.admin.ListConsumerGroupOffsets(groupID, nil)
+		if err != nil {
+			log.Warn("SaramaConsumerCollector: failed to list offsets for group %s: %v", groupID, err)
+			continue
+		}
+
+		for topic, partitions := range offsets.Blocks {
+			for partition, block := range partitions {
+				if block.Err != sarama.ErrNoError || block.Offset < 0 {
+					continue
+				}
+				c.collectPartition(ctx, groupID, topic, partition, block.Offset)
+			}
+		}
+	}
+
+	return nil
+}
+
+// collectPartition fetches topic/partition's live high-water mark,
+// computes lag against committedOffset, records it on the aggregator, and
+// emits it through the dimensional transformer.
+func (c *SaramaConsumerCollector) collectPartition(ctx context.Context, group, topic string, partition int32, committedOffset int64) {
+	highWaterMark, err := c.fetcher.HighWaterMark(ctx, group, topic, partition)
+	if err != nil {
+		log.Debug("SaramaConsumerCollector: failed to fetch high-water mark for %s/%s[%d]: %v", group, topic, partition, err)
+		return
+	}
+
+	lag := highWaterMark - committedOffset
+	if lag < 0 {
+		lag = 0
+	}
+
+	if c.aggregator != nil {
+		c.aggregator.AddConsumerPartitionLag(topic, group, partition, float64(lag))
+	}
+
+	if c.dimensionalTransformer != nil {
+		metrics := map[string]interface{}{
+			"consumerGroup":  group,
+			"topic":          topic,
+			"partition":      fmt.Sprintf("%d", partition),
+			"consumerLag":    float64(lag),
+			"consumerOffset": float64(committedOffset),
+			"highWaterMark":  float64(highWaterMark),
+		}
+
+		// Time lag (KIP-734) is only available on Kafka 3.0+ brokers --
+		// timeFetcher reports ok=false rather than an error when the
+		// broker rejects the MaxTimestamp query, and timeLagSeconds/
+		// maxTimestamp are simply omitted so TransformConsumerMetrics
+		// falls back to offset-based lag alone.
+		if c.timeFetcher != nil {
+			if lagSeconds, maxTimestamp, ok, err := c.timeFetcher.Seconds(ctx, topic, partition, committedOffset); err != nil {
+				log.Debug("SaramaConsumerCollector: time lag unavailable for %s/%s[%d]: %v", group, topic, partition, err)
+			} else if ok {
+				metrics["consumer.lag.seconds"] = lagSeconds
+				metrics["topic.maxTimestamp"] = float64(maxTimestamp.Unix())
+			}
+		}
+
+		if err := c.dimensionalTransformer.TransformConsumerMetrics(group, topic, metrics); err != nil {
+			log.Warn("SaramaConsumerCollector: failed to transform consumer metrics for %s/%s: %v", group, topic, err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package msk
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// SourceKind selects where MSKShim pulls its samples from. JMX is the
+// long-standing default (an upstream scraper hands flattened samples to
+// TransformBrokerMetrics/TransformTopicMetrics/ProcessConsumerOffset);
+// SourceAdmin lets a SaramaSource fill the aggregator directly from the
+// cluster's admin API for deployments where JMX isn't reachable, or
+// SourceMixed runs both so admin-sourced data (partition/ISR counts,
+// consumer lag) fills in anything JMX doesn't report.
+type SourceKind string
+
+const (
+	SourceJMX   SourceKind = "jmx"
+	SourceAdmin SourceKind = "admin"
+	SourceMixed SourceKind = "mixed"
+)
+
+// SaramaSource collects cluster/topic/consumer-group shape directly from
+// the Kafka admin API via github.com/IBM/sarama, instead of requiring an
+// upstream JMX scraper. It builds on ClusterDescriber (controller-sourced
+// cluster/topic shape) and adds consumer-group discovery, feeding
+// everything into a MetricAggregator the same way the JMX path does.
+type SaramaSource struct {
+	admin      sarama.ClusterAdmin
+	client     sarama.Client
+	describer  *ClusterDescriber
+	aggregator *MetricAggregator
+
+	mu sync.Mutex
+}
+
+// defaultSaramaVersion is the minimum broker protocol version
+// DescribeConfigs/DescribeCluster/ListConsumerGroupOffsets require to
+// behave consistently; sarama's own zero-value default predates these
+// APIs, so SaramaSource always pins this floor explicitly.
+var defaultSaramaVersion = sarama.V2_1_0_0
+
+// NewSaramaConfig returns a sarama.Config pinned to defaultSaramaVersion,
+// for callers building the ClusterAdmin NewSaramaSource wraps.
+func NewSaramaConfig() *sarama.Config {
+	config := sarama.NewConfig()
+	config.Version = defaultSaramaVersion
+	return config
+}
+
+// NewSaramaSource wraps an already-connected sarama.ClusterAdmin and the
+// MetricAggregator it should populate. client is used only to fetch
+// partition high-water marks for lag computation (GetOffset isn't part of
+// the ClusterAdmin interface); pass nil to skip lag collection entirely.
+func NewSaramaSource(admin sarama.ClusterAdmin, client sarama.Client, aggregator *MetricAggregator) *SaramaSource {
+	return &SaramaSource{
+		admin:      admin,
+		client:     client,
+		describer:  NewClusterDescriber(admin),
+		aggregator: aggregator,
+	}
+}
+
+// Collect runs one full discovery pass: cluster shape, every topic's
+// partition/ISR/config shape, and every consumer group's lag, all sourced
+// from the admin API rather than JMX.
+func (s *SaramaSource) Collect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	described, err := s.describer.DescribeCluster()
+	if err != nil {
+		return fmt.Errorf("describing cluster: %w", err)
+	}
+	s.aggregator.AddDescribedCluster(described)
+
+	topics, err := s.listTopicNames()
+	if err != nil {
+		return fmt.Errorf("listing topics: %w", err)
+	}
+
+	describedTopics, err := s.describer.DescribeTopics(topics)
+	if err != nil {
+		return fmt.Errorf("describing topics: %w", err)
+	}
+	for _, topic := range describedTopics {
+		s.aggregator.AddDescribedTopic(topic)
+	}
+
+	if err := s.collectConsumerLag(topics); err != nil {
+		log.Warn("SaramaSource: failed to collect consumer group lag: %v", err)
+	}
+
+	return nil
+}
+
+// listTopicNames returns every topic the cluster currently has, via
+// ListTopics, as the set to describe and to check consumer offsets
+// against.
+func (s *SaramaSource) listTopicNames() ([]string, error) {
+	topicDetails, err := s.admin.ListTopics()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(topicDetails))
+	for name := range topicDetails {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// collectConsumerLag lists every consumer group, describes its members,
+// and for each one fetches committed offsets via
+// ListConsumerGroupOffsets, deriving lag as high-water-mark minus
+// committed offset per partition and feeding the per-topic/per-group
+// total into the aggregator.
+func (s *SaramaSource) collectConsumerLag(topics []string) error {
+	groups, err := s.admin.ListConsumerGroups()
+	if err != nil {
+		return fmt.Errorf("listing consumer groups: %w", err)
+	}
+
+	groupNames := make([]string, 0, len(groups))
+	for name := range groups {
+		groupNames = append(groupNames, name)
+	}
+	if len(groupNames) == 0 {
+		return nil
+	}
+
+	descriptions, err := s.admin.DescribeConsumerGroups(groupNames)
+	if err != nil {
+		return fmt.Errorf("describing consumer groups: %w", err)
+	}
+
+	highWaterMarks, err := s.fetchHighWaterMarks(topics)
+	if err != nil {
+		log.Debug("SaramaSource: failed to fetch high water marks, lag will be unset: %v", err)
+	}
+
+	for _, desc := range descriptions {
+		if desc.Err != sarama.ErrNoError {
+			log.Warn("SaramaSource: consumer group %s returned error %v", desc.GroupId, desc.Err)
+			continue
+		}
+
+		offsets, err := s.admin.ListConsumerGroupOffsets(desc.GroupId, nil)
+		if err != nil {
+			log.Warn("SaramaSource: failed to list offsets for group %s: %v", desc.GroupId, err)
+			continue
+		}
+
+		for topic, partitions := range offsets.Blocks {
+			var totalLag float64
+			for partition, block := range partitions {
+				if block.Err != sarama.ErrNoError || block.Offset < 0 {
+					continue
+				}
+				hwm, ok := highWaterMarks[topicPartitionKey(topic, partition)]
+				if !ok {
+					continue
+				}
+				lag := hwm - block.Offset
+				if lag < 0 {
+					lag = 0
+				}
+				totalLag += float64(lag)
+			}
+			s.aggregator.AddConsumerLag(topic, desc.GroupId, totalLag)
+		}
+	}
+
+	return nil
+}
+
+// topicPartitionKey builds the lookup key fetchHighWaterMarks indexes its
+// result by.
+func topicPartitionKey(topic string, partition int32) string {
+	return fmt.Sprintf("%s-%d", topic, partition)
+}
+
+// fetchHighWaterMarks asks the controller for each topic's partition
+// leadership, then uses s.client (plain Kafka client, not the admin API)
+// to fetch the current log-end-offset of each partition, since
+// ClusterAdmin doesn't expose GetOffset.
+func (s *SaramaSource) fetchHighWaterMarks(topics []string) (map[string]int64, error) {
+	marks := make(map[string]int64)
+
+	if s.client == nil {
+		return marks, fmt.Errorf("no sarama.Client configured, cannot fetch high water marks")
+	}
+
+	describedTopics, err := s.describer.DescribeTopics(topics)
+	if err != nil {
+		return marks, err
+	}
+
+	for _, topic := range describedTopics {
+		for partition := range topic.Leaders {
+			offset, err := s.client.GetOffset(topic.Name, partition, sarama.OffsetNewest)
+			if err != nil {
+				continue
+			}
+			marks[topicPartitionKey(topic.Name, partition)] = offset
+		}
+	}
+
+	return marks, nil
+}
@@ -0,0 +1,71 @@
+// Package saramalag fetches a single Kafka partition's high-water mark
+// directly from the broker (via sarama.Client.GetOffset), wrapping each
+// fetch in an OpenTelemetry span so operators can trace collector latency
+// per broker/partition the same way the Shopify/sarama otel-contrib
+// instrumentation traces produce/consume calls. It intentionally does
+// nothing else -- listing groups, describing membership, and committed
+// offsets stay on msk.ConsumerGroupLagCollector's sarama.ClusterAdmin
+// path; this package is only the live, per-partition GetOffset leg that
+// feeds msk.SaramaConsumerCollector.
+package saramalag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer is named after this package's import path, matching the
+// convention every otel-contrib Kafka instrumentation uses for its
+// own Tracer.
+var tracer = otel.Tracer("github.com/newrelic/nri-kafka/src/msk/saramalag")
+
+// Fetcher issues live GetOffset calls against an already-connected
+// sarama.Client.
+type Fetcher struct {
+	client sarama.Client
+}
+
+// NewFetcher wraps an already-connected sarama.Client.
+func NewFetcher(client sarama.Client) *Fetcher {
+	return &Fetcher{client: client}
+}
+
+// HighWaterMark returns topic/partition's current log-end offset,
+// tracing the call with messaging.system/messaging.destination/
+// messaging.kafka.partition/messaging.kafka.consumer_group attributes so
+// the span can be correlated back to the consumer group this fetch was
+// made on behalf of.
+func (f *Fetcher) HighWaterMark(ctx context.Context, group, topic string, partition int32) (int64, error) {
+	_, span := tracer.Start(ctx, "saramalag.HighWaterMark",
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.kafka.consumer_group", group),
+			attribute.String("messaging.destination", topic),
+			attribute.Int64("messaging.kafka.partition", int64(partition)),
+		),
+	)
+	defer span.End()
+
+	if f.client == nil {
+		err := fmt.Errorf("saramalag: no sarama.Client configured")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	// GetOffset has no context parameter -- the span above is still the
+	// thing operators trace collector latency through, via its own
+	// start/end timestamps.
+	offset, err := f.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	return offset, nil
+}
@@ -0,0 +1,62 @@
+package saramalag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient embeds sarama.Client (left nil) and overrides only the method
+// Fetcher actually calls, so this test double doesn't have to implement
+// sarama.Client's entire, mostly-irrelevant-here method set.
+type fakeClient struct {
+	sarama.Client
+
+	gotTopic     string
+	gotPartition int32
+	gotTime      int64
+
+	offset int64
+	err    error
+}
+
+func (f *fakeClient) GetOffset(topic string, partitionID int32, time int64) (int64, error) {
+	f.gotTopic = topic
+	f.gotPartition = partitionID
+	f.gotTime = time
+	return f.offset, f.err
+}
+
+func TestHighWaterMark_ReturnsOffsetNewestForTopicAndPartition(t *testing.T) {
+	fc := &fakeClient{offset: 12345}
+	f := NewFetcher(fc)
+
+	offset, err := f.HighWaterMark(context.Background(), "my-group", "my-topic", 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(12345), offset)
+	assert.Equal(t, "my-topic", fc.gotTopic)
+	assert.Equal(t, int32(3), fc.gotPartition)
+	assert.Equal(t, sarama.OffsetNewest, fc.gotTime)
+}
+
+func TestHighWaterMark_PropagatesClientError(t *testing.T) {
+	fc := &fakeClient{err: errors.New("GetOffset: broker not available")}
+	f := NewFetcher(fc)
+
+	_, err := f.HighWaterMark(context.Background(), "my-group", "my-topic", 0)
+
+	assert.Error(t, err)
+}
+
+func TestHighWaterMark_NilClient_ReturnsError(t *testing.T) {
+	f := NewFetcher(nil)
+
+	_, err := f.HighWaterMark(context.Background(), "my-group", "my-topic", 0)
+
+	assert.Error(t, err)
+}
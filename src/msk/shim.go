@@ -1,11 +1,23 @@
 package msk
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"sync"
+	"time"
 
+	"github.com/newrelic/infra-integrations-sdk/v3/data/event"
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-kafka/src/configguard"
+	"github.com/newrelic/nri-kafka/src/msk/awsenrich"
+	"github.com/newrelic/nri-kafka/src/msk/cloudwatch"
+	"github.com/newrelic/nri-kafka/src/msk/clusterinfo"
+	"github.com/newrelic/nri-kafka/src/msk/metricspec"
+	"github.com/newrelic/nri-kafka/src/msk/promexport"
 )
 
 // MSKShim is the consolidated MSK shim implementation
@@ -16,7 +28,168 @@ type MSKShim struct {
 	entityCache            *EntityCache
 	systemAPI              InfrastructureAPI
 	dimensionalTransformer *DimensionalTransformer
-	mu                     sync.Mutex
+	rateTracker            *ConsumerRateTracker
+	kafkaSink              *KafkaSink
+	consumerGroupCollector  *ConsumerGroupLagCollector
+	saramaConsumerCollector *SaramaConsumerCollector
+	groupStateCollector     *ConsumerGroupStateCollector
+	offsetPipelineCollector *OffsetPipelineCollector
+	controllerResolver      *ControllerResolver
+	topicConfigEnricher    *TopicConfigEnricher
+	reassignPoller         *ReassignmentPoller
+
+	// reassignmentCollector, when config.EnableReassignmentMetrics and
+	// config.ReassignmentBootstrapServers are both set, polls the
+	// controller for in-flight KIP-455 state and is wired into
+	// dimensionalTransformer as soon as SetIntegration creates it.
+	reassignmentCollector *ReassignmentCollector
+	// reassignmentAdminClient is the live sarama connection backing
+	// reassignmentCollector; Stop closes it alongside the collector so
+	// shutdown doesn't leak a TCP connection to the cluster.
+	reassignmentAdminClient io.Closer
+
+	// awsEnrich, when config.AWSClusterEnrichEnabled is set, replaces
+	// SimpleTransformClusterMetrics's hardcoded cluster-level defaults with
+	// a live awsenrich.Client answer for config.ClusterARN.
+	awsEnrich *awsenrich.Client
+
+	// cloudWatchHostMetrics, when config.CloudWatchHostMetricsEnabled is
+	// set, replaces SimpleTransformBrokerMetrics's hardcoded cpuIdle/
+	// memoryUsed/networkRxPackets/etc constants with a live
+	// cloudwatch.Client answer for each broker.
+	cloudWatchHostMetrics *cloudwatch.Client
+
+	// clusterTopology, when set, replaces SimpleTransformBrokerMetrics's
+	// hardcoded provider.leaderCount default and feeds
+	// SimpleTransformClusterMetrics's provider.controllerBrokerId with a
+	// live Kafka Metadata-derived answer.
+	clusterTopology *clusterinfo.Client
+
+	// promExporter, when config.PromExportEnabled is set, receives every
+	// provider.* metric the SimpleTransform* methods emit so a Prometheus
+	// scraper can read them directly without the New Relic agent.
+	promExporter *promexport.Exporter
+
+	// configGuard, configGuardAdmin and configGuardStop, when
+	// config.ConfigGuardEnabled is set, poll every known broker's config
+	// on config.ConfigGuardPollInterval and raise
+	// KafkaConfigChangeSample/KafkaConfigPolicyViolation events through
+	// EmitEvent when a sensitive property changes or violates
+	// config.ConfigGuardPolicyPath's rules.
+	configGuard      *configguard.Guard
+	configGuardSink  *configguard.MSKEventSink
+	configGuardAdmin *AdminAPIHelper
+	configGuardStop  chan struct{}
+
+	// staleEvictionStop, closed by Stop, halts the background ticker that
+	// calls aggregator.EvictStaleBrokers so a broker that stopped
+	// reporting (shut down, or otherwise dropped out of the cluster)
+	// stops contributing stale bytes/sec and under-replicated-partition
+	// counts to the cluster rollup forever.
+	staleEvictionStop chan struct{}
+
+	mu sync.Mutex
+}
+
+// SetReassignmentPoller wires in a ReassignmentPoller that
+// SimpleTransformClusterMetrics/SimpleTransformBrokerMetrics/
+// SimpleTransformTopicMetrics consult for in-flight KIP-455 partition
+// reassignment state. Passing nil skips attaching reassignment metrics
+// entirely -- it's the caller's responsibility to call Poll on whatever
+// cadence fits (this shim does not self-schedule a poll loop).
+func (s *MSKShim) SetReassignmentPoller(poller *ReassignmentPoller) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reassignPoller = poller
+}
+
+// SetTopicConfigEnricher wires in a TopicConfigEnricher that
+// SimpleTransformTopicMetrics uses to attach provider.config.<name>
+// attributes (cleanup.policy, retention, min.insync.replicas, etc.) to
+// every AwsMskTopicSample. Passing nil disables config enrichment.
+func (s *MSKShim) SetTopicConfigEnricher(enricher *TopicConfigEnricher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.topicConfigEnricher = enricher
+}
+
+// SetControllerResolver wires in a ControllerResolver that
+// SimpleTransformBrokerMetrics/SimpleTransformClusterMetrics use to
+// determine ActiveControllerCount and provider.isController from the
+// Kafka AdminClient instead of inferring it from whatever value a broker
+// happens to self-report. Passing nil falls back to treating every
+// broker as a non-controller.
+func (s *MSKShim) SetControllerResolver(resolver *ControllerResolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.controllerResolver = resolver
+}
+
+// SetConsumerGroupLagCollector wires in a ConsumerGroupLagCollector that
+// Flush will run when config.ConsumerLagEnrich is true, actively computing
+// lag from the admin API instead of relying on whatever lag value happens
+// to already be present in an inbound offset sample. Passing nil disables
+// active collection, leaving the passive per-sample path as-is.
+func (s *MSKShim) SetConsumerGroupLagCollector(collector *ConsumerGroupLagCollector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consumerGroupCollector = collector
+}
+
+// SetSaramaConsumerCollector wires in a SaramaConsumerCollector that
+// Flush will run when config.SaramaLiveLagEnabled is true, fetching live
+// per-partition high-water marks from the broker and pushing lag through
+// DimensionalTransformer.TransformConsumerMetrics. Passing nil disables
+// it, leaving ConsumerGroupLagCollector (if wired) as the only active lag
+// source.
+func (s *MSKShim) SetSaramaConsumerCollector(collector *SaramaConsumerCollector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saramaConsumerCollector = collector
+}
+
+// SetConsumerGroupStateCollector wires in a ConsumerGroupStateCollector
+// that Flush will run when config.ConsumerGroupStateEnabled is true,
+// snapshotting every consumer group's state, membership, and per-member
+// assignment alongside the lag-only collectors above. Passing nil
+// disables it.
+func (s *MSKShim) SetConsumerGroupStateCollector(collector *ConsumerGroupStateCollector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groupStateCollector = collector
+}
+
+// SetOffsetPipelineCollector wires in an OffsetPipelineCollector that
+// Flush will run when config.OffsetPipelineEnabled is true, reading a
+// merged snapshot from whichever offsetpipeline.ConsumerOffsetSources
+// (CloudWatch, Sarama, Burrow, Kminion) the caller configured the
+// underlying pipeline with. Passing nil disables it, leaving the other
+// lag collectors above as-is.
+func (s *MSKShim) SetOffsetPipelineCollector(collector *OffsetPipelineCollector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offsetPipelineCollector = collector
+}
+
+// SetClusterTopology wires in a clusterinfo.Client that
+// SimpleTransformBrokerMetrics/SimpleTransformClusterMetrics consult for
+// real per-broker leader counts and the current controller broker ID,
+// resolved from a live Kafka Metadata request instead of the previous
+// hardcoded defaults. Passing nil reverts both back to those defaults.
+func (s *MSKShim) SetClusterTopology(client *clusterinfo.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clusterTopology = client
+}
+
+// SetKafkaSink wires in a KafkaSink that Flush will also publish the
+// cluster-level aggregated metrics snapshot to, alongside the normal
+// infra-integrations SDK entity output. Passing nil disables sink
+// publishing.
+func (s *MSKShim) SetKafkaSink(sink *KafkaSink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kafkaSink = sink
 }
 
 // EntityCache manages entities to avoid duplicates
@@ -27,32 +200,180 @@ type EntityCache struct {
 
 // NewMSKShim creates a new consolidated MSK shim
 func NewMSKShim(config Config) *MSKShim {
+	if err := ValidateConfig(&config); err != nil {
+		log.Warn("MSK shim config failed validation: %v", err)
+	}
+	if err := metricspec.Validate(); err != nil {
+		log.Error("MSK shim: %v", err)
+	}
+
 	shim := &MSKShim{
 		config:     config,
 		aggregator: NewMetricAggregator(),
 		entityCache: &EntityCache{
 			entities: make(map[string]*integration.Entity),
 		},
+		rateTracker: NewConsumerRateTracker(defaultLagRateWindow),
 	}
-	
-	log.Info("MSK shim initialized for cluster: %s in region: %s", 
+
+	// When enabled, replace SimpleTransformClusterMetrics's hardcoded
+	// cluster-level defaults with what AWS's MSK control plane actually
+	// provisioned for config.ClusterARN.
+	if config.AWSClusterEnrichEnabled {
+		if config.ClusterARN == "" {
+			log.Warn("MSK shim: AWS cluster enrichment disabled, no cluster ARN configured")
+		} else if client, err := awsenrich.NewClient(context.Background(), config.AWSRegion, config.ClusterARN, config.AWSClusterEnrichTTL); err != nil {
+			log.Warn("MSK shim: AWS cluster enrichment disabled, failed to create AWS MSK client: %v", err)
+		} else {
+			shim.awsEnrich = client
+		}
+	}
+
+	// When enabled, replace SimpleTransformBrokerMetrics's hardcoded
+	// host-level defaults with real AWS/Kafka CloudWatch datapoints for
+	// config.ClusterName.
+	if config.CloudWatchHostMetricsEnabled {
+		client, err := cloudwatch.NewClient(context.Background(), config.AWSRegion, config.ClusterName,
+			config.CloudWatchHostMetricsTimeout, config.CloudWatchHostMetricsTTL)
+		if err != nil {
+			log.Warn("MSK shim: CloudWatch host metrics disabled, failed to create AWS CloudWatch client: %v", err)
+		} else {
+			shim.cloudWatchHostMetrics = client
+		}
+	}
+
+	// When enabled, poll the controller directly for in-flight KIP-455
+	// partition reassignments so DimensionalTransformer's
+	// kafka.cluster.ReassigningPartitions/ReassignmentBytesRemaining
+	// metrics reflect real cluster state instead of the -1 "unknown"
+	// sentinel transformPartitionReassignments falls back to.
+	if config.EnableReassignmentMetrics {
+		if len(config.ReassignmentBootstrapServers) == 0 {
+			log.Warn("MSK shim: reassignment metrics disabled, no bootstrap servers configured")
+		} else if adminClient, err := newSaramaReassignmentAdminClient(config.ReassignmentBootstrapServers); err != nil {
+			log.Warn("MSK shim: reassignment metrics disabled, failed to connect: %v", err)
+		} else {
+			collector := NewReassignmentCollector(adminClient, config.ReassignmentPollInterval)
+			collector.Start()
+			shim.reassignmentCollector = collector
+			shim.reassignmentAdminClient = adminClient
+		}
+	}
+
+	// When enabled, mirror every provider.* metric onto a native Prometheus
+	// exporter so a cluster can be scraped directly.
+	if config.PromExportEnabled {
+		shim.promExporter = promexport.NewExporter(config.PromExportListenAddr)
+		shim.promExporter.Handle("/golden-metrics", http.HandlerFunc(serveGoldenMetrics))
+		shim.promExporter.Start()
+	}
+
+	// When enabled, poll every known broker's config and raise events on
+	// sensitive-property changes and policy violations.
+	if config.ConfigGuardEnabled {
+		if len(config.ConfigGuardBootstrapServers) == 0 {
+			log.Warn("MSK shim: config guard disabled, no bootstrap servers configured")
+		} else if admin, err := NewAdminAPIHelper(config.ConfigGuardBootstrapServers, NewSaramaConfig()); err != nil {
+			log.Warn("MSK shim: config guard disabled, failed to connect: %v", err)
+		} else {
+			var policy *configguard.Policy
+			if config.ConfigGuardPolicyPath != "" {
+				if loaded, err := configguard.LoadPolicy(config.ConfigGuardPolicyPath); err != nil {
+					log.Warn("MSK shim: config guard running without a policy, failed to load %s: %v", config.ConfigGuardPolicyPath, err)
+				} else {
+					policy = loaded
+				}
+			}
+
+			clusterGUID := GenerateEntityGUID(EntityTypeCluster, config.AWSAccountID, config.ClusterName, nil)
+			shim.configGuardAdmin = admin
+			shim.configGuard = configguard.NewGuard(policy)
+			shim.configGuardSink = configguard.NewMSKEventSink(shim, clusterGUID)
+			shim.configGuardStop = make(chan struct{})
+			go shim.runConfigGuard()
+		}
+	}
+
+	shim.staleEvictionStop = make(chan struct{})
+	go shim.runStaleEviction()
+
+	log.Info("MSK shim initialized for cluster: %s in region: %s",
 		config.ClusterName, config.AWSRegion)
-	
+
 	return shim
 }
 
+// runStaleEviction periodically evicts broker metrics that have gone quiet
+// for longer than StaleMetricTTL, so a broker that shut down stops
+// contributing to the cluster rollup instead of lingering there forever.
+// It runs until Stop closes staleEvictionStop.
+func (s *MSKShim) runStaleEviction() {
+	ticker := time.NewTicker(StaleMetricTTL / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.aggregator.EvictStaleBrokers(StaleMetricTTL)
+		case <-s.staleEvictionStop:
+			return
+		}
+	}
+}
+
+// runConfigGuard periodically fetches every broker configGuardAdmin knows
+// about from aggregator.BrokerIDs and diffs it through configGuard,
+// raising events for any sensitive-property change or policy violation
+// found. It runs until Stop closes configGuardStop.
+func (s *MSKShim) runConfigGuard() {
+	ticker := time.NewTicker(s.config.ConfigGuardPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, brokerID := range s.aggregator.BrokerIDs() {
+				config, err := s.configGuardAdmin.DescribeBrokerConfig(brokerID)
+				if err != nil {
+					log.Warn("MSK shim: config guard skipping broker %s: %v", brokerID, err)
+					continue
+				}
+
+				changes, violations := s.configGuard.Observe(brokerID, config)
+				for _, change := range changes {
+					s.configGuardSink.EmitChange(change)
+				}
+				for _, violation := range violations {
+					s.configGuardSink.EmitViolation(violation)
+				}
+			}
+		case <-s.configGuardStop:
+			return
+		}
+	}
+}
+
 // SetIntegration sets the integration instance
 func (s *MSKShim) SetIntegration(i *integration.Integration) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.integration = i
-	
+
 	// Initialize dimensional transformer if integration is set
 	if i != nil {
 		s.dimensionalTransformer = NewDimensionalTransformer(i, &s.config)
+		if s.reassignmentCollector != nil {
+			s.dimensionalTransformer.SetReassignmentCollector(s.reassignmentCollector)
+		}
 	}
 }
 
+// GetTopology returns the current ClusterTopology snapshot (controller
+// broker ID and per-partition leader assignment), for tests and
+// downstream consumers that need controller/leader awareness without
+// reaching back into JMX samples.
+func (s *MSKShim) GetTopology() *ClusterTopology {
+	return s.aggregator.Topology()
+}
+
 // IsEnabled returns whether the MSK shim is enabled
 func (s *MSKShim) IsEnabled() bool {
 	return s.config.Enabled
@@ -101,9 +422,28 @@ func (s *MSKShim) SetSystemSampleAPI(api InfrastructureAPI) {
 
 // Stop stops the MSK shim and flushes any pending metrics
 func (s *MSKShim) Stop() {
+	if s.staleEvictionStop != nil {
+		close(s.staleEvictionStop)
+	}
+	if s.configGuardStop != nil {
+		close(s.configGuardStop)
+	}
 	if s.dimensionalTransformer != nil {
 		s.dimensionalTransformer.Stop()
 	}
+	if s.reassignmentCollector != nil {
+		s.reassignmentCollector.Stop()
+	}
+	if s.reassignmentAdminClient != nil {
+		if err := s.reassignmentAdminClient.Close(); err != nil {
+			log.Warn("MSK shim: error closing reassignment admin client: %v", err)
+		}
+	}
+	if s.promExporter != nil {
+		if err := s.promExporter.Stop(context.Background()); err != nil {
+			log.Warn("MSK shim: error stopping promexport server: %v", err)
+		}
+	}
 }
 
 // Flush performs final aggregations and creates cluster entity
@@ -113,7 +453,42 @@ func (s *MSKShim) Flush() error {
 	}
 	
 	log.Info("Flushing MSK shim data for cluster: %s", s.config.ClusterName)
-	
+
+	// When consumer lag enrichment is enabled and an active collector is
+	// wired in, run it to replace the passive per-sample lag path with
+	// real admin-API-sourced lag, group state, and membership.
+	if s.config.ConsumerLagEnrich && s.consumerGroupCollector != nil {
+		if err := s.consumerGroupCollector.Collect(); err != nil {
+			log.Error("Failed to collect consumer group lag: %v", err)
+		}
+	}
+
+	// When live Sarama lag collection is enabled and wired in, fetch fresh
+	// per-partition high-water marks right before emitting so lag reflects
+	// the broker's current state rather than the last CloudWatch/JMX poll.
+	if s.config.SaramaLiveLagEnabled && s.saramaConsumerCollector != nil {
+		if err := s.saramaConsumerCollector.Collect(context.Background()); err != nil {
+			log.Error("Failed to collect live Sarama consumer lag: %v", err)
+		}
+	}
+
+	// When enabled and wired in, snapshot every consumer group's state and
+	// membership alongside the lag-only collectors above.
+	if s.config.ConsumerGroupStateEnabled && s.groupStateCollector != nil {
+		if err := s.groupStateCollector.Collect(); err != nil {
+			log.Error("Failed to collect consumer group state: %v", err)
+		}
+	}
+
+	// When enabled and wired in, read the offset pipeline's merged
+	// multi-source (CloudWatch/Sarama/Burrow/Kminion) snapshot alongside
+	// the lag-only collectors above.
+	if s.config.OffsetPipelineEnabled && s.offsetPipelineCollector != nil {
+		if err := s.offsetPipelineCollector.Collect(); err != nil {
+			log.Error("Failed to collect offset pipeline samples: %v", err)
+		}
+	}
+
 	// Create cluster entity with aggregated metrics
 	if err := s.SimpleTransformClusterMetrics(); err != nil {
 		log.Error("Failed to create cluster entity: %v", err)
@@ -126,7 +501,16 @@ func (s *MSKShim) Flush() error {
 			log.Error("Failed to flush dimensional metrics: %v", err)
 		}
 	}
-	
+
+	// Also publish the aggregated snapshot to the Kafka sink, if one is
+	// configured, so a sidecar collector can consume it without the
+	// New Relic agent needing to reach the control plane.
+	if s.kafkaSink != nil {
+		if err := s.kafkaSink.Publish("AwsMskClusterSample", s.aggregator.GetAggregatedMetrics()); err != nil {
+			log.Error("Failed to publish cluster metrics to kafka sink: %v", err)
+		}
+	}
+
 	// Log summary
 	s.logSummary()
 	
@@ -154,6 +538,33 @@ func (s *MSKShim) GetOrCreateEntity(entityType, eventType string) (*integration.
 	return entity, nil
 }
 
+// EmitEvent raises an integration event of the given eventType, scoped to
+// the entity identified by guid, with the provided attributes folded into
+// the event summary/category. Used by subsystems (e.g. configguard) that
+// need to surface entity-scoped events through the MSK hook rather than
+// plain unscoped integration events.
+func (s *MSKShim) EmitEvent(eventType, guid string, attributes map[string]interface{}) {
+	if s.integration == nil {
+		log.Warn("EmitEvent: integration not set, dropping %s event for entity %s", eventType, guid)
+		return
+	}
+
+	entity, err := s.GetOrCreateEntity(string(EntityTypeCluster), guid)
+	if err != nil {
+		log.Error("EmitEvent: failed to resolve entity for guid %s: %v", guid, err)
+		return
+	}
+
+	summary, _ := attributes["title"].(string)
+	if summary == "" {
+		summary = eventType
+	}
+
+	if err := entity.AddEvent(event.New(summary, eventType)); err != nil {
+		log.Error("EmitEvent: failed to add %s event: %v", eventType, err)
+	}
+}
+
 // logSummary logs a summary of the MSK shim activity
 func (s *MSKShim) logSummary() {
 	brokerCount := len(s.aggregator.GetBrokerMetrics())
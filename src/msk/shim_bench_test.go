@@ -0,0 +1,246 @@
+package msk
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+)
+
+// perfBudget is the opt-in flag that turns these benchmarks into a
+// regression gate: when set, TestPerfBudget compares fresh benchmark runs
+// against the thresholds recorded in perfBudgetFile and fails if either
+// per-op latency or allocations have regressed past them.
+var perfBudget = flag.Bool("perf-budget", false, "fail if shim benchmark latency/allocations regress past perf_budget.json")
+
+const perfBudgetFile = "testdata/perf_budget.json"
+
+// budgetEntry is one benchmark's recorded latency/allocation ceiling.
+type budgetEntry struct {
+	MaxNsPerOp     int64 `json:"maxNsPerOp"`
+	MaxAllocsPerOp int64 `json:"maxAllocsPerOp"`
+}
+
+// newBenchShim builds an MSKShim wired to a real integration.Integration,
+// the same way production code and shim_test.go do, so the benchmarks
+// exercise the actual entity/aggregator path rather than a mock.
+func newBenchShim(tb testing.TB) *MSKShim {
+	tb.Helper()
+
+	i, err := integration.New("bench", "1.0.0")
+	if err != nil {
+		tb.Fatalf("failed to create integration: %v", err)
+	}
+
+	shim := NewMSKShim(Config{
+		Enabled:      true,
+		ClusterName:  "bench-cluster",
+		ClusterARN:   "arn:aws:kafka:us-east-1:123456789012:cluster/bench-cluster/abc",
+		AWSAccountID: "123456789012",
+		AWSRegion:    "us-east-1",
+		Environment:  "production",
+	})
+	shim.SetIntegration(i)
+	return shim
+}
+
+// syntheticBrokerData returns a broker data payload shaped like the one
+// brokerCollection assembles, indexed so 30 distinct brokers round-trip
+// through the entity cache instead of collapsing onto one key.
+func syntheticBrokerData(brokerID int) map[string]interface{} {
+	return map[string]interface{}{
+		"broker.id":                        fmt.Sprintf("%d", brokerID),
+		"broker.host":                      fmt.Sprintf("broker%d.example.com", brokerID),
+		"broker.bytesInPerSecond":          1000.0 + float64(brokerID),
+		"broker.bytesOutPerSecond":         500.0 + float64(brokerID),
+		"broker.messagesInPerSecond":       100.0 + float64(brokerID),
+		"broker.underReplicatedPartitions": 0,
+		"broker.cpuUser":                   45.0,
+		"broker.cpuSystem":                 10.0,
+		"broker.cpuIdle":                   45.0,
+	}
+}
+
+// syntheticTopicData returns a topic data payload for a 30-broker/
+// 500-topic cluster shape, topicIdx threaded through so entities don't
+// collapse onto a single cache key.
+func syntheticTopicData(topicIdx int) map[string]interface{} {
+	return map[string]interface{}{
+		"topic.name":              fmt.Sprintf("topic-%04d", topicIdx),
+		"topic.partitions":        10,
+		"topic.bytesInPerSec":     500.0,
+		"topic.bytesOutPerSec":    250.0,
+		"topic.replicationFactor": 3,
+	}
+}
+
+// syntheticOffsetData returns a consumer-offset payload for a 2000-group
+// cluster shape.
+func syntheticOffsetData(groupIdx int) map[string]interface{} {
+	return map[string]interface{}{
+		"consumerGroup":  fmt.Sprintf("consumer-group-%04d", groupIdx),
+		"topic":          fmt.Sprintf("topic-%04d", groupIdx%500),
+		"partition":      fmt.Sprintf("%d", groupIdx%10),
+		"consumerOffset": int64(groupIdx * 1000),
+		"highWaterMark":  int64(groupIdx*1000 + 500),
+		"lag":            int64(500),
+	}
+}
+
+const (
+	benchBrokerCount = 30
+	benchTopicCount  = 500
+	benchGroupCount  = 2000
+)
+
+// BenchmarkMSKShimTransformation exercises TransformBrokerMetrics end to
+// end for one synthetic broker's payload via NewMSKShim, driving
+// MetricAggregator and EntityCache the way a real collection cycle would.
+func BenchmarkMSKShimTransformation(b *testing.B) {
+	shim := newBenchShim(b)
+	brokerData := syntheticBrokerData(1)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if err := shim.TransformBrokerMetrics(brokerData); err != nil {
+			b.Fatalf("TransformBrokerMetrics: %v", err)
+		}
+	}
+}
+
+// BenchmarkMSKShimTransformation_AllBrokers sweeps the full 30-broker
+// payload every iteration, which is closer to the shape of one real
+// collection cycle than hammering a single broker ID.
+func BenchmarkMSKShimTransformation_AllBrokers(b *testing.B) {
+	shim := newBenchShim(b)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for id := 1; id <= benchBrokerCount; id++ {
+			if err := shim.TransformBrokerMetrics(syntheticBrokerData(id)); err != nil {
+				b.Fatalf("TransformBrokerMetrics: %v", err)
+			}
+		}
+	}
+}
+
+// BenchmarkMSKShimTransformTopicMetrics sweeps the full synthetic
+// 500-topic payload every iteration.
+func BenchmarkMSKShimTransformTopicMetrics(b *testing.B) {
+	shim := newBenchShim(b)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for idx := 0; idx < benchTopicCount; idx++ {
+			if err := shim.TransformTopicMetrics(syntheticTopicData(idx)); err != nil {
+				b.Fatalf("TransformTopicMetrics: %v", err)
+			}
+		}
+	}
+}
+
+// BenchmarkMSKShimProcessConsumerOffset sweeps the full synthetic
+// 2000-consumer-group payload every iteration.
+func BenchmarkMSKShimProcessConsumerOffset(b *testing.B) {
+	shim := newBenchShim(b)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for idx := 0; idx < benchGroupCount; idx++ {
+			if err := shim.ProcessConsumerOffset(syntheticOffsetData(idx)); err != nil {
+				b.Fatalf("ProcessConsumerOffset: %v", err)
+			}
+		}
+	}
+}
+
+// BenchmarkMSKShimFlush measures a full collection cycle: populate the
+// 30/500/2000 synthetic payload, then Flush, which is where
+// DimensionalTransformer and the cluster-level aggregation happen.
+func BenchmarkMSKShimFlush(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		shim := newBenchShim(b)
+		for id := 1; id <= benchBrokerCount; id++ {
+			_ = shim.TransformBrokerMetrics(syntheticBrokerData(id))
+		}
+		for idx := 0; idx < benchTopicCount; idx++ {
+			_ = shim.TransformTopicMetrics(syntheticTopicData(idx))
+		}
+		for idx := 0; idx < benchGroupCount; idx++ {
+			_ = shim.ProcessConsumerOffset(syntheticOffsetData(idx))
+		}
+		b.StartTimer()
+
+		if err := shim.Flush(); err != nil {
+			b.Fatalf("Flush: %v", err)
+		}
+	}
+}
+
+// TestPerfBudget runs the benchmarks above and fails if -perf-budget is
+// set and either per-op latency or allocations have regressed past the
+// thresholds recorded in testdata/perf_budget.json. It is a no-op
+// (skipped) unless the flag is passed, since comparing against absolute
+// ns/op thresholds on arbitrary CI hardware is only meaningful when
+// explicitly opted into.
+func TestPerfBudget(t *testing.T) {
+	if !*perfBudget {
+		t.Skip("perf budget check disabled; pass -perf-budget to enable")
+	}
+
+	budgets, err := loadPerfBudget(perfBudgetFile)
+	if err != nil {
+		t.Fatalf("failed to load perf budget file %s: %v", perfBudgetFile, err)
+	}
+
+	benchmarks := map[string]func(*testing.B){
+		"BenchmarkMSKShimTransformation":            BenchmarkMSKShimTransformation,
+		"BenchmarkMSKShimTransformation_AllBrokers": BenchmarkMSKShimTransformation_AllBrokers,
+		"BenchmarkMSKShimTransformTopicMetrics":     BenchmarkMSKShimTransformTopicMetrics,
+		"BenchmarkMSKShimProcessConsumerOffset":     BenchmarkMSKShimProcessConsumerOffset,
+		"BenchmarkMSKShimFlush":                     BenchmarkMSKShimFlush,
+	}
+
+	for name, fn := range benchmarks {
+		budget, ok := budgets[name]
+		if !ok {
+			t.Logf("no perf budget recorded for %s, skipping regression check", name)
+			continue
+		}
+
+		result := testing.Benchmark(fn)
+
+		nsPerOp := result.NsPerOp()
+		allocsPerOp := result.AllocsPerOp()
+
+		if nsPerOp > budget.MaxNsPerOp {
+			t.Errorf("%s: %d ns/op exceeds budget of %d ns/op", name, nsPerOp, budget.MaxNsPerOp)
+		}
+		if allocsPerOp > budget.MaxAllocsPerOp {
+			t.Errorf("%s: %d allocs/op exceeds budget of %d allocs/op", name, allocsPerOp, budget.MaxAllocsPerOp)
+		}
+	}
+}
+
+// loadPerfBudget reads the JSON baseline mapping benchmark name to its
+// recorded latency/allocation ceiling.
+func loadPerfBudget(path string) (map[string]budgetEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var budgets map[string]budgetEntry
+	if err := json.Unmarshal(data, &budgets); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return budgets, nil
+}
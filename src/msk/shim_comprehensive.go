@@ -1,6 +1,7 @@
 package msk
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -16,18 +17,54 @@ import (
 type ComprehensiveMSKShim struct {
 	enabled          bool
 	config           Config
-	transformer      *ComprehensiveTransformer
+	transformer      Transformer
 	aggregator       *MetricAggregator
 	entityCache      map[string]*integration.Entity
 	mutex            sync.RWMutex
 	integration      *integration.Integration
-	
+
+	// clusterMode is the ClusterMode transformer currently selected,
+	// detected per broker sample by detectClusterMode - see setClusterMode.
+	clusterMode ClusterMode
+
+	// adminDiscoverer, when config.EnhancedDiscoveryBootstrapServers is set,
+	// authoritatively enumerates the controller, topic list, partition
+	// assignments and per-topic configs instead of inferring them from JMX
+	// samples - see extractBrokerInfo and prePopulateDiscoveredEntities.
+	adminDiscoverer  *AdminDiscoverer
+
+	// lagCalculator computes real per-partition consumer-group lag via
+	// adminDiscoverer.Groups() instead of trusting a single broker's
+	// self-reported consumer.lag. Non-nil only when adminDiscoverer is.
+	lagCalculator *LagCalculator
+
+	// backlogEstimator derives aws.msk.LagInSeconds from the record
+	// timestamp at each group's committed offset instead of dividing lag
+	// by message rate, falling back to that division only when the
+	// broker's message format predates timestamps. Non-nil only when
+	// adminDiscoverer is.
+	backlogEstimator *BacklogTimeEstimator
+
+	// mskDiscovery, when config.MSKDiscoveryEnabled is set, calls the real
+	// AWS MSK control plane to cross-validate inferredBrokers (this cycle's
+	// extractBrokerInfo results, keyed by broker ID) against what AWS
+	// actually provisioned - see setClusterMetrics's discoveryDrift metric.
+	mskDiscovery    *MSKDiscoveryClient
+	inferredBrokers map[string]brokerInfo
+
+	// groupLastSeenCycle records, for each consumer group that has had a
+	// ProcessConsumerOffsetSample call, the cycleCount as of its most
+	// recent one - so evictStaleConsumerGroups can tell a group that
+	// stopped consuming (or was deleted) from one simply mid-cycle.
+	groupLastSeenCycle map[string]int
+	cycleCount         int
+
 	// Enhanced mode tracking
 	enhancedMode     bool
 	metricsReceived  int
 	lastMetricTime   time.Time
 	emptyMetricCount int
-	
+
 	// Debug support
 	debugMode        bool
 }
@@ -35,17 +72,54 @@ type ComprehensiveMSKShim struct {
 // NewComprehensiveMSKShim creates a new comprehensive MSK shim
 func NewComprehensiveMSKShim(config Config) *ComprehensiveMSKShim {
 	shim := &ComprehensiveMSKShim{
-		enabled:      config.Enabled,
-		config:       config,
-		aggregator:   NewMetricAggregator(),
-		entityCache:  make(map[string]*integration.Entity),
-		debugMode:    false, // Could be controlled by config later
-		integration:  nil,   // Will be set later via SetIntegration
+		enabled:            config.Enabled,
+		config:             config,
+		aggregator:         NewMetricAggregator(),
+		entityCache:        make(map[string]*integration.Entity),
+		groupLastSeenCycle: make(map[string]int),
+		inferredBrokers:    make(map[string]brokerInfo),
+		debugMode:          false, // Could be controlled by config later
+		integration:        nil,   // Will be set later via SetIntegration
+	}
+	
+	// Start assuming a classic ZooKeeper-backed controller; ProcessBrokerMetrics
+	// re-detects this from each broker sample's MBean keys and swaps in the
+	// KRaft or enhanced-synthetic transformer the moment the evidence changes.
+	shim.clusterMode = ClusterModeZooKeeperClassic
+	shim.transformer = transformerFor(shim.clusterMode, config)
+
+	// When enhanced.discovery.bootstrap_servers is configured, connect a real
+	// admin client and run one discovery pass so setClusterMetrics and the
+	// broker/topic entities below are backed by the controller's authoritative
+	// view instead of only whatever a single broker's JMX beans report.
+	if len(config.EnhancedDiscoveryBootstrapServers) > 0 {
+		discoverer, err := NewAdminDiscoverer(config.EnhancedDiscoveryBootstrapServers, &config)
+		if err != nil {
+			log.Warn("MSK shim: admin discovery disabled, falling back to JMX-inferred cluster shape: %v", err)
+		} else {
+			shim.adminDiscoverer = discoverer
+			if _, err := discoverer.Discover(shim.aggregator); err != nil {
+				log.Warn("MSK shim: initial admin discovery pass failed, falling back to JMX-inferred cluster shape: %v", err)
+			}
+			shim.lagCalculator = NewLagCalculator(discoverer.Groups(), LagCalculatorConfig{})
+			shim.backlogEstimator = NewBacklogTimeEstimator(discoverer.Groups(), 0)
+		}
 	}
-	
-	// Start with simple transformer
-	shim.transformer = NewComprehensiveTransformer(config.ClusterName)
-	
+
+	// When MSK discovery is enabled, reconcile every cycle's JMX-inferred
+	// broker list against the real AWS MSK control plane's ListNodes answer
+	// for config.ClusterARN, so a self-managed Kafka deployment migrating to
+	// MSK can confirm the shim's synthesized entities match what AWS sees.
+	if config.MSKDiscoveryEnabled {
+		if config.ClusterARN == "" {
+			log.Warn("MSK shim: discovery drift reconciliation disabled, no cluster ARN configured")
+		} else if api, err := newAWSMSKAPI(context.Background(), config.AWSRegion); err != nil {
+			log.Warn("MSK shim: discovery drift reconciliation disabled, failed to create AWS MSK client: %v", err)
+		} else {
+			shim.mskDiscovery = NewMSKDiscoveryClient(api, config.ClusterARN, config.MSKDiscoveryRefreshInterval)
+		}
+	}
+
 	return shim
 }
 
@@ -65,7 +139,9 @@ func (s *ComprehensiveMSKShim) ProcessBrokerMetrics(brokerSample *metric.Set, en
 		if s.enhancedMode {
 			log.Info("MSK Shim: Switching back to real metrics mode")
 			s.enhancedMode = false
-			// Transformer remains the same
+		}
+		if mode := detectClusterMode(brokerSample.Metrics); mode != "" {
+			s.setClusterMode(mode)
 		}
 	} else {
 		s.emptyMetricCount++
@@ -73,7 +149,7 @@ func (s *ComprehensiveMSKShim) ProcessBrokerMetrics(brokerSample *metric.Set, en
 		if !s.enhancedMode && s.emptyMetricCount > 5 {
 			log.Info("MSK Shim: Switching to enhanced mode due to lack of real metrics")
 			s.enhancedMode = true
-			// Could switch to different transformer implementation here if needed
+			s.setClusterMode(ClusterModeEnhancedSynthetic)
 		}
 	}
 	
@@ -82,7 +158,15 @@ func (s *ComprehensiveMSKShim) ProcessBrokerMetrics(brokerSample *metric.Set, en
 	if brokerInfo.ID == "" {
 		return fmt.Errorf("unable to extract broker ID from sample")
 	}
-	
+
+	// Record this cycle's JMX-inferred view so setClusterMetrics can
+	// reconcile it against the real MSK control plane, when enabled.
+	if s.mskDiscovery != nil {
+		s.mutex.Lock()
+		s.inferredBrokers[brokerInfo.ID] = brokerInfo
+		s.mutex.Unlock()
+	}
+
 	// Create or get MSK broker entity
 	brokerEntity, err := s.getOrCreateBrokerEntity(entity, brokerInfo)
 	if err != nil {
@@ -114,7 +198,17 @@ func (s *ComprehensiveMSKShim) ProcessBrokerMetrics(brokerSample *metric.Set, en
 		brokerMetric.MessagesInPerSec, _ = toFloat64(val)
 	}
 	s.aggregator.AddBrokerMetric(brokerInfo.ID, brokerMetric)
-	
+
+	// Feed produce/fetch request-latency samples into the per-broker
+	// t-digests so setClusterMetrics can report real cross-broker
+	// percentiles instead of a single broker's self-reported average.
+	if avgProduceTime, ok := getFloatValue(brokerSample.Metrics, "request.avgTimeProduceRequest"); ok {
+		s.aggregator.AddBrokerLatencySample(brokerInfo.ID, "ProduceTotalTimeMs", avgProduceTime)
+	}
+	if avgFetchTime, ok := getFloatValue(brokerSample.Metrics, "request.avgTimeFetch"); ok {
+		s.aggregator.AddBrokerLatencySample(brokerInfo.ID, "FetchConsumerTotalTimeMs", avgFetchTime)
+	}
+
 	s.debugLog("Successfully processed broker %s with %d metrics", brokerInfo.ID, len(mskSample.Metrics))
 	
 	return nil
@@ -173,23 +267,27 @@ func (s *ComprehensiveMSKShim) ProcessConsumerOffsetSample(offsetSample *metric.
 	if !s.enabled {
 		return nil
 	}
-	
+
 	// Extract consumer group and topic
 	consumerGroup, _ := offsetSample.Metrics["consumerGroup"].(string)
 	topicName, _ := offsetSample.Metrics["topic"].(string)
 	lag, _ := offsetSample.Metrics["consumer.lag"]
-	
+
 	if consumerGroup == "" || topicName == "" {
 		return fmt.Errorf("consumer group or topic not found in offset sample")
 	}
-	
+
 	s.debugLog("Processing consumer offset for group: %s, topic: %s, lag: %v", consumerGroup, topicName, lag)
-	
+
+	s.mutex.Lock()
+	s.groupLastSeenCycle[consumerGroup] = s.cycleCount
+	s.mutex.Unlock()
+
 	// Add consumer lag to aggregator for enrichment
 	if lagFloat, err := toFloat64(lag); err == nil {
 		s.aggregator.AddConsumerLag(topicName, consumerGroup, lagFloat)
 	}
-	
+
 	// Also create a consumer group entity if needed
 	if topicEntity, err := s.getOrCreateTopicEntity(entity, topicName); err == nil {
 		// Add consumer lag as a metric on the topic
@@ -200,10 +298,95 @@ func (s *ComprehensiveMSKShim) ProcessConsumerOffsetSample(offsetSample *metric.
 			topicSample.SetMetric("provider.consumerLag", lagFloat, metric.GAUGE)
 		}
 	}
-	
+
+	// When admin discovery is configured, also emit a proper
+	// AWSMSKCONSUMERGROUP entity carrying real per-partition lag, group
+	// state, membership and assignment strategy instead of only the
+	// sample's self-reported scalar consumer.lag above.
+	if s.lagCalculator != nil {
+		if err := s.processConsumerGroupLag(entity, consumerGroup, topicName); err != nil {
+			log.Warn("MSK Shim: failed to compute admin-sourced lag for group %s topic %s: %v", consumerGroup, topicName, err)
+		}
+	}
+
+	return nil
+}
+
+// processConsumerGroupLag computes real per-partition lag for (group, topic)
+// via s.lagCalculator (ListConsumerGroupOffsets + ListOffsets under the
+// hood) and emits it, along with group state, member count and assignment
+// strategy, on an AWSMSKCONSUMERGROUP entity.
+func (s *ComprehensiveMSKShim) processConsumerGroupLag(parentEntity *integration.Entity, consumerGroup, topicName string) error {
+	summary, err := s.lagCalculator.CalculateGroupLag(consumerGroup, topicName)
+	if err != nil {
+		return fmt.Errorf("calculating group lag: %w", err)
+	}
+	s.aggregator.AddGroupLagToAggregator(summary)
+
+	described, err := s.adminDiscoverer.Groups().DescribeGroup(consumerGroup)
+	if err != nil {
+		log.Debug("MSK Shim: failed to describe consumer group %s, member count and assignment strategy will be unset: %v", consumerGroup, err)
+		described = &DescribedConsumerGroup{Group: consumerGroup, State: summary.State}
+	}
+
+	groupEntity, err := s.getOrCreateConsumerGroupEntity(parentEntity, consumerGroup)
+	if err != nil {
+		return fmt.Errorf("failed to create consumer group entity: %w", err)
+	}
+
+	groupSample := groupEntity.NewMetricSet("AwsMskConsumerGroupSample")
+	s.setConsumerGroupAttributes(groupSample, described)
+	groupSample.SetMetric("topic", topicName, metric.ATTRIBUTE)
+	groupSample.SetMetric("aws.msk.MaxLag", float64(summary.MaxLag), metric.GAUGE)
+	groupSample.SetMetric("aws.msk.SumLag", float64(summary.SumLag), metric.GAUGE)
+
+	// LagInSeconds reports how far behind in time the group is. When
+	// backlogEstimator is wired, that's the record timestamp at the
+	// group's committed offset on the partition with the most lag;
+	// otherwise it falls back to dividing total lag by the topic's
+	// current message rate, which is wildly inaccurate under bursty
+	// traffic or on idle topics.
+	if backlogSeconds, ok := s.backlogSecondsFor(consumerGroup, topicName, summary); ok {
+		groupSample.SetMetric("aws.msk.LagInSeconds", backlogSeconds, metric.GAUGE)
+	} else if topicMetrics := s.aggregator.GetTopicMetrics(topicName); topicMetrics.MessagesInPerSec > 0 {
+		groupSample.SetMetric("aws.msk.LagInSeconds", float64(summary.SumLag)/topicMetrics.MessagesInPerSec, metric.GAUGE)
+	}
+
+	for _, partitionLag := range summary.Partitions {
+		partitionSample := groupEntity.NewMetricSet("AwsMskConsumerGroupSample")
+		s.setConsumerGroupAttributes(partitionSample, described)
+		partitionSample.SetMetric("topic", partitionLag.Topic, metric.ATTRIBUTE)
+		partitionSample.SetMetric("partition", float64(partitionLag.Partition), metric.ATTRIBUTE)
+		partitionSample.SetMetric("aws.msk.ConsumerOffset", float64(partitionLag.EffectiveOffset), metric.GAUGE)
+		partitionSample.SetMetric("aws.msk.LogEndOffset", float64(partitionLag.HighWaterMark), metric.GAUGE)
+		partitionSample.SetMetric("aws.msk.EstimatedMaxLag", float64(partitionLag.Lag), metric.GAUGE)
+	}
+
 	return nil
 }
 
+// backlogSecondsFor returns the timestamp-based backlog seconds (falling
+// back internally to lag/messagesInPerSec where timestamps aren't
+// available, via EstimateOrFallback) for consumerGroup's most-lagging
+// partition of topicName. ok is false when backlogEstimator isn't wired
+// (admin discovery disabled), in which case the caller should use its own
+// lag/messagesInPerSec estimate instead.
+func (s *ComprehensiveMSKShim) backlogSecondsFor(consumerGroup, topicName string, summary *GroupLagSummary) (float64, bool) {
+	if s.backlogEstimator == nil || len(summary.Partitions) == 0 {
+		return 0, false
+	}
+
+	worst := summary.Partitions[0]
+	for _, partitionLag := range summary.Partitions[1:] {
+		if partitionLag.Lag > worst.Lag {
+			worst = partitionLag
+		}
+	}
+
+	messagesInPerSec := s.aggregator.GetTopicMetrics(topicName).MessagesInPerSec
+	return s.backlogEstimator.EstimateOrFallback(consumerGroup, topicName, worst.Partition, true, float64(summary.SumLag), messagesInPerSec), true
+}
+
 // ProcessClusterMetrics creates cluster-level aggregated metrics
 func (s *ComprehensiveMSKShim) ProcessClusterMetrics(entity *integration.Entity) error {
 	if !s.enabled {
@@ -218,6 +401,10 @@ func (s *ComprehensiveMSKShim) ProcessClusterMetrics(entity *integration.Entity)
 		return fmt.Errorf("failed to create cluster entity: %v", err)
 	}
 	
+	// Ensure every broker/topic admin discovery already knows about has an
+	// entity, even if it hasn't reported a JMX sample yet this cycle.
+	s.prePopulateDiscoveredEntities(entity)
+
 	// Get aggregated data
 	clusterData := s.aggregator.GetClusterMetrics()
 	if clusterData == nil {
@@ -260,6 +447,18 @@ func (s *ComprehensiveMSKShim) hasRealMetrics(sample *metric.Set) bool {
 	return false
 }
 
+// setClusterMode swaps in the Transformer registered for mode, if it differs
+// from the one currently selected, so a sample's worth of detection doesn't
+// reconstruct a transformer on every call once the cluster has settled.
+func (s *ComprehensiveMSKShim) setClusterMode(mode ClusterMode) {
+	if mode == s.clusterMode {
+		return
+	}
+	log.Info("MSK Shim: Switching cluster mode from %s to %s", s.clusterMode, mode)
+	s.clusterMode = mode
+	s.transformer = transformerFor(mode, s.config)
+}
+
 type brokerInfo struct {
 	ID   string
 	Host string
@@ -284,7 +483,16 @@ func (s *ComprehensiveMSKShim) extractBrokerInfo(sample *metric.Set) brokerInfo
 		info.Host = host
 	}
 	
-	// If no ID found, try to extract from host
+	// If still no ID, prefer the authoritative broker list from admin
+	// discovery over guessing from the hostname.
+	if info.ID == "" && info.Host != "" && s.adminDiscoverer != nil {
+		if id, ok := s.adminDiscoverer.BrokerAddresses()[info.Host]; ok {
+			info.ID = strconv.Itoa(int(id))
+		}
+	}
+
+	// Last resort: no admin discovery configured (or it doesn't know this
+	// host), so fall back to the brittle "kafka-0 -> id 0" heuristic.
 	if info.ID == "" && info.Host != "" {
 		// Format might be: kafka-0, production-kafka-0, etc.
 		parts := strings.Split(info.Host, "-")
@@ -292,10 +500,33 @@ func (s *ComprehensiveMSKShim) extractBrokerInfo(sample *metric.Set) brokerInfo
 			info.ID = parts[len(parts)-1]
 		}
 	}
-	
+
 	return info
 }
 
+// prePopulateDiscoveredEntities ensures entityCache has a broker entity for
+// every broker ID and a topic entity for every topic name admin discovery
+// has found, rather than waiting for each one's own JMX sample to arrive -
+// so a topic or broker with no (or not-yet-polled) JMX metrics still shows
+// up as an AWSMSKBROKER/AWSMSKTOPIC entity. A no-op when adminDiscoverer was
+// never configured, since then entityCache only ever grows from JMX samples.
+func (s *ComprehensiveMSKShim) prePopulateDiscoveredEntities(parentEntity *integration.Entity) {
+	if s.adminDiscoverer == nil {
+		return
+	}
+
+	for _, brokerID := range s.aggregator.BrokerIDs() {
+		if _, err := s.getOrCreateBrokerEntity(parentEntity, brokerInfo{ID: brokerID}); err != nil {
+			log.Warn("MSK Shim: failed to pre-populate broker entity %s from admin discovery: %v", brokerID, err)
+		}
+	}
+	for _, topicName := range s.aggregator.TopicNames() {
+		if _, err := s.getOrCreateTopicEntity(parentEntity, topicName); err != nil {
+			log.Warn("MSK Shim: failed to pre-populate topic entity %s from admin discovery: %v", topicName, err)
+		}
+	}
+}
+
 func (s *ComprehensiveMSKShim) getOrCreateBrokerEntity(parentEntity *integration.Entity, info brokerInfo) (*integration.Entity, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -344,6 +575,10 @@ func (s *ComprehensiveMSKShim) setBrokerAttributes(sample *metric.Set, info brok
 	// AWS specific attributes
 	sample.SetMetric("provider.awsAccountId", s.config.AWSAccountID, metric.ATTRIBUTE)
 	sample.SetMetric("provider.awsRegion", s.config.AWSRegion, metric.ATTRIBUTE)
+
+	// isController comes from admin discovery (AddDescribedCluster), not
+	// anything reported in the broker's own JMX sample.
+	sample.SetMetric("provider.isController", s.aggregator.IsControllerBroker(info.ID), metric.ATTRIBUTE)
 }
 
 func (s *ComprehensiveMSKShim) getOrCreateTopicEntity(parentEntity *integration.Entity, topicName string) (*integration.Entity, error) {
@@ -385,6 +620,63 @@ func (s *ComprehensiveMSKShim) setTopicAttributes(sample *metric.Set, topicName
 	sample.SetMetric("provider.topic", topicName, metric.ATTRIBUTE)
 	sample.SetMetric("clusterName", s.config.ClusterName, metric.ATTRIBUTE)
 	sample.SetMetric("provider.clusterName", s.config.ClusterName, metric.ATTRIBUTE)
+
+	// Replication factor, min.insync.replicas and retention.ms come from
+	// admin discovery (AddDescribedTopic), not anything reported in the
+	// topic's own JMX sample - they default to zero when discovery is
+	// unconfigured or hasn't seen this topic yet.
+	discovered := s.aggregator.GetTopicMetrics(topicName)
+	if discovered.ReplicationFactor > 0 {
+		sample.SetMetric("provider.replicationFactor", float64(discovered.ReplicationFactor), metric.ATTRIBUTE)
+	}
+	if discovered.MinInSyncReplicas > 0 {
+		sample.SetMetric("provider.minInSyncReplicas", float64(discovered.MinInSyncReplicas), metric.ATTRIBUTE)
+	}
+	if discovered.RetentionMs > 0 {
+		sample.SetMetric("provider.retentionMs", float64(discovered.RetentionMs), metric.ATTRIBUTE)
+	}
+}
+
+func (s *ComprehensiveMSKShim) getOrCreateConsumerGroupEntity(parentEntity *integration.Entity, group string) (*integration.Entity, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entityKey := fmt.Sprintf("consumergroup:%s", group)
+	if cached, ok := s.entityCache[entityKey]; ok {
+		return cached, nil
+	}
+
+	// Create entity name in MSK format
+	entityName := fmt.Sprintf("%s:%s:%s:consumer-group-%s",
+		s.config.AWSAccountID, s.config.AWSRegion, s.config.ClusterName, group)
+
+	// Create entity with integration
+	var groupEntity *integration.Entity
+	var err error
+	if s.integration != nil {
+		groupEntity, err = s.integration.Entity(entityName, "AWSMSKCONSUMERGROUP")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Fallback - create minimal entity
+		groupEntity = &integration.Entity{}
+	}
+
+	s.entityCache[entityKey] = groupEntity
+	return groupEntity, nil
+}
+
+func (s *ComprehensiveMSKShim) setConsumerGroupAttributes(sample *metric.Set, described *DescribedConsumerGroup) {
+	sample.SetMetric("consumerGroup", described.Group, metric.ATTRIBUTE)
+	sample.SetMetric("provider.consumerGroup", described.Group, metric.ATTRIBUTE)
+	sample.SetMetric("clusterName", s.config.ClusterName, metric.ATTRIBUTE)
+	sample.SetMetric("provider.clusterName", s.config.ClusterName, metric.ATTRIBUTE)
+	sample.SetMetric("provider.state", string(described.State), metric.ATTRIBUTE)
+	sample.SetMetric("provider.memberCount", float64(described.MemberCount), metric.ATTRIBUTE)
+	if described.AssignmentStrategy != "" {
+		sample.SetMetric("provider.assignmentStrategy", described.AssignmentStrategy, metric.ATTRIBUTE)
+	}
 }
 
 func (s *ComprehensiveMSKShim) getOrCreateClusterEntity(parentEntity *integration.Entity) (*integration.Entity, error) {
@@ -440,6 +732,72 @@ func (s *ComprehensiveMSKShim) setClusterMetrics(sample *metric.Set, data *Clust
 	sample.SetMetric("provider.globalPartitionCount.Average", float64(data.GlobalPartitionCount), metric.GAUGE)
 	sample.SetMetric("provider.offlinePartitionsCount.Sum", float64(data.OfflinePartitionsCount), metric.GAUGE)
 	sample.SetMetric("provider.underReplicatedPartitions.Sum", float64(data.UnderReplicatedPartitions), metric.GAUGE)
+
+	// True 1m/5m/15m EWMA throughput rates, built from every cycle's
+	// cluster-wide BytesInPerSec/BytesOutPerSec/MessagesInPerSec rather
+	// than this single cycle's instantaneous value -- the same OneMinuteRate
+	// etc shape Kafka's own Meter MBeans expose.
+	s.aggregator.UpdateClusterRateMeters(data)
+	rateMetricNames := map[string]string{
+		"BytesInPerSec":    "provider.bytesInPerSec",
+		"BytesOutPerSec":   "provider.bytesOutPerSec",
+		"MessagesInPerSec": "provider.messagesInPerSec",
+	}
+	for metricName, rates := range s.aggregator.ClusterRateSnapshot() {
+		prefix, ok := rateMetricNames[metricName]
+		if !ok {
+			continue
+		}
+		sample.SetMetric(prefix+".1MinuteRate", rates.OneMinuteRate, metric.GAUGE)
+		sample.SetMetric(prefix+".5MinuteRate", rates.FiveMinuteRate, metric.GAUGE)
+		sample.SetMetric(prefix+".15MinuteRate", rates.FifteenMinuteRate, metric.GAUGE)
+	}
+
+	// Real cross-broker percentiles for request-latency metrics, computed by
+	// merging every broker's t-digest rather than summing or averaging
+	// per-broker averages.
+	providerLatencyNames := map[string]string{
+		"ProduceTotalTimeMs":       "provider.produceTotalTimeMs",
+		"FetchConsumerTotalTimeMs": "provider.fetchConsumerTotalTimeMs",
+	}
+	for metricName, quantiles := range s.aggregator.ClusterLatencyPercentiles() {
+		prefix, ok := providerLatencyNames[metricName]
+		if !ok {
+			continue
+		}
+		sample.SetMetric(prefix+".p50", quantiles.P50, metric.GAUGE)
+		sample.SetMetric(prefix+".p95", quantiles.P95, metric.GAUGE)
+		sample.SetMetric(prefix+".p99", quantiles.P99, metric.GAUGE)
+	}
+
+	// Self-metrics exposing the bounded-cardinality guard's health, so
+	// operators can tell a cluster with thousands of topics is actively
+	// having its oldest rate history evicted rather than silently losing
+	// it.
+	rateStats := s.aggregator.TopicRateMeterStats()
+	sample.SetMetric("provider.shim.rateMeterTrackedTopics", float64(rateStats.TrackedTopics), metric.GAUGE)
+	sample.SetMetric("provider.shim.rateMeterEvictionCount", float64(rateStats.Evictions), metric.GAUGE)
+	sample.SetMetric("provider.shim.rateMeterSampleCount", float64(rateStats.Samples), metric.GAUGE)
+
+	// Cross-validate this cycle's JMX-inferred broker list against the real
+	// AWS MSK control plane, when discovery is enabled, so an operator
+	// migrating from self-managed Kafka to MSK can confirm the shim's
+	// synthesized entities line up with what AWS actually provisioned.
+	if s.mskDiscovery != nil {
+		s.mutex.Lock()
+		inferred := make(map[string]brokerInfo, len(s.inferredBrokers))
+		for id, info := range s.inferredBrokers {
+			inferred[id] = info
+		}
+		s.mutex.Unlock()
+
+		drift := s.mskDiscovery.Reconcile(context.Background(), inferred)
+		if drift.Count() > 0 {
+			log.Warn("MSK shim: discovery drift detected: %d broker(s) missing from JMX %v, %d broker(s) missing from MSK %v, %d host mismatch(es)",
+				len(drift.MissingFromJMX), drift.MissingFromJMX, len(drift.MissingFromMSK), drift.MissingFromMSK, drift.HostMismatches)
+		}
+		sample.SetMetric("provider.shim.discoveryDrift", float64(drift.Count()), metric.GAUGE)
+	}
 }
 
 func (s *ComprehensiveMSKShim) generateClusterARN() string {
@@ -565,12 +923,49 @@ func (s *ComprehensiveMSKShim) Flush() error {
 		}
 	}
 	
-	// Reset aggregator for next collection cycle
-	s.aggregator = NewMetricAggregator()
-	
+	// Advance the cycle counter and evict any consumer group that hasn't
+	// had a ProcessConsumerOffsetSample call in StaleGroupTTLCycles cycles
+	// before the aggregator (and its per-cycle lag data) is reset.
+	s.mutex.Lock()
+	s.cycleCount++
+	s.mutex.Unlock()
+	s.evictStaleConsumerGroups()
+
+	// Reset per-cycle aggregated metrics, but keep the aggregator itself
+	// (rather than replacing it with NewMetricAggregator()) so its rate
+	// meters keep building true 1m/5m/15m EWMAs across cycles instead of
+	// restarting from zero every Flush.
+	s.aggregator.Reset()
+
 	return nil
 }
 
+// evictStaleConsumerGroups removes consumer-group entities that haven't had
+// a ProcessConsumerOffsetSample call in config.StaleGroupTTLCycles cycles,
+// so a group that stopped consuming (or was deleted outright) eventually
+// stops being reported instead of lingering forever on its last-known lag.
+// There's no SDK hook to emit an explicit delete event for an entity, so the
+// best this integration can do is stop refreshing it: evicting it from
+// entityCache means the next cycle's samples simply won't include it.
+func (s *ComprehensiveMSKShim) evictStaleConsumerGroups() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ttl := s.config.StaleGroupTTLCycles
+	if ttl <= 0 {
+		return
+	}
+
+	for group, lastSeen := range s.groupLastSeenCycle {
+		if s.cycleCount-lastSeen <= ttl {
+			continue
+		}
+		delete(s.entityCache, fmt.Sprintf("consumergroup:%s", group))
+		delete(s.groupLastSeenCycle, group)
+		log.Info("MSK Shim: evicted stale consumer group %s after %d cycles with no offset sample", group, ttl)
+	}
+}
+
 // SetIntegration sets the integration instance for entity creation
 func (s *ComprehensiveMSKShim) SetIntegration(i *integration.Integration) {
 	s.integration = i
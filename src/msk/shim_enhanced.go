@@ -5,10 +5,17 @@ import (
 	"os"
 	"sync"
 
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 )
 
+// Shim is the type the pre-enhanced IntegrationHook/transformer code
+// below was written against. EnhancedShim grew to be the only real
+// implementation of it, so Shim is kept as an alias rather than forking
+// a second struct that would drift from it.
+type Shim = EnhancedShim
+
 // EnhancedShim provides MSK transformation with fallback metric generation
 type EnhancedShim struct {
 	integration      *integration.Integration
@@ -18,10 +25,30 @@ type EnhancedShim struct {
 	transformer      interface{} // Can be SimpleTransformer or EnhancedTransformer
 	enhancedMode     bool
 	lagEnricher      *SimpleConsumerLagEnricher
+	mapper           *MetricMapper
+	reassignPoller   *ReassignmentPoller
+	discoverer       *AdminDiscoverer
 	mu               sync.Mutex
 	metricsCollected int
 }
 
+// SetReassignmentPoller wires in the poller Flush will consult for the
+// cluster-wide activeReassignments count. It's optional; if unset, Flush
+// simply skips attaching that metric.
+func (s *EnhancedShim) SetReassignmentPoller(poller *ReassignmentPoller) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reassignPoller = poller
+}
+
+// NewShim creates the shim used by the non-enhanced IntegrationHook path.
+// It is just NewEnhancedShim under its pre-rename name: Shim is an alias
+// for EnhancedShim, and "enhanced mode" itself is still gated by
+// MSK_ENHANCED_MODE inside the transformer it selects.
+func NewShim(i *integration.Integration, config *Config) (*Shim, error) {
+	return NewEnhancedShim(i, config)
+}
+
 // NewEnhancedShim creates a new enhanced MSK shim instance
 func NewEnhancedShim(i *integration.Integration, config *Config) (*EnhancedShim, error) {
 	if config == nil {
@@ -45,7 +72,15 @@ func NewEnhancedShim(i *integration.Integration, config *Config) (*EnhancedShim,
 	// Initialize transformer based on mode
 	if shim.enhancedMode {
 		log.Info("MSK shim running in ENHANCED mode with metric generation")
-		shim.transformer = NewEnhancedTransformer(shim.ToShim())
+		enhanced := NewEnhancedTransformer(shim.ToShim(), resolveWorkloadProfile(config.WorkloadProfile), config.EnhancedSeed)
+		if config.CapacityFilePath != "" {
+			if capacity, err := LoadCapacityProvider(config.CapacityFilePath); err != nil {
+				log.Warn("MSK shim: failed to load capacity file %s, utilization metrics disabled: %v", config.CapacityFilePath, err)
+			} else {
+				enhanced.SetCapacityProvider(capacity)
+			}
+		}
+		shim.transformer = enhanced
 	} else {
 		shim.transformer = NewSimpleTransformer(shim.ToShim())
 	}
@@ -55,19 +90,31 @@ func NewEnhancedShim(i *integration.Integration, config *Config) (*EnhancedShim,
 		shim.lagEnricher = NewSimpleConsumerLagEnricher(shim.ToShim())
 	}
 
+	// When enhanced.discovery.bootstrap_servers is configured, connect a
+	// real AdminClient and run one discovery pass so the simulated
+	// brokerCount/topicCount/controller defaults only cover whatever
+	// DescribeCluster/DescribeTopics couldn't answer, instead of everything.
+	if len(config.EnhancedDiscoveryBootstrapServers) > 0 {
+		discoverer, err := NewAdminDiscoverer(config.EnhancedDiscoveryBootstrapServers, config)
+		if err != nil {
+			log.Warn("MSK shim: enhanced discovery disabled, falling back to simulated cluster shape: %v", err)
+		} else {
+			shim.discoverer = discoverer
+			if _, err := discoverer.Discover(shim.aggregator); err != nil {
+				log.Warn("MSK shim: initial enhanced discovery pass failed, falling back to simulated cluster shape: %v", err)
+			}
+		}
+	}
+
 	return shim, nil
 }
 
-// ToShim converts EnhancedShim to regular Shim for compatibility
+// ToShim returns s itself: now that Shim is an alias for EnhancedShim,
+// callers that want a *Shim to hand to NewSimpleTransformer/
+// NewEnhancedTransformer/NewSimpleConsumerLagEnricher can just use the
+// shim they already have.
 func (s *EnhancedShim) ToShim() *Shim {
-	return &Shim{
-		integration: s.integration,
-		config:      s.config,
-		aggregator:  s.aggregator,
-		entityCache: s.entityCache,
-		transformer: nil, // Will be set separately
-		lagEnricher: s.lagEnricher,
-	}
+	return s
 }
 
 // IsEnabled returns whether the MSK shim is enabled
@@ -80,10 +127,12 @@ func (s *EnhancedShim) TransformBrokerMetrics(brokerData map[string]interface{})
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Check if we're getting real metrics
+	// Check if we're getting real metrics. A healthy but idle broker can
+	// report BytesInPerSec=0 while still reporting non-zero purgatory
+	// metrics, so those count as evidence of real metrics flowing too.
 	hasRealMetrics := false
 	criticalMetrics := []string{"broker.bytesInPerSecond", "broker.messagesInPerSecond", "broker.IOInPerSecond"}
-	
+
 	for _, metric := range criticalMetrics {
 		if val, exists := brokerData[metric]; exists && val != nil && val != 0 {
 			hasRealMetrics = true
@@ -91,28 +140,101 @@ func (s *EnhancedShim) TransformBrokerMetrics(brokerData map[string]interface{})
 		}
 	}
 
+	if !hasRealMetrics && hasPurgatoryMetrics(brokerData) {
+		hasRealMetrics = true
+	}
+
 	// Auto-switch to enhanced mode if no real metrics after several attempts
 	if !hasRealMetrics {
 		s.metricsCollected++
 		if s.metricsCollected > 5 && !s.enhancedMode {
 			log.Warn("No real metrics detected after %d attempts, switching to enhanced mode", s.metricsCollected)
 			s.enhancedMode = true
-			s.transformer = NewEnhancedTransformer(s.ToShim())
+			s.transformer = NewEnhancedTransformer(s.ToShim(), resolveWorkloadProfile(s.config.WorkloadProfile), s.config.EnhancedSeed)
+		}
+	}
+
+	// Client-id/user quota metrics carry a dimensionality the
+	// broker-level transformers below don't model, so they're handled
+	// separately regardless of which transformer is active.
+	if rawSamples, ok := brokerData["client.metrics"].([]ClientMetricSample); ok {
+		if err := s.TransformClientMetrics(rawSamples, s.metricMapper()); err != nil {
+			log.Error("Failed to transform client metrics: %v", err)
 		}
 	}
 
 	// Use appropriate transformer
+	var err error
 	if s.enhancedMode {
 		if enhanced, ok := s.transformer.(*EnhancedTransformer); ok {
-			return enhanced.TransformBrokerMetricsEnhanced(brokerData)
+			err = enhanced.TransformBrokerMetricsEnhanced(brokerData)
 		}
+	} else if simple, ok := s.transformer.(*SimpleTransformer); ok {
+		err = simple.TransformBrokerMetricsSimple(brokerData)
+	} else {
+		return fmt.Errorf("invalid transformer type")
 	}
-	
-	if simple, ok := s.transformer.(*SimpleTransformer); ok {
-		return simple.TransformBrokerMetricsSimple(brokerData)
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("invalid transformer type")
+	if brokerID, ok := getIntValue(brokerData, "broker.id"); ok {
+		s.attachBrokerReassignment(int32(brokerID))
+		s.attachBrokerPurgatory(brokerData, int32(brokerID))
+	}
+
+	return nil
+}
+
+// attachBrokerPurgatory emits kafka.broker.purgatory<Op><Size|DelayedOps>
+// metrics onto the broker entity TransformBrokerMetrics just populated, for
+// every delayed operation present in brokerData.
+func (s *EnhancedShim) attachBrokerPurgatory(brokerData map[string]interface{}, brokerID int32) {
+	if !hasPurgatoryMetrics(brokerData) {
+		return
+	}
+
+	entityName := fmt.Sprintf("%s-broker-%d", s.config.ClusterName, brokerID)
+	entity, err := s.GetOrCreateEntity("AwsMskBrokerSample", entityName)
+	if err != nil {
+		log.Error("Failed to resolve broker entity to attach purgatory metrics: %v", err)
+		return
+	}
+
+	ms := entity.NewMetricSet("AwsMskBrokerSample")
+	TransformPurgatoryMetrics(brokerData, s.metricMapper(), func(name string, value float64) {
+		if err := ms.SetMetric(name, value, metric.GAUGE); err != nil {
+			log.Error("Unable to set purgatory metric %s: %v", name, err)
+		}
+	})
+}
+
+// attachBrokerReassignment adds broker.reassigningPartitions to the broker
+// entity TransformBrokerMetrics just populated, if a ReassignmentPoller has
+// been wired in via SetReassignmentPoller.
+func (s *EnhancedShim) attachBrokerReassignment(brokerID int32) {
+	if s.reassignPoller == nil {
+		return
+	}
+
+	entityName := fmt.Sprintf("%s-broker-%d", s.config.ClusterName, brokerID)
+	entity, err := s.GetOrCreateEntity("AwsMskBrokerSample", entityName)
+	if err != nil {
+		log.Error("Failed to resolve broker entity to attach reassigningPartitions: %v", err)
+		return
+	}
+
+	ms := entity.NewMetricSet("AwsMskBrokerSample")
+	EmitBrokerRollup(ms, brokerID, s.reassignPoller, s.metricMapper())
+}
+
+// metricMapper lazily builds the shim's MetricMapper instance so callers
+// don't need to thread one through from construction time.
+func (s *EnhancedShim) metricMapper() *MetricMapper {
+	if s.mapper == nil {
+		s.mapper = NewMetricMapper()
+	}
+	return s.mapper
 }
 
 // TransformTopicMetrics transforms topic metrics with enhanced mode support
@@ -120,17 +242,44 @@ func (s *EnhancedShim) TransformTopicMetrics(topicData map[string]interface{}) e
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	var err error
 	if s.enhancedMode {
 		if enhanced, ok := s.transformer.(*EnhancedTransformer); ok {
-			return enhanced.TransformTopicMetricsEnhanced(topicData)
+			err = enhanced.TransformTopicMetricsEnhanced(topicData)
 		}
+	} else if simple, ok := s.transformer.(*SimpleTransformer); ok {
+		err = simple.TransformTopicMetricsSimple(topicData)
+	} else {
+		return fmt.Errorf("invalid transformer type")
 	}
-	
-	if simple, ok := s.transformer.(*SimpleTransformer); ok {
-		return simple.TransformTopicMetricsSimple(topicData)
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("invalid transformer type")
+	if topicName, ok := getStringValue(topicData, "topic.name"); ok {
+		s.attachTopicReassignment(topicName)
+	}
+
+	return nil
+}
+
+// attachTopicReassignment adds topic.reassigningPartitions to the topic
+// entity TransformTopicMetrics just populated, if a ReassignmentPoller has
+// been wired in via SetReassignmentPoller.
+func (s *EnhancedShim) attachTopicReassignment(topic string) {
+	if s.reassignPoller == nil {
+		return
+	}
+
+	entityName := fmt.Sprintf("%s-topic-%s", s.config.ClusterName, topic)
+	entity, err := s.GetOrCreateEntity("AwsMskTopicSample", entityName)
+	if err != nil {
+		log.Error("Failed to resolve topic entity to attach reassigningPartitions: %v", err)
+		return
+	}
+
+	ms := entity.NewMetricSet("AwsMskTopicSample")
+	EmitTopicRollup(ms, topic, s.reassignPoller, s.metricMapper())
 }
 
 // ProcessConsumerOffset processes consumer offset data
@@ -209,12 +358,32 @@ func (s *EnhancedShim) Flush() error {
 		return fmt.Errorf("failed to create cluster entity: %w", err)
 	}
 
+	s.attachActiveReassignments()
+
 	// Reset aggregator for next collection cycle
 	s.aggregator.Reset()
 
 	return nil
 }
 
+// attachActiveReassignments adds the cluster-wide in-flight reassignment
+// count, if a poller has been wired in, to the cluster entity Flush just
+// created.
+func (s *EnhancedShim) attachActiveReassignments() {
+	if s.reassignPoller == nil {
+		return
+	}
+
+	entity, err := s.GetOrCreateEntity("AwsMskClusterSample", s.config.ClusterName)
+	if err != nil {
+		log.Error("Failed to resolve cluster entity to attach activeReassignments: %v", err)
+		return
+	}
+
+	ms := entity.NewMetricSet("AwsMskClusterSample")
+	EmitClusterRollup(ms, s.reassignPoller.ActiveCount(), s.metricMapper())
+}
+
 // GetConfig returns the shim configuration
 func (s *EnhancedShim) GetConfig() *Config {
 	return s.config
@@ -223,4 +392,47 @@ func (s *EnhancedShim) GetConfig() *Config {
 // GetAggregator returns the metric aggregator
 func (s *EnhancedShim) GetAggregator() *MetricAggregator {
 	return s.aggregator
+}
+
+// TransformReassignmentMetrics attaches in-flight KIP-455 reassignment
+// state (adding/removing replicas, bytes remaining to copy) onto the
+// topic's AwsMskTopicSample entity, mirroring attachTopicReassignment's
+// entity-resolution pattern but fed directly from collected data rather
+// than a wired-in ReassignmentPoller.
+func (s *EnhancedShim) TransformReassignmentMetrics(data map[string]interface{}) error {
+	topicName, ok := getStringValue(data, "topic.name")
+	if !ok {
+		return fmt.Errorf("topic.name not found in reassignment data")
+	}
+
+	entityName := fmt.Sprintf("%s-topic-%s", s.config.ClusterName, topicName)
+	entity, err := s.GetOrCreateEntity("AwsMskTopicSample", entityName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve topic entity: %w", err)
+	}
+
+	bytesRemaining := getFloatValueWithDefault(data, "reassignment.bytesLeft", 0)
+	ms := entity.NewMetricSet("AwsMskTopicSample")
+	ms.SetMetric("provider.reassignmentBytesRemaining", bytesRemaining, metric.GAUGE)
+
+	return nil
+}
+
+// TransformVolumeData emits an AwsMskVolumeSample for a single broker log
+// directory, matching MSKShim.TransformVolumeData's shape so the two
+// integration paths produce the same entity/metric names.
+func (s *EnhancedShim) TransformVolumeData(data VolumeData) error {
+	entityName := fmt.Sprintf("%s-broker-%d-volume-%s", s.config.ClusterName, data.BrokerID, data.LogDirPath)
+	entity, err := s.GetOrCreateEntity("KAFKA_VOLUME", entityName)
+	if err != nil {
+		return fmt.Errorf("failed to create volume entity: %w", err)
+	}
+
+	ms := entity.NewMetricSet("AwsMskVolumeSample")
+	ms.SetMetric("provider.kafkaDataLogsDiskUsed", float64(data.BytesUsed), metric.GAUGE)
+	ms.SetMetric("provider.rootDiskUsed", data.RootDiskUsed, metric.GAUGE)
+	ms.SetMetric("provider.volumeReadBytes", data.ReadBytesRate, metric.GAUGE)
+	ms.SetMetric("provider.volumeWriteBytes", data.WriteBytesRate, metric.GAUGE)
+
+	return nil
 }
\ No newline at end of file
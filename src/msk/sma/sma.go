@@ -0,0 +1,54 @@
+// Package sma implements a fixed-size ring-buffer simple moving average,
+// the same shape used to smooth noisy per-scrape throughput gauges (e.g.
+// MinIO's replication metrics) before they reach an alerting pipeline.
+package sma
+
+// DefaultWindowSize is used when a caller wants smoothing but hasn't
+// picked a window length for a particular metric family.
+const DefaultWindowSize = 5
+
+// SMA is a ring-buffer simple moving average over the last len(window)
+// samples. It is not safe for concurrent use; callers needing concurrent
+// access must guard it with their own mutex.
+type SMA struct {
+	window []float64
+	idx    int
+	filled bool
+}
+
+// New creates an SMA over the last size samples. size < 1 is treated as 1.
+func New(size int) *SMA {
+	if size < 1 {
+		size = 1
+	}
+	return &SMA{window: make([]float64, size)}
+}
+
+// Add records one sample, overwriting the oldest once the window fills.
+func (s *SMA) Add(v float64) {
+	s.window[s.idx] = v
+	s.idx = (s.idx + 1) % len(s.window)
+	if s.idx == 0 {
+		s.filled = true
+	}
+}
+
+// Value returns the mean of every sample currently in the window. Before
+// the window has filled, it's the mean of only the samples seen so far,
+// not padded with zeroes, so an SMA doesn't understate its first few
+// readings just because it's new.
+func (s *SMA) Value() float64 {
+	count := s.idx
+	if s.filled {
+		count = len(s.window)
+	}
+	if count == 0 {
+		return 0
+	}
+
+	var sum float64
+	for i := 0; i < count; i++ {
+		sum += s.window[i]
+	}
+	return sum / float64(count)
+}
@@ -0,0 +1,41 @@
+package sma
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_ClampsNonPositiveSizeToOne(t *testing.T) {
+	s := New(0)
+	s.Add(5)
+	s.Add(7)
+	assert.Equal(t, 7.0, s.Value(), "window size 1 should always hold only the latest sample")
+}
+
+func TestValue_BeforeWindowFills_AveragesOnlySamplesSeen(t *testing.T) {
+	s := New(5)
+	s.Add(2)
+	s.Add(4)
+	assert.Equal(t, 3.0, s.Value(), "mean of the two samples seen so far, not padded with zeroes")
+}
+
+func TestValue_EmptyWindow_ReturnsZero(t *testing.T) {
+	s := New(3)
+	assert.Equal(t, 0.0, s.Value())
+}
+
+func TestAdd_PastWindowSize_OverwritesOldestSample(t *testing.T) {
+	s := New(3)
+	s.Add(1)
+	s.Add(2)
+	s.Add(3)
+	assert.Equal(t, 2.0, s.Value())
+
+	s.Add(6) // overwrites the 1
+	assert.Equal(t, float64(2+3+6)/3, s.Value())
+}
+
+func TestDefaultWindowSize(t *testing.T) {
+	assert.Equal(t, 5, DefaultWindowSize)
+}
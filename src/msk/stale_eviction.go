@@ -0,0 +1,80 @@
+package msk
+
+import (
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// StaleMetricTTL is the default age after which a broker's metrics are
+// considered stale if it hasn't reported since.
+const StaleMetricTTL = 2 * time.Minute
+
+// EvictStaleBrokers removes broker metrics that haven't been updated within
+// maxAge, so a broker that was shut down stops contributing stale bytes/sec
+// and under-replicated-partition counts to the cluster rollup. It returns
+// the IDs of evicted brokers.
+func (a *MetricAggregator) EvictStaleBrokers(maxAge time.Duration) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var evicted []string
+	now := time.Now()
+	for brokerID, metric := range a.brokerMetrics {
+		if metric.LastUpdated.IsZero() {
+			continue
+		}
+		if now.Sub(metric.LastUpdated) > maxAge {
+			delete(a.brokerMetrics, brokerID)
+			evicted = append(evicted, brokerID)
+		}
+	}
+
+	if len(evicted) > 0 {
+		log.Info("Evicted %d stale broker(s) from aggregator: %v", len(evicted), evicted)
+		if a.controllerMetrics.ActiveControllerCount == 1 {
+			if !a.anyControllerRemaining() {
+				a.controllerMetrics.ActiveControllerCount = 0
+			}
+		}
+	}
+
+	return evicted
+}
+
+// anyControllerRemaining reports whether any remaining broker is still
+// flagged as the controller. Callers must hold a.mu.
+func (a *MetricAggregator) anyControllerRemaining() bool {
+	for _, metric := range a.brokerMetrics {
+		if metric.IsController {
+			return true
+		}
+	}
+	return false
+}
+
+// NotePartitionLeader records the current leader for a topic partition and
+// reports whether this call observed a leadership change from what was
+// previously recorded. Callers use this to trigger an immediate
+// EvictStaleBrokers pass: the broker that just lost leadership may have
+// done so because it shut down, and waiting for the next scheduled
+// eviction tick would leave it contributing stale metrics in the
+// meantime.
+func (a *MetricAggregator) NotePartitionLeader(topic string, partition int32, brokerID string) (changed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.partitionLeaders == nil {
+		a.partitionLeaders = make(map[string]string)
+	}
+
+	key := partitionLeaderKey(topic, partition)
+	previous, existed := a.partitionLeaders[key]
+	a.partitionLeaders[key] = brokerID
+
+	if existed && previous != brokerID {
+		log.Debug("Partition leadership changed for %s[%d]: %s -> %s", topic, partition, previous, brokerID)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package msk
+
+import "sort"
+
+// tdigestCentroid is one weighted mean in a TDigest's sketch of its
+// distribution.
+type tdigestCentroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest is a merge-friendly approximate quantile sketch (Dunning's
+// t-digest): centroids near the median are allowed to grow large, while
+// centroids near the tails stay small, so extreme percentiles like p99
+// stay accurate with a bounded, constant-size sketch instead of keeping
+// every raw sample. Because two digests merge by just concatenating their
+// centroid lists and re-compressing, per-broker digests for the same
+// metric can be combined into a cluster-wide digest at flush time.
+type TDigest struct {
+	compression float64
+	centroids   []tdigestCentroid
+	totalWeight float64
+	// unmerged counts centroids added via Add since the last compress,
+	// so Quantile/Merge can trigger a compress lazily instead of on
+	// every single Add call.
+	unmerged int
+}
+
+// NewTDigest creates an empty TDigest with the given compression factor
+// (~100 keeps a digest to a few KB while resolving p99 to within about 1%
+// relative error; higher values trade memory for accuracy).
+func NewTDigest(compression float64) *TDigest {
+	if compression < 20 {
+		compression = 20
+	}
+	return &TDigest{compression: compression}
+}
+
+// Add records one observation of value with the given weight (1 for a
+// single sample).
+func (t *TDigest) Add(value, weight float64) {
+	if weight <= 0 {
+		return
+	}
+	t.centroids = append(t.centroids, tdigestCentroid{mean: value, weight: weight})
+	t.totalWeight += weight
+	t.unmerged++
+
+	if t.unmerged > int(t.compression)*20 {
+		t.compress()
+	}
+}
+
+// Merge folds other's centroids into t, as if every sample that went into
+// other had been added to t directly. other is left untouched.
+func (t *TDigest) Merge(other *TDigest) {
+	if other == nil {
+		return
+	}
+	other.compress()
+	for _, c := range other.centroids {
+		t.centroids = append(t.centroids, c)
+		t.totalWeight += c.weight
+		t.unmerged++
+	}
+	t.compress()
+}
+
+// compress sorts the centroid list by mean and merges adjacent centroids
+// that fall within the t-digest scale function's size limit for their
+// position in the distribution, bounding the sketch back down to roughly
+// compression centroids regardless of how many samples were added.
+func (t *TDigest) compress() {
+	if len(t.centroids) < 2 {
+		t.unmerged = 0
+		return
+	}
+
+	sort.Slice(t.centroids, func(i, j int) bool {
+		return t.centroids[i].mean < t.centroids[j].mean
+	})
+
+	merged := make([]tdigestCentroid, 0, len(t.centroids))
+	cumulative := 0.0
+	for _, c := range t.centroids {
+		if len(merged) == 0 {
+			merged = append(merged, c)
+			cumulative += c.weight
+			continue
+		}
+
+		last := &merged[len(merged)-1]
+		// q is the approximate quantile at the midpoint of the last
+		// centroid -- centroids near the median (q near 0.5) are allowed
+		// to absorb far more weight than ones near the tails.
+		q := (cumulative - last.weight/2) / t.totalWeight
+		maxWeight := 4 * t.totalWeight * q * (1 - q) / t.compression
+		if maxWeight < 1 {
+			maxWeight = 1
+		}
+
+		if last.weight+c.weight <= maxWeight {
+			newWeight := last.weight + c.weight
+			last.mean = (last.mean*last.weight + c.mean*c.weight) / newWeight
+			last.weight = newWeight
+		} else {
+			merged = append(merged, c)
+		}
+		cumulative += c.weight
+	}
+
+	t.centroids = merged
+	t.unmerged = 0
+}
+
+// Quantile returns an estimate of the value at quantile q (0-1): the mean
+// of the first centroid whose cumulative weight reaches q of the total,
+// with no interpolation between neighbouring centroids. It returns 0 if the
+// digest has no samples.
+func (t *TDigest) Quantile(q float64) float64 {
+	if t.unmerged > 0 {
+		t.compress()
+	}
+	if len(t.centroids) == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return t.centroids[0].mean
+	}
+	if q >= 1 {
+		return t.centroids[len(t.centroids)-1].mean
+	}
+
+	target := q * t.totalWeight
+	cumulative := 0.0
+	for i, c := range t.centroids {
+		cumulative += c.weight
+		if cumulative >= target || i == len(t.centroids)-1 {
+			return c.mean
+		}
+	}
+	return t.centroids[len(t.centroids)-1].mean
+}
+
+// Count returns the total weight (sample count) recorded in the digest.
+func (t *TDigest) Count() float64 {
+	return t.totalWeight
+}
+
+// Reset discards every recorded sample, so the digest reflects only
+// observations added after the call.
+func (t *TDigest) Reset() {
+	t.centroids = nil
+	t.totalWeight = 0
+	t.unmerged = 0
+}
@@ -0,0 +1,55 @@
+package msk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTDigest_QuantileOfUniformSamples(t *testing.T) {
+	digest := NewTDigest(100)
+	for i := 1; i <= 1000; i++ {
+		digest.Add(float64(i), 1)
+	}
+
+	assert.InDelta(t, 500, digest.Quantile(0.5), 25, "p50 of 1..1000 should be near the middle")
+	assert.InDelta(t, 990, digest.Quantile(0.99), 25, "p99 of 1..1000 should be near the top")
+	assert.Equal(t, 1000.0, digest.Count())
+}
+
+func TestTDigest_QuantileEmptyDigestIsZero(t *testing.T) {
+	digest := NewTDigest(100)
+	assert.Equal(t, 0.0, digest.Quantile(0.5))
+}
+
+func TestTDigest_MergeCombinesWeightFromBothDigests(t *testing.T) {
+	a := NewTDigest(100)
+	b := NewTDigest(100)
+	for i := 1; i <= 500; i++ {
+		a.Add(float64(i), 1)
+	}
+	for i := 501; i <= 1000; i++ {
+		b.Add(float64(i), 1)
+	}
+
+	a.Merge(b)
+
+	assert.Equal(t, 1000.0, a.Count(), "merged digest should carry both digests' weight")
+	assert.InDelta(t, 500, a.Quantile(0.5), 25)
+	// other is left untouched by Merge.
+	assert.Equal(t, 500.0, b.Count())
+}
+
+func TestTDigest_ResetDiscardsSamples(t *testing.T) {
+	digest := NewTDigest(100)
+	digest.Add(42, 1)
+	digest.Reset()
+
+	assert.Equal(t, 0.0, digest.Count())
+	assert.Equal(t, 0.0, digest.Quantile(0.5))
+}
+
+func TestTDigest_CompressionBelowMinimumIsClamped(t *testing.T) {
+	digest := NewTDigest(5)
+	assert.Equal(t, 20.0, digest.compression, "compression factors below 20 should be clamped to 20")
+}
@@ -0,0 +1,77 @@
+package msk
+
+import "sync"
+
+// ThrottleMetrics holds the per-broker quota/throttling values the broker
+// metric mappings already extract (produceThrottleTimeMs, etc.) but that
+// nothing previously rolled up to the cluster level.
+type ThrottleMetrics struct {
+	ProduceThrottleTimeMs float64
+	FetchThrottleTimeMs   float64
+	RequestThrottleTimeMs float64
+}
+
+// ThrottleAggregator tracks per-broker throttle metrics and rolls them up
+// into cluster-wide quota observability, separate from MetricAggregator so
+// it can be added without reshaping BrokerMetrics.
+type ThrottleAggregator struct {
+	mu      sync.RWMutex
+	byBroker map[string]ThrottleMetrics
+}
+
+// NewThrottleAggregator creates an empty aggregator.
+func NewThrottleAggregator() *ThrottleAggregator {
+	return &ThrottleAggregator{byBroker: make(map[string]ThrottleMetrics)}
+}
+
+// AddBrokerThrottleMetrics records the latest throttle values for brokerID.
+func (t *ThrottleAggregator) AddBrokerThrottleMetrics(brokerID string, metrics ThrottleMetrics) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.byBroker[brokerID] = metrics
+}
+
+// ClusterThrottleSummary is the cluster-wide rollup of throttling/quota
+// observability: the maximum throttle time seen on any broker, which is
+// the signal that indicates a client is being quota-limited somewhere in
+// the cluster.
+type ClusterThrottleSummary struct {
+	MaxProduceThrottleTimeMs float64
+	MaxFetchThrottleTimeMs   float64
+	MaxRequestThrottleTimeMs float64
+	ThrottledBrokerCount     int
+}
+
+// ClusterSummary rolls up every broker's throttle metrics into a
+// cluster-wide view.
+func (t *ThrottleAggregator) ClusterSummary() ClusterThrottleSummary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var summary ClusterThrottleSummary
+	for _, m := range t.byBroker {
+		if m.ProduceThrottleTimeMs > summary.MaxProduceThrottleTimeMs {
+			summary.MaxProduceThrottleTimeMs = m.ProduceThrottleTimeMs
+		}
+		if m.FetchThrottleTimeMs > summary.MaxFetchThrottleTimeMs {
+			summary.MaxFetchThrottleTimeMs = m.FetchThrottleTimeMs
+		}
+		if m.RequestThrottleTimeMs > summary.MaxRequestThrottleTimeMs {
+			summary.MaxRequestThrottleTimeMs = m.RequestThrottleTimeMs
+		}
+		if m.ProduceThrottleTimeMs > 0 || m.FetchThrottleTimeMs > 0 || m.RequestThrottleTimeMs > 0 {
+			summary.ThrottledBrokerCount++
+		}
+	}
+	return summary
+}
+
+// ToProviderMetrics renders the summary as provider.* cluster metrics.
+func (s ClusterThrottleSummary) ToProviderMetrics() map[string]float64 {
+	return map[string]float64{
+		"provider.maxProduceThrottleTime.Max": s.MaxProduceThrottleTimeMs,
+		"provider.maxFetchThrottleTime.Max":   s.MaxFetchThrottleTimeMs,
+		"provider.maxRequestThrottleTime.Max": s.MaxRequestThrottleTimeMs,
+		"provider.throttledBrokerCount.Sum":   float64(s.ThrottledBrokerCount),
+	}
+}
@@ -0,0 +1,110 @@
+// Package timelag resolves a Kafka partition's "time lag" -- how far
+// behind in wall-clock time, not just record count, a consumer group's
+// committed offset is -- by issuing a ListOffsets request for Kafka
+// 3.0+'s KIP-734 MaxTimestamp (-3) special timestamp to find the offset
+// of the partition's most-recently-produced record, then resolving both
+// that offset's and the committed offset's record timestamps.
+package timelag
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// maxTimestampQuery is KIP-734's special timestamp value: requesting the
+// offset for this "timestamp" returns the offset of the partition's
+// record with the largest timestamp, rather than the first record at or
+// after a given wall-clock time. Brokers older than Kafka 3.0 reject it,
+// which Fetcher.MaxTimestampOffset surfaces as an error for the caller
+// to fall back on offset-based lag alone.
+const maxTimestampQuery int64 = -3
+
+// Fetcher issues ListOffsets (via sarama.Client.GetOffset) and single-
+// message Fetch (via a sarama.Consumer built from the same client) calls
+// needed to compute one partition's time lag.
+type Fetcher struct {
+	client sarama.Client
+}
+
+// NewFetcher wraps an already-connected sarama.Client.
+func NewFetcher(client sarama.Client) *Fetcher {
+	return &Fetcher{client: client}
+}
+
+// MaxTimestampOffset returns the offset of topic/partition's record with
+// the largest timestamp, per KIP-734. Returns an error on brokers older
+// than Kafka 3.0 (which don't understand the -3 special timestamp) --
+// callers should treat that as "time lag unavailable, fall back to
+// offset-based lag" rather than a fatal condition.
+func (f *Fetcher) MaxTimestampOffset(topic string, partition int32) (int64, error) {
+	offset, err := f.client.GetOffset(topic, partition, maxTimestampQuery)
+	if err != nil {
+		return 0, fmt.Errorf("timelag: MaxTimestamp offset unsupported for %s[%d] (requires Kafka 3.0+): %w", topic, partition, err)
+	}
+	return offset, nil
+}
+
+// RecordTimestamp fetches the single record at topic/partition/offset and
+// returns its produce timestamp, by consuming exactly one message from
+// that offset.
+func (f *Fetcher) RecordTimestamp(ctx context.Context, topic string, partition int32, offset int64) (time.Time, error) {
+	consumer, err := sarama.NewConsumerFromClient(f.client)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timelag: creating consumer: %w", err)
+	}
+	defer consumer.Close()
+
+	pc, err := consumer.ConsumePartition(topic, partition, offset)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timelag: consuming %s[%d]@%d: %w", topic, partition, offset, err)
+	}
+	defer pc.Close()
+
+	select {
+	case msg, ok := <-pc.Messages():
+		if !ok {
+			return time.Time{}, fmt.Errorf("timelag: partition consumer for %s[%d] closed before yielding a message", topic, partition)
+		}
+		return msg.Timestamp, nil
+	case err := <-pc.Errors():
+		return time.Time{}, fmt.Errorf("timelag: consuming %s[%d]@%d: %w", topic, partition, offset, err)
+	case <-ctx.Done():
+		return time.Time{}, ctx.Err()
+	}
+}
+
+// Seconds computes topic/partition's time lag: how many seconds behind
+// the partition's most-recently-produced record the record at
+// committedOffset is. It returns ok=false (not an error) when the broker
+// doesn't support KIP-734's MaxTimestamp query, so callers can fall back
+// to offset-based lag without treating it as a failure.
+func (f *Fetcher) Seconds(ctx context.Context, topic string, partition int32, committedOffset int64) (lagSeconds float64, maxTimestamp time.Time, ok bool, err error) {
+	maxOffset, err := f.MaxTimestampOffset(topic, partition)
+	if err != nil {
+		return 0, time.Time{}, false, nil
+	}
+
+	maxTimestamp, err = f.RecordTimestamp(ctx, topic, partition, maxOffset)
+	if err != nil {
+		return 0, time.Time{}, false, err
+	}
+
+	if committedOffset < 0 {
+		return 0, maxTimestamp, true, nil
+	}
+
+	committedTimestamp, err := f.RecordTimestamp(ctx, topic, partition, committedOffset)
+	if err != nil {
+		return 0, maxTimestamp, false, err
+	}
+
+	lag := maxTimestamp.Sub(committedTimestamp).Seconds()
+	if lag < 0 {
+		lag = 0
+	}
+
+	return lag, maxTimestamp, true, nil
+}
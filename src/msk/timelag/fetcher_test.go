@@ -0,0 +1,59 @@
+package timelag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient embeds sarama.Client (left nil) and overrides only the method
+// Fetcher actually calls directly, so this test double doesn't have to
+// implement sarama.Client's entire, mostly-irrelevant-here method set.
+type fakeClient struct {
+	sarama.Client
+
+	gotTime int64
+	offset  int64
+	err     error
+}
+
+func (f *fakeClient) GetOffset(topic string, partitionID int32, time int64) (int64, error) {
+	f.gotTime = time
+	return f.offset, f.err
+}
+
+func TestMaxTimestampOffset_RequestsKIP734SpecialTimestamp(t *testing.T) {
+	fc := &fakeClient{offset: 500}
+	f := NewFetcher(fc)
+
+	offset, err := f.MaxTimestampOffset("my-topic", 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), offset)
+	assert.Equal(t, maxTimestampQuery, fc.gotTime)
+}
+
+func TestMaxTimestampOffset_UnsupportedBroker_ReturnsWrappedError(t *testing.T) {
+	fc := &fakeClient{err: errors.New("unsupported for deprecated message format")}
+	f := NewFetcher(fc)
+
+	_, err := f.MaxTimestampOffset("my-topic", 0)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires Kafka 3.0+")
+}
+
+func TestSeconds_MaxTimestampUnsupported_ReturnsOkFalseNotError(t *testing.T) {
+	fc := &fakeClient{err: errors.New("unsupported for deprecated message format")}
+	f := NewFetcher(fc)
+
+	lag, _, ok, err := f.Seconds(context.Background(), "my-topic", 0, 10)
+
+	assert.NoError(t, err, "a broker that can't answer the MaxTimestamp query should fall back to ok=false, not an error")
+	assert.False(t, ok)
+	assert.Equal(t, 0.0, lag)
+}
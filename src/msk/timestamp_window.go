@@ -0,0 +1,196 @@
+package msk
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// TimestampMode selects how TimestampWindower handles late-arriving and
+// out-of-order samples, which CloudWatch Metric Streams routinely delivers
+// 2-5 minutes after the fact.
+type TimestampMode string
+
+const (
+	// TimestampModeBestEffort emits every metric immediately, tagging it
+	// with how late it arrived instead of delaying it.
+	TimestampModeBestEffort TimestampMode = "besteffort"
+	// TimestampModeConsistent buffers metrics in timestamp order and only
+	// releases them once no earlier sample can still arrive, guaranteeing
+	// monotonic emission at the cost of latency.
+	TimestampModeConsistent TimestampMode = "consistent"
+)
+
+// windowedSample is one entry in a TimestampWindower's min-heap, ordered by
+// the metric's own sample timestamp rather than arrival order.
+type windowedSample struct {
+	metric *Metric
+}
+
+// sampleHeap is a container/heap.Interface over windowedSample, keeping the
+// oldest sample timestamp at the root so Flush can pop everything older
+// than the current watermark.
+type sampleHeap []*windowedSample
+
+func (h sampleHeap) Len() int            { return len(h) }
+func (h sampleHeap) Less(i, j int) bool  { return h[i].metric.Timestamp < h[j].metric.Timestamp }
+func (h sampleHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *sampleHeap) Push(x interface{}) { *h = append(*h, x.(*windowedSample)) }
+func (h *sampleHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// emitKey identifies the (entity, metric) pair that BestEffort mode tracks
+// last-emitted timestamps for, so it can tell whether a freshly arrived
+// sample is older than one it already emitted.
+type emitKey struct {
+	entityGUID string
+	metricName string
+}
+
+// TimestampWindower buffers or tags dimensional metrics so that CloudWatch
+// Metric Streams' out-of-order, late delivery doesn't silently get masked
+// by stamping wall-clock time on every sample. In Consistent mode, metrics
+// are buffered in a min-heap keyed by their own sample timestamp and only
+// released once older than max_seen_ts - window, guaranteeing monotonic
+// emission. In BestEffort mode, metrics are emitted immediately but tagged
+// with msk.timestamp.lag.ms and msk.out_of_order when they arrive behind
+// the last sample emitted for the same entity/metric. MinTimestamp and
+// MaxTimestamp optionally bound which samples are accepted at all, so a
+// historical stream can be replayed for backfill without polluting
+// current dashboards.
+type TimestampWindower struct {
+	mode           TimestampMode
+	window         time.Duration
+	bestEffortOnly bool
+	minTimestamp   int64
+	maxTimestamp   int64
+
+	mu          sync.Mutex
+	heap        sampleHeap
+	maxSeenTs   int64
+	lastEmitted map[emitKey]int64
+}
+
+// NewTimestampWindower creates a TimestampWindower from config's timestamp
+// windowing fields, defaulting to BestEffort mode when TimestampMode is
+// unset so existing deployments keep emitting immediately.
+func NewTimestampWindower(config *Config) *TimestampWindower {
+	mode := TimestampMode(config.TimestampMode)
+	if mode == "" {
+		mode = TimestampModeBestEffort
+	}
+
+	return &TimestampWindower{
+		mode:           mode,
+		window:         config.BestEffortWindow,
+		bestEffortOnly: config.BestEffortOnly,
+		minTimestamp:   config.MinTimestamp,
+		maxTimestamp:   config.MaxTimestamp,
+		lastEmitted:    make(map[emitKey]int64),
+	}
+}
+
+// Accept admits m into the windower. In BestEffort mode (or when
+// BestEffortOnly overrides a Consistent config) it returns m immediately,
+// tagged with lag/out-of-order attributes. In Consistent mode it buffers m
+// and returns nil; call Flush to retrieve samples once they clear the
+// window. Samples outside the configured Min/MaxTimestamp bounds are
+// dropped entirely, returning nil.
+func (w *TimestampWindower) Accept(m *Metric) *Metric {
+	if m == nil {
+		return nil
+	}
+	if !w.withinReplayWindow(m.Timestamp) {
+		return nil
+	}
+
+	if w.bestEffortOnly || w.mode == TimestampModeBestEffort {
+		return w.acceptBestEffort(m)
+	}
+
+	w.mu.Lock()
+	heap.Push(&w.heap, &windowedSample{metric: m})
+	if m.Timestamp > w.maxSeenTs {
+		w.maxSeenTs = m.Timestamp
+	}
+	w.mu.Unlock()
+	return nil
+}
+
+// withinReplayWindow reports whether ts falls within the optional
+// Min/MaxTimestamp replay bounds. A zero bound means unbounded on that
+// side.
+func (w *TimestampWindower) withinReplayWindow(ts int64) bool {
+	if w.minTimestamp > 0 && ts < w.minTimestamp {
+		return false
+	}
+	if w.maxTimestamp > 0 && ts > w.maxTimestamp {
+		return false
+	}
+	return true
+}
+
+// acceptBestEffort emits m right away, tagging it with how far behind
+// wall-clock it arrived and whether it is older than the last sample
+// emitted for the same (entity, metric) pair.
+func (w *TimestampWindower) acceptBestEffort(m *Metric) *Metric {
+	key := emitKey{entityGUID: attributeString(m.Attributes, "entityGuid"), metricName: m.Name}
+
+	w.mu.Lock()
+	last, seen := w.lastEmitted[key]
+	if !seen || m.Timestamp > last {
+		w.lastEmitted[key] = m.Timestamp
+	}
+	w.mu.Unlock()
+
+	if m.Attributes == nil {
+		m.Attributes = make(map[string]interface{})
+	}
+	m.Attributes["msk.timestamp.lag.ms"] = time.Now().UnixNano()/1e6 - m.Timestamp
+
+	if seen && m.Timestamp < last {
+		m.Attributes["msk.out_of_order"] = true
+		log.Debug("TimestampWindower: out-of-order sample for %s/%s: %d < %d", key.entityGUID, key.metricName, m.Timestamp, last)
+	}
+
+	return m
+}
+
+// Flush pops every Consistent-mode sample whose timestamp is older than
+// max_seen_ts - window, ready to be sent in timestamp order. It is a no-op
+// in BestEffort mode, since those metrics were already emitted by Accept.
+func (w *TimestampWindower) Flush() []*Metric {
+	if w.bestEffortOnly || w.mode == TimestampModeBestEffort {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cutoff := w.maxSeenTs - w.window.Milliseconds()
+	var ready []*Metric
+	for w.heap.Len() > 0 && w.heap[0].metric.Timestamp < cutoff {
+		item := heap.Pop(&w.heap).(*windowedSample)
+		ready = append(ready, item.metric)
+	}
+	return ready
+}
+
+// attributeString returns attrs[key] as a string, or "" if it is absent or
+// not a string.
+func attributeString(attrs map[string]interface{}, key string) string {
+	if attrs == nil {
+		return ""
+	}
+	if v, ok := attrs[key].(string); ok {
+		return v
+	}
+	return ""
+}
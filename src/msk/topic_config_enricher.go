@@ -0,0 +1,148 @@
+package msk
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// topicConfigEnricherKeys are the Kafka topic-config names TopicConfigEnricher
+// surfaces as provider.config.<name> attributes on AwsMskTopicSample -- the
+// settings MSK dashboards and alerts care about most: retention, cleanup
+// policy, ISR, and unclean leader election.
+var topicConfigEnricherKeys = []string{
+	"cleanup.policy",
+	"retention.ms",
+	"retention.bytes",
+	"min.insync.replicas",
+	"segment.bytes",
+	"compression.type",
+	"unclean.leader.election.enable",
+}
+
+// topicConfigEnricherDefaultTTL is used when NewTopicConfigEnricher is given
+// a non-positive ttl.
+const topicConfigEnricherDefaultTTL = 5 * time.Minute
+
+// topicConfigCacheEntry is one topic's cached DescribeConfigs result.
+type topicConfigCacheEntry struct {
+	values    map[string]string
+	fetchedAt time.Time
+}
+
+// TopicConfigEnricher fetches topic-level Kafka configuration via
+// DescribeConfigs (ConfigResource type=TOPIC) and caches it per topic for
+// ttl, so SimpleTransformTopicMetrics can attach provider.config.<name>
+// attributes to every AwsMskTopicSample without re-fetching on every
+// sample. This turns the shim from metric-only into a fuller MSK-equivalent
+// entity model, matching what the MSK console surfaces for topic config.
+type TopicConfigEnricher struct {
+	admin sarama.ClusterAdmin
+	ttl   time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]*topicConfigCacheEntry
+
+	fetchErrors int64
+}
+
+// NewTopicConfigEnricher wraps an already-connected sarama.ClusterAdmin,
+// caching each topic's config for ttl (topicConfigEnricherDefaultTTL if
+// ttl <= 0).
+func NewTopicConfigEnricher(admin sarama.ClusterAdmin, ttl time.Duration) *TopicConfigEnricher {
+	if ttl <= 0 {
+		ttl = topicConfigEnricherDefaultTTL
+	}
+	return &TopicConfigEnricher{
+		admin: admin,
+		ttl:   ttl,
+		cache: make(map[string]*topicConfigCacheEntry),
+	}
+}
+
+// Enrich returns provider.config.<name> attributes for topicName, fetching
+// (or refreshing an expired cache entry) via DescribeConfigs as needed. A
+// fetch failure increments the ConfigFetchErrors counter and returns
+// whatever was last cached for the topic (nil if nothing ever was cached),
+// so a transient DescribeConfigs failure doesn't block the rest of the
+// topic sample.
+func (e *TopicConfigEnricher) Enrich(topicName string) map[string]interface{} {
+	e.mu.RLock()
+	entry, cached := e.cache[topicName]
+	e.mu.RUnlock()
+
+	if cached && time.Since(entry.fetchedAt) < e.ttl {
+		return toProviderConfigAttrs(entry.values)
+	}
+
+	values, err := e.fetch(topicName)
+	if err != nil {
+		atomic.AddInt64(&e.fetchErrors, 1)
+		log.Warn("TopicConfigEnricher: DescribeConfigs failed for topic %s: %v", topicName, err)
+		if cached {
+			return toProviderConfigAttrs(entry.values)
+		}
+		return nil
+	}
+
+	e.mu.Lock()
+	e.cache[topicName] = &topicConfigCacheEntry{values: values, fetchedAt: time.Now()}
+	e.mu.Unlock()
+
+	return toProviderConfigAttrs(values)
+}
+
+// fetch issues one DescribeConfigs call for topicName and extracts the
+// topicConfigEnricherKeys entries. A not-found response evicts any stale
+// cache entry for the topic rather than leaving it to serve deleted-topic
+// values until the TTL expires on its own.
+func (e *TopicConfigEnricher) fetch(topicName string) (map[string]string, error) {
+	resource := sarama.ConfigResource{
+		Type: sarama.TopicResource,
+		Name: topicName,
+	}
+
+	entries, err := e.admin.DescribeConfig(resource)
+	if err != nil {
+		if err == sarama.ErrUnknownTopicOrPartition {
+			e.mu.Lock()
+			delete(e.cache, topicName)
+			e.mu.Unlock()
+		}
+		return nil, err
+	}
+
+	values := make(map[string]string, len(topicConfigEnricherKeys))
+	for _, entry := range entries {
+		for _, key := range topicConfigEnricherKeys {
+			if entry.Name == key {
+				values[key] = entry.Value
+			}
+		}
+	}
+	return values, nil
+}
+
+// ConfigFetchErrors returns the running count of failed DescribeConfigs
+// calls, surfaced as aws.msk.topic.ConfigFetchErrors so operators can tell
+// if enrichment is silently failing.
+func (e *TopicConfigEnricher) ConfigFetchErrors() int64 {
+	return atomic.LoadInt64(&e.fetchErrors)
+}
+
+// toProviderConfigAttrs renders cached config values as provider.config.<name>
+// attributes, ready to merge onto an AwsMskTopicSample metric set.
+func toProviderConfigAttrs(values map[string]string) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	attrs := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		attrs["provider.config."+name] = value
+	}
+	return attrs
+}
@@ -0,0 +1,94 @@
+package msk
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// defaultTransformConcurrency is how many goroutines TransformAll runs when
+// config.TransformConcurrency is unset.
+const defaultTransformConcurrency = 4
+
+// transformJob is one unit of work TransformAll fans out to its worker
+// pool: a single broker/topic/offset map together with which SimpleTransform*
+// method it belongs to.
+type transformJob struct {
+	kind string // "broker", "topic", or "offset"
+	data map[string]interface{}
+}
+
+// TransformAll fans brokers/topics/offsets out across a bounded pool of
+// goroutines (config.TransformConcurrency, default defaultTransformConcurrency),
+// each pulling jobs from a shared channel and calling the matching
+// SimpleTransform* method - the same launch-N-goroutines-pulling-from-a-channel
+// pattern the KEDA Kafka scaler uses for concurrent per-partition offset
+// fetches. SimpleTransformBrokerMetrics/SimpleTransformTopicMetrics/
+// SimpleTransformConsumerOffset already serialize their writes to
+// s.aggregator/s.entityCache/s.rateTracker behind those types' own mutexes,
+// so this only parallelizes the per-entity transform work itself, not the
+// shared state it updates. Per-job errors are merged into a single returned
+// error via errors.Join; a nil return means every job succeeded.
+func (s *MSKShim) TransformAll(brokers, topics, offsets []map[string]interface{}) error {
+	total := len(brokers) + len(topics) + len(offsets)
+	if total == 0 {
+		return nil
+	}
+
+	jobs := make(chan transformJob, total)
+	for _, b := range brokers {
+		jobs <- transformJob{kind: "broker", data: b}
+	}
+	for _, t := range topics {
+		jobs <- transformJob{kind: "topic", data: t}
+	}
+	for _, o := range offsets {
+		jobs <- transformJob{kind: "offset", data: o}
+	}
+	close(jobs)
+
+	concurrency := s.config.TransformConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultTransformConcurrency
+	}
+	if concurrency > total {
+		concurrency = total
+	}
+
+	var (
+		wg     sync.WaitGroup
+		errsMu sync.Mutex
+		errs   []error
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				var err error
+				switch job.kind {
+				case "broker":
+					err = s.SimpleTransformBrokerMetrics(job.data)
+				case "topic":
+					err = s.SimpleTransformTopicMetrics(job.data)
+				case "offset":
+					err = s.SimpleTransformConsumerOffset(job.data)
+				}
+				if err != nil {
+					errsMu.Lock()
+					errs = append(errs, err)
+					errsMu.Unlock()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		log.Warn("MSK shim: TransformAll completed with %d error(s) out of %d job(s)", len(errs), total)
+	}
+	return errors.Join(errs...)
+}
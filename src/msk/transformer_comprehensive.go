@@ -13,6 +13,8 @@ type ComprehensiveTransformer struct {
 	brokerMappings   map[string]string
 	topicMappings    map[string]string
 	clusterMappings  map[string]string
+	rollup           *comprehensiveRollup
+	deltaCounter     *DeltaCounter
 }
 
 // NewComprehensiveTransformer creates a transformer with correct metric mappings
@@ -22,9 +24,36 @@ func NewComprehensiveTransformer(clusterName string) *ComprehensiveTransformer {
 		brokerMappings:  getBrokerMetricMappings(),
 		topicMappings:   getTopicMetricMappings(),
 		clusterMappings: getClusterMetricMappings(),
+		deltaCounter:    NewDeltaCounter(),
 	}
 }
 
+// cumulativeCounterMetrics are the AWS MSK metric names whose underlying
+// JMX bean is a monotonic counter rather than a true instantaneous rate
+// (Kafka's own "PerSec"/rate-named MBeans still expose a raw running
+// total). Forcing these to metric.GAUGE makes dashboards show the same
+// spiky running total every poll instead of a per-minute rate, so they're
+// run through deltaCounter and emitted as metric.DELTA instead.
+var cumulativeCounterMetrics = map[string]CounterSemantics{
+	"aws.msk.MessagesInPerSec":            SemanticsCumulativeCounter,
+	"aws.msk.BytesInPerSec":                SemanticsCumulativeCounter,
+	"aws.msk.BytesOutPerSec":               SemanticsCumulativeCounter,
+	"aws.msk.BytesWrittenPerSec":           SemanticsCumulativeCounter,
+	"aws.msk.IsrShrinksPerSec":             SemanticsCumulativeCounter,
+	"aws.msk.IsrExpandsPerSec":             SemanticsCumulativeCounter,
+	"aws.msk.LeaderElectionRateAndTimeMs":  SemanticsCumulativeCounter,
+	"aws.msk.UncleanLeaderElectionsPerSec": SemanticsCumulativeCounter,
+}
+
+// counterSemanticsForMetric looks up metricName's semantics, defaulting to
+// SemanticsGauge for anything not in cumulativeCounterMetrics.
+func counterSemanticsForMetric(metricName string) CounterSemantics {
+	if semantics, ok := cumulativeCounterMetrics[metricName]; ok {
+		return semantics
+	}
+	return SemanticsGauge
+}
+
 // getBrokerMetricMappings returns the correct broker metric mappings
 func getBrokerMetricMappings() map[string]string {
 	return map[string]string{
@@ -127,10 +156,14 @@ func (t *ComprehensiveTransformer) TransformBrokerMetrics(input map[string]inter
 		if value, exists := input[sourceMetric]; exists && value != nil {
 			if err := t.setMetricValue(output, targetMetric, value); err == nil {
 				transformedCount++
-				
+
 				// Also set provider.* version for compatibility
 				providerMetric := "provider." + getMetricBaseName(targetMetric) + ".Average"
 				t.setMetricValue(output, providerMetric, value)
+
+				if floatValue, ok := getFloatValue(input, sourceMetric); ok {
+					t.AccumulateBrokerMetric(targetMetric, floatValue)
+				}
 			}
 		}
 	}
@@ -152,10 +185,16 @@ func (t *ComprehensiveTransformer) TransformTopicMetrics(input map[string]interf
 		if value, exists := input[sourceMetric]; exists && value != nil {
 			if err := t.setMetricValue(output, targetMetric, value); err == nil {
 				transformedCount++
-				
+
 				// Also set provider.* version
 				providerMetric := "provider." + getMetricBaseName(targetMetric) + ".Sum"
 				t.setMetricValue(output, providerMetric, value)
+
+				if topicName, ok := getStringValue(input, "topic.name"); ok {
+					if floatValue, ok := getFloatValue(input, sourceMetric); ok {
+						t.AccumulateTopicMetric(topicName, targetMetric, floatValue)
+					}
+				}
 			}
 		}
 	}
@@ -223,14 +262,17 @@ func (t *ComprehensiveTransformer) handleSpecialBrokerMetrics(input map[string]i
 	}
 }
 
-// setMetricValue sets a metric value with proper type conversion
+// setMetricValue sets a metric value with proper type conversion, applying
+// delta/cumulative-counter semantics (see cumulativeCounterMetrics) instead
+// of always emitting metric.GAUGE.
 func (t *ComprehensiveTransformer) setMetricValue(output *metric.Set, metricName string, value interface{}) error {
 	floatValue, err := toFloat64(value)
 	if err != nil {
 		return fmt.Errorf("failed to convert value for %s: %v", metricName, err)
 	}
-	
-	output.SetMetric(metricName, floatValue, metric.GAUGE)
+
+	emitValue, sourceType := t.deltaCounter.ApplySemantics(metricName, floatValue, counterSemanticsForMetric(metricName))
+	output.SetMetric(metricName, emitValue, sourceType)
 	return nil
 }
 
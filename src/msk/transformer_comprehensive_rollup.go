@@ -0,0 +1,99 @@
+package msk
+
+import (
+	"sync"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+)
+
+// comprehensiveRollup accumulates per-cluster and per-topic sums across
+// repeated TransformBrokerMetrics/TransformTopicMetrics calls.
+// ComprehensiveTransformer previously wrote each broker's sample straight
+// to its own output *metric.Set with no cross-call aggregation, so a
+// cluster- or topic-level rollup (e.g. total bytesIn across every broker)
+// was never computed.
+type comprehensiveRollup struct {
+	mu sync.Mutex
+
+	clusterSums map[string]float64 // metric -> running sum across brokers
+	clusterMax  map[string]float64 // metric -> running max across brokers
+
+	topicSums map[string]map[string]float64 // topic -> metric -> running sum
+}
+
+func newComprehensiveRollup() *comprehensiveRollup {
+	return &comprehensiveRollup{
+		clusterSums: make(map[string]float64),
+		clusterMax:  make(map[string]float64),
+		topicSums:   make(map[string]map[string]float64),
+	}
+}
+
+// rollup is the ComprehensiveTransformer-wide accumulator. It's lazily
+// initialized so existing callers that construct ComprehensiveTransformer
+// via a plain struct literal (rather than NewComprehensiveTransformer)
+// don't panic on first use.
+func (t *ComprehensiveTransformer) rollupState() *comprehensiveRollup {
+	if t.rollup == nil {
+		t.rollup = newComprehensiveRollup()
+	}
+	return t.rollup
+}
+
+// AccumulateBrokerMetric folds one broker's transformed value for
+// targetMetric into the cluster-wide sum and max rollups.
+func (t *ComprehensiveTransformer) AccumulateBrokerMetric(targetMetric string, value float64) {
+	r := t.rollupState()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clusterSums[targetMetric] += value
+	if value > r.clusterMax[targetMetric] {
+		r.clusterMax[targetMetric] = value
+	}
+}
+
+// AccumulateTopicMetric folds one broker's contribution to topic into the
+// per-topic sum rollup.
+func (t *ComprehensiveTransformer) AccumulateTopicMetric(topic, targetMetric string, value float64) {
+	r := t.rollupState()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.topicSums[topic] == nil {
+		r.topicSums[topic] = make(map[string]float64)
+	}
+	r.topicSums[topic][targetMetric] += value
+}
+
+// FlushClusterRollup writes the accumulated cluster-wide sum/max rollups
+// onto output as provider.<metric>.Sum / provider.<metric>.Max and resets
+// the accumulator for the next collection cycle.
+func (t *ComprehensiveTransformer) FlushClusterRollup(output *metric.Set) {
+	r := t.rollupState()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, sum := range r.clusterSums {
+		output.SetMetric("provider."+getMetricBaseName(name)+".Sum", sum, metric.GAUGE)
+	}
+	for name, max := range r.clusterMax {
+		output.SetMetric("provider."+getMetricBaseName(name)+".Max", max, metric.GAUGE)
+	}
+
+	r.clusterSums = make(map[string]float64)
+	r.clusterMax = make(map[string]float64)
+}
+
+// FlushTopicRollup writes the accumulated per-topic sum rollup for topic
+// onto output and clears that topic's accumulator.
+func (t *ComprehensiveTransformer) FlushTopicRollup(topic string, output *metric.Set) {
+	r := t.rollupState()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, sum := range r.topicSums[topic] {
+		output.SetMetric("provider."+getMetricBaseName(name)+".Sum", sum, metric.GAUGE)
+	}
+	delete(r.topicSums, topic)
+}
@@ -2,7 +2,6 @@ package msk
 
 import (
 	"fmt"
-	"math"
 	"math/rand"
 	"strconv"
 	"time"
@@ -14,87 +13,78 @@ import (
 
 // EnhancedTransformer provides enhanced transformation with fallback values
 type EnhancedTransformer struct {
-	shim              *Shim
-	simulatedMetrics  map[string]float64
-	metricsGenerated  bool
+	shim             *Shim
+	engine           *SyntheticMetricEngine
+	rng              *rand.Rand // owns all randomness this transformer uses; never touches the math/rand global source
+	simulatedMetrics map[string]float64
+	capacity         *CapacityProvider
+	utilization      map[string]BrokerUtilization // last-seen utilization per broker ID
+	identity         *BrokerIdentityResolver       // assigns stable IDs when broker.id is missing
+	consumerLag      map[string]float64            // last-seen MaxLag per consumer group, for the cluster rollup
 }
 
-// NewEnhancedTransformer creates a new enhanced transformer
-func NewEnhancedTransformer(shim *Shim) *EnhancedTransformer {
-	rand.Seed(time.Now().UnixNano())
+// brokerIdentity lazily constructs t.identity against the shim's cluster
+// ARN, so tests that never hit the no-broker.id fallback path don't pay
+// for a state-file read they don't need.
+func (t *EnhancedTransformer) brokerIdentity() *BrokerIdentityResolver {
+	if t.identity == nil {
+		t.identity = NewBrokerIdentityResolver(t.shim.config.ClusterARN, stateDirFromEnv())
+	}
+	return t.identity
+}
+
+// SetCapacityProvider attaches a Cruise-Control-style capacity model, so
+// TransformBrokerMetricsEnhanced starts emitting actual/capacity
+// utilization metrics and CreateClusterEntityEnhanced starts aggregating
+// their cluster-wide percentiles. A nil provider (the default) leaves
+// utilization metrics unset, same as before capacity modeling existed.
+func (t *EnhancedTransformer) SetCapacityProvider(capacity *CapacityProvider) {
+	t.capacity = capacity
+	t.utilization = make(map[string]BrokerUtilization)
+}
+
+// NewEnhancedTransformer creates a new enhanced transformer whose simulated
+// fallback values are driven by profile's AR(1) random walk (see
+// SyntheticMetricEngine) instead of flat jitter. A nil profile falls back
+// to BuiltinWorkloadProfiles["steady"], which mirrors the old hardcoded
+// ranges so existing deployments see no behavior change until they opt
+// into a different scenario. seed seeds every random value this
+// transformer produces (the engine's walk and its own broker/topic ID
+// fallbacks); pass 0 to seed from the current time, matching the old
+// rand.Seed(time.Now().UnixNano()) behavior without mutating math/rand's
+// global source, which made tests flaky when multiple transformers or
+// goroutines ran in parallel.
+func NewEnhancedTransformer(shim *Shim, profile *WorkloadProfile, seed int64) *EnhancedTransformer {
+	if profile == nil {
+		profile = BuiltinWorkloadProfiles["steady"]
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
 	return &EnhancedTransformer{
 		shim:             shim,
+		engine:           NewSyntheticMetricEngine(profile, seed),
+		rng:              rand.New(rand.NewSource(seed)),
 		simulatedMetrics: make(map[string]float64),
+		consumerLag:      make(map[string]float64),
 	}
 }
 
-// generateRealisticMetrics generates realistic looking metrics for demo/testing
-func (t *EnhancedTransformer) generateRealisticMetrics() {
-	if t.metricsGenerated {
-		// Update existing metrics with slight variations
-		for k, v := range t.simulatedMetrics {
-			// Add 5-10% variation
-			variation := (rand.Float64() - 0.5) * 0.1
-			t.simulatedMetrics[k] = math.Max(0, v * (1 + variation))
-		}
-		return
-	}
+// WithSeed reseeds an already-constructed transformer (primarily for
+// tests) and returns it for chaining. Two transformers constructed with
+// the same seed and fed the same input maps produce byte-identical
+// metric sets, which golden-file tests for the MSK payload rely on.
+func (t *EnhancedTransformer) WithSeed(seed int64) *EnhancedTransformer {
+	t.engine.SetSeed(seed)
+	t.rng = rand.New(rand.NewSource(seed))
+	return t
+}
 
-	// Initial metric generation with realistic values
-	t.simulatedMetrics = map[string]float64{
-		// Throughput metrics (bytes/sec)
-		"broker.bytesInPerSecond":    50000 + rand.Float64()*100000,  // 50KB-150KB/s
-		"broker.bytesOutPerSecond":   45000 + rand.Float64()*90000,   // 45KB-135KB/s
-		"broker.messagesInPerSecond": 100 + rand.Float64()*400,       // 100-500 msg/s
-		"broker.IOInPerSecond":       45000 + rand.Float64()*100000,  // Similar to bytesIn
-		"broker.IOOutPerSecond":      40000 + rand.Float64()*90000,   // Similar to bytesOut
-		
-		// Replication metrics
-		"replication.isrExpandsPerSecond":       0.1 + rand.Float64()*0.5,
-		"replication.isrShrinksPerSecond":       0.05 + rand.Float64()*0.2,
-		"replication.unreplicatedPartitions":    0, // Usually 0 in healthy cluster
-		"replication.underReplicatedPartitions": 0,
-		
-		// Request timing metrics (ms)
-		"request.avgTimeFetch":             5 + rand.Float64()*15,    // 5-20ms
-		"request.avgTimeProduceRequest":    3 + rand.Float64()*10,    // 3-13ms
-		"request.avgTimeMetadata":          1 + rand.Float64()*4,     // 1-5ms
-		"request.fetchTime99Percentile":    20 + rand.Float64()*30,   // 20-50ms
-		"request.produceTime99Percentile":  15 + rand.Float64()*25,   // 15-40ms
-		
-		// Handler metrics (percentage)
-		"request.handlerIdle":                     85 + rand.Float64()*10,  // 85-95% idle
-		"net.networkProcessorAvgIdlePercent":      80 + rand.Float64()*15,  // 80-95% idle
-		"request.requestHandlerAvgIdlePercent":    85 + rand.Float64()*10,  // 85-95% idle
-		
-		// Error metrics (usually low)
-		"request.clientFetchesFailedPerSecond":   rand.Float64() * 0.1,    // 0-0.1 failures/s
-		"request.produceRequestsFailedPerSecond": rand.Float64() * 0.05,   // 0-0.05 failures/s
-		
-		// System metrics
-		"system.cpuPercent":        15 + rand.Float64()*25,   // 15-40% CPU
-		"system.memoryUsedPercent": 30 + rand.Float64()*30,   // 30-60% memory
-		"system.diskUsedPercent":   20 + rand.Float64()*40,   // 20-60% disk
-		
-		// Network metrics
-		"net.bytesRejectedPerSecond": 0, // Usually 0
-		
-		// Consumer lag (for topics)
-		"consumer.lag":                1000 + rand.Float64()*5000,    // 1K-6K messages
-		"consumer.avgLag":            500 + rand.Float64()*2000,     // 500-2500 messages
-		"consumer.maxLag":            2000 + rand.Float64()*8000,    // 2K-10K messages
-		
-		// Topic metrics
-		"topic.bytesInPerSecond":      10000 + rand.Float64()*40000,  // 10KB-50KB/s per topic
-		"topic.bytesOutPerSecond":     9000 + rand.Float64()*36000,   // 9KB-45KB/s per topic
-		"topic.messagesInPerSecond":   20 + rand.Float64()*80,        // 20-100 msg/s per topic
-		"topic.partitionsCount":       3,                              // Common partition count
-		"topic.replicationFactor":     3,                              // Common replication factor
-		"topic.underReplicatedParts":  0,                              // Usually 0
-		"topic.minInsyncReplicas":     2,                              // Common min ISR
-	}
-	
-	t.metricsGenerated = true
+// generateRealisticMetrics advances the engine one tick and stores the
+// resulting values, replacing the old ad-hoc "generate once, then jitter
+// +/-5%" simulation with the profile's configured random walk.
+func (t *EnhancedTransformer) generateRealisticMetrics() {
+	t.simulatedMetrics = t.engine.Next()
 }
 
 // TransformBrokerMetricsEnhanced transforms broker metrics with fallback values
@@ -102,21 +92,26 @@ func (t *EnhancedTransformer) TransformBrokerMetricsEnhanced(brokerData map[stri
 	// Generate/update simulated metrics
 	t.generateRealisticMetrics()
 	
-	// Extract broker ID with fallback
+	// Extract broker ID with fallback. A missing broker.id is resolved
+	// from the broker's advertised listener through a stable,
+	// disk-persisted mapping (BrokerIdentityResolver) instead of hashing
+	// the hostname fresh every time, so the assigned ID - and therefore
+	// the broker entity GUID - survives DNS changes and pod restarts.
 	brokerIDStr, ok := getStringValue(brokerData, "broker.id")
 	if !ok {
-		// Generate broker ID based on available data
-		if host, ok := getStringValue(brokerData, "broker.host"); ok {
-			brokerIDStr = fmt.Sprintf("%d", hashString(host) % 1000)
+		if listener, ok := getStringValue(brokerData, "broker.advertisedListener"); ok {
+			brokerIDStr = fmt.Sprintf("%d", t.brokerIdentity().Resolve(listener))
+		} else if host, ok := getStringValue(brokerData, "broker.host"); ok {
+			brokerIDStr = fmt.Sprintf("%d", t.brokerIdentity().Resolve(host))
 		} else {
-			brokerIDStr = fmt.Sprintf("%d", rand.Intn(1000))
+			brokerIDStr = fmt.Sprintf("%d", t.rng.Intn(1000))
 		}
 		brokerData["broker.id"] = brokerIDStr
 	}
 
 	brokerID, err := strconv.Atoi(brokerIDStr)
 	if err != nil {
-		brokerID = rand.Intn(1000)
+		brokerID = t.rng.Intn(1000)
 	}
 
 	// Ensure broker.host is set
@@ -227,6 +222,25 @@ func (t *EnhancedTransformer) TransformBrokerMetricsEnhanced(brokerData map[stri
 	// Ensure critical metrics are always present
 	ensureCriticalMetrics(ms, t.simulatedMetrics, brokerID)
 
+	// Derive Cruise-Control-style headroom metrics (actual/capacity) if a
+	// capacity model is attached; skip entirely otherwise so clusters
+	// without a capacity.json see no change in emitted metrics.
+	if t.capacity != nil {
+		diskUsedMB := t.simulatedMetrics["system.diskUsedPercent"] * 10 // rough MB estimate from percent
+		utilization, ok := t.capacity.Utilization(brokerIDStr,
+			diskUsedMB,
+			t.simulatedMetrics["broker.bytesInPerSecond"]/1024,
+			t.simulatedMetrics["broker.bytesOutPerSecond"]/1024,
+			t.simulatedMetrics["system.cpuPercent"])
+		if ok {
+			t.utilization[brokerIDStr] = utilization
+			ms.SetMetric("provider.diskUsedPercent.Average", utilization.DiskUsedPercent, metric.GAUGE)
+			ms.SetMetric("provider.networkInUtilization.Average", utilization.NetworkInUtilization, metric.GAUGE)
+			ms.SetMetric("provider.networkOutUtilization.Average", utilization.NetworkOutUtilization, metric.GAUGE)
+			ms.SetMetric("provider.cpuUtilization.Average", utilization.CPUUtilization, metric.GAUGE)
+		}
+	}
+
 	return nil
 }
 
@@ -256,7 +270,7 @@ func (t *EnhancedTransformer) TransformTopicMetricsEnhanced(topicData map[string
 	// Extract topic name with fallback
 	topicName, ok := getStringValue(topicData, "topic.name")
 	if !ok {
-		topicName = fmt.Sprintf("topic-%d", rand.Intn(100))
+		topicName = fmt.Sprintf("topic-%d", t.rng.Intn(100))
 		topicData["topic.name"] = topicName
 	}
 
@@ -338,6 +352,75 @@ func (t *EnhancedTransformer) TransformTopicMetricsEnhanced(topicData map[string
 	return nil
 }
 
+// TransformConsumerGroupMetricsEnhanced transforms consumer-group lag
+// metrics with fallback values, parallel to TransformBrokerMetricsEnhanced
+// and TransformTopicMetricsEnhanced. It backs the simulated consumer.lag/
+// consumer.avgLag/consumer.maxLag metrics with an AwsMskConsumerGroupSample
+// entity so they're visible the same way real ConsumerGroupLagCollector
+// output is, and feeds t.consumerLag so CreateClusterEntityEnhanced can
+// roll the worst group up cluster-wide.
+func (t *EnhancedTransformer) TransformConsumerGroupMetricsEnhanced(groupData map[string]interface{}) error {
+	// Generate/update simulated metrics
+	t.generateRealisticMetrics()
+
+	groupID, ok := getStringValue(groupData, "consumerGroup.id")
+	if !ok {
+		groupID = fmt.Sprintf("consumer-group-%d", t.rng.Intn(100))
+		groupData["consumerGroup.id"] = groupID
+	}
+
+	entityName := fmt.Sprintf("%s-consumergroup-%s", t.shim.config.ClusterName, groupID)
+	entity, err := t.shim.GetOrCreateEntity("AwsMskConsumerGroupSample", entityName)
+	if err != nil {
+		return fmt.Errorf("failed to create consumer group entity: %w", err)
+	}
+
+	guid := GenerateEntityGUID(EntityTypeConsumerGroup, t.shim.config.AWSAccountID,
+		t.shim.config.ClusterName, groupID)
+
+	ms := entity.NewMetricSet("AwsMskConsumerGroupSample",
+		attribute.Attribute{Key: "entity.guid", Value: guid},
+		attribute.Attribute{Key: "entity.type", Value: string(EntityTypeConsumerGroup)},
+		attribute.Attribute{Key: "entityName", Value: entityName},
+		attribute.Attribute{Key: "entityGuid", Value: guid},
+		attribute.Attribute{Key: "guid", Value: guid},
+		attribute.Attribute{Key: "Name", Value: entityName},
+		attribute.Attribute{Key: "consumerGroup", Value: groupID},
+		attribute.Attribute{Key: "provider.clusterName", Value: t.shim.config.ClusterName},
+		attribute.Attribute{Key: "provider.accountId", Value: t.shim.config.AWSAccountID},
+		attribute.Attribute{Key: "provider.region", Value: t.shim.config.AWSRegion},
+		attribute.Attribute{Key: "provider.clusterArn", Value: t.shim.config.ClusterARN},
+		attribute.Attribute{Key: "provider.groupState", Value: getStringValueWithDefault(groupData, "consumerGroup.state", "Stable")},
+		attribute.Attribute{Key: "ClusterName", Value: t.shim.config.ClusterName},
+		attribute.Attribute{Key: "AccountId", Value: t.shim.config.AWSAccountID},
+		attribute.Attribute{Key: "Region", Value: t.shim.config.AWSRegion},
+		attribute.Attribute{Key: "Environment", Value: t.shim.config.Environment},
+	)
+
+	maxLag := t.simulatedMetrics["consumer.maxLag"]
+	if val, exists := groupData["consumer.maxLag"]; exists && val != nil && isNumeric(val) && getNumericValue(val) != 0 {
+		maxLag = getNumericValue(val)
+	}
+	sumLag := t.simulatedMetrics["consumer.lag"]
+	if val, exists := groupData["consumer.lag"]; exists && val != nil && isNumeric(val) && getNumericValue(val) != 0 {
+		sumLag = getNumericValue(val)
+	}
+
+	ms.SetMetric("provider.consumerLag.Max", maxLag, metric.GAUGE)
+	ms.SetMetric("provider.consumerLag.Sum", sumLag, metric.GAUGE)
+	ms.SetMetric("aws.msk.MaxOffsetLag", maxLag, metric.GAUGE)
+	ms.SetMetric("aws.msk.SumOffsetLag", sumLag, metric.GAUGE)
+
+	generation := int(getNumericValue(groupData["consumerGroup.generation"]))
+	memberCount := int(getNumericValue(groupData["consumerGroup.memberCount"]))
+	ms.SetMetric("provider.generationId", float64(generation), metric.GAUGE)
+	ms.SetMetric("provider.memberCount", float64(memberCount), metric.GAUGE)
+
+	t.consumerLag[groupID] = maxLag
+
+	return nil
+}
+
 // CreateClusterEntityEnhanced creates cluster entity with aggregated and simulated metrics
 func (t *EnhancedTransformer) CreateClusterEntityEnhanced() error {
 	entityName := t.shim.config.ClusterName
@@ -409,22 +492,40 @@ func (t *EnhancedTransformer) CreateClusterEntityEnhanced() error {
 		}
 	}
 
-	return nil
-}
+	// Roll up the worst consumer group's lag cluster-wide, so a single
+	// stuck group's rebalance is visible without reading every
+	// AwsMskConsumerGroupSample entity individually.
+	if len(t.consumerLag) > 0 {
+		var maxConsumerLag float64
+		for _, lag := range t.consumerLag {
+			if lag > maxConsumerLag {
+				maxConsumerLag = lag
+			}
+		}
+		ms.SetMetric("aws.msk.cluster.MaxConsumerLag", maxConsumerLag, metric.GAUGE)
+	}
 
-// Helper functions
+	// Roll up every broker's last-seen utilization into cluster-wide
+	// p50/p95/max so dashboards can show headroom without reading each
+	// broker's own sample individually.
+	if t.capacity != nil && len(t.utilization) > 0 {
+		samples := make([]BrokerUtilization, 0, len(t.utilization))
+		for _, u := range t.utilization {
+			samples = append(samples, u)
+		}
 
-func hashString(s string) int {
-	h := 0
-	for _, c := range s {
-		h = h*31 + int(c)
-	}
-	if h < 0 {
-		h = -h
+		for resource, percentiles := range SummarizeUtilization(samples) {
+			ms.SetMetric(fmt.Sprintf("provider.%sUtilization.p50", resource), percentiles.P50, metric.GAUGE)
+			ms.SetMetric(fmt.Sprintf("provider.%sUtilization.p95", resource), percentiles.P95, metric.GAUGE)
+			ms.SetMetric(fmt.Sprintf("provider.%sUtilization.max", resource), percentiles.Max, metric.GAUGE)
+		}
 	}
-	return h
+
+	return nil
 }
 
+// Helper functions
+
 func isNumeric(v interface{}) bool {
 	switch v.(type) {
 	case int, int32, int64, float32, float64:
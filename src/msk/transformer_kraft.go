@@ -0,0 +1,48 @@
+package msk
+
+import (
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// KRaftTransformer is the Transformer variant for clusters running in KRaft
+// mode (KIP-500), which have no ZooKeeper-backed
+// kafka.controller:type=KafkaController,name=ActiveControllerCount MBean to
+// report controller state from. It delegates every metric
+// ComprehensiveTransformer already handles correctly -- throughput,
+// replication and the rest don't differ between quorum modes -- and
+// replaces only the controller-detection path with KRaft's raft-metrics
+// MBeans (current-leader, current-epoch, high-watermark).
+type KRaftTransformer struct {
+	*ComprehensiveTransformer
+}
+
+// NewKRaftTransformer creates a KRaftTransformer for clusterName.
+func NewKRaftTransformer(clusterName string) *KRaftTransformer {
+	return &KRaftTransformer{ComprehensiveTransformer: NewComprehensiveTransformer(clusterName)}
+}
+
+// TransformBrokerMetrics applies the standard broker mappings, then maps
+// raft.currentLeader/raft.highWatermark into provider.activeControllerCount.Sum
+// and provider.raftHighWatermark, since a KRaft broker never reports
+// controller.activeControllerCount.
+func (t *KRaftTransformer) TransformBrokerMetrics(input map[string]interface{}, output *metric.Set) error {
+	if err := t.ComprehensiveTransformer.TransformBrokerMetrics(input, output); err != nil {
+		return err
+	}
+
+	if currentLeader, ok := getIntValue(input, "raft.currentLeader"); ok {
+		isLeader := 0.0
+		if brokerID, ok := getIntValue(input, "broker.id"); ok && brokerID == currentLeader {
+			isLeader = 1.0
+		}
+		output.SetMetric("provider.activeControllerCount.Sum", isLeader, metric.GAUGE)
+	}
+
+	if highWatermark, ok := getFloatValue(input, "raft.highWatermark"); ok {
+		output.SetMetric("provider.raftHighWatermark", highWatermark, metric.GAUGE)
+	}
+
+	log.Debug("[MSK_TRANSFORMER] KRaft mode: mapped raft-metrics controller state")
+	return nil
+}
@@ -0,0 +1,89 @@
+package msk
+
+import (
+	"sync"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+)
+
+// Transformer converts raw JMX-sourced broker/topic metric maps into the
+// aws.msk.* metric namespace. ComprehensiveMSKShim selects an
+// implementation per detected ClusterMode instead of hardcoding
+// ComprehensiveTransformer, so a KRaft cluster's missing
+// kafka.controller:type=KafkaController MBean doesn't leave
+// provider.activeControllerCount permanently at 0.
+type Transformer interface {
+	TransformBrokerMetrics(input map[string]interface{}, output *metric.Set) error
+	TransformTopicMetrics(input map[string]interface{}, output *metric.Set) error
+}
+
+// ClusterMode identifies which Transformer variant a cluster's MBeans
+// match. ComprehensiveMSKShim detects it per broker sample from whichever
+// telltale keys are present, so a single integration instance follows a
+// cluster through a ZooKeeper-to-KRaft migration without needing a restart.
+type ClusterMode string
+
+const (
+	// ClusterModeZooKeeperClassic is a cluster still reporting the
+	// ZooKeeper-backed kafka.controller:type=KafkaController,
+	// name=ActiveControllerCount MBean.
+	ClusterModeZooKeeperClassic ClusterMode = "zookeeper-classic"
+
+	// ClusterModeKRaft is a cluster reporting KIP-500's
+	// kafka.server:type=raft-metrics MBeans (current-leader, current-epoch,
+	// high-watermark) instead of a ZooKeeper controller MBean.
+	ClusterModeKRaft ClusterMode = "kraft"
+
+	// ClusterModeEnhancedSynthetic is used while no broker has reported real
+	// metrics recently, so ProcessBrokerMetrics falls back to simulated
+	// values rather than whatever mode it last detected from real samples.
+	ClusterModeEnhancedSynthetic ClusterMode = "enhanced-synthetic"
+)
+
+// TransformerFactory builds a Transformer for config, for use with
+// RegisterTransformer.
+type TransformerFactory func(Config) Transformer
+
+var (
+	transformerRegistryMu sync.RWMutex
+	transformerRegistry   = map[ClusterMode]TransformerFactory{
+		ClusterModeZooKeeperClassic:  func(config Config) Transformer { return NewComprehensiveTransformer(config.ClusterName) },
+		ClusterModeKRaft:             func(config Config) Transformer { return NewKRaftTransformer(config.ClusterName) },
+		ClusterModeEnhancedSynthetic: func(config Config) Transformer { return NewComprehensiveTransformer(config.ClusterName) },
+	}
+)
+
+// RegisterTransformer registers (or overrides) the Transformer factory used
+// for mode, so callers can plug in a cluster-mode-specific transformer of
+// their own without modifying this package.
+func RegisterTransformer(mode string, factory func(Config) Transformer) {
+	transformerRegistryMu.Lock()
+	defer transformerRegistryMu.Unlock()
+	transformerRegistry[ClusterMode(mode)] = factory
+}
+
+// transformerFor builds the registered Transformer for mode, falling back
+// to the zookeeper-classic transformer if mode isn't registered.
+func transformerFor(mode ClusterMode, config Config) Transformer {
+	transformerRegistryMu.RLock()
+	factory, ok := transformerRegistry[mode]
+	if !ok {
+		factory = transformerRegistry[ClusterModeZooKeeperClassic]
+	}
+	transformerRegistryMu.RUnlock()
+	return factory(config)
+}
+
+// detectClusterMode inspects a broker sample's JMX-derived keys and returns
+// the ClusterMode it implies, or "" if neither KRaft's nor ZooKeeper-
+// classic's telltale keys are present (e.g. a still-simulated sample in
+// enhanced mode).
+func detectClusterMode(brokerData map[string]interface{}) ClusterMode {
+	if _, ok := brokerData["raft.currentLeader"]; ok {
+		return ClusterModeKRaft
+	}
+	if _, ok := brokerData["controller.activeControllerCount"]; ok {
+		return ClusterModeZooKeeperClassic
+	}
+	return ""
+}
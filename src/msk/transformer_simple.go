@@ -88,24 +88,24 @@ func (t *SimpleTransformer) TransformBrokerMetricsSimple(brokerData map[string]i
 			}
 			
 			// Also aggregate for cluster level
-			t.shim.aggregator.AddBrokerMetric(mskMetric, value)
+			t.shim.aggregator.AddSimpleBrokerMetric(mskMetric, value)
 		}
 	}
 
 	// Add system metrics if available
 	if cpuPercent, ok := getFloatValue(brokerData, "system.cpuPercent"); ok {
 		ms.SetMetric("aws.msk.broker.CpuUser", cpuPercent, metric.GAUGE)
-		t.shim.aggregator.AddBrokerMetric("aws.msk.broker.CpuUser", cpuPercent)
+		t.shim.aggregator.AddSimpleBrokerMetric("aws.msk.broker.CpuUser", cpuPercent)
 	}
 
 	if memUsed, ok := getFloatValue(brokerData, "system.memoryUsedPercent"); ok {
 		ms.SetMetric("aws.msk.broker.MemoryUsed", memUsed, metric.GAUGE)
-		t.shim.aggregator.AddBrokerMetric("aws.msk.broker.MemoryUsed", memUsed)
+		t.shim.aggregator.AddSimpleBrokerMetric("aws.msk.broker.MemoryUsed", memUsed)
 	}
 
 	if diskUsed, ok := getFloatValue(brokerData, "system.diskUsedPercent"); ok {
 		ms.SetMetric("aws.msk.broker.RootDiskUsed", diskUsed, metric.GAUGE)
-		t.shim.aggregator.AddBrokerMetric("aws.msk.broker.RootDiskUsed", diskUsed)
+		t.shim.aggregator.AddSimpleBrokerMetric("aws.msk.broker.RootDiskUsed", diskUsed)
 	}
 
 	return nil
@@ -170,7 +170,7 @@ func (t *SimpleTransformer) TransformTopicMetricsSimple(topicData map[string]int
 			}
 			
 			// Also aggregate for cluster level
-			t.shim.aggregator.AddTopicMetric(topicName, mskMetric, value)
+			t.shim.aggregator.AddSimpleTopicMetric(topicName, mskMetric, value)
 		}
 	}
 
@@ -4,86 +4,197 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/nri-kafka/src/msk/sma"
 )
 
+// smoothingKeyTTL bounds how long a (brokerId|topic, target) SMA survives
+// without a new sample before smoothingOnce purges it, so a broker or
+// topic that disappears doesn't leak its smoothing state forever.
+const smoothingKeyTTL = 30 * time.Minute
+
+// TransformerConfig configures optional TransformerFixed behavior that
+// doesn't belong on every call site.
+type TransformerConfig struct {
+	// SmoothedMetrics maps a target metric name (as emitted by
+	// copyMetricValue, e.g. "aws.msk.BytesInPerSec") to the SMA window
+	// length, in samples, to smooth it over. Metrics absent from this map
+	// are emitted as-is with no .SMA variant.
+	SmoothedMetrics map[string]int
+}
+
 // TransformerFixed is an improved version of the transformer with correct metric mappings
 type TransformerFixed struct {
-	clusterName string
+	clusterName  string
+	config       TransformerConfig
+	requestTimes *RequestTimeAggregator
+
+	smoothedMu sync.RWMutex
+	smoothed   map[string]*sma.SMA
+	lastSeen   map[string]time.Time
 }
 
-// NewTransformerFixed creates a new fixed transformer
-func NewTransformerFixed(clusterName string) *TransformerFixed {
+// NewTransformerFixed creates a new fixed transformer. As a startup
+// validation pass, it also checks the broker metric registry for any
+// target whose name implies an aggregation semantic its Kind/Rollup
+// doesn't actually declare (see ValidateMetricMappingConsistency), since
+// that drift is otherwise invisible until a dashboard shows the wrong
+// number.
+func NewTransformerFixed(clusterName string, config TransformerConfig) *TransformerFixed {
+	ValidateMetricMappingConsistency()
 	return &TransformerFixed{
-		clusterName: clusterName,
-	}
-}
-
-// GetBrokerMetricMappings returns the correct mappings for broker metrics
-func (t *TransformerFixed) GetBrokerMetricMappings() map[string]string {
-	return map[string]string{
-		// Critical throughput metrics - these were wrong in original
-		"broker.messagesInPerSecond":          "aws.msk.MessagesInPerSec",
-		"broker.IOInPerSecond":                "aws.msk.BytesInPerSec",
-		"broker.IOOutPerSecond":               "aws.msk.BytesOutPerSec",
-		"broker.bytesWrittenToDiscPerSecond":  "aws.msk.BytesWrittenPerSec",
-		
-		// Fetch/Produce request rates
-		"broker.totalFetchRequestsPerSecond":   "aws.msk.FetchMessageConversionsPerSec",
-		"broker.totalProduceRequestsPerSecond": "aws.msk.ProduceMessageConversionsPerSec",
-		
-		// Replication health metrics
-		"replication.unreplicatedPartitions":   "aws.msk.UnderReplicatedPartitions",
-		"replication.isrShrinksPerSecond":      "aws.msk.IsrShrinksPerSec",
-		"replication.isrExpandsPerSecond":      "aws.msk.IsrExpandsPerSec",
-		"replication.leaderElectionPerSecond":  "aws.msk.LeaderElectionRateAndTimeMs",
-		
-		// Request performance metrics
-		"request.avgTimeFetch":                "aws.msk.RequestTime.Fetch.Mean",
-		"request.avgTimeProduceRequest":       "aws.msk.RequestTime.Produce.Mean",
-		"request.fetchTime99Percentile":       "aws.msk.FetchConsumerTotalTimeMs99thPercentile",
-		"request.produceTime99Percentile":     "aws.msk.ProduceTotalTimeMs99thPercentile",
-		"request.avgTimeUpdateMetadata":       "aws.msk.RequestTime.UpdateMetadata.Mean",
-		
-		// Network and partition metrics
-		"net.bytesRejectedPerSecond":          "aws.msk.NetworkRxDropped",
-		"broker.partitionCount":               "aws.msk.PartitionCount",
-		"controller.activeControllerCount":    "aws.msk.ActiveControllerCount",
-		"controller.offlinePartitionsCount":   "aws.msk.OfflinePartitionsCount",
-		
-		// Consumer metrics
-		"consumer.requestsExpiredPerSecond":   "aws.msk.ExpiredFetchResponsesPerSec",
-		"consumer.avgFetchSizeBytes":          "aws.msk.FetchMessageConversionsPerSec",
-		
-		// Disk usage metrics
-		"broker.logSize":                      "aws.msk.KafkaDataLogsDiskUsed",
-		"broker.diskUsedPercent":              "aws.msk.RootDiskUsed",
+		clusterName:  clusterName,
+		config:       config,
+		requestTimes: NewRequestTimeAggregator(),
+		smoothed:     make(map[string]*sma.SMA),
+		lastSeen:     make(map[string]time.Time),
 	}
 }
 
+// requestTimeAPIKeys maps each request.time.samples key TransformBrokerMetrics
+// looks for to the aws.msk.RequestTime.* metric name prefix it feeds.
+var requestTimeAPIKeys = map[string]string{
+	"fetch":          "Fetch",
+	"produce":        "Produce",
+	"updateMetadata": "UpdateMetadata",
+}
+
+// GetBrokerMetricMappings returns the broker-level metric registry. It
+// used to return a plain map[string]string, which left every metric's
+// aggregation behavior implicit; it now returns BrokerMetricSpecs(),
+// which pairs each mapping with a RollupMetricKind/RollupKind so the
+// Aggregator knows how to roll it up the partition->topic->broker->
+// cluster hierarchy instead of guessing.
+func (t *TransformerFixed) GetBrokerMetricMappings() []MetricSpec {
+	return BrokerMetricSpecs()
+}
+
 // TransformBrokerMetrics transforms broker metrics with proper value handling
 func (t *TransformerFixed) TransformBrokerMetrics(inputMetrics map[string]interface{}, outputSet *metric.Set) error {
 	log.Debug("TransformBrokerMetrics: Starting transformation for cluster %s", t.clusterName)
-	
+
 	// First, ensure critical attributes are set
 	t.ensureBrokerAttributes(inputMetrics, outputSet)
-	
-	// Transform metrics using correct mappings
-	mappings := t.GetBrokerMetricMappings()
-	for sourceMetric, targetMetric := range mappings {
-		if err := t.copyMetricValue(sourceMetric, targetMetric, inputMetrics, outputSet); err != nil {
-			log.Debug("Failed to copy metric %s: %v", sourceMetric, err)
+
+	brokerID := extractBrokerID(inputMetrics)
+
+	// Transform metrics using the broker metric registry
+	for _, spec := range t.GetBrokerMetricMappings() {
+		if err := t.copyMetricValue(spec.Source, spec.Target, inputMetrics, outputSet); err != nil {
+			log.Debug("Failed to copy metric %s: %v", spec.Source, err)
+			continue
+		}
+
+		// Per-second JMX gauges are spiky between scrapes and flap alerts
+		// downstream; TransformerConfig opts individual targets into an
+		// additional smoothed ".SMA" variant alongside the raw value.
+		if window, ok := t.config.SmoothedMetrics[spec.Target]; ok {
+			if raw, exists := inputMetrics[spec.Source]; exists && raw != nil {
+				if floatVal, convErr := toFloat64(raw); convErr == nil {
+					t.emitSmoothed(brokerID, spec.Target, window, floatVal, outputSet)
+				}
+			}
 		}
 	}
-	
+
 	// Add provider-specific metrics
 	t.addProviderMetrics(inputMetrics, outputSet)
-	
+
+	// request.fetchTime99Percentile/request.produceTime99Percentile above
+	// are JMX's own pre-baked percentiles; when raw per-request samples
+	// are present too, recompute p50/p95/p99/p999 ourselves instead of
+	// trusting whatever window JMX happened to use.
+	t.recordRequestTimeSamples(brokerID, inputMetrics, outputSet)
+
 	return nil
 }
 
+// extractBrokerID pulls the broker ID out of entityName ("broker:ID"),
+// the same way ensureBrokerAttributes does, returning "" if absent.
+func extractBrokerID(inputMetrics map[string]interface{}) string {
+	entityName, ok := inputMetrics["entityName"].(string)
+	if !ok || !strings.HasPrefix(entityName, "broker:") {
+		return ""
+	}
+	return strings.TrimPrefix(entityName, "broker:")
+}
+
+// recordRequestTimeSamples feeds any raw request.time.samples present in
+// inputMetrics into t.requestTimes and, once a histogram has samples,
+// emits its p50/p95/p99/p999 as aws.msk.RequestTime.<API>.Pxx. Absent
+// raw samples, this is a no-op and TransformBrokerMetrics' pass-through
+// of request.fetchTime99Percentile/request.produceTime99Percentile above
+// remains the only source for those metrics.
+func (t *TransformerFixed) recordRequestTimeSamples(brokerID string, inputMetrics map[string]interface{}, outputSet *metric.Set) {
+	samplesByAPI, ok := inputMetrics["request.time.samples"].(map[string][]float64)
+	if !ok {
+		return
+	}
+
+	for sampleKey, metricPrefix := range requestTimeAPIKeys {
+		samples, ok := samplesByAPI[sampleKey]
+		if !ok {
+			continue
+		}
+		for _, micros := range samples {
+			t.requestTimes.RecordMicros(t.clusterName, brokerID, sampleKey, int64(micros))
+		}
+
+		percentiles, ok := t.requestTimes.Percentiles(t.clusterName, brokerID, sampleKey)
+		if !ok {
+			continue
+		}
+		outputSet.SetMetric(fmt.Sprintf("aws.msk.RequestTime.%s.P50", metricPrefix), percentiles.P50, metric.GAUGE)
+		outputSet.SetMetric(fmt.Sprintf("aws.msk.RequestTime.%s.P95", metricPrefix), percentiles.P95, metric.GAUGE)
+		outputSet.SetMetric(fmt.Sprintf("aws.msk.RequestTime.%s.P99", metricPrefix), percentiles.P99, metric.GAUGE)
+		outputSet.SetMetric(fmt.Sprintf("aws.msk.RequestTime.%s.P999", metricPrefix), percentiles.P999, metric.GAUGE)
+	}
+}
+
+// emitSmoothed feeds value into the SMA tracked for (entityKey, target),
+// sized to windowSize samples (falling back to sma.DefaultWindowSize if
+// windowSize isn't positive), and writes the resulting average as
+// "<target>.SMA<windowSize>" onto outputSet alongside the raw value
+// copyMetricValue already wrote.
+func (t *TransformerFixed) emitSmoothed(entityKey, target string, windowSize int, value float64, outputSet *metric.Set) {
+	if windowSize < 1 {
+		windowSize = sma.DefaultWindowSize
+	}
+	key := entityKey + "|" + target
+	now := time.Now()
+
+	t.smoothedMu.Lock()
+	s, ok := t.smoothed[key]
+	if !ok {
+		s = sma.New(windowSize)
+		t.smoothed[key] = s
+	}
+	s.Add(value)
+	t.lastSeen[key] = now
+	smoothedValue := s.Value()
+	t.purgeStaleSmoothingLocked(now)
+	t.smoothedMu.Unlock()
+
+	outputSet.SetMetric(fmt.Sprintf("%s.SMA%d", target, windowSize), smoothedValue, metric.GAUGE)
+}
+
+// purgeStaleSmoothingLocked drops any SMA whose key hasn't been fed a
+// sample in smoothingKeyTTL, so a broker or topic that stops reporting
+// doesn't hold its smoothing state in memory forever. Callers must hold
+// smoothedMu.
+func (t *TransformerFixed) purgeStaleSmoothingLocked(now time.Time) {
+	for key, seenAt := range t.lastSeen {
+		if now.Sub(seenAt) > smoothingKeyTTL {
+			delete(t.lastSeen, key)
+			delete(t.smoothed, key)
+		}
+	}
+}
+
 // ensureBrokerAttributes adds missing broker attributes
 func (t *TransformerFixed) ensureBrokerAttributes(inputMetrics map[string]interface{}, outputSet *metric.Set) {
 	// Fix missing broker_host
@@ -162,19 +273,10 @@ func (t *TransformerFixed) TransformTopicMetrics(topicName string, inputMetrics
 	outputSet.SetMetric("provider.topic", topicName, metric.ATTRIBUTE)
 	outputSet.SetMetric("provider.clusterName", t.clusterName, metric.ATTRIBUTE)
 	
-	// Transform topic metrics
-	topicMappings := map[string]string{
-		"topic.bytesInPerSecond":       "provider.bytesInPerSec.Sum",
-		"topic.bytesOutPerSecond":      "provider.bytesOutPerSec.Sum",
-		"topic.messagesInPerSecond":    "provider.messagesInPerSec.Sum",
-		"topic.partitionCount":         "provider.partitionCount",
-		"topic.replicationFactor":      "provider.replicationFactor",
-		"topic.underReplicatedPartitions": "provider.underReplicatedPartitions",
-	}
-	
-	for source, target := range topicMappings {
-		if err := t.copyMetricValue(source, target, inputMetrics, outputSet); err != nil {
-			log.Debug("Topic metric %s not available: %v", source, err)
+	// Transform topic metrics using the topic metric registry
+	for _, spec := range TopicMetricSpecs() {
+		if err := t.copyMetricValue(spec.Source, spec.Target, inputMetrics, outputSet); err != nil {
+			log.Debug("Topic metric %s not available: %v", spec.Source, err)
 		}
 	}
 	
@@ -200,27 +302,69 @@ func (t *TransformerFixed) TransformClusterMetrics(aggregatedData map[string]int
 	outputSet.SetMetric("clusterName", t.clusterName, metric.ATTRIBUTE)
 	outputSet.SetMetric("provider.clusterName", t.clusterName, metric.ATTRIBUTE)
 	
-	// Transform cluster-level metrics
-	clusterMappings := map[string]string{
-		"totalBrokers":               "provider.brokerCount",
-		"totalTopics":                "provider.topicCount",
-		"totalPartitions":            "provider.globalPartitionCount.Average",
-		"activeControllerCount":      "provider.activeControllerCount.Sum",
-		"offlinePartitionsCount":     "provider.offlinePartitionsCount.Sum",
-		"underReplicatedPartitions":  "provider.underReplicatedPartitions.Sum",
-	}
-	
-	for source, target := range clusterMappings {
-		if value, ok := aggregatedData[source]; ok && value != nil {
+	// Transform cluster-level metrics using the cluster metric registry
+	for _, spec := range ClusterMetricSpecs() {
+		if value, ok := aggregatedData[spec.Source]; ok && value != nil {
 			if floatVal, err := toFloat64(value); err == nil {
-				outputSet.SetMetric(target, floatVal, metric.GAUGE)
+				outputSet.SetMetric(spec.Target, floatVal, metric.GAUGE)
 			}
 		}
 	}
-	
+
+	// Fleet-wide request-time rollup: merge every broker's histogram for
+	// each API into one cluster-wide distribution instead of averaging
+	// each broker's own p99, which hides a single slow broker behind a
+	// healthy fleet-wide mean.
+	for sampleKey, metricPrefix := range requestTimeAPIKeys {
+		percentiles, ok := t.requestTimes.FleetWide(t.clusterName, sampleKey)
+		if !ok {
+			continue
+		}
+		outputSet.SetMetric(fmt.Sprintf("aws.msk.RequestTime.%s.P50", metricPrefix), percentiles.P50, metric.GAUGE)
+		outputSet.SetMetric(fmt.Sprintf("aws.msk.RequestTime.%s.P95", metricPrefix), percentiles.P95, metric.GAUGE)
+		outputSet.SetMetric(fmt.Sprintf("aws.msk.RequestTime.%s.P99", metricPrefix), percentiles.P99, metric.GAUGE)
+		outputSet.SetMetric(fmt.Sprintf("aws.msk.RequestTime.%s.P999", metricPrefix), percentiles.P999, metric.GAUGE)
+	}
+
 	return nil
 }
 
+// EmitBrokerSample writes one broker's rolled-up metrics, as produced by
+// RollupAggregator.Flush, onto a KafkaBrokerSample outputSet. Like
+// TransformBrokerMetrics, it emits everything as metric.GAUGE: a
+// RollupResult's values have already been reduced per their MetricSpec's
+// Kind/Rollup, so by the time they reach here they're all just numbers.
+func (t *TransformerFixed) EmitBrokerSample(brokerID string, values RollupResult, outputSet *metric.Set) {
+	outputSet.SetMetric("clusterName", t.clusterName, metric.ATTRIBUTE)
+	outputSet.SetMetric("provider.clusterName", t.clusterName, metric.ATTRIBUTE)
+	outputSet.SetMetric("brokerId", brokerID, metric.ATTRIBUTE)
+	outputSet.SetMetric("provider.brokerId", brokerID, metric.ATTRIBUTE)
+	for target, value := range values {
+		outputSet.SetMetric(target, value, metric.GAUGE)
+	}
+}
+
+// EmitTopicSample writes one topic's rolled-up metrics onto a
+// KafkaTopicSample outputSet.
+func (t *TransformerFixed) EmitTopicSample(topicName string, values RollupResult, outputSet *metric.Set) {
+	outputSet.SetMetric("topic", topicName, metric.ATTRIBUTE)
+	outputSet.SetMetric("provider.topic", topicName, metric.ATTRIBUTE)
+	outputSet.SetMetric("provider.clusterName", t.clusterName, metric.ATTRIBUTE)
+	for target, value := range values {
+		outputSet.SetMetric(target, value, metric.GAUGE)
+	}
+}
+
+// EmitClusterSample writes the cluster-wide rolled-up metrics onto a
+// KafkaClusterSample outputSet.
+func (t *TransformerFixed) EmitClusterSample(values RollupResult, outputSet *metric.Set) {
+	outputSet.SetMetric("clusterName", t.clusterName, metric.ATTRIBUTE)
+	outputSet.SetMetric("provider.clusterName", t.clusterName, metric.ATTRIBUTE)
+	for target, value := range values {
+		outputSet.SetMetric(target, value, metric.GAUGE)
+	}
+}
+
 // toFloat64 converts various types to float64
 func toFloat64(value interface{}) (float64, error) {
 	switch v := value.(type) {
@@ -106,6 +106,40 @@ func getIntValueWithDefault(data map[string]interface{}, key string, defaultValu
 	return defaultValue
 }
 
+// metricValidationRule declares the abnormal-value checks
+// validateMetricValue runs for one metric, beyond the universal NaN/Inf
+// and negative-value checks every metric gets. ExpectedSet models a
+// small enumerated set of sane values (e.g. ActiveControllerCount is 0 or
+// 1); MaxValue models an upper bound past which the value is merely
+// logged as suspicious, not rejected; CriticalIfPositive models metrics
+// where any positive value is itself the alert (e.g. offline partitions).
+type metricValidationRule struct {
+	ExpectedSet        []float64
+	MaxValue           *float64
+	CriticalIfPositive bool
+}
+
+// metricValidationRules is the table validateMetricValue consults,
+// keyed by AWS MSK target metric name. New metrics register their bounds
+// here instead of adding a new switch case; a MetricSchema's
+// MinValue/MaxValue (RegisterBrokerMetricSchema) are not consulted.
+var metricValidationRules = map[string]metricValidationRule{
+	"kafka.cluster.ActiveControllerCount":        {ExpectedSet: []float64{0, 1}},
+	"kafka.cluster.OfflinePartitionsCount":       {CriticalIfPositive: true},
+	"kafka.broker.BytesInPerSec":                 {MaxValue: floatPtr(10 * 1024 * 1024 * 1024)},
+	"kafka.broker.BytesOutPerSec":                {MaxValue: floatPtr(10 * 1024 * 1024 * 1024)},
+	"kafka.broker.MessagesInPerSec":              {MaxValue: floatPtr(10000000)},
+	"kafka.consumer.MaxLag":                      {MaxValue: floatPtr(1000000)},
+	"kafka.consumer.TotalLag":                    {MaxValue: floatPtr(1000000)},
+	"kafka.partition.ReassignmentInProgress":     {ExpectedSet: []float64{0, 1}},
+	"kafka.partition.ReassignmentBytesRemaining": {MaxValue: floatPtr(10 * 1024 * 1024 * 1024)},
+	"kafka.partition.AddingReplicas":             {MaxValue: floatPtr(10)},
+	"kafka.partition.RemovingReplicas":           {MaxValue: floatPtr(10)},
+	"kafka.partition.ReplicaLagMs":               {MaxValue: floatPtr(600000)},
+	"kafka.consumer.LagRatio":                    {MaxValue: floatPtr(100)},
+	"kafka.cluster.ControllerChanges":            {MaxValue: floatPtr(1000)},
+}
+
 // validateMetricValue validates metric values - standalone helper
 func validateMetricValue(value float64, metricName string) bool {
 	// Check for NaN/Inf
@@ -113,40 +147,34 @@ func validateMetricValue(value float64, metricName string) bool {
 		log.Warn("Invalid float value for metric %s: %f", metricName, value)
 		return false
 	}
-	
+
 	// Check for negative values (most metrics should be non-negative)
 	if value < 0 {
 		log.Debug("Negative value for metric %s: %f", metricName, value)
 		return false
 	}
-	
-	// Metric-specific validations
-	switch metricName {
-	case "kafka.cluster.ActiveControllerCount":
-		if value != 0 && value != 1 {
-			log.Warn("Abnormal ActiveControllerCount: %f (expected 0 or 1)", value)
+
+	if rule, ok := metricValidationRules[metricName]; ok {
+		if len(rule.ExpectedSet) > 0 {
+			matched := false
+			for _, expected := range rule.ExpectedSet {
+				if value == expected {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				log.Warn("Abnormal %s: %f (expected one of %v)", metricName, value, rule.ExpectedSet)
+			}
 		}
-	case "kafka.cluster.OfflinePartitionsCount":
-		if value > 0 {
+		if rule.CriticalIfPositive && value > 0 {
 			log.Error("CRITICAL: %f offline partitions detected", value)
 		}
-	case "kafka.broker.BytesInPerSec", "kafka.broker.BytesOutPerSec":
-		// Check for unreasonable throughput (> 10GB/sec)
-		if value > 10*1024*1024*1024 {
-			log.Warn("Unusually high throughput: %f bytes/sec", value)
-		}
-	case "kafka.broker.MessagesInPerSec":
-		// Check for unreasonable message rate (> 10M msgs/sec)
-		if value > 10000000 {
-			log.Warn("Unusually high message rate: %f msgs/sec", value)
-		}
-	case "kafka.consumer.MaxLag", "kafka.consumer.TotalLag":
-		// Very high lag (> 1M) should log warning
-		if value > 1000000 {
-			log.Warn("Very high consumer lag detected: %f", value)
+		if rule.MaxValue != nil && value > *rule.MaxValue {
+			log.Warn("Unusually high value for %s: %f (exceeds %f)", metricName, value, *rule.MaxValue)
 		}
 	}
-	
+
 	return true
 }
 
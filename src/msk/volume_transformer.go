@@ -0,0 +1,53 @@
+package msk
+
+import (
+	"fmt"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+)
+
+// VolumeData is one broker log directory's storage signal, as collected
+// by the broker package's LogDirCollector.
+type VolumeData struct {
+	BrokerID       int32
+	LogDirPath     string
+	BytesUsed      int64
+	RootDiskUsed   float64
+	ReadBytesRate  float64
+	WriteBytesRate float64
+}
+
+// TransformVolumeData emits an AwsMskVolumeSample for a single broker log
+// directory. AWS MSK brokers frequently have multiple EBS volumes, so
+// unlike broker/topic/cluster samples this is emitted once per log
+// directory rather than once per broker.
+func (s *MSKShim) TransformVolumeData(data VolumeData) error {
+	entityName := fmt.Sprintf("%s-broker-%d-volume-%s", s.config.ClusterName, data.BrokerID, data.LogDirPath)
+	entity, err := s.integration.Entity(entityName, "KAFKA_VOLUME")
+	if err != nil {
+		return fmt.Errorf("failed to create volume entity: %v", err)
+	}
+
+	guid := GenerateEntityGUID(EntityTypeVolume, s.config.AWSAccountID, s.config.ClusterName, VolumeIdentifier{
+		BrokerID:   data.BrokerID,
+		LogDirPath: data.LogDirPath,
+	})
+
+	ms := entity.NewMetricSet("AwsMskVolumeSample",
+		attribute.Attribute{Key: "provider.accountId", Value: s.config.AWSAccountID},
+		attribute.Attribute{Key: "provider.region", Value: s.config.AWSRegion},
+		attribute.Attribute{Key: "provider.clusterName", Value: s.config.ClusterName},
+		attribute.Attribute{Key: "provider.brokerId", Value: fmt.Sprintf("%d", data.BrokerID)},
+		attribute.Attribute{Key: "provider.logDirPath", Value: data.LogDirPath},
+		attribute.Attribute{Key: "entityName", Value: entityName},
+		attribute.Attribute{Key: "entityGuid", Value: guid},
+	)
+
+	ms.SetMetric("provider.kafkaDataLogsDiskUsed", float64(data.BytesUsed), metric.GAUGE)
+	ms.SetMetric("provider.rootDiskUsed", data.RootDiskUsed, metric.GAUGE)
+	ms.SetMetric("provider.volumeReadBytes", data.ReadBytesRate, metric.GAUGE)
+	ms.SetMetric("provider.volumeWriteBytes", data.WriteBytesRate, metric.GAUGE)
+
+	return nil
+}
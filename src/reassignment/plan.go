@@ -0,0 +1,113 @@
+// Package reassignment drives KIP-455 partition reassignments against a
+// live cluster: listing in-flight moves for the metrics path, and
+// submitting or cancelling a desired-state plan for the command-mode
+// path (analogous to how the broker package's worker pools are started
+// from main, but invoked once rather than run continuously).
+package reassignment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// Plan is the desired-state reassignment document a command-mode caller
+// submits: for each topic/partition, the full list of replica broker IDs
+// it should end up on. A partition can be removed from an in-flight plan
+// (cancelled) by submitting it with an empty Replicas list.
+type Plan struct {
+	Assignments []PartitionAssignment `json:"assignments"`
+}
+
+// PartitionAssignment is one topic/partition's desired replica set.
+type PartitionAssignment struct {
+	Topic     string  `json:"topic"`
+	Partition int32   `json:"partition"`
+	Replicas  []int32 `json:"replicas"`
+}
+
+// MaxConcurrentPartitions caps how many partitions a single Submit call
+// will touch, as a guardrail against accidentally kicking off a
+// cluster-wide rebalance in one shot.
+const MaxConcurrentPartitions = 50
+
+// LoadPlan reads a desired-state reassignment plan from a JSON file.
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading reassignment plan %s: %w", path, err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("parsing reassignment plan %s: %w", path, err)
+	}
+	return &plan, nil
+}
+
+// Submitter submits and cancels KIP-455 partition reassignments via a
+// Sarama ClusterAdmin.
+type Submitter struct {
+	admin       sarama.ClusterAdmin
+	maxPartitions int
+}
+
+// NewSubmitter wraps an already-connected ClusterAdmin. maxPartitions
+// overrides MaxConcurrentPartitions when non-zero.
+func NewSubmitter(admin sarama.ClusterAdmin, maxPartitions int) *Submitter {
+	if maxPartitions <= 0 {
+		maxPartitions = MaxConcurrentPartitions
+	}
+	return &Submitter{admin: admin, maxPartitions: maxPartitions}
+}
+
+// Submit applies plan via AlterPartitionReassignments, refusing to submit
+// a plan that touches more partitions than the configured safety limit.
+func (s *Submitter) Submit(plan *Plan) error {
+	if len(plan.Assignments) > s.maxPartitions {
+		return fmt.Errorf("reassignment plan touches %d partitions, exceeding the safety limit of %d; split it into smaller batches", len(plan.Assignments), s.maxPartitions)
+	}
+
+	for _, a := range plan.Assignments {
+		replicas := a.Replicas
+		if len(replicas) == 0 {
+			log.Info("reassignment: cancelling in-flight move for %s[%d]", a.Topic, a.Partition)
+		} else {
+			log.Info("reassignment: submitting move for %s[%d] -> %v", a.Topic, a.Partition, replicas)
+		}
+
+		if err := s.admin.AlterPartitionReassignments(a.Topic, [][]int32{replicas}); err != nil {
+			return fmt.Errorf("submitting reassignment for %s[%d]: %w", a.Topic, a.Partition, err)
+		}
+	}
+
+	return nil
+}
+
+// Cancel is a convenience wrapper that submits an empty-replica plan for
+// every assignment in plan, cancelling each in-flight move.
+func (s *Submitter) Cancel(plan *Plan) error {
+	cancelPlan := &Plan{Assignments: make([]PartitionAssignment, len(plan.Assignments))}
+	for i, a := range plan.Assignments {
+		cancelPlan.Assignments[i] = PartitionAssignment{Topic: a.Topic, Partition: a.Partition}
+	}
+	return s.Submit(cancelPlan)
+}
+
+// RunCommand is the command-mode entry point: it loads a plan from
+// planPath and submits it through admin, mirroring the way broker pools
+// are started from main but executing once rather than running a
+// continuous worker loop.
+func RunCommand(admin sarama.ClusterAdmin, planPath string, maxPartitions int) error {
+	plan, err := LoadPlan(planPath)
+	if err != nil {
+		return err
+	}
+
+	submitter := NewSubmitter(admin, maxPartitions)
+	return submitter.Submit(plan)
+}
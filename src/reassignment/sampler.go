@@ -0,0 +1,68 @@
+package reassignment
+
+import (
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"github.com/newrelic/nri-kafka/src/msk"
+)
+
+// SaramaAdminAdapter implements msk.ReassignmentAdminClient against a real
+// Sarama ClusterAdmin, so msk.ReassignmentCollector can poll an actual
+// cluster's in-flight KIP-455 reassignments instead of a test double.
+type SaramaAdminAdapter struct {
+	admin sarama.ClusterAdmin
+}
+
+// NewSaramaAdminAdapter wraps an already-connected ClusterAdmin.
+func NewSaramaAdminAdapter(admin sarama.ClusterAdmin) *SaramaAdminAdapter {
+	return &SaramaAdminAdapter{admin: admin}
+}
+
+// ListPartitionReassignments returns every in-flight reassignment the
+// controller currently knows about.
+func (a *SaramaAdminAdapter) ListPartitionReassignments() ([]msk.ReplicaReassignment, error) {
+	statuses, err := a.admin.ListPartitionReassignments("", nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing partition reassignments: %w", err)
+	}
+
+	var out []msk.ReplicaReassignment
+	for topic, partitions := range statuses {
+		for partition, status := range partitions {
+			out = append(out, msk.ReplicaReassignment{
+				Topic:            topic,
+				Partition:        partition,
+				AddingReplicas:   status.AddingReplicas,
+				RemovingReplicas: status.RemovingReplicas,
+			})
+		}
+	}
+	return out, nil
+}
+
+// ReplicaLogEndOffset returns the log-end-offset for topic/partition on
+// brokerID by querying that broker directly, used to derive bytes
+// remaining in an in-flight move.
+func (a *SaramaAdminAdapter) ReplicaLogEndOffset(topic string, partition int32, brokerID int32) (int64, error) {
+	dirs, err := a.admin.DescribeLogDirs([]int32{brokerID})
+	if err != nil {
+		return 0, fmt.Errorf("describing log dirs on broker %d: %w", brokerID, err)
+	}
+
+	for _, dir := range dirs[brokerID] {
+		for _, topicDir := range dir.Topics {
+			if topicDir.Topic != topic {
+				continue
+			}
+			for _, partDir := range topicDir.Partitions {
+				if partDir.PartitionID == partition {
+					return partDir.Size, nil
+				}
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("partition %s[%d] not found on broker %d", topic, partition, brokerID)
+}